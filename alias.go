@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// builtinAliases are short names for common commands. Aliases in the config file can add more,
+// and can override these, but not the commands themselves.
+var builtinAliases = map[string]string{
+	"b":     "browse",
+	"f":     "follow",
+	"today": "browse --since today --limit 50",
+}
+
+// maxAliasDepth bounds how many aliases can expand into one another, so that a loop is reported
+// instead of running forever
+const maxAliasDepth = 10
+
+// alias returns what an alias expands to, checking the config's aliases before the built-in ones
+func (c *commands) alias(name string) (string, bool) {
+	if expansion, ok := c.aliases[name]; ok {
+		return expansion, true
+	}
+	expansion, ok := builtinAliases[name]
+	return expansion, ok
+}
+
+// expand replaces an alias with the command it stands for, keeping the arguments given after it.
+// A command name is returned unchanged, as is an unknown name, for check to report.
+func (c *commands) expand(cmd command) (command, error) {
+	alias := cmd.name
+	for range maxAliasDepth {
+		if _, exists := c.handlers[cmd.name]; exists {
+			return cmd, nil
+		}
+		expansion, ok := c.alias(cmd.name)
+		if !ok {
+			return cmd, nil
+		}
+
+		words, err := splitArgs(expansion)
+		if err != nil {
+			return cmd, fmt.Errorf("invalid alias %s: %w", cmd.name, err)
+		}
+		if len(words) == 0 {
+			return cmd, fmt.Errorf("alias %s is empty", cmd.name)
+		}
+		cmd = command{name: words[0], args: append(words[1:], cmd.args...)}
+	}
+	if _, ok := c.alias(cmd.name); ok {
+		return cmd, fmt.Errorf("alias %s expands into itself", alias)
+	}
+	return cmd, nil
+}
+
+// aliasInfo describes an alias for help
+type aliasInfo struct {
+	Name      string `json:"name"`
+	Expansion string `json:"expansion"`
+}
+
+// aliasList lists the aliases in effect, sorted by name, with config aliases in place of the
+// built-in ones they override
+func (c *commands) aliasList() []aliasInfo {
+	all := maps.Clone(builtinAliases)
+	maps.Copy(all, c.aliases)
+
+	list := []aliasInfo{}
+	for _, name := range slices.Sorted(maps.Keys(all)) {
+		if _, exists := c.handlers[name]; !exists {
+			list = append(list, aliasInfo{Name: name, Expansion: all[name]})
+		}
+	}
+	return list
+}
+
+func (a aliasInfo) writeText(w io.Writer) {
+	fmt.Fprintf(w, "%s is an alias for: gator %s\n", a.Name, a.Expansion)
+}
+
+func (a aliasInfo) table() ([]string, [][]string) {
+	return []string{"name", "expansion"}, [][]string{{a.Name, a.Expansion}}
+}
+
+// splitArgs splits an alias into words like a shell would: on spaces, except inside single or
+// double quotes, with a backslash escaping the next character outside single quotes
+func splitArgs(s string) ([]string, error) {
+	words := []string{}
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, errors.New("ends with a backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
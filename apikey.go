@@ -0,0 +1,230 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// API key scopes: read keys may only make GET requests
+const (
+	apiKeyScopeRead  = "read"
+	apiKeyScopeWrite = "write"
+)
+
+// apiTokenPrefix marks gator tokens so they're easy to spot in config files and secret scanners
+const apiTokenPrefix = "gator_"
+
+// handlerAPIKey dispatches API key management subcommands
+func handlerAPIKey(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("apikey command requires a subcommand: create, list, revoke, show")
+	}
+
+	sub := command{name: cmd.args[0], args: cmd.args[1:]}
+	switch sub.name {
+	case "create":
+		return handlerAPIKeyCreate(s, sub, user)
+	case "list":
+		return handlerAPIKeyList(s, sub, user)
+	case "revoke":
+		return handlerAPIKeyRevoke(s, sub, user)
+	case "show":
+		return handlerAPIKeyShow(s, sub, user)
+	default:
+		return fmt.Errorf("unknown apikey subcommand: %s", sub.name)
+	}
+}
+
+// handlerAPIKeyCreate generates a named token for the HTTP API. Only its hash is stored, so the
+// token is shown once.
+func handlerAPIKeyCreate(s *state, cmd command, user database.User) error {
+	flags := newFlagSet("apikey create")
+	var scope string
+	flags.StringVar(&scope, "scope", apiKeyScopeRead, "What the key may do: `read|write`")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return errors.New("apikey create requires a name argument")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected apikey create argument %q", args[1])
+	}
+	name := args[0]
+
+	if scope != apiKeyScopeRead && scope != apiKeyScopeWrite {
+		return fmt.Errorf("unknown scope %q (expected %s or %s)", scope, apiKeyScopeRead, apiKeyScopeWrite)
+	}
+
+	token, err := newAPIToken()
+	if err != nil {
+		return fmt.Errorf("couldn't generate token: %w", err)
+	}
+
+	key, err := s.db.CreateAPIKey(s.ctx, database.CreateAPIKeyParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		Name:      name,
+		TokenHash: hashAPIToken(token),
+		Scope:     scope,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return fmt.Errorf("you already have an API key named %s", name)
+		}
+		return fmt.Errorf("couldn't create API key: %w", err)
+	}
+
+	return s.emit(apiKeyCreateResult{apiKeyEntry: toAPIKeyEntry(key), Token: token})
+}
+
+// handlerAPIKeyList shows the current user's API keys, without their tokens
+func handlerAPIKeyList(s *state, cmd command, user database.User) error {
+	keys, err := s.db.GetAPIKeysForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get API keys: %w", err)
+	}
+
+	res := apiKeysResult{Keys: []apiKeyEntry{}}
+	for _, key := range keys {
+		res.Keys = append(res.Keys, toAPIKeyEntry(key))
+	}
+	return s.emit(res)
+}
+
+// handlerAPIKeyRevoke deletes one of the current user's API keys by name
+func handlerAPIKeyRevoke(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("apikey revoke requires a name argument")
+	}
+
+	n, err := s.db.DeleteAPIKey(s.ctx, database.DeleteAPIKeyParams{
+		UserID: user.ID,
+		Name:   cmd.args[0],
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't revoke API key: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("API key %s doesn't exist", cmd.args[0])
+	}
+
+	return s.emit(messageResult{Message: fmt.Sprintf("API key revoked: %s", cmd.args[0])})
+}
+
+// handlerAPIKeyShow prints the current user's main API key, the one register shows, after
+// asking for their password if they have one
+func handlerAPIKeyShow(s *state, cmd command, user database.User) error {
+	if len(cmd.args) > 0 {
+		return fmt.Errorf("unexpected apikey show argument %q", cmd.args[0])
+	}
+	if err := verifyUserPassword(s, user); err != nil {
+		return err
+	}
+
+	resp := toAPIUser(user)
+	resp.ApiKey = user.ApiKey
+	return s.emit(messageResult{Message: fmt.Sprintf("API key for %s: %s", user.Name, user.ApiKey), Item: resp})
+}
+
+// newUserAPIKey returns a random main API key for a new user. Unlike tokens it has no prefix,
+// since it's also the Fever and Google Reader password.
+func newUserAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// newAPIToken returns a random bearer token
+func newAPIToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiTokenPrefix + hex.EncodeToString(b), nil
+}
+
+// hashAPIToken is how tokens are stored and looked up. Tokens are random, so a plain SHA-256
+// is enough; there's nothing to brute-force the way there is with passwords.
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyEntry is an API key in the apikey listing
+type apiKeyEntry struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Scope      string     `json:"scope"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func toAPIKeyEntry(key database.ApiKey) apiKeyEntry {
+	return apiKeyEntry{
+		ID:         key.ID,
+		Name:       key.Name,
+		Scope:      key.Scope,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: nullTimePtr(key.LastUsedAt),
+	}
+}
+
+// apiKeyCreateResult is the output of apikey create
+type apiKeyCreateResult struct {
+	apiKeyEntry
+	Token string `json:"token"`
+}
+
+func (r apiKeyCreateResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Created %s API key %s:\n", r.Scope, r.Name)
+	fmt.Fprintf(w, "  %s\n", r.Token)
+	fmt.Fprintln(w, "Store it now; it won't be shown again. Use it as 'Authorization: Bearer <token>'.")
+}
+
+func (r apiKeyCreateResult) table() ([]string, [][]string) {
+	return []string{"id", "name", "scope", "token"}, [][]string{{r.ID.String(), r.Name, r.Scope, r.Token}}
+}
+
+// apiKeysResult is the output of apikey list
+type apiKeysResult struct {
+	Keys []apiKeyEntry `json:"keys"`
+}
+
+func (r apiKeysResult) writeText(w io.Writer) {
+	if len(r.Keys) == 0 {
+		fmt.Fprintln(w, "No API keys found")
+		return
+	}
+
+	for _, key := range r.Keys {
+		lastUsed := "never"
+		if key.LastUsedAt != nil {
+			lastUsed = key.LastUsedAt.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "* %s (%s) - created %s, last used %s\n", key.Name, key.Scope, key.CreatedAt.Format("2006-01-02"), lastUsed)
+	}
+}
+
+func (r apiKeysResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, key := range r.Keys {
+		rows = append(rows, []string{key.ID.String(), key.Name, key.Scope, formatTime(&key.CreatedAt), formatTime(key.LastUsedAt)})
+	}
+	return []string{"id", "name", "scope", "created_at", "last_used_at"}, rows
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Utkarsh736/gator/internal/database"
+)
+
+// bulkEntry is what happened to one line of a --from-file list. Status is followed or
+// unfollowed, already (following, or not following for unfollow), or failed.
+type bulkEntry struct {
+	Ref    string `json:"ref"`
+	Feed   string `json:"feed,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// bulkResult is the output of follow and unfollow with --from-file
+type bulkResult struct {
+	Action  string      `json:"action"`
+	Entries []bulkEntry `json:"entries"`
+}
+
+// counts returns how many entries were done, already done, and failed
+func (r bulkResult) counts() (done, already, failed int) {
+	for _, entry := range r.Entries {
+		switch entry.Status {
+		case "already":
+			already++
+		case "failed":
+			failed++
+		default:
+			done++
+		}
+	}
+	return done, already, failed
+}
+
+func (r bulkResult) writeText(w io.Writer) {
+	for _, entry := range r.Entries {
+		switch entry.Status {
+		case "failed":
+			fmt.Fprintf(w, "! %s: %s\n", entry.Ref, entry.Error)
+		case "already":
+			if r.Action == "follow" {
+				fmt.Fprintf(w, "= %s (already following)\n", entry.Feed)
+			} else {
+				fmt.Fprintf(w, "= %s (not following)\n", entry.Feed)
+			}
+		default:
+			fmt.Fprintf(w, "+ %s\n", entry.Feed)
+		}
+	}
+
+	done, already, failed := r.counts()
+	if r.Action == "follow" {
+		fmt.Fprintf(w, "Followed %d feeds, %d already followed, %d failed\n", done, already, failed)
+	} else {
+		fmt.Fprintf(w, "Unfollowed %d feeds, %d not followed, %d failed\n", done, already, failed)
+	}
+}
+
+func (r bulkResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, entry := range r.Entries {
+		rows = append(rows, []string{entry.Ref, entry.Feed, entry.Status, entry.Error})
+	}
+	return []string{"ref", "feed", "status", "error"}, rows
+}
+
+// readFeedList reads the feed URLs, names or IDs in path, or stdin for "-", one per line.
+// Blank lines and lines starting with # are skipped.
+func readFeedList(path string) ([]string, error) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't open feed list: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	refs := []string{}
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		refs = append(refs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't read feed list: %w", err)
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("no feeds listed in %s", path)
+	}
+	return refs, nil
+}
+
+// resolveBulkRef finds the feed ref names without prompting, so a long list can run unattended;
+// a ref matching several feeds fails and is left for the user to spell out
+func resolveBulkRef(ref string, candidates []feedRef) (feedRef, error) {
+	matches := feedRefMatches(ref, candidates)
+	switch len(matches) {
+	case 0:
+		return feedRef{}, fmt.Errorf("no feed matches %q", ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, f := range matches {
+		names[i] = f.Name
+	}
+	return feedRef{}, fmt.Errorf("matches %d feeds: %s", len(matches), strings.Join(names, ", "))
+}
+
+// finishBulk emits res and fails if any of its entries did, so scripts can tell
+func finishBulk(s *state, res bulkResult) error {
+	if err := s.emit(res); err != nil {
+		return err
+	}
+	if _, _, failed := res.counts(); failed > 0 {
+		return fmt.Errorf("%d of %d feeds failed", failed, len(res.Entries))
+	}
+	return nil
+}
+
+// followFromFile follows every feed listed in path, carrying on past ones that fail
+func followFromFile(s *state, user database.User, path, categoryName string) error {
+	refs, err := readFeedList(path)
+	if err != nil {
+		return err
+	}
+
+	var category database.Category
+	if categoryName != "" {
+		category, err = getCategory(s, user, categoryName)
+		if err != nil {
+			return err
+		}
+	}
+
+	candidates, err := allFeedRefs(s)
+	if err != nil {
+		return err
+	}
+
+	res := bulkResult{Action: "follow", Entries: []bulkEntry{}}
+	for _, ref := range refs {
+		entry := bulkEntry{Ref: ref, Status: "failed"}
+		match, err := resolveBulkRef(ref, candidates)
+		if err == nil {
+			entry.Feed = match.Name
+			var feed database.Feed
+			feed, err = getFeedByURL(s.ctx, s.db, match.Url)
+			if err == nil {
+				var alreadyFollowing bool
+				alreadyFollowing, err = followFeed(s, user, feed, category)
+				if err == nil {
+					entry.Status = "followed"
+					if alreadyFollowing {
+						entry.Status = "already"
+					}
+				}
+			}
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		res.Entries = append(res.Entries, entry)
+	}
+	return finishBulk(s, res)
+}
+
+// unfollowFromFile unfollows every feed listed in path, carrying on past ones that fail. A feed
+// that exists but isn't followed counts as already unfollowed rather than failed.
+func unfollowFromFile(s *state, user database.User, path string) error {
+	refs, err := readFeedList(path)
+	if err != nil {
+		return err
+	}
+
+	followed, err := followedFeedRefs(s, user)
+	if err != nil {
+		return err
+	}
+	all, err := allFeedRefs(s)
+	if err != nil {
+		return err
+	}
+
+	res := bulkResult{Action: "unfollow", Entries: []bulkEntry{}}
+	for _, ref := range refs {
+		entry := bulkEntry{Ref: ref, Status: "failed"}
+		match, err := resolveBulkRef(ref, followed)
+		if err == nil {
+			entry.Feed = match.Name
+			err = s.db.DeleteFeedFollow(s.ctx, database.DeleteFeedFollowParams{
+				UserID: user.ID,
+				FeedID: match.ID,
+			})
+			if err == nil {
+				entry.Status = "unfollowed"
+				// A feed listed twice is already unfollowed the second time
+				followed = slices.DeleteFunc(followed, func(f feedRef) bool { return f.ID == match.ID })
+			} else {
+				err = fmt.Errorf("couldn't unfollow feed: %w", err)
+			}
+		} else if other, otherErr := resolveBulkRef(ref, all); otherErr == nil {
+			entry.Feed = other.Name
+			entry.Status = "already"
+			err = nil
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		res.Entries = append(res.Entries, entry)
+	}
+	return finishBulk(s, res)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/config"
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// handlerCatchup marks posts from followed feeds read in bulk, optionally only one feed's or
+// only those older than a duration, to clear a backlog without reading it
+func handlerCatchup(s *state, cmd command, user database.User) error {
+	var feedURL, olderThan string
+	flags := newFlagSet("catchup")
+	flags.StringVar(&feedURL, "feed", "", "Only posts from the feed at `feed_url`")
+	flags.StringVar(&olderThan, "older-than", "", "Only posts published more than this `duration` ago, like 7d")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unknown catchup argument %q", args[0])
+	}
+
+	params := database.MarkPostsReadParams{UserID: user.ID}
+	res := catchupResult{OlderThan: olderThan}
+	if olderThan != "" {
+		age, err := config.ParseDuration(olderThan)
+		if err != nil {
+			return err
+		}
+		params.Before = sql.NullTime{Time: time.Now().Add(-age), Valid: true}
+	}
+	if feedURL != "" {
+		feed, err := getFeedByURL(s.ctx, s.db, feedURL)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("feed %s doesn't exist", feedURL)
+			}
+			return fmt.Errorf("couldn't find feed: %w", err)
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+		res.Feed = feed.Name
+	}
+
+	res.Marked, err = s.db.MarkPostsRead(s.ctx, params)
+	if err != nil {
+		return fmt.Errorf("couldn't mark posts read: %w", err)
+	}
+
+	return s.emit(res)
+}
+
+// catchupResult is the output of catchup; Feed and OlderThan are empty when catchup wasn't
+// limited by them
+type catchupResult struct {
+	Marked    int64  `json:"marked"`
+	Feed      string `json:"feed,omitempty"`
+	OlderThan string `json:"older_than,omitempty"`
+}
+
+func (r catchupResult) writeText(w io.Writer) {
+	msg := fmt.Sprintf("Marked %d posts read", r.Marked)
+	if r.Feed != "" {
+		msg += " from " + r.Feed
+	}
+	if r.OlderThan != "" {
+		msg += " older than " + r.OlderThan
+	}
+	fmt.Fprintln(w, msg)
+}
+
+func (r catchupResult) table() ([]string, [][]string) {
+	return []string{"marked", "feed", "older_than"}, [][]string{{strconv.FormatInt(r.Marked, 10), r.Feed, r.OlderThan}}
+}
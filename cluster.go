@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/Utkarsh736/gator/internal/database"
+)
+
+// Two titles are about the same story when at least clusterMinShared of their words are shared
+// and the shared words make up clusterSimilarity of all the words in either
+const (
+	clusterSimilarity = 0.5
+	clusterMinShared  = 2
+)
+
+// clusterWindow is how many posts browse --clustered reads for each cluster it shows, so a page
+// of clusters usually fills up
+const clusterWindow = 4
+
+// postCluster is a story and the other posts covering it; Index is where the story's first post
+// was in the list it was clustered from
+type postCluster struct {
+	Post    database.Post
+	Index   int
+	Related []database.Post
+}
+
+// titleWords is the set of words of a title that trends would count, without the bigrams
+func titleWords(title string) map[string]bool {
+	words := map[string]bool{}
+	for _, term := range titleTerms(title) {
+		if !strings.Contains(term, " ") {
+			words[term] = true
+		}
+	}
+	return words
+}
+
+// similarTitles compares two titles' word sets by their Jaccard similarity
+func similarTitles(a, b map[string]bool) bool {
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	return shared >= clusterMinShared && float64(shared) >= clusterSimilarity*float64(union)
+}
+
+// clusterPosts groups posts whose titles are near duplicates, as when several outlets cover the
+// same story. Posts are taken in order, each joining the first cluster whose story it resembles,
+// so each cluster is led by its first post and clusters keep the order of the list.
+func clusterPosts(posts []database.Post) []postCluster {
+	var clusters []postCluster
+	var words []map[string]bool
+	for i, post := range posts {
+		w := titleWords(post.Title)
+		joined := false
+		for c := range clusters {
+			if similarTitles(words[c], w) {
+				clusters[c].Related = append(clusters[c].Related, post)
+				joined = true
+				break
+			}
+		}
+		if !joined {
+			clusters = append(clusters, postCluster{Post: post, Index: i})
+			words = append(words, w)
+		}
+	}
+	return clusters
+}
@@ -5,20 +5,60 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Utkarsh736/gator/internal/config"
 	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/fuzzy"
+	"github.com/Utkarsh736/gator/internal/hostlimit"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/Utkarsh736/gator/internal/pubdate"
+	"github.com/Utkarsh736/gator/internal/schedule"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
 // state holds the application state (config, DB connection)
 type state struct {
-	db  *database.Queries
-	cfg *config.Config
+	db     *database.Queries
+	conn   *sql.DB
+	cfg    *config.Config
+	output string
+	out    io.Writer       // where emit writes; stdout if nil
+	ctx    context.Context // cancelled when gator is interrupted; set by commands.run
+	dryRun bool            // agg and fetch --dry-run: fetch and parse feeds, but save nothing
+	// webhooks sends webhook deliveries in the background while agg, fetch or worker runs
+	webhooks *webhookSender
+}
+
+// withContext returns a copy of s whose database and HTTP calls run under ctx
+func (s *state) withContext(ctx context.Context) *state {
+	copied := *s
+	copied.ctx = ctx
+	return &copied
+}
+
+// inTx runs fn with queries bound to one transaction, committing if fn succeeds and rolling
+// everything back if it returns an error
+func (s *state) inTx(ctx context.Context, fn func(q *database.Queries) error) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(database.New(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // command represents a CLI command with its name and arguments
@@ -27,30 +67,74 @@ type command struct {
 	args []string
 }
 
-// commands holds all registered command handlers
+// commands holds all registered command handlers, and what help says about them in the order
+// they were registered. aliases are the ones from the config file.
 type commands struct {
 	handlers map[string]func(*state, command) error
+	info     []commandInfo
+	aliases  map[string]string
 }
 
-// register adds a new command handler
-func (c *commands) register(name string, f func(*state, command) error) {
+// commandInfo describes a command for help. Usage is the command's arguments, without its name.
+type commandInfo struct {
+	Name        string `json:"name"`
+	Usage       string `json:"usage"`
+	Description string `json:"description"`
+}
+
+// register adds a new command handler, with the usage and one-line description help shows
+func (c *commands) register(name, usage, description string, f func(*state, command) error) {
 	c.handlers[name] = f
+	c.info = append(c.info, commandInfo{Name: name, Usage: usage, Description: description})
+}
+
+// check returns an error naming close matches and the known commands if name isn't one of them
+// or an alias
+func (c *commands) check(name string) error {
+	if _, exists := c.handlers[name]; exists {
+		return nil
+	}
+	if _, ok := c.alias(name); ok {
+		return nil
+	}
+
+	names := c.names()
+	msg := fmt.Sprintf("unknown command: %s", name)
+	if match, ok := fuzzy.Closest(name, names, 2); ok {
+		msg += fmt.Sprintf(" (did you mean %s?)", match)
+	}
+	return fmt.Errorf("%s\nCommands: %s\nRun 'gator help <command>' for how to use one", msg, strings.Join(names, ", "))
+}
+
+// names lists the registered commands, sorted
+func (c *commands) names() []string {
+	names := make([]string, 0, len(c.info))
+	for _, info := range c.info {
+		names = append(names, info.Name)
+	}
+	sort.Strings(names)
+	return names
 }
 
-// run executes a command by name if it exists
-func (c *commands) run(s *state, cmd command) error {
-	handler, exists := c.handlers[cmd.name]
-	if !exists {
-		return fmt.Errorf("unknown command: %s", cmd.name)
+// run executes a command by name if it exists, expanding aliases first. The handler's
+// database and HTTP calls run under ctx, through s.ctx.
+func (c *commands) run(ctx context.Context, s *state, cmd command) error {
+	cmd, err := c.expand(cmd)
+	if err != nil {
+		return err
 	}
-	return handler(s, cmd)
+	if err := c.check(cmd.name); err != nil {
+		return err
+	}
+	s.ctx = ctx
+	return c.handlers[cmd.name](s, cmd)
 }
 
 // middlewareLoggedIn wraps handlers that require a logged-in user
 func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
 	return func(s *state, cmd command) error {
 		// Get current user
-		user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUserName)
+		user, err := s.db.GetUser(s.ctx, s.cfg.CurrentUserName)
 		if err != nil {
 			return fmt.Errorf("couldn't get current user: %w", err)
 		}
@@ -67,35 +151,81 @@ func handlerRegister(s *state, cmd command) error {
 	}
 
 	name := cmd.args[0]
+	user, err := createUser(s, name)
+	if err != nil {
+		return err
+	}
+
+	// Set current user in config
+	err = s.cfg.SetUser(name)
+	if err != nil {
+		return fmt.Errorf("couldn't set current user: %w", err)
+	}
+
+	resp := toAPIUser(user)
+	resp.ApiKey = user.ApiKey
+	return s.emit(registerResult{User: resp})
+}
+
+// createUser asks for a password for the new user name, unless passwords are off, and creates them
+func createUser(s *state, name string) (database.User, error) {
+	// Ask for the password before creating the user so a typo doesn't leave a half-made account
+	password := ""
+	if !s.cfg.Passwordless {
+		var err error
+		password, err = promptNewPassword("Password (leave empty for none): ")
+		if err != nil {
+			return database.User{}, err
+		}
+	}
 
-	// Create user in database
-	user, err := s.db.CreateUser(context.Background(), database.CreateUserParams{
+	apiKey, err := newUserAPIKey()
+	if err != nil {
+		return database.User{}, fmt.Errorf("couldn't generate API key: %w", err)
+	}
+	user, err := s.db.CreateUser(s.ctx, database.CreateUserParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		Name:      name,
+		ApiKey:    apiKey,
 	})
-
 	if err != nil {
 		// Check if it's a duplicate key error
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			return fmt.Errorf("user %s already exists", name)
+			return database.User{}, fmt.Errorf("user %s already exists", name)
 		}
-		return fmt.Errorf("couldn't create user: %w", err)
+		return database.User{}, fmt.Errorf("couldn't create user: %w", err)
 	}
 
-	// Set current user in config
-	err = s.cfg.SetUser(name)
-	if err != nil {
-		return fmt.Errorf("couldn't set current user: %w", err)
+	if password != "" {
+		if err := setUserPassword(s.ctx, s.db, user, password); err != nil {
+			return database.User{}, err
+		}
 	}
+	return user, nil
+}
+
+// registerResult is the output of register
+type registerResult struct {
+	User apiUser `json:"user"`
+}
 
-	fmt.Println("User created successfully:")
-	fmt.Printf("  ID: %s\n", user.ID)
-	fmt.Printf("  Name: %s\n", user.Name)
-	fmt.Printf("  Created at: %s\n", user.CreatedAt)
+func (r registerResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, "User created successfully:")
+	fmt.Fprintf(w, "  ID: %s\n", r.User.ID)
+	fmt.Fprintf(w, "  Name: %s\n", r.User.Name)
+	fmt.Fprintf(w, "  Created at: %s\n", r.User.CreatedAt)
+	fmt.Fprintf(w, "  API key: %s\n", r.User.ApiKey)
+}
 
-	return nil
+func (r registerResult) table() ([]string, [][]string) {
+	return []string{"id", "name", "created_at", "api_key"}, [][]string{{
+		r.User.ID.String(),
+		r.User.Name,
+		formatTime(&r.User.CreatedAt),
+		r.User.ApiKey,
+	}}
 }
 
 // handlerLogin sets the current user in the config
@@ -107,7 +237,7 @@ func handlerLogin(s *state, cmd command) error {
 	username := cmd.args[0]
 
 	// Check if user exists in database
-	_, err := s.db.GetUser(context.Background(), username)
+	user, err := s.db.GetUser(s.ctx, username)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf("user %s doesn't exist", username)
@@ -115,376 +245,3119 @@ func handlerLogin(s *state, cmd command) error {
 		return fmt.Errorf("couldn't get user: %w", err)
 	}
 
+	if err := verifyUserPassword(s, user); err != nil {
+		return err
+	}
+
 	// Set current user
 	err = s.cfg.SetUser(username)
 	if err != nil {
 		return fmt.Errorf("couldn't set current user: %w", err)
 	}
 
-	fmt.Printf("User has been set to: %s\n", username)
-	return nil
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("User has been set to: %s", username),
+		Item:    toAPIUser(user),
+	})
 }
 
-// handlerReset deletes all users from the database
+// handlerReset deletes all users and their data, one user's data, or only posts. It refuses to
+// run without --yes.
 func handlerReset(s *state, cmd command) error {
-	err := s.db.DeleteAllUsers(context.Background())
+	var yes, postsOnly bool
+	var userName string
+	flags := newFlagSet("reset")
+	flags.BoolVar(&yes, "yes", false, "Confirm the reset")
+	flags.BoolVar(&postsOnly, "posts-only", false, "Delete posts, keeping users and feeds")
+	flags.StringVar(&userName, "user", "", "Delete only this `username` and what they own")
+	args, err := flags.parse(cmd.args)
 	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unknown reset argument %q", args[0])
+	}
+
+	var scope string
+	switch {
+	case postsOnly && userName != "":
+		scope = fmt.Sprintf("all posts from feeds added by %s", userName)
+	case postsOnly:
+		scope = "all posts, keeping users and feeds"
+	case userName != "":
+		scope = fmt.Sprintf("user %s and everything they own", userName)
+	default:
+		scope = "every user, feed and post"
+	}
+	if !yes {
+		return fmt.Errorf("reset would delete %s; run it again with --yes to confirm", scope)
+	}
+
+	var user database.User
+	if userName != "" {
+		user, err = getUserByName(s, userName)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case postsOnly && userName != "":
+		n, err := s.db.DeletePostsForFeedsOfUser(s.ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't delete posts: %w", err)
+		}
+		return s.emit(messageResult{Message: fmt.Sprintf("Deleted %d posts from feeds added by %s", n, user.Name)})
+	case postsOnly:
+		n, err := s.db.DeleteAllPosts(s.ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't delete posts: %w", err)
+		}
+		return s.emit(messageResult{Message: fmt.Sprintf("Deleted %d posts", n)})
+	case userName != "":
+		if err := deleteUser(s, user); err != nil {
+			return err
+		}
+		if s.cfg.CurrentUserName == user.Name {
+			if err := s.cfg.SetUser(""); err != nil {
+				return fmt.Errorf("couldn't clear current user: %w", err)
+			}
+		}
+		return s.emit(messageResult{Message: fmt.Sprintf("Deleted user %s and their data", user.Name)})
+	}
+
+	if err := s.db.DeleteAllUsers(s.ctx); err != nil {
 		return fmt.Errorf("couldn't reset database: %w", err)
 	}
 
-	fmt.Println("Database has been reset successfully")
-	return nil
+	return s.emit(messageResult{Message: "Database has been reset successfully"})
 }
 
 // handlerUsers lists all users in the database
 func handlerUsers(s *state, cmd command) error {
-	users, err := s.db.GetUsers(context.Background())
+	users, err := s.db.GetUsers(s.ctx)
 	if err != nil {
 		return fmt.Errorf("couldn't get users: %w", err)
 	}
 
-	if len(users) == 0 {
-		fmt.Println("No users found")
-		return nil
+	res := usersResult{Users: []userEntry{}}
+	for _, user := range users {
+		res.Users = append(res.Users, userEntry{
+			apiUser: toAPIUser(user),
+			Current: user.Name == s.cfg.CurrentUserName,
+		})
 	}
 
-	// Get current user from config
-	currentUser := s.cfg.CurrentUserName
+	return s.emit(res)
+}
 
-	// Print all users
-	for _, user := range users {
-		if user.Name == currentUser {
-			fmt.Printf("* %s (current)\n", user.Name)
+// userEntry is a user in the users listing
+type userEntry struct {
+	apiUser
+	Current bool `json:"current"`
+}
+
+// usersResult is the output of users
+type usersResult struct {
+	Users []userEntry `json:"users"`
+}
+
+func (r usersResult) writeText(w io.Writer) {
+	if len(r.Users) == 0 {
+		fmt.Fprintln(w, "No users found")
+		return
+	}
+
+	for _, user := range r.Users {
+		if user.Current {
+			fmt.Fprintf(w, "* %s (current)\n", user.Name)
 		} else {
-			fmt.Printf("* %s\n", user.Name)
+			fmt.Fprintf(w, "* %s\n", user.Name)
 		}
 	}
+}
 
-	return nil
+func (r usersResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, user := range r.Users {
+		rows = append(rows, []string{user.Name, strconv.FormatBool(user.Current)})
+	}
+	return []string{"name", "current"}, rows
 }
 
-// handlerAgg continuously fetches feeds at specified intervals
-func handlerAgg(s *state, cmd command) error {
+// handlerUser dispatches user management subcommands
+func handlerUser(s *state, cmd command) error {
 	if len(cmd.args) == 0 {
-		return errors.New("agg command requires a time_between_reqs argument")
+		return errors.New("user command requires a subcommand: delete, rename")
 	}
 
-	// Parse duration
-	timeBetweenRequests, err := time.ParseDuration(cmd.args[0])
-	if err != nil {
-		return fmt.Errorf("invalid duration: %w", err)
+	sub := command{name: cmd.args[0], args: cmd.args[1:]}
+	switch sub.name {
+	case "delete":
+		return handlerUserDelete(s, sub)
+	case "rename":
+		return handlerUserRename(s, sub)
+	default:
+		return fmt.Errorf("unknown user subcommand: %s", sub.name)
 	}
+}
 
-	fmt.Printf("Collecting feeds every %s\n", timeBetweenRequests)
-
-	// Create ticker
-	ticker := time.NewTicker(timeBetweenRequests)
-	defer ticker.Stop()
-
-	// Run immediately, then on each tick
-	for ; ; <-ticker.C {
-		err := scrapeFeeds(s)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error scraping feeds: %v\n", err)
+// getUserByName looks up a user, with a friendly error if they don't exist
+func getUserByName(s *state, name string) (database.User, error) {
+	user, err := s.db.GetUser(s.ctx, name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return database.User{}, fmt.Errorf("user %s doesn't exist", name)
 		}
+		return database.User{}, fmt.Errorf("couldn't get user: %w", err)
 	}
+	return user, nil
 }
 
-// scrapeFeeds fetches the next feed and processes its posts
-func scrapeFeeds(s *state) error {
-	// Get next feed to fetch
-	feed, err := s.db.GetNextFeedToFetch(context.Background())
+// handlerUserDelete removes a user along with the feeds they added, their follows, and their
+// read, saved and tagged posts, after confirming
+func handlerUserDelete(s *state, cmd command) error {
+	var yes bool
+	flags := newFlagSet("user delete")
+	flags.BoolVar(&yes, "yes", false, "Delete without asking")
+	flags.BoolVar(&yes, "y", false, "Short for --yes")
+	args, err := flags.parse(cmd.args)
 	if err != nil {
-		return fmt.Errorf("couldn't get next feed to fetch: %w", err)
+		return err
 	}
-
-	fmt.Printf("Fetching feed: %s (URL: %s)\n", feed.Name, feed.Url)
-
-	// Mark feed as fetched
-	err = s.db.MarkFeedFetched(context.Background(), feed.ID)
-	if err != nil {
-		return fmt.Errorf("couldn't mark feed as fetched: %w", err)
+	if len(args) == 0 {
+		return errors.New("user delete requires a username argument")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected user delete argument %q", args[1])
 	}
+	name := args[0]
 
-	// Fetch the RSS feed
-	rssFeed, err := fetchFeed(context.Background(), feed.Url)
+	user, err := getUserByName(s, name)
 	if err != nil {
-		return fmt.Errorf("couldn't fetch feed: %w", err)
+		return err
+	}
+	if err := verifyUserPassword(s, user); err != nil {
+		return err
 	}
 
-	// Save posts to database
-	fmt.Printf("Found %d posts in %s\n", len(rssFeed.Channel.Item), feed.Name)
-	for _, item := range rssFeed.Channel.Item {
-		// Parse published date - try multiple formats
-		var publishedAt sql.NullTime
-		if item.PubDate != "" {
-			t, err := parsePublishedDate(item.PubDate)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: couldn't parse date %q: %v\n", item.PubDate, err)
-			} else {
-				publishedAt = sql.NullTime{Time: t, Valid: true}
-			}
+	if !yes {
+		summary, err := s.db.GetUserDeletionSummary(s.ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't summarise user data: %w", err)
 		}
 
-		// Handle nullable description
-		var description sql.NullString
-		if item.Description != "" {
-			description = sql.NullString{String: item.Description, Valid: true}
-		}
-
-		// Create post
-		_, err := s.db.CreatePost(context.Background(), database.CreatePostParams{
-			ID:          uuid.New(),
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
-			Title:       item.Title,
-			Url:         item.Link,
-			Description: description,
-			PublishedAt: publishedAt,
-			FeedID:      feed.ID,
-		})
-
+		fmt.Fprintf(os.Stderr, "Deleting %s will also remove %d feeds they added, %d follows and %d read markers.\n",
+			user.Name, summary.Feeds, summary.Follows, summary.Reads)
+		if summary.OtherFollows > 0 {
+			fmt.Fprintf(os.Stderr, "Other users follow their feeds %d times; those follows and the posts only those feeds carried will be removed too.\n", summary.OtherFollows)
+		}
+		ok, err := confirm(fmt.Sprintf("Delete user %s?", user.Name))
 		if err != nil {
-			// Ignore duplicate URL errors (post already exists)
-			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-				continue
-			}
-			// Log other errors but don't stop
-			fmt.Fprintf(os.Stderr, "Warning: couldn't save post %q: %v\n", item.Title, err)
+			return err
+		}
+		if !ok {
+			return errors.New("user delete cancelled")
 		}
 	}
 
-	fmt.Printf("Saved posts from %s\n\n", feed.Name)
-	return nil
-}
-
-// parsePublishedDate tries multiple date formats common in RSS feeds
-func parsePublishedDate(dateStr string) (time.Time, error) {
-	formats := []string{
-		time.RFC1123Z,
-		time.RFC1123,
-		time.RFC822Z,
-		time.RFC822,
-		"2006-01-02T15:04:05Z07:00",
-		"2006-01-02 15:04:05",
+	if err := deleteUser(s, user); err != nil {
+		return err
 	}
 
-	for _, format := range formats {
-		t, err := time.Parse(format, dateStr)
-		if err == nil {
-			return t, nil
+	// Don't leave the config pointing at a user that no longer exists
+	if s.cfg.CurrentUserName == user.Name {
+		if err := s.cfg.SetUser(""); err != nil {
+			return fmt.Errorf("couldn't clear current user: %w", err)
 		}
 	}
 
-	return time.Time{}, fmt.Errorf("no valid date format found")
+	return s.emit(messageResult{Message: fmt.Sprintf("User deleted: %s", user.Name), Item: toAPIUser(user)})
 }
 
-// handlerAddFeed adds a new feed for the current user
-func handlerAddFeed(s *state, cmd command, user database.User) error {
+// handlerUserRename changes a user's name, following the change in the config if they're current
+func handlerUserRename(s *state, cmd command) error {
 	if len(cmd.args) < 2 {
-		return errors.New("addfeed command requires name and url arguments")
+		return errors.New("user rename requires old and new username arguments")
 	}
 
-	name := cmd.args[0]
-	url := cmd.args[1]
+	user, err := getUserByName(s, cmd.args[0])
+	if err != nil {
+		return err
+	}
+	if err := verifyUserPassword(s, user); err != nil {
+		return err
+	}
 
-	// Create feed (user is already provided)
-	feed, err := s.db.CreateFeed(context.Background(), database.CreateFeedParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Name:      name,
-		Url:       url,
-		UserID:    user.ID,
+	newName := cmd.args[1]
+	renamed, err := s.db.RenameUser(s.ctx, database.RenameUserParams{
+		ID:   user.ID,
+		Name: newName,
 	})
-
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			return fmt.Errorf("feed with URL %s already exists", url)
+			return fmt.Errorf("user %s already exists", newName)
 		}
-		return fmt.Errorf("couldn't create feed: %w", err)
+		return fmt.Errorf("couldn't rename user: %w", err)
 	}
 
-	// Automatically create feed follow
-	_, err = s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		UserID:    user.ID,
-		FeedID:    feed.ID,
-	})
-
-	if err != nil {
-		return fmt.Errorf("couldn't follow feed: %w", err)
+	if s.cfg.CurrentUserName == user.Name {
+		if err := s.cfg.SetUser(renamed.Name); err != nil {
+			return fmt.Errorf("couldn't update current user: %w", err)
+		}
 	}
 
-	fmt.Println("Feed created successfully:")
-	fmt.Printf("  ID: %s\n", feed.ID)
-	fmt.Printf("  Name: %s\n", feed.Name)
-	fmt.Printf("  URL: %s\n", feed.Url)
-	fmt.Printf("  User ID: %s\n", feed.UserID)
-	fmt.Printf("  Created at: %s\n", feed.CreatedAt)
-	fmt.Println("(Automatically followed)")
-
-	return nil
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("User %s renamed to %s", user.Name, renamed.Name),
+		Item:    toAPIUser(renamed),
+	})
 }
 
-// handlerFeeds lists all feeds in the database
-func handlerFeeds(s *state, cmd command) error {
-	feeds, err := s.db.GetFeeds(context.Background())
+// handlerAgg continuously fetches feeds at specified intervals
+func handlerAgg(s *state, cmd command) error {
+	var once, coordinator bool
+	var pidFile, metricsAddr string
+	notify := s.cfg.Notify
+	flags := newFlagSet("agg")
+	flags.BoolVar(&once, "once", false, "Fetch the due feeds once and exit")
+	flags.BoolVar(&coordinator, "coordinator", false, "Queue due feeds for gator worker processes instead of fetching them")
+	flags.BoolVar(&notify, "notify", notify, "Send a desktop notification for new posts")
+	flags.BoolFunc("no-notify", "Don't send desktop notifications", negatedBool("no-notify", &notify))
+	// --no-images and --auto-update-urls are only for this run; the config makes them permanent
+	flags.BoolVar(&s.cfg.SkipImages, "no-images", s.cfg.SkipImages, "Don't fetch images for posts")
+	flags.BoolVar(&s.cfg.AutoUpdateURLs, "auto-update-urls", s.cfg.AutoUpdateURLs, "Follow permanent redirects to a feed's new URL")
+	flags.StringVar(&pidFile, "pidfile", "", "Write the process ID to `path` while running")
+	flags.StringVar(&metricsAddr, "metrics-addr", s.cfg.MetricsAddr, "Serve Prometheus metrics on `addr`")
+	flags.BoolVar(&s.dryRun, "dry-run", false, "Fetch and parse the due feeds once, showing what would be saved without saving it")
+	args, err := flags.parse(cmd.args)
 	if err != nil {
-		return fmt.Errorf("couldn't get feeds: %w", err)
+		return err
 	}
-
-	if len(feeds) == 0 {
-		fmt.Println("No feeds found")
-		return nil
+	var durationArg string
+	switch len(args) {
+	case 0:
+	case 1:
+		durationArg = args[0]
+	default:
+		return fmt.Errorf("unexpected agg argument %q", args[1])
 	}
-
-	fmt.Println("Feeds:")
-	for _, feed := range feeds {
-		fmt.Printf("* Name: %s\n", feed.Name)
-		fmt.Printf("  URL: %s\n", feed.Url)
-		fmt.Printf("  User: %s\n", feed.UserName)
-		fmt.Println()
+	if coordinator {
+		if s.dryRun {
+			return errors.New("--dry-run can't be used with --coordinator, which doesn't fetch feeds")
+		}
+		// Workers send the notifications for the feeds they fetch
+		notify = false
+	}
+	if err := guardPrivateAddresses(s, false); err != nil {
+		return err
 	}
 
-	return nil
-}
+	var notifier *postNotifier
+	if notify {
+		user, err := s.db.GetUser(s.ctx, s.cfg.CurrentUserName)
+		if err != nil {
+			return fmt.Errorf("--notify requires a logged-in user: %w", err)
+		}
+		notifier = &postNotifier{user: user}
+	}
 
-// handlerFollow follows a feed by URL
-func handlerFollow(s *state, cmd command, user database.User) error {
-	if len(cmd.args) == 0 {
-		return errors.New("follow command requires a URL argument")
+	// The pidfile is there for --once too, so a supervisor can signal a single pass
+	if pidFile != "" {
+		release, err := writePidFile(pidFile)
+		if err != nil {
+			return fmt.Errorf("couldn't write pidfile: %w", err)
+		}
+		defer release()
 	}
 
-	url := cmd.args[0]
+	s.webhooks = startWebhookSender()
+	defer s.webhooks.close()
 
-	// Get feed by URL
-	feed, err := s.db.GetFeedByURL(context.Background(), url)
-	if err != nil {
-		return fmt.Errorf("couldn't find feed: %w", err)
+	// Passes ignore SIGINT/SIGTERM, so one under way finishes and sends its notifications rather
+	// than being cut off; the loop stops between passes instead
+	work := s.withContext(context.WithoutCancel(s.ctx))
+
+	// A coordinator's pass queues the due feeds for workers to fetch
+	pass := func() error { return runScrape(work, notifier) }
+	if coordinator {
+		pass = func() error { return enqueueFetchJobs(work) }
 	}
 
-	// Create feed follow
-	feedFollow, err := s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		UserID:    user.ID,
-		FeedID:    feed.ID,
-	})
+	// A dry run doesn't reschedule the feeds it fetches, so a second pass would only fetch the
+	// same ones again
+	// A single pass that's interrupted still finishes, and then exits with an error as fetch does
+	if once || s.dryRun {
+		if err := applyRetention(work); err != nil {
+			slog.Error("couldn't prune posts", "error", err)
+		}
+		err := pass()
+		if s.ctx.Err() != nil {
+			return errors.Join(err, fmt.Errorf("agg interrupted: %w", s.ctx.Err()))
+		}
+		return err
+	}
 
+	// Parse duration, falling back to agg_interval from the config
+	timeBetweenRequests, ok, err := s.cfg.DefaultAggInterval()
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			return fmt.Errorf("already following this feed")
+		return err
+	}
+	if durationArg != "" {
+		timeBetweenRequests, err = time.ParseDuration(durationArg)
+		if err != nil {
+			return fmt.Errorf("invalid duration: %w", err)
 		}
-		return fmt.Errorf("couldn't follow feed: %w", err)
+	} else if !ok {
+		return errors.New("agg command requires a time_between_reqs argument, or agg_interval in the config")
 	}
 
-	fmt.Printf("%s is now following %s\n", feedFollow.UserName, feedFollow.FeedName)
-	return nil
-}
-
-// handlerFollowing lists feeds the current user is following
-func handlerFollowing(s *state, cmd command, user database.User) error {
-	// Get feed follows
-	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	digests, err := newDigestScheduler(s)
 	if err != nil {
-		return fmt.Errorf("couldn't get feed follows: %w", err)
+		return err
 	}
 
-	if len(follows) == 0 {
-		fmt.Println("Not following any feeds")
-		return nil
+	if metricsAddr != "" {
+		stopMetrics := serveMetrics(metricsAddr, s.db)
+		defer stopMetrics()
 	}
 
-	fmt.Printf("Feeds followed by %s:\n", user.Name)
-	for _, follow := range follows {
-		fmt.Printf("* %s\n", follow.FeedName)
+	if coordinator {
+		s.emit(messageResult{Message: fmt.Sprintf("Queueing due feeds for workers every %s", timeBetweenRequests)})
+	} else {
+		s.emit(messageResult{Message: fmt.Sprintf("Collecting feeds every %s", timeBetweenRequests)})
 	}
 
-	return nil
-}
+	// Create ticker
+	ticker := time.NewTicker(timeBetweenRequests)
+	defer ticker.Stop()
 
-// handlerUnfollow unfollows a feed by URL
-func handlerUnfollow(s *state, cmd command, user database.User) error {
-	if len(cmd.args) == 0 {
-		return errors.New("unfollow command requires a URL argument")
-	}
+	// Run immediately, then on each tick
+	var lastPrune time.Time
+	for {
+		if err := spreadUnscheduledFeeds(work); err != nil {
+			slog.Error("couldn't schedule new feeds", "error", err)
+		}
+		err := pass()
+		if err != nil {
+			slog.Error("couldn't scrape feeds", "error", err)
+		}
+
+		// Apply the retention policy at most once an hour
+		if time.Since(lastPrune) >= time.Hour {
+			if err := applyRetention(work); err != nil {
+				slog.Error("couldn't prune posts", "error", err)
+			}
+			lastPrune = time.Now()
+		}
 
-	url := cmd.args[0]
+		if err := digests.runIfDue(work); err != nil {
+			slog.Error("couldn't send digest", "error", err)
+		}
 
-	// Get feed by URL
-	feed, err := s.db.GetFeedByURL(context.Background(), url)
-	if err != nil {
-		return fmt.Errorf("couldn't find feed: %w", err)
+		select {
+		case <-s.ctx.Done():
+			return s.emit(messageResult{Message: "Shutting down aggregator"})
+		case <-ticker.C:
+		}
 	}
+}
 
-	// Delete feed follow
-	err = s.db.DeleteFeedFollow(context.Background(), database.DeleteFeedFollowParams{
-		UserID: user.ID,
-		FeedID: feed.ID,
-	})
+// applyRetention prunes old posts when a retention_period is configured, unless this is a dry run
+func applyRetention(s *state) error {
+	retention, enabled, err := s.cfg.Retention()
+	if err != nil || !enabled || s.dryRun {
+		return err
+	}
 
+	pruned, err := s.db.PrunePosts(s.ctx, time.Now().Add(-retention))
 	if err != nil {
-		return fmt.Errorf("couldn't unfollow feed: %w", err)
+		return err
+	}
+	if pruned > 0 {
+		return s.emit(pruneResult{Pruned: pruned, OlderThan: s.cfg.RetentionPeriod})
 	}
-
-	fmt.Printf("%s has unfollowed %s\n", user.Name, feed.Name)
 	return nil
 }
 
-// handlerBrowse displays posts from feeds the user follows
-func handlerBrowse(s *state, cmd command, user database.User) error {
-	limit := 2 // default
+// pruneResult is the output of prune and of automatic retention during agg
+type pruneResult struct {
+	Pruned    int64  `json:"pruned"`
+	OlderThan string `json:"older_than"`
+}
 
-	if len(cmd.args) > 0 {
-		// Parse limit from args
-		var err error
-		_, err = fmt.Sscan(cmd.args[0], &limit)
-		if err != nil {
-			return fmt.Errorf("invalid limit: %w", err)
-		}
-	}
+func (r pruneResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Pruned %d posts older than %s\n", r.Pruned, r.OlderThan)
+}
 
-	posts, err := s.db.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  int32(limit),
-	})
+func (r pruneResult) table() ([]string, [][]string) {
+	return []string{"pruned", "older_than"}, [][]string{{strconv.FormatInt(r.Pruned, 10), r.OlderThan}}
+}
 
+// handlerPrune deletes old posts that nobody has saved, queued or recently read
+func handlerPrune(s *state, cmd command) error {
+	var olderThan string
+	flags := newFlagSet("prune")
+	flags.StringVar(&olderThan, "older-than", s.cfg.RetentionPeriod, "Prune posts older than this `duration`, like 90d")
+	args, err := flags.parse(cmd.args)
 	if err != nil {
-		return fmt.Errorf("couldn't get posts: %w", err)
+		return err
 	}
-
-	if len(posts) == 0 {
-		fmt.Println("No posts found. Follow some feeds first!")
-		return nil
+	if len(args) > 0 {
+		return fmt.Errorf("unknown prune argument: %s", args[0])
 	}
 
-	fmt.Printf("Found %d posts for %s:\n", len(posts), user.Name)
-	fmt.Println(strings.Repeat("=", 80))
+	if olderThan == "" {
+		return errors.New("prune command requires --older-than (e.g. 90d) or retention_period in the config")
+	}
 
-	for _, post := range posts {
-		fmt.Printf("\nTitle: %s\n", post.Title)
-		fmt.Printf("URL: %s\n", post.Url)
+	age, err := config.ParseDuration(olderThan)
+	if err != nil {
+		return err
+	}
 
-		if post.Description.Valid {
-			// Truncate long descriptions
-			desc := post.Description.String
-			if len(desc) > 200 {
-				desc = desc[:200] + "..."
-			}
-			fmt.Printf("Description: %s\n", desc)
-		}
+	pruned, err := s.db.PrunePosts(s.ctx, time.Now().Add(-age))
+	if err != nil {
+		return fmt.Errorf("couldn't prune posts: %w", err)
+	}
 
-		if post.PublishedAt.Valid {
-			fmt.Printf("Published: %s\n", post.PublishedAt.Time.Format("2006-01-02 15:04:05"))
-		}
+	return s.emit(pruneResult{Pruned: pruned, OlderThan: olderThan})
+}
 
-		fmt.Println(strings.Repeat("-", 80))
+// feedClaimLease is how long a claimed feed is left to the agg or fetch that claimed it. Fetching
+// it reschedules it and ends the claim well before then; the lease only runs out when that
+// process died or lost the database, and the feed is then fetched by whoever runs next.
+const feedClaimLease = 3 * feedFetchTimeout
+
+// nextFeedsToFetch picks up to limit due feeds and claims them, so that other agg and fetch
+// processes on the same database skip them. A dry run doesn't reschedule feeds, so it only
+// looks without claiming.
+func nextFeedsToFetch(s *state, limit int) ([]database.Feed, error) {
+	if s.dryRun {
+		return s.db.GetNextFeedsToFetch(s.ctx, int32(limit))
 	}
+	return s.db.ClaimNextFeedsToFetch(s.ctx, database.ClaimNextFeedsToFetchParams{
+		LeaseSeconds: int32(feedClaimLease / time.Second),
+		MaxFeeds:     int32(limit),
+	})
+}
 
-	return nil
+// spreadUnscheduledFeeds gives feeds that have never been scheduled, like new feeds or every feed
+// after an upgrade, a random first fetch within the minimum interval, so they don't all fall due
+// in the first pass. A single pass, as in fetch or agg --once, still fetches them straight away.
+func spreadUnscheduledFeeds(s *state) error {
+	min, _, err := s.cfg.FetchIntervalBounds()
+	if err != nil {
+		return err
+	}
+	_, err = s.db.SpreadUnscheduledFeeds(s.ctx, int32(min/time.Second))
+	return err
 }
 
+// runScrape scrapes the next due feeds, prints the results, and announces new posts through notifications, webhooks and Telegram
+func runScrape(s *state, notifier *postNotifier) error {
+	feeds, err := nextFeedsToFetch(s, s.cfg.FetchConcurrencyLimit())
+	if err != nil {
+		return fmt.Errorf("couldn't get next feeds to fetch: %w", err)
+	}
+	if len(feeds) == 0 {
+		return s.emit(scrapeResult{})
+	}
+
+	results, errs := scrapeFeeds(s, feeds)
+	for i, res := range results {
+		if errs[i] != nil {
+			errs[i] = fmt.Errorf("%s: %w", feeds[i].Name, errs[i])
+			continue
+		}
+		announceScrape(s, notifier, res)
+		if err := s.emit(res); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// announceScrape sends a scrape's new posts out as notifications, webhooks and Telegram
+// messages. A dry run's posts weren't saved, so they aren't announced.
+func announceScrape(s *state, notifier *postNotifier, res scrapeResult) {
+	if s.dryRun {
+		return
+	}
+
+	if err := notifier.notify(s, res); err != nil {
+		slog.Warn("couldn't send notification", "error", err)
+	}
+
+	if err := deliverWebhooks(s, res); err != nil {
+		slog.Warn("couldn't deliver webhooks", "error", err)
+	}
+
+	if err := pushTelegram(s, res); err != nil {
+		slog.Warn("couldn't push to Telegram", "error", err)
+	}
+
+	runIngestedHooks(s, res)
+}
+
+// scrapeFeeds scrapes feeds all at once, returning each feed's result or error in feeds' order
+func scrapeFeeds(s *state, feeds []database.Feed) ([]scrapeResult, []error) {
+	results := make([]scrapeResult, len(feeds))
+	errs := make([]error, len(feeds))
+	var wg sync.WaitGroup
+	for i, feed := range feeds {
+		wg.Go(func() {
+			results[i], errs[i] = scrapeFeed(s, feed)
+		})
+	}
+	wg.Wait()
+	return results, errs
+}
+
+// scrapeFeed fetches one feed, due or not, and processes its posts
+func scrapeFeed(s *state, feed database.Feed) (scrapeResult, error) {
+	// Fetch the RSS feed, retrying transient failures, and keep a record of how it went
+	retries, retryDelay, err := s.cfg.FetchRetryPolicy()
+	if err != nil {
+		return scrapeResult{}, err
+	}
+	slog.Debug("fetching feed", "feed", feed.Name, "url", feed.Url)
+	start := time.Now()
+	fetchCtx, cancel := context.WithTimeout(s.ctx, feedFetchTimeout)
+	rssFeed, status, err := fetchFeedWithRetry(fetchCtx, feed.Url, retries, retryDelay)
+	cancel()
+	fetchDuration.Observe(time.Since(start).Seconds())
+	slog.Debug("fetched feed", "feed", feed.Name, "status", status, "duration", time.Since(start), "error", err)
+	var delayed *hostlimit.DelayError
+	if errors.As(err, &delayed) {
+		fetchesTotal.Inc("delayed")
+		// The host asked to be left alone for a while; that's not the feed's fault
+		if !s.dryRun {
+			err := s.db.ScheduleFeedFetch(s.ctx, database.ScheduleFeedFetchParams{
+				ID:              feed.ID,
+				NextFetchAt:     sql.NullTime{Time: delayed.Until, Valid: true},
+				AvgPostInterval: feed.AvgPostInterval,
+			})
+			if err != nil {
+				slog.Warn("couldn't schedule retry", "feed", feed.Name, "error", err)
+			}
+		}
+		return scrapeResult{}, fmt.Errorf("couldn't fetch feed: %w", delayed)
+	}
+	if err != nil {
+		fetchesTotal.Inc("failure")
+		if !s.dryRun {
+			failures := recordFetch(s, feed, status, time.Since(start), 0, sql.NullInt32{}, sql.NullInt32{}, err)
+			// Try again soon rather than at the next regular fetch, so a flaky network doesn't
+			// send the feed to the back of the queue, while a broken feed doesn't block it
+			if err := scheduleFetchRetry(s, feed, failures); err != nil {
+				slog.Warn("couldn't schedule retry", "feed", feed.Name, "error", err)
+			}
+			runFeedFailedHook(s, feed, status, failures, err)
+		}
+		return scrapeResult{}, fmt.Errorf("couldn't fetch feed: %w", err)
+	}
+	fetchesTotal.Inc("success")
+	elapsed := time.Since(start)
+	var newPosts, seenPosts sql.NullInt32
+
+	// A dry run leaves the feed's fetch log, health, schedule and URL as they were
+	if !s.dryRun {
+		// The fetch is logged once its posts are saved, with how many of them were new
+		defer func() {
+			recordFetch(s, feed, status, elapsed, len(rssFeed.Channel.Item), newPosts, seenPosts, nil)
+		}()
+		recordFeedMove(s, &feed, rssFeed.MovedTo)
+		recordFeedChannel(s, &feed, rssFeed)
+
+		// Mark feed as fetched
+		err = s.db.MarkFeedFetched(s.ctx, feed.ID)
+		if err != nil {
+			return scrapeResult{}, fmt.Errorf("couldn't mark feed as fetched: %w", err)
+		}
+
+		// Schedule the next fetch however saving the posts goes
+		defer func() {
+			if err := scheduleNextFetch(s, feed); err != nil {
+				slog.Warn("couldn't schedule next fetch", "feed", feed.Name, "error", err)
+			}
+		}()
+	} else if rssFeed.MovedTo != "" {
+		slog.Info("feed moved permanently", "feed", feed.Name, "moved_to", rssFeed.MovedTo)
+	}
+
+	// Save posts to database
+	apiFeed := toAPIFeed(feed)
+	res := scrapeResult{Feed: &apiFeed, PostsFound: len(rssFeed.Channel.Item), NewPosts: []apiPost{}, DryRun: s.dryRun}
+	fallbackDate := channelDate(rssFeed)
+
+	// The feed owner's ingest filters keep matching posts out of the database altogether
+	ingestFilters, err := s.db.GetIngestFiltersForFeed(s.ctx, feed.ID)
+	if err != nil {
+		slog.Warn("couldn't get ingest filters", "feed", feed.Name, "error", err)
+	}
+	filters := compileFilters(ingestFilters)
+	// Rules of the feed's followers act on its new posts; the owner's drop rules work like filters
+	ingestRules := loadIngestRules(s, feed)
+
+	var pending []pendingPost
+	for _, item := range rssFeed.Channel.Item {
+		// A post is found and opened by its link, so an item without one is no use
+		if item.Link == "" {
+			slog.Warn("skipping post without a link", "feed", feed.Name, "post", item.Title)
+			res.PostsSkipped++
+			continue
+		}
+
+		// Use the item's own date if it has one we can read, otherwise estimate from the channel
+		publishedAt := sql.NullTime{Time: fallbackDate, Valid: true}
+		estimated := true
+		for _, raw := range []string{item.PubDate, item.DCDate} {
+			if raw == "" {
+				continue
+			}
+			t, err := pubdate.Parse(raw)
+			if err != nil {
+				slog.Warn("couldn't parse post date", "feed", feed.Name, "post", item.Title, "error", err)
+				continue
+			}
+			publishedAt, estimated = sql.NullTime{Time: t, Valid: true}, false
+			break
+		}
+
+		// Handle nullable description, falling back to a podcast's itunes:summary
+		var description sql.NullString
+		if item.Description == "" {
+			item.Description = item.Summary
+		}
+		if item.Description != "" {
+			description = sql.NullString{String: htmltext.Sanitize(item.Description), Valid: true}
+		}
+
+		// Podcast episodes carry their audio as an enclosure
+		var enclosureURL, enclosureType sql.NullString
+		var enclosureLength sql.NullInt64
+		if item.Enclosure != nil && item.Enclosure.URL != "" {
+			enclosureURL = sql.NullString{String: item.Enclosure.URL, Valid: true}
+			enclosureType = sql.NullString{String: item.Enclosure.Type, Valid: item.Enclosure.Type != ""}
+			if n, err := strconv.ParseInt(item.Enclosure.Length, 10, 64); err == nil && n > 0 {
+				enclosureLength = sql.NullInt64{Int64: n, Valid: true}
+			}
+		}
+		var duration sql.NullInt32
+		if seconds, ok := parseItunesDuration(item.Duration); ok {
+			duration = sql.NullInt32{Int32: seconds, Valid: true}
+		}
+
+		author := itemAuthor(item)
+		post := database.Post{
+			ID:                   uuid.New(),
+			CreatedAt:            time.Now(),
+			Title:                item.Title,
+			Url:                  item.Link,
+			Description:          description,
+			PublishedAt:          publishedAt,
+			FeedID:               feed.ID,
+			EnclosureUrl:         enclosureURL,
+			EnclosureType:        enclosureType,
+			EnclosureLength:      enclosureLength,
+			DurationSeconds:      duration,
+			PublishedAtEstimated: estimated,
+			CanonicalUrl:         sql.NullString{String: canonicalURL(item.Link), Valid: item.Link != ""},
+			ContentHash:          contentHash(item.Title, description.String),
+			Author:               sql.NullString{String: author, Valid: author != ""},
+			Guid:                 itemGUID(item),
+		}
+		if filters.hides(feed.ID, item.Title, description.String) || dropsPost(ingestRules, rulePost(feed, item, post)) {
+			res.PostsFiltered++
+			postsFiltered.Inc()
+			continue
+		}
+		pending = append(pending, pendingPost{post: post, item: item})
+	}
+
+	saved, updated, err := savePosts(s.ctx, s, feed, pending)
+	if err != nil {
+		return scrapeResult{}, fmt.Errorf("couldn't save posts: %w", err)
+	}
+	// Everything that wasn't saved as new matched a post saved before, whether or not it changed
+	res.PostsSeen = len(pending) - len(saved)
+	res.PostsUpdated = len(updated)
+	if s.dryRun {
+		for _, p := range updated {
+			res.UpdatedPosts = append(res.UpdatedPosts, toAPIPost(p.post))
+		}
+	}
+	newPosts = sql.NullInt32{Int32: int32(len(saved)), Valid: true}
+	seenPosts = sql.NullInt32{Int32: int32(res.PostsSeen), Valid: true}
+	for _, p := range saved {
+		post := p.post
+		res.PostsSaved++
+		// A dry run lists the posts it would have saved, without their images or content
+		if s.dryRun {
+			res.NewPosts = append(res.NewPosts, toAPIPost(post))
+			continue
+		}
+		postsIngested.Inc()
+
+		if !s.cfg.SkipImages {
+			if err := savePostImages(s, post.ID, itemImages(p.item)); err != nil {
+				slog.Warn("couldn't save post images", "feed", feed.Name, "post", post.Title, "error", err)
+			}
+		}
+
+		if feed.ExtractContent {
+			content, err := fetchArticleContent(s.ctx, post.Url)
+			if err != nil {
+				slog.Warn("couldn't extract post content", "feed", feed.Name, "post", post.Title, "error", err)
+			} else {
+				post.Content = sql.NullString{String: content, Valid: true}
+				post.WordCount = wordCount(content)
+				err = s.db.SetPostContent(s.ctx, database.SetPostContentParams{
+					ID:        post.ID,
+					Content:   post.Content,
+					WordCount: post.WordCount,
+				})
+				if err != nil {
+					slog.Warn("couldn't save post content", "feed", feed.Name, "post", post.Title, "error", err)
+				}
+			}
+		}
+
+		applyRules(s, ingestRules, feed, post, rulePost(feed, p.item, p.post))
+		res.NewPosts = append(res.NewPosts, toAPIPost(post))
+	}
+
+	return res, nil
+}
+
+// scrapeResult is the output of one aggregation pass; Feed is nil when nothing was due.
+// PostsSeen counts posts that were already saved, PostsFiltered posts dropped by ingest filters,
+// PostsSkipped items without a usable link, and PostsUpdated already saved posts whose title or
+// description changed since. In a dry run
+// nothing is saved: NewPosts and UpdatedPosts are the posts that would have been.
+type scrapeResult struct {
+	Feed          *apiFeed  `json:"feed"`
+	PostsFound    int       `json:"posts_found"`
+	PostsSaved    int       `json:"posts_saved"`
+	PostsSeen     int       `json:"posts_seen"`
+	PostsUpdated  int       `json:"posts_updated"`
+	PostsFiltered int       `json:"posts_filtered"`
+	PostsSkipped  int       `json:"posts_skipped,omitempty"`
+	NewPosts      []apiPost `json:"new_posts"`
+	UpdatedPosts  []apiPost `json:"updated_posts,omitempty"`
+	DryRun        bool      `json:"dry_run,omitempty"`
+}
+
+func (r scrapeResult) writeText(w io.Writer) {
+	if r.Feed == nil {
+		fmt.Fprintln(w, "No feeds due for fetching")
+		return
+	}
+
+	fmt.Fprintf(w, "Fetched feed: %s (URL: %s)\n", r.Feed.Name, r.Feed.Url)
+	fmt.Fprintf(w, "Found %d posts: %d new, %d already seen", r.PostsFound, r.PostsSaved, r.PostsSeen)
+	if r.PostsUpdated > 0 {
+		fmt.Fprintf(w, " (%d updated)", r.PostsUpdated)
+	}
+	if r.PostsFiltered > 0 {
+		fmt.Fprintf(w, ", filtered out %d", r.PostsFiltered)
+	}
+	if r.PostsSkipped > 0 {
+		fmt.Fprintf(w, ", skipped %d without a link", r.PostsSkipped)
+	}
+	fmt.Fprintln(w)
+
+	if r.DryRun {
+		fmt.Fprintln(w, "Dry run, so nothing was saved; new posts are marked +, updated ones ~")
+		for _, post := range r.NewPosts {
+			fmt.Fprintf(w, "  + %s (%s)\n", post.Title, post.Url)
+		}
+		for _, post := range r.UpdatedPosts {
+			fmt.Fprintf(w, "  ~ %s (%s)\n", post.Title, post.Url)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+func (r scrapeResult) table() ([]string, [][]string) {
+	if r.Feed == nil {
+		return []string{"feed", "url", "posts_found", "posts_saved", "posts_seen"}, [][]string{}
+	}
+	return []string{"feed", "url", "posts_found", "posts_saved", "posts_seen"}, [][]string{{
+		r.Feed.Name,
+		r.Feed.Url,
+		strconv.Itoa(r.PostsFound),
+		strconv.Itoa(r.PostsSaved),
+		strconv.Itoa(r.PostsSeen),
+	}}
+}
+
+// scheduleNextFetch sets when a feed is next due, from its fixed interval or its publishing cadence,
+// give or take some jitter
+func scheduleNextFetch(s *state, feed database.Feed) error {
+	times, err := s.db.GetRecentPublishTimesForFeed(s.ctx, database.GetRecentPublishTimesForFeedParams{
+		FeedID: feed.ID,
+		Limit:  20,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get publish times: %w", err)
+	}
+
+	published := make([]time.Time, 0, len(times))
+	for _, t := range times {
+		published = append(published, t.Time)
+	}
+	avg, known := schedule.AverageInterval(published)
+
+	var interval time.Duration
+	if feed.FetchInterval.Valid {
+		interval = time.Duration(feed.FetchInterval.Int32) * time.Second
+	} else {
+		min, max, err := s.cfg.FetchIntervalBounds()
+		if err != nil {
+			return err
+		}
+		interval = schedule.NextInterval(avg, known, min, max)
+	}
+
+	var avgSeconds sql.NullInt32
+	if known {
+		avgSeconds = sql.NullInt32{Int32: int32(avg / time.Second), Valid: true}
+	}
+
+	return s.db.ScheduleFeedFetch(s.ctx, database.ScheduleFeedFetchParams{
+		ID:              feed.ID,
+		NextFetchAt:     sql.NullTime{Time: time.Now().Add(schedule.Jitter(interval)), Valid: true},
+		AvgPostInterval: avgSeconds,
+	})
+}
+
+// recordFeedMove notes where a feed has permanently moved to, or follows it there when
+// auto_update_urls is on. Problems are only warned about, like the rest of the bookkeeping.
+func recordFeedMove(s *state, feed *database.Feed, movedTo string) {
+	ctx := s.ctx
+
+	if movedTo != "" && s.cfg.AutoUpdateURLs {
+		err := s.db.SetFeedURL(ctx, database.SetFeedURLParams{ID: feed.ID, Url: movedTo})
+		if err == nil {
+			slog.Info("feed moved permanently; updated its URL", "feed", feed.Name, "from", feed.Url, "to", movedTo)
+			feed.Url = movedTo
+			feed.MovedTo = sql.NullString{}
+			return
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			slog.Warn("feed moved, but another feed already uses its new URL", "feed", feed.Name, "moved_to", movedTo)
+		} else {
+			slog.Warn("couldn't update feed URL", "feed", feed.Name, "error", err)
+		}
+	}
+
+	moved := sql.NullString{String: movedTo, Valid: movedTo != ""}
+	if moved == feed.MovedTo {
+		return
+	}
+	if err := s.db.SetFeedMovedTo(ctx, database.SetFeedMovedToParams{ID: feed.ID, MovedTo: moved}); err != nil {
+		slog.Warn("couldn't record feed move", "feed", feed.Name, "error", err)
+		return
+	}
+	feed.MovedTo = moved
+	if moved.Valid {
+		slog.Warn("feed moved permanently; update it with 'gator feed set-url' or run agg with --auto-update-urls", "feed", feed.Name, "moved_to", movedTo)
+	}
+}
+
+// recordFeedChannel keeps the title, description, link and language the feed gives itself up to
+// date, as they can differ from the name it was added under
+func recordFeedChannel(s *state, feed *database.Feed, rssFeed *RSSFeed) {
+	title := strings.TrimSpace(rssFeed.Channel.Title)
+	description := htmltext.RenderInline(rssFeed.Channel.Description)
+	link := strings.TrimSpace(rssFeed.Channel.Link)
+	language := strings.TrimSpace(rssFeed.Channel.Language)
+	params := database.SetFeedChannelParams{
+		ID:                 feed.ID,
+		ChannelTitle:       sql.NullString{String: title, Valid: title != ""},
+		ChannelDescription: sql.NullString{String: description, Valid: description != ""},
+		ChannelLink:        sql.NullString{String: link, Valid: link != ""},
+		ChannelLanguage:    sql.NullString{String: language, Valid: language != ""},
+	}
+	if err := s.db.SetFeedChannel(s.ctx, params); err != nil {
+		slog.Warn("couldn't record feed channel details", "feed", feed.Name, "error", err)
+		return
+	}
+	feed.ChannelTitle = params.ChannelTitle
+	feed.ChannelDescription = params.ChannelDescription
+	feed.ChannelLink = params.ChannelLink
+	feed.ChannelLanguage = params.ChannelLanguage
+}
+
+// scheduleFetchRetry makes a feed due again after a failed fetch, backing off from the minimum
+// polling interval as failures pile up but never waiting longer than its regular schedule would
+func scheduleFetchRetry(s *state, feed database.Feed, failures int32) error {
+	min, max, err := s.cfg.FetchIntervalBounds()
+	if err != nil {
+		return err
+	}
+	if feed.FetchInterval.Valid {
+		max = time.Duration(feed.FetchInterval.Int32) * time.Second
+		if min > max {
+			min = max
+		}
+	}
+
+	return s.db.ScheduleFeedFetch(s.ctx, database.ScheduleFeedFetchParams{
+		ID:              feed.ID,
+		NextFetchAt:     sql.NullTime{Time: time.Now().Add(schedule.Backoff(int(failures), min, max)), Valid: true},
+		AvgPostInterval: feed.AvgPostInterval,
+	})
+}
+
+// channelDate is the date given to posts that don't have a readable one: when the channel was
+// last built or published, or failing that now
+func channelDate(feed *RSSFeed) time.Time {
+	for _, raw := range []string{feed.Channel.LastBuildDate, feed.Channel.PubDate} {
+		if t, err := pubdate.Parse(raw); err == nil {
+			return t
+		}
+	}
+	return time.Now().UTC()
+}
+
+// handlerAddFeed adds a new feed for the current user
+func handlerAddFeed(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 {
+		return errors.New("addfeed command requires name and url arguments")
+	}
+
+	name := cmd.args[0]
+
+	// Accept either a feed URL or a site URL that advertises one
+	url, err := resolveFeedURL(s.ctx, cmd.args[1])
+	if err != nil {
+		return fmt.Errorf("couldn't find feed: %w", err)
+	}
+	url = upgradeFeedScheme(s.ctx, url)
+
+	// The same feed under another spelling of its URL is followed rather than added again
+	existing, found, err := findEquivalentFeed(s.ctx, s.db, url)
+	if err != nil {
+		return err
+	}
+	if found {
+		return followExistingFeed(s, user, url, existing)
+	}
+
+	// Create the feed and follow it together, so a failed follow doesn't leave the feed behind
+	var feed database.Feed
+	err = s.inTx(s.ctx, func(q *database.Queries) error {
+		feed, err = q.CreateFeed(s.ctx, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Name:      name,
+			Url:       url,
+			UserID:    user.ID,
+		})
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return fmt.Errorf("feed with URL %s already exists", url)
+			}
+			return fmt.Errorf("couldn't create feed: %w", err)
+		}
+
+		_, err = q.CreateFeedFollow(s.ctx, database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't follow feed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.emit(addFeedResult{Feed: toAPIFeed(feed)})
+}
+
+// followExistingFeed offers to follow feed, which was already added under url or another
+// spelling of it, instead of adding it again. Without a terminal to ask on it only says so.
+func followExistingFeed(s *state, user database.User, url string, feed database.Feed) error {
+	exists := fmt.Errorf("%s is already added as %s (%s); follow it with: gator follow %s", url, feed.Name, feed.Url, feed.Url)
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return exists
+	}
+
+	fmt.Fprintf(os.Stderr, "%s is already added as %s (%s).\n", url, feed.Name, feed.Url)
+	ok, err := confirm("Follow it instead?")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return exists
+	}
+
+	alreadyFollowing, err := followFeed(s, user, feed, database.Category{})
+	if err != nil {
+		return err
+	}
+	if alreadyFollowing {
+		return fmt.Errorf("already following %s", feed.Name)
+	}
+	return s.emit(followResult{UserName: user.Name, FeedName: feed.Name, Feed: toAPIFeed(feed)})
+}
+
+// addFeedResult is the output of addfeed
+type addFeedResult struct {
+	Feed apiFeed `json:"feed"`
+}
+
+func (r addFeedResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, "Feed created successfully:")
+	fmt.Fprintf(w, "  ID: %s\n", r.Feed.ID)
+	fmt.Fprintf(w, "  Name: %s\n", r.Feed.Name)
+	fmt.Fprintf(w, "  URL: %s\n", r.Feed.Url)
+	fmt.Fprintf(w, "  User ID: %s\n", r.Feed.UserID)
+	fmt.Fprintf(w, "  Created at: %s\n", r.Feed.CreatedAt)
+	fmt.Fprintln(w, "(Automatically followed)")
+}
+
+func (r addFeedResult) table() ([]string, [][]string) {
+	return []string{"id", "name", "url", "user_id", "created_at"}, [][]string{{
+		r.Feed.ID.String(),
+		r.Feed.Name,
+		r.Feed.Url,
+		r.Feed.UserID.String(),
+		formatTime(&r.Feed.CreatedAt),
+	}}
+}
+
+// handlerFeeds lists all feeds in the database
+func handlerFeeds(s *state, cmd command) error {
+	feeds, err := s.db.GetFeeds(s.ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't get feeds: %w", err)
+	}
+
+	lastPosts, err := lastPostTimes(s)
+	if err != nil {
+		return err
+	}
+
+	// Notes and read state are personal, so only the current user's are shown, when someone is
+	// logged in, and only for the feeds they follow
+	notes := map[uuid.UUID]string{}
+	unread := map[uuid.UUID]int64{}
+	if user, err := s.db.GetUser(s.ctx, s.cfg.CurrentUserName); err == nil {
+		follows, err := s.db.GetFeedFollowsForUser(s.ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get feed follows: %w", err)
+		}
+		for _, follow := range follows {
+			unread[follow.FeedID] = 0
+			if follow.Note.Valid {
+				notes[follow.FeedID] = follow.Note.String
+			}
+		}
+		counts, err := s.db.GetUnreadCountsForUser(s.ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't count unread posts: %w", err)
+		}
+		for _, count := range counts {
+			unread[count.FeedID] = count.UnreadCount
+		}
+	}
+
+	res := feedsResult{Feeds: []feedEntry{}}
+	for _, feed := range feeds {
+		entry := feedEntry{
+			apiFeed:    toAPIFeed(feedFromRow(feed)),
+			UserName:   feed.UserName,
+			LastPostAt: lastPosts[feed.ID],
+		}
+		if note, ok := notes[feed.ID]; ok {
+			entry.Note = &note
+		}
+		if count, ok := unread[feed.ID]; ok {
+			entry.Unread = &count
+		}
+		res.Feeds = append(res.Feeds, entry)
+	}
+
+	return s.emit(res)
+}
+
+// feedFromRow drops the joined columns from a GetFeeds row
+func feedFromRow(row database.GetFeedsRow) database.Feed {
+	return database.Feed{
+		ID:                  row.ID,
+		CreatedAt:           row.CreatedAt,
+		UpdatedAt:           row.UpdatedAt,
+		Name:                row.Name,
+		Url:                 row.Url,
+		UserID:              row.UserID,
+		LastFetchedAt:       row.LastFetchedAt,
+		FeverID:             row.FeverID,
+		FetchInterval:       row.FetchInterval,
+		NextFetchAt:         row.NextFetchAt,
+		AvgPostInterval:     row.AvgPostInterval,
+		ExtractContent:      row.ExtractContent,
+		Paused:              row.Paused,
+		ConsecutiveFailures: row.ConsecutiveFailures,
+		FailureCount:        row.FailureCount,
+		LastError:           row.LastError,
+		LastSuccessAt:       row.LastSuccessAt,
+		MovedTo:             row.MovedTo,
+		ChannelTitle:        row.ChannelTitle,
+		ChannelDescription:  row.ChannelDescription,
+		ChannelLink:         row.ChannelLink,
+		ChannelLanguage:     row.ChannelLanguage,
+	}
+}
+
+// feedEntry is a feed in the feeds listing
+type feedEntry struct {
+	apiFeed
+	UserName   string     `json:"user_name"`
+	LastPostAt *time.Time `json:"last_post_at"`
+	// Unread is only set for feeds the current user follows
+	Unread *int64  `json:"unread_count,omitempty"`
+	Note   *string `json:"note,omitempty"`
+}
+
+// feedsResult is the output of feeds
+type feedsResult struct {
+	Feeds []feedEntry `json:"feeds"`
+}
+
+func (r feedsResult) writeText(w io.Writer) {
+	if len(r.Feeds) == 0 {
+		fmt.Fprintln(w, "No feeds found")
+		return
+	}
+
+	fmt.Fprintln(w, "Feeds:")
+	for _, feed := range r.Feeds {
+		fmt.Fprintf(w, "* Name: %s\n", feed.Name)
+		fmt.Fprintf(w, "  ID: %s\n", feed.ID)
+		fmt.Fprintf(w, "  URL: %s\n", feed.Url)
+		fmt.Fprintf(w, "  User: %s\n", feed.UserName)
+		if feed.ChannelTitle != nil && *feed.ChannelTitle != feed.Name {
+			fmt.Fprintf(w, "  Title: %s\n", *feed.ChannelTitle)
+		}
+		if feed.ChannelDescription != nil {
+			fmt.Fprintf(w, "  Description: %s\n", truncateText(*feed.ChannelDescription, 200))
+		}
+		if feed.ChannelLink != nil {
+			fmt.Fprintf(w, "  Site: %s\n", *feed.ChannelLink)
+		}
+		if feed.ChannelLanguage != nil {
+			fmt.Fprintf(w, "  Language: %s\n", *feed.ChannelLanguage)
+		}
+		if feed.Note != nil {
+			fmt.Fprintf(w, "  Note: %s\n", *feed.Note)
+		}
+		if feed.Unread != nil {
+			fmt.Fprintf(w, "  Unread: %d\n", *feed.Unread)
+		}
+		if feed.LastPostAt != nil {
+			fmt.Fprintf(w, "  Last post: %s\n", feed.LastPostAt.Format("2006-01-02 15:04:05"))
+		}
+		if feed.LastFetchedAt != nil {
+			fmt.Fprintf(w, "  Last fetched: %s", feed.LastFetchedAt.Format("2006-01-02 15:04:05"))
+			if feed.Failures > 0 {
+				fmt.Fprintf(w, " (failing, %d in a row)", feed.Failures)
+			}
+			fmt.Fprintln(w)
+		}
+		if feed.Paused {
+			fmt.Fprintln(w, "  Paused: yes")
+		}
+		if feed.MovedTo != nil {
+			fmt.Fprintf(w, "  Moved to: %s\n", *feed.MovedTo)
+		}
+		if feed.FetchInterval != nil {
+			fmt.Fprintf(w, "  Fetch interval: %s\n", time.Duration(*feed.FetchInterval)*time.Second)
+		}
+		if feed.NextFetchAt != nil {
+			fmt.Fprintf(w, "  Next fetch: %s\n", feed.NextFetchAt.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func (r feedsResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, feed := range r.Feeds {
+		interval := ""
+		if feed.FetchInterval != nil {
+			interval = (time.Duration(*feed.FetchInterval) * time.Second).String()
+		}
+		note := ""
+		if feed.Note != nil {
+			note = *feed.Note
+		}
+		unread := ""
+		if feed.Unread != nil {
+			unread = strconv.FormatInt(*feed.Unread, 10)
+		}
+		rows = append(rows, []string{
+			feed.ID.String(), feed.Name, feed.Url, feed.UserName, strconv.FormatBool(feed.Paused), interval,
+			formatTime(feed.NextFetchAt), unread, formatTime(feed.LastPostAt), formatTime(feed.LastFetchedAt), note,
+		})
+	}
+	return []string{"id", "name", "url", "user", "paused", "fetch_interval", "next_fetch_at", "unread", "last_post_at", "last_fetched_at", "note"}, rows
+}
+
+// handlerFollow follows a feed by URL, name or ID
+func handlerFollow(s *state, cmd command, user database.User) error {
+	var categoryName, fromFile string
+	flags := newFlagSet("follow")
+	flags.StringVar(&categoryName, "category", "", "Put the feed in this category `name`")
+	flags.StringVar(&fromFile, "from-file", "", "Follow every feed listed in this `file`, one per line, or - for stdin")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	if fromFile != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("unexpected follow argument %q with --from-file", args[0])
+		}
+		return followFromFile(s, user, fromFile, categoryName)
+	}
+	if len(args) == 0 {
+		return errors.New("follow command requires a feed URL, name or ID")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected follow argument %q", args[1])
+	}
+
+	// Resolve the category first so a typo doesn't leave a half-done follow
+	var category database.Category
+	if categoryName != "" {
+		category, err = getCategory(s, user, categoryName)
+		if err != nil {
+			return err
+		}
+	}
+
+	feeds, err := allFeedRefs(s)
+	if err != nil {
+		return err
+	}
+	feed, err := findFeed(s, args[0], feeds)
+	if err != nil {
+		return err
+	}
+
+	alreadyFollowing, err := followFeed(s, user, feed, category)
+	if err != nil {
+		return err
+	}
+	if alreadyFollowing {
+		// Following again with a category just moves the feed
+		if categoryName == "" {
+			return fmt.Errorf("already following this feed")
+		}
+		return s.emit(messageResult{
+			Message: fmt.Sprintf("Moved %s to %s", feed.Name, category.Name),
+			Item:    toAPIFeed(feed),
+		})
+	}
+
+	res := followResult{UserName: user.Name, FeedName: feed.Name, Feed: toAPIFeed(feed)}
+	if categoryName != "" {
+		res.Category = category.Name
+	}
+	return s.emit(res)
+}
+
+// followFeed makes user follow feed, putting it in category unless that is the zero Category.
+// It reports whether user already followed the feed, in which case only the category changes.
+func followFeed(s *state, user database.User, feed database.Feed, category database.Category) (bool, error) {
+	_, err := s.db.CreateFeedFollow(s.ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+
+	alreadyFollowing := false
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if !ok || pqErr.Code != "23505" {
+			return false, fmt.Errorf("couldn't follow feed: %w", err)
+		}
+		alreadyFollowing = true
+	}
+
+	if category.ID != uuid.Nil {
+		err = s.db.SetFeedFollowCategory(s.ctx, database.SetFeedFollowCategoryParams{
+			UserID:     user.ID,
+			FeedID:     feed.ID,
+			CategoryID: uuid.NullUUID{UUID: category.ID, Valid: true},
+		})
+		if err != nil {
+			return alreadyFollowing, fmt.Errorf("couldn't set category: %w", err)
+		}
+	}
+	return alreadyFollowing, nil
+}
+
+// addAndFollowFeed adds the feed at url and follows it, or just follows it if it was already
+// added, under url or another spelling of it. It reports whether the feed was added, and whether user already followed it.
+func addAndFollowFeed(s *state, user database.User, name, url string, category database.Category) (added, alreadyFollowing bool, err error) {
+	url, err = normalizeFeedURL(url)
+	if err != nil {
+		return false, false, err
+	}
+	if existing, found, err := findEquivalentFeed(s.ctx, s.db, url); err != nil {
+		return false, false, err
+	} else if found {
+		alreadyFollowing, err = followFeed(s, user, existing, category)
+		return false, alreadyFollowing, err
+	}
+
+	feed, err := s.db.CreateFeed(s.ctx, database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      name,
+		Url:       url,
+		UserID:    user.ID,
+	})
+	if err == nil {
+		added = true
+	} else {
+		if pqErr, ok := err.(*pq.Error); !ok || pqErr.Code != "23505" {
+			return false, false, fmt.Errorf("couldn't create feed: %w", err)
+		}
+		feed, err = getFeedByURL(s.ctx, s.db, url)
+		if err != nil {
+			return false, false, fmt.Errorf("couldn't find feed: %w", err)
+		}
+	}
+
+	alreadyFollowing, err = followFeed(s, user, feed, category)
+	return added, alreadyFollowing, err
+}
+
+// followResult is the output of follow
+type followResult struct {
+	UserName string  `json:"user_name"`
+	FeedName string  `json:"feed_name"`
+	Category string  `json:"category,omitempty"`
+	Feed     apiFeed `json:"feed"`
+}
+
+func (r followResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "%s is now following %s\n", r.UserName, r.FeedName)
+	if r.Category != "" {
+		fmt.Fprintf(w, "  Category: %s\n", r.Category)
+	}
+}
+
+func (r followResult) table() ([]string, [][]string) {
+	return []string{"user", "feed", "category"}, [][]string{{r.UserName, r.FeedName, r.Category}}
+}
+
+// handlerFollowing lists feeds the current user is following
+func handlerFollowing(s *state, cmd command, user database.User) error {
+	// Get feed follows
+	follows, err := s.db.GetFeedFollowsForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed follows: %w", err)
+	}
+
+	counts, err := s.db.GetUnreadCountsForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't count unread posts: %w", err)
+	}
+	unread := make(map[uuid.UUID]int64, len(counts))
+	for _, count := range counts {
+		unread[count.FeedID] = count.UnreadCount
+	}
+	lastPosts, err := lastPostTimes(s)
+	if err != nil {
+		return err
+	}
+	health, err := s.db.GetFeedHealthForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed health: %w", err)
+	}
+	feeds := make(map[uuid.UUID]database.GetFeedHealthForUserRow, len(health))
+	for _, row := range health {
+		feeds[row.ID] = row
+	}
+
+	res := followingResult{UserName: user.Name, Follows: []followEntry{}}
+	for _, follow := range follows {
+		entry := followEntry{
+			FeedID:     follow.FeedID,
+			FeedName:   follow.FeedName,
+			Category:   follow.CategoryName.String,
+			Muted:      follow.Muted,
+			Unread:     unread[follow.FeedID],
+			LastPostAt: lastPosts[follow.FeedID],
+		}
+		if row, ok := feeds[follow.FeedID]; ok {
+			entry.LastFetchedAt = nullTimePtr(row.LastFetchedAt)
+			entry.LastError = nullStringPtr(row.LastError)
+			entry.Health = feedHealth(feedFromHealthRow(row), row.Attempts, row.Failures)
+		}
+		res.Follows = append(res.Follows, entry)
+	}
+
+	return s.emit(res)
+}
+
+// lastPostTimes maps each feed to when its newest post was published
+func lastPostTimes(s *state) (map[uuid.UUID]*time.Time, error) {
+	rows, err := s.db.GetLastPostTimes(s.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get last post times: %w", err)
+	}
+	times := make(map[uuid.UUID]*time.Time, len(rows))
+	for _, row := range rows {
+		times[row.FeedID] = &row.LastPostAt
+	}
+	return times, nil
+}
+
+// followEntry is a feed in the following listing, with how it's doing at a glance
+type followEntry struct {
+	FeedID        uuid.UUID  `json:"feed_id"`
+	FeedName      string     `json:"feed_name"`
+	Category      string     `json:"category,omitempty"`
+	Muted         bool       `json:"muted"`
+	Unread        int64      `json:"unread_count"`
+	LastPostAt    *time.Time `json:"last_post_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	Health        string     `json:"health"`
+	LastError     *string    `json:"last_error,omitempty"`
+}
+
+// followingResult is the output of following
+type followingResult struct {
+	UserName string        `json:"user_name"`
+	Follows  []followEntry `json:"follows"`
+}
+
+func (r followingResult) writeText(w io.Writer) {
+	if len(r.Follows) == 0 {
+		fmt.Fprintln(w, "Not following any feeds")
+		return
+	}
+
+	fmt.Fprintf(w, "Feeds followed by %s:\n", r.UserName)
+	for _, follow := range r.Follows {
+		line := "* " + follow.FeedName
+		if follow.Category != "" {
+			line += " [" + follow.Category + "]"
+		}
+		if follow.Muted {
+			line += " (muted)"
+		}
+		fmt.Fprintln(w, line)
+
+		status := fmt.Sprintf("  %d unread", follow.Unread)
+		if follow.LastPostAt != nil {
+			status += ", last post " + follow.LastPostAt.Format("2006-01-02")
+		}
+		if follow.LastFetchedAt != nil {
+			status += ", fetched " + follow.LastFetchedAt.Format("2006-01-02 15:04")
+		} else {
+			status += ", never fetched"
+		}
+		if follow.Health != "" && follow.Health != "ok" {
+			status += " [" + follow.Health + "]"
+		}
+		fmt.Fprintln(w, status)
+		if follow.LastError != nil {
+			fmt.Fprintf(w, "  Last error: %s\n", *follow.LastError)
+		}
+	}
+}
+
+func (r followingResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, follow := range r.Follows {
+		lastError := ""
+		if follow.LastError != nil {
+			lastError = *follow.LastError
+		}
+		rows = append(rows, []string{
+			follow.FeedName,
+			follow.Category,
+			strconv.FormatBool(follow.Muted),
+			strconv.FormatInt(follow.Unread, 10),
+			formatTime(follow.LastPostAt),
+			formatTime(follow.LastFetchedAt),
+			follow.Health,
+			lastError,
+		})
+	}
+	return []string{"feed", "category", "muted", "unread", "last_post_at", "last_fetched_at", "health", "last_error"}, rows
+}
+
+// handlerUnfollow unfollows a feed by URL, name or ID
+func handlerUnfollow(s *state, cmd command, user database.User) error {
+	var fromFile string
+	flags := newFlagSet("unfollow")
+	flags.StringVar(&fromFile, "from-file", "", "Unfollow every feed listed in this `file`, one per line, or - for stdin")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	if fromFile != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("unexpected unfollow argument %q with --from-file", args[0])
+		}
+		return unfollowFromFile(s, user, fromFile)
+	}
+	if len(args) == 0 {
+		return errors.New("unfollow command requires a feed URL, name or ID")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected unfollow argument %q", args[1])
+	}
+
+	// Only the feeds the user follows are candidates, so a name shared with another feed still
+	// picks out the right one
+	feeds, err := followedFeedRefs(s, user)
+	if err != nil {
+		return err
+	}
+	feed, err := findFeed(s, args[0], feeds)
+	if err != nil {
+		return err
+	}
+
+	// Delete feed follow
+	err = s.db.DeleteFeedFollow(s.ctx, database.DeleteFeedFollowParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+
+	if err != nil {
+		return fmt.Errorf("couldn't unfollow feed: %w", err)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("%s has unfollowed %s", user.Name, feed.Name),
+		Item:    toAPIFeed(feed),
+	})
+}
+
+// handlerBrowse displays posts from feeds the user follows
+func handlerBrowse(s *state, cmd command, user database.User) error {
+	var limit int
+	var showAll, clustered bool
+	var categoryName, tagName, smartName, feedRef, author, feedCategory string
+	var maxWords sql.NullInt32
+	opts := browseOptions{sortBy: "published"}
+
+	flags := newFlagSet("browse")
+	flags.IntVar(&limit, "limit", 2, "Show at most `n` posts")
+	flags.BoolVar(&showAll, "all", false, "Include posts you've read")
+	flags.BoolVar(&clustered, "clustered", false, "Show one post per story, with the other posts covering it")
+	flags.StringVar(&categoryName, "category", "", "Only posts from feeds in this category `name`")
+	flags.StringVar(&tagName, "tag", "", "Only posts with this tag `name`")
+	flags.StringVar(&smartName, "smart", "", "Only posts matching this smart folder `name`")
+	flags.StringVar(&feedRef, "feed", "", "Only posts from this followed `feed`, by URL, name or ID")
+	flags.StringVar(&author, "author", "", "Only posts whose author includes `name`")
+	flags.StringVar(&feedCategory, "feed-category", "", "Only posts the feed filed under category `name`")
+	flags.Func("max-read-time", "Only posts with extracted content that takes at most `duration` to read, like 5m", func(value string) error {
+		var err error
+		maxWords, err = maxReadWords(value)
+		return err
+	})
+	for _, name := range []string{"offset", "page", "since", "until", "sort", "order"} {
+		flags.Func(name, browseFlagUsage[name], func(value string) error {
+			return opts.set("--"+name, value)
+		})
+	}
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	// The limit can also be given on its own, as in 'browse 10'
+	switch len(args) {
+	case 0:
+	case 1:
+		if limit, err = strconv.Atoi(args[0]); err != nil {
+			return fmt.Errorf("invalid limit %q", args[0])
+		}
+	default:
+		return fmt.Errorf("unexpected browse argument %q", args[1])
+	}
+	if limit < 1 {
+		return fmt.Errorf("invalid limit %d: must be at least 1", limit)
+	}
+	limit = min(limit, maxPostLimit)
+
+	if opts.page > 0 {
+		if opts.offset > 0 {
+			return errors.New("use either --page or --offset, not both")
+		}
+		opts.offset = (opts.page - 1) * limit
+	}
+
+	var feedID uuid.UUID
+	if feedRef != "" {
+		if categoryName != "" || tagName != "" || smartName != "" {
+			return errors.New("--feed can't be combined with --category, --tag or --smart")
+		}
+		if opts.sortBy == "feed" {
+			return errors.New("--sort feed makes no sense with --feed")
+		}
+		feeds, err := followedFeedRefs(s, user)
+		if err != nil {
+			return err
+		}
+		feed, err := findFeed(s, feedRef, feeds)
+		if err != nil {
+			return err
+		}
+		feedID = feed.ID
+	}
+
+	var categoryID uuid.NullUUID
+	if categoryName != "" {
+		category, err := getCategory(s, user, categoryName)
+		if err != nil {
+			return err
+		}
+		categoryID = uuid.NullUUID{UUID: category.ID, Valid: true}
+	}
+
+	var tagID uuid.NullUUID
+	if tagName != "" {
+		tag, err := getTag(s, user, tagName)
+		if err != nil {
+			return err
+		}
+		tagID = uuid.NullUUID{UUID: tag.ID, Valid: true}
+	}
+
+	// A smart folder narrows the listing further; --since on the command line wins over its own
+	var smart smartQuery
+	if smartName != "" {
+		smart, err = loadSmartFolder(s, user, smartName)
+		if err != nil {
+			return err
+		}
+		if !opts.since.Valid {
+			opts.since = smart.since
+		}
+		if !opts.until.Valid {
+			opts.until = smart.until
+		}
+	}
+	if opts.since.Valid && opts.until.Valid && !opts.since.Time.Before(opts.until.Time) {
+		return errors.New("--since must be before --until")
+	}
+
+	// Clustering folds several posts into each story shown, so read more of them
+	fetch := limit
+	if clustered {
+		fetch = limit * clusterWindow
+	}
+
+	query := func(limit, offset int) ([]database.Post, error) {
+		if feedRef != "" {
+			return s.db.GetPostsForUserByFeed(s.ctx, database.GetPostsForUserByFeedParams{
+				FeedID:       feedID,
+				UserID:       user.ID,
+				Since:        opts.since,
+				Until:        opts.until,
+				Author:       sql.NullString{String: author, Valid: author != ""},
+				MaxWords:     maxWords,
+				FeedCategory: sql.NullString{String: feedCategory, Valid: feedCategory != ""},
+				UnreadOnly:   !showAll,
+				SortBy:       opts.sortBy,
+				SortDesc:     opts.descending(),
+				Limit:        int32(limit),
+				Offset:       int32(offset),
+			})
+		}
+		if showAll {
+			return s.db.GetPostsForUser(s.ctx, database.GetPostsForUserParams{
+				UserID:       user.ID,
+				CategoryID:   categoryID,
+				TagID:        tagID,
+				Since:        opts.since,
+				Until:        opts.until,
+				Keywords:     smart.keywords,
+				Author:       sql.NullString{String: author, Valid: author != ""},
+				MaxWords:     maxWords,
+				FeedCategory: sql.NullString{String: feedCategory, Valid: feedCategory != ""},
+				FeedIds:      smart.feedIDs,
+				TagIds:       smart.tagIDs,
+				SortBy:       opts.sortBy,
+				SortDesc:     opts.descending(),
+				Limit:        int32(limit),
+				Offset:       int32(offset),
+			})
+		}
+		return s.db.GetUnreadPostsForUser(s.ctx, database.GetUnreadPostsForUserParams{
+			UserID:       user.ID,
+			CategoryID:   categoryID,
+			TagID:        tagID,
+			Since:        opts.since,
+			Until:        opts.until,
+			Keywords:     smart.keywords,
+			Author:       sql.NullString{String: author, Valid: author != ""},
+			MaxWords:     maxWords,
+			FeedCategory: sql.NullString{String: feedCategory, Valid: feedCategory != ""},
+			FeedIds:      smart.feedIDs,
+			TagIds:       smart.tagIDs,
+			SortBy:       opts.sortBy,
+			SortDesc:     opts.descending(),
+			Limit:        int32(limit),
+			Offset:       int32(offset),
+		})
+	}
+
+	filters, err := loadFilters(s.ctx, s.db, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// Filters are matched here rather than in the query, so posts they hide leave gaps in what
+	// it returns; keep reading until the page is full. positions holds where each kept post is
+	// in the unfiltered listing, which is what --offset counts.
+	var kept []database.Post
+	var positions []int
+	offset, batch := opts.offset, fetch+1
+	for len(kept) <= fetch {
+		posts, err := query(batch, offset)
+		if err != nil {
+			return fmt.Errorf("couldn't get posts: %w", err)
+		}
+		for i, post := range posts {
+			if !filters.hides(post.FeedID, post.Title, post.Description.String) {
+				kept = append(kept, post)
+				positions = append(positions, offset+i)
+			}
+		}
+		if len(posts) < batch {
+			break
+		}
+		offset += len(posts)
+		batch = min(batch*2, maxPostLimit)
+	}
+
+	res := browseResult{
+		UserName: user.Name,
+		All:      showAll,
+		Offset:   opts.offset,
+	}
+	if len(kept) > fetch {
+		next := positions[fetch]
+		res.NextOffset = &next
+		kept, positions = kept[:fetch], positions[:fetch]
+	}
+
+	var clusters []postCluster
+	if clustered {
+		clusters = clusterPosts(kept)
+		if len(clusters) > limit {
+			// The next page starts at the first story left out. Posts after it that were folded
+			// into stories shown here come up again there.
+			first := clusters[limit].Post.ID
+			next := positions[slices.IndexFunc(kept, func(p database.Post) bool { return p.ID == first })]
+			res.NextOffset = &next
+			clusters = clusters[:limit]
+		}
+		kept = make([]database.Post, 0, len(clusters))
+		for _, cluster := range clusters {
+			kept = append(kept, cluster.Post)
+		}
+	}
+	posts := kept
+
+	res.Posts = toAPIPosts(posts)
+	for i, cluster := range clusters {
+		for _, post := range cluster.Related {
+			res.Posts[i].Related = append(res.Posts[i].Related, apiRelatedPost{
+				ID:     post.ID,
+				Title:  post.Title,
+				Url:    post.Url,
+				FeedID: post.FeedID,
+			})
+		}
+	}
+	if err := addAlsoIn(s.ctx, s.db, res.Posts); err != nil {
+		return fmt.Errorf("couldn't get post sources: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	if err := saveLastBrowse(s, user, ids); err != nil {
+		return err
+	}
+
+	return s.emit(res)
+}
+
+// saveLastBrowse remembers the listed posts so "gator open <n>" can refer to them by position
+func saveLastBrowse(s *state, user database.User, ids []uuid.UUID) error {
+	err := s.db.ClearLastBrowse(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't clear last browse: %w", err)
+	}
+
+	err = s.db.SaveLastBrowse(s.ctx, database.SaveLastBrowseParams{
+		UserID:  user.ID,
+		PostIds: ids,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't save last browse: %w", err)
+	}
+	return nil
+}
+
+// browseOptions holds browse's paging, filtering and sorting flags
+type browseOptions struct {
+	offset int
+	page   int
+	since  sql.NullTime
+	until  sql.NullTime
+	sortBy string
+	order  string
+}
+
+// browseFlagUsage describes the browse flags browseOptions.set reads
+var browseFlagUsage = map[string]string{
+	"offset": "Skip the first `n` posts",
+	"page":   "Show page `n`, counting pages of --limit posts",
+	"since":  "Only posts published since `when`: a duration like 7d, a date, today or yesterday",
+	"until":  "Only posts published before `when`, given as for --since",
+	"sort":   "Sort by `field`: published, added, updated, feed or title",
+	"order":  "Sort `direction`: asc or desc",
+}
+
+// set applies one browse flag
+func (o *browseOptions) set(name, value string) error {
+	switch name {
+	case "--offset":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid offset %q", value)
+		}
+		o.offset = n
+	case "--page":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid page %q", value)
+		}
+		o.page = n
+	case "--since":
+		t, err := parseTimeArg(name, value)
+		if err != nil {
+			return err
+		}
+		o.since = sql.NullTime{Time: t, Valid: true}
+	case "--until":
+		t, err := parseTimeArg(name, value)
+		if err != nil {
+			return err
+		}
+		o.until = sql.NullTime{Time: t, Valid: true}
+	case "--sort":
+		switch value {
+		case "published", "added", "updated", "feed", "title":
+			o.sortBy = value
+		default:
+			return fmt.Errorf("invalid sort %q: expected published, added, updated, feed, or title", value)
+		}
+	case "--order":
+		if value != "asc" && value != "desc" {
+			return fmt.Errorf("invalid order %q: expected asc or desc", value)
+		}
+		o.order = value
+	}
+	return nil
+}
+
+// descending reports the sort direction; dates default to newest first, names to A-Z
+func (o *browseOptions) descending() bool {
+	if o.order != "" {
+		return o.order == "desc"
+	}
+	return o.sortBy == "published" || o.sortBy == "added" || o.sortBy == "updated"
+}
+
+// parseTimeArg reads the value of a flag like --since: a duration back from now (e.g. 36h, 7d),
+// a date (2006-01-02 or RFC 3339), or the start of today, yesterday or tomorrow in local time
+func parseTimeArg(flag, value string) (time.Time, error) {
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	switch value {
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return midnight.AddDate(0, 0, 1), nil
+	}
+	if d, err := config.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s %q: expected a duration like 48h or 7d, a date like 2006-01-02, today or yesterday", flag, value)
+}
+
+// browseResult is the output of browse; NextOffset is set when more posts follow this page
+type browseResult struct {
+	UserName   string    `json:"user_name"`
+	All        bool      `json:"all"`
+	Offset     int       `json:"offset"`
+	NextOffset *int      `json:"next_offset"`
+	Posts      []apiPost `json:"posts"`
+}
+
+func (r browseResult) writeText(w io.Writer) {
+	if len(r.Posts) == 0 {
+		if r.Offset > 0 {
+			fmt.Fprintln(w, "No more posts.")
+		} else if r.All {
+			fmt.Fprintln(w, "No posts found. Follow some feeds first!")
+		} else {
+			fmt.Fprintln(w, "No unread posts. Use --all to include read posts.")
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "Found %d posts for %s:\n", len(r.Posts), r.UserName)
+	fmt.Fprintln(w, strings.Repeat("=", 80))
+
+	for i, post := range r.Posts {
+		fmt.Fprintf(w, "\n[%d] ID: %s\n", i+1, post.ID)
+		fmt.Fprintf(w, "Title: %s\n", post.Title)
+		fmt.Fprintf(w, "URL: %s\n", post.Url)
+		if post.Author != nil {
+			fmt.Fprintf(w, "Author: %s\n", *post.Author)
+		}
+		if post.ReadingMinutes != nil {
+			fmt.Fprintf(w, "Reading time: %d min\n", *post.ReadingMinutes)
+		}
+
+		if post.Description != nil {
+			// Truncate long descriptions
+			fmt.Fprintf(w, "Description: %s\n", truncateText(htmltext.RenderInline(*post.Description), 200))
+		}
+
+		if post.PublishedAt != nil {
+			estimate := ""
+			if post.PublishedAtEstimated {
+				estimate = " (estimated)"
+			}
+			fmt.Fprintf(w, "Published: %s%s\n", post.PublishedAt.Format("2006-01-02 15:04:05"), estimate)
+		}
+
+		if len(post.AlsoIn) > 0 {
+			fmt.Fprintf(w, "Also in: %s\n", strings.Join(post.AlsoIn, ", "))
+		}
+
+		if len(post.Related) > 0 {
+			fmt.Fprintf(w, "Related coverage (%d):\n", len(post.Related))
+			for _, related := range post.Related {
+				fmt.Fprintf(w, "  - %s (%s)\n", related.Title, related.Url)
+			}
+		}
+
+		fmt.Fprintln(w, strings.Repeat("-", 80))
+	}
+
+	if r.NextOffset != nil {
+		fmt.Fprintf(w, "\nMore posts available: add --offset %d to see the next page.\n", *r.NextOffset)
+	}
+}
+
+func (r browseResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for i, post := range r.Posts {
+		rows = append(rows, []string{strconv.Itoa(i + 1), post.ID.String(), post.Title, post.Url, formatTime(post.PublishedAt)})
+	}
+	return []string{"n", "id", "title", "url", "published_at"}, rows
+}
+
+// getPostByIDOrURL looks up a post by its UUID or, failing that, its URL
+func getPostByIDOrURL(s *state, idOrURL string) (database.Post, error) {
+	var post database.Post
+	var err error
+	if id, parseErr := uuid.Parse(idOrURL); parseErr == nil {
+		post, err = s.db.GetPost(s.ctx, id)
+	} else {
+		post, err = s.db.GetPostByURL(s.ctx, idOrURL)
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return database.Post{}, fmt.Errorf("post %s doesn't exist", idOrURL)
+		}
+		return database.Post{}, fmt.Errorf("couldn't get post: %w", err)
+	}
+
+	return post, nil
+}
+
+// getListedPost looks up a post by its number in the last browse or podcasts listing, or by ID or URL
+func getListedPost(s *state, user database.User, ref string) (database.Post, error) {
+	n, err := strconv.Atoi(ref)
+	if err != nil {
+		return getPostByIDOrURL(s, ref)
+	}
+
+	post, err := s.db.GetLastBrowsePost(s.ctx, database.GetLastBrowsePostParams{
+		UserID:   user.ID,
+		Position: int32(n),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return database.Post{}, fmt.Errorf("no post %d in your last listing", n)
+		}
+		return database.Post{}, fmt.Errorf("couldn't get post: %w", err)
+	}
+	return post, nil
+}
+
+// handlerRead marks a post as read for the current user
+func handlerRead(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("read command requires a post ID or URL argument")
+	}
+
+	post, err := getPostByIDOrURL(s, cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	err = s.db.MarkPostRead(s.ctx, database.MarkPostReadParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		PostID:    post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't mark post as read: %w", err)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Marked as read: %s", post.Title),
+		Item:    toAPIPost(post),
+	})
+}
+
+// handlerOpen opens a post in the system browser and marks it read.
+// The post is the Nth from the last browse, or given by ID or URL.
+func handlerOpen(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("open command requires a post number, ID, or URL argument")
+	}
+
+	post, err := getListedPost(s, user, cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := openBrowser(post.Url); err != nil {
+		return fmt.Errorf("couldn't open browser: %w", err)
+	}
+
+	err = s.db.MarkPostRead(s.ctx, database.MarkPostReadParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		PostID:    post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't mark post as read: %w", err)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Opened: %s", post.Title),
+		Item:    toAPIPost(post),
+	})
+}
+
+// handlerUnread marks a post as unread for the current user
+func handlerUnread(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("unread command requires a post ID or URL argument")
+	}
+
+	post, err := getPostByIDOrURL(s, cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	err = s.db.MarkPostUnread(s.ctx, database.MarkPostUnreadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't mark post as unread: %w", err)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Marked as unread: %s", post.Title),
+		Item:    toAPIPost(post),
+	})
+}
+
+// handlerSave bookmarks a post for the current user
+func handlerSave(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("save command requires a post URL argument")
+	}
+
+	post, err := getPostByIDOrURL(s, cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	err = s.db.SavePost(s.ctx, database.SavePostParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		PostID:    post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't save post: %w", err)
+	}
+	forwardSavedPost(s.ctx, s.cfg, user, post)
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Saved: %s", post.Title),
+		Item:    toAPIPost(post),
+	})
+}
+
+// handlerUnsave removes a bookmark for the current user
+func handlerUnsave(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("unsave command requires a post URL argument")
+	}
+
+	post, err := getPostByIDOrURL(s, cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	err = s.db.UnsavePost(s.ctx, database.UnsavePostParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't unsave post: %w", err)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Removed from saved: %s", post.Title),
+		Item:    toAPIPost(post),
+	})
+}
+
+// handlerSaved lists posts the current user has saved
+func handlerSaved(s *state, cmd command, user database.User) error {
+	posts, err := s.db.GetSavedPostsForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get saved posts: %w", err)
+	}
+
+	return s.emit(savedResult{UserName: user.Name, Posts: toAPIPosts(posts)})
+}
+
+// savedResult is the output of saved
+type savedResult struct {
+	UserName string    `json:"user_name"`
+	Posts    []apiPost `json:"posts"`
+}
+
+func (r savedResult) writeText(w io.Writer) {
+	if len(r.Posts) == 0 {
+		fmt.Fprintln(w, "No saved posts")
+		return
+	}
+
+	fmt.Fprintf(w, "Saved posts for %s:\n", r.UserName)
+	for _, post := range r.Posts {
+		fmt.Fprintf(w, "* %s\n", post.Title)
+		fmt.Fprintf(w, "  URL: %s\n", post.Url)
+	}
+}
+
+func (r savedResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, post := range r.Posts {
+		rows = append(rows, []string{post.ID.String(), post.Title, post.Url})
+	}
+	return []string{"id", "title", "url"}, rows
+}
+
+// handlerSearch runs a full-text search over stored posts
+func handlerSearch(s *state, cmd command, user database.User) error {
+	var allFeeds bool
+	var limit int
+	flags := newFlagSet("search")
+	flags.BoolVar(&allFeeds, "all-feeds", false, "Search every feed, not just the ones you follow")
+	flags.IntVar(&limit, "limit", 20, "Show at most `n` results")
+	terms, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	if len(terms) == 0 {
+		return errors.New("search command requires a query argument")
+	}
+	if limit < 1 {
+		return fmt.Errorf("invalid limit %d: must be at least 1", limit)
+	}
+
+	query := strings.Join(terms, " ")
+
+	res := searchResult{Query: query, Results: []searchEntry{}}
+
+	if allFeeds {
+		rows, err := s.db.SearchPosts(s.ctx, database.SearchPostsParams{
+			Query:       query,
+			ResultLimit: int32(limit),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't search posts: %w", err)
+		}
+		for _, row := range rows {
+			res.Results = append(res.Results, searchEntry{
+				apiPost: toAPIPost(database.Post{
+					ID:          row.ID,
+					CreatedAt:   row.CreatedAt,
+					UpdatedAt:   row.UpdatedAt,
+					Title:       row.Title,
+					Url:         row.Url,
+					Description: row.Description,
+					PublishedAt: row.PublishedAt,
+					FeedID:      row.FeedID,
+				}),
+				Rank: row.Rank,
+			})
+		}
+	} else {
+		rows, err := s.db.SearchPostsForUser(s.ctx, database.SearchPostsForUserParams{
+			Query:       query,
+			UserID:      user.ID,
+			ResultLimit: int32(limit),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't search posts: %w", err)
+		}
+		for _, row := range rows {
+			res.Results = append(res.Results, searchEntry{
+				apiPost: toAPIPost(database.Post{
+					ID:          row.ID,
+					CreatedAt:   row.CreatedAt,
+					UpdatedAt:   row.UpdatedAt,
+					Title:       row.Title,
+					Url:         row.Url,
+					Description: row.Description,
+					PublishedAt: row.PublishedAt,
+					FeedID:      row.FeedID,
+				}),
+				Rank: row.Rank,
+			})
+		}
+	}
+
+	return s.emit(res)
+}
+
+// searchEntry is a post matching a search, with its relevance
+type searchEntry struct {
+	apiPost
+	Rank float32 `json:"rank"`
+}
+
+// searchResult is the output of search
+type searchResult struct {
+	Query   string        `json:"query"`
+	Results []searchEntry `json:"results"`
+}
+
+func (r searchResult) writeText(w io.Writer) {
+	if len(r.Results) == 0 {
+		fmt.Fprintf(w, "No posts matching %q\n", r.Query)
+		return
+	}
+
+	fmt.Fprintf(w, "Found %d posts matching %q:\n", len(r.Results), r.Query)
+	for _, result := range r.Results {
+		fmt.Fprintf(w, "* %s (rank %.3f)\n", result.Title, result.Rank)
+		fmt.Fprintf(w, "  URL: %s\n", result.Url)
+		if result.PublishedAt != nil {
+			fmt.Fprintf(w, "  Published: %s\n", result.PublishedAt.Format("2006-01-02 15:04:05"))
+		}
+	}
+}
+
+func (r searchResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, result := range r.Results {
+		rows = append(rows, []string{
+			strconv.FormatFloat(float64(result.Rank), 'f', 3, 32),
+			result.Title,
+			result.Url,
+			formatTime(result.PublishedAt),
+		})
+	}
+	return []string{"rank", "title", "url", "published_at"}, rows
+}
+
+// handlerFeed dispatches feed management subcommands
+func handlerFeed(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("feed command requires a subcommand: status, interval, pause, resume, mute, unmute, note, extract, rename, set-url, delete, transfer")
+	}
+
+	sub := command{name: cmd.args[0], args: cmd.args[1:]}
+	switch sub.name {
+	case "status":
+		return handlerFeedStatus(s, sub, user)
+	case "interval":
+		return handlerFeedInterval(s, sub, user)
+	case "pause":
+		return handlerFeedPause(s, sub, user, true)
+	case "resume":
+		return handlerFeedPause(s, sub, user, false)
+	case "mute":
+		return handlerFeedMute(s, sub, user, true)
+	case "unmute":
+		return handlerFeedMute(s, sub, user, false)
+	case "note":
+		return handlerFeedNote(s, sub, user)
+	case "extract":
+		return handlerFeedExtract(s, sub, user)
+	case "rename":
+		return handlerFeedRename(s, sub, user)
+	case "set-url":
+		return handlerFeedSetURL(s, sub, user)
+	case "delete":
+		return handlerFeedDelete(s, sub, user)
+	case "transfer":
+		return handlerFeedTransfer(s, sub, user)
+	default:
+		return fmt.Errorf("unknown feed subcommand: %s", sub.name)
+	}
+}
+
+// getOwnedFeed looks up a feed by URL and checks that user added it
+func getOwnedFeed(s *state, url string, user database.User) (database.Feed, error) {
+	feed, err := getFeedByURL(s.ctx, s.db, url)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return database.Feed{}, fmt.Errorf("feed %s doesn't exist", url)
+		}
+		return database.Feed{}, fmt.Errorf("couldn't find feed: %w", err)
+	}
+
+	if feed.UserID != user.ID {
+		return database.Feed{}, fmt.Errorf("only the user who added %s can change it", feed.Name)
+	}
+
+	return feed, nil
+}
+
+// handlerFeedInterval sets how often a feed is polled by agg
+func handlerFeedInterval(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 {
+		return errors.New("feed interval requires url and duration arguments (or \"auto\")")
+	}
+
+	feed, err := getOwnedFeed(s, cmd.args[0], user)
+	if err != nil {
+		return err
+	}
+
+	var interval sql.NullInt32
+	if cmd.args[1] != "auto" {
+		d, err := time.ParseDuration(cmd.args[1])
+		if err != nil {
+			return fmt.Errorf("invalid duration: %w", err)
+		}
+		if d < time.Second {
+			return errors.New("fetch interval must be at least 1s")
+		}
+		interval = sql.NullInt32{Int32: int32(d / time.Second), Valid: true}
+	}
+
+	err = s.db.SetFeedFetchInterval(s.ctx, database.SetFeedFetchIntervalParams{
+		ID:            feed.ID,
+		FetchInterval: interval,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't set fetch interval: %w", err)
+	}
+
+	feed.FetchInterval = interval
+	msg := fmt.Sprintf("%s will be fetched on an adaptive schedule", feed.Name)
+	if interval.Valid {
+		msg = fmt.Sprintf("%s will be fetched at most every %s", feed.Name, time.Duration(interval.Int32)*time.Second)
+	}
+	return s.emit(messageResult{Message: msg, Item: toAPIFeed(feed)})
+}
+
+// handlerFeedPause stops or restarts agg fetching a feed; its posts and follows are kept
+func handlerFeedPause(s *state, cmd command, user database.User, paused bool) error {
+	if len(cmd.args) == 0 {
+		return fmt.Errorf("feed %s requires a url argument", cmd.name)
+	}
+
+	feed, err := getOwnedFeed(s, cmd.args[0], user)
+	if err != nil {
+		return err
+	}
+
+	feed.Paused = paused
+	err = s.db.SetFeedPaused(s.ctx, database.SetFeedPausedParams{
+		ID:     feed.ID,
+		Paused: paused,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't update feed: %w", err)
+	}
+
+	msg := fmt.Sprintf("Resumed fetching %s", feed.Name)
+	if paused {
+		msg = fmt.Sprintf("Paused fetching %s", feed.Name)
+	}
+	return s.emit(messageResult{Message: msg, Item: toAPIFeed(feed)})
+}
+
+// handlerFeedExtract turns full-text extraction of new posts on or off for a feed
+func handlerFeedExtract(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 || (cmd.args[0] != "on" && cmd.args[0] != "off") {
+		return errors.New("feed extract requires on or off and a url argument")
+	}
+
+	feed, err := getOwnedFeed(s, cmd.args[1], user)
+	if err != nil {
+		return err
+	}
+
+	feed.ExtractContent = cmd.args[0] == "on"
+	err = s.db.SetFeedExtractContent(s.ctx, database.SetFeedExtractContentParams{
+		ID:             feed.ID,
+		ExtractContent: feed.ExtractContent,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't update feed: %w", err)
+	}
+
+	msg := fmt.Sprintf("New posts from %s will store only the feed's description", feed.Name)
+	if feed.ExtractContent {
+		msg = fmt.Sprintf("New posts from %s will have their article text extracted", feed.Name)
+	}
+	return s.emit(messageResult{Message: msg, Item: toAPIFeed(feed)})
+}
+
+// handlerFeedRename changes a feed's display name
+func handlerFeedRename(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 {
+		return errors.New("feed rename requires url and new name arguments")
+	}
+
+	feed, err := getOwnedFeed(s, cmd.args[0], user)
+	if err != nil {
+		return err
+	}
+
+	oldName := feed.Name
+	feed.Name = cmd.args[1]
+	err = s.db.RenameFeed(s.ctx, database.RenameFeedParams{
+		ID:   feed.ID,
+		Name: feed.Name,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't rename feed: %w", err)
+	}
+
+	return s.emit(messageResult{Message: fmt.Sprintf("%s renamed to %s", oldName, feed.Name), Item: toAPIFeed(feed)})
+}
+
+// handlerFeedSetURL points a feed that has moved at its new address, keeping its posts and follows
+func handlerFeedSetURL(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 {
+		return errors.New("feed set-url requires old and new url arguments")
+	}
+
+	feed, err := getOwnedFeed(s, cmd.args[0], user)
+	if err != nil {
+		return err
+	}
+
+	// Accept a site URL here too, as addfeed does
+	newURL, err := resolveFeedURL(s.ctx, cmd.args[1])
+	if err != nil {
+		return err
+	}
+
+	err = s.db.SetFeedURL(s.ctx, database.SetFeedURLParams{
+		ID:  feed.ID,
+		Url: newURL,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return fmt.Errorf("feed with URL %s already exists", newURL)
+		}
+		return fmt.Errorf("couldn't update feed URL: %w", err)
+	}
+
+	feed.Url = newURL
+	feed.MovedTo = sql.NullString{}
+	feed.NextFetchAt = sql.NullTime{}
+	return s.emit(messageResult{Message: fmt.Sprintf("%s now fetches from %s", feed.Name, newURL), Item: toAPIFeed(feed)})
+}
+
+// reassignFeedPosts moves the posts first saved from feedIDs, which are about to be deleted, onto
+// another feed that also carried them. A post is stored once however many feeds carry it, so
+// otherwise deleting the feed it came from first would take it from everyone else's feeds too.
+func reassignFeedPosts(ctx context.Context, q *database.Queries, feedIDs []uuid.UUID) error {
+	// One feed at a time, so two posts with the same guid can't both move onto one feed
+	for _, id := range feedIDs {
+		_, err := q.ReassignFeedPosts(ctx, database.ReassignFeedPostsParams{
+			FeedID:         id,
+			DeletedFeedIds: feedIDs,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't move posts to their other feeds: %w", err)
+		}
+	}
+	return nil
+}
+
+// deleteUser deletes a user along with the feeds they added, keeping those feeds' posts that
+// other feeds carried
+func deleteUser(s *state, user database.User) error {
+	return s.inTx(s.ctx, func(q *database.Queries) error {
+		feedIDs, err := q.GetFeedIDsAddedByUser(s.ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get feeds: %w", err)
+		}
+		if err := reassignFeedPosts(s.ctx, q, feedIDs); err != nil {
+			return err
+		}
+		if _, err := q.DeleteUser(s.ctx, user.ID); err != nil {
+			return fmt.Errorf("couldn't delete user: %w", err)
+		}
+		return nil
+	})
+}
+
+// handlerFeedDelete removes a feed with its follows and the posts no other feed carried. The owner can always delete it;
+// anyone else only once nobody else follows it.
+func handlerFeedDelete(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("feed delete requires a url argument")
+	}
+
+	feed, err := getFeedByURL(s.ctx, s.db, cmd.args[0])
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("feed %s doesn't exist", cmd.args[0])
+		}
+		return fmt.Errorf("couldn't find feed: %w", err)
+	}
+
+	followers, err := s.db.CountOtherFeedFollowers(s.ctx, database.CountOtherFeedFollowersParams{
+		FeedID: feed.ID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't count followers: %w", err)
+	}
+	if feed.UserID != user.ID && followers > 0 {
+		return fmt.Errorf("only the user who added %s can delete it while others follow it", feed.Name)
+	}
+
+	// Follows go with the feed through ON DELETE CASCADE, and so do posts no other feed carried,
+	// with their read state
+	err = s.inTx(s.ctx, func(q *database.Queries) error {
+		if err := reassignFeedPosts(s.ctx, q, []uuid.UUID{feed.ID}); err != nil {
+			return err
+		}
+		if err := q.DeleteFeed(s.ctx, feed.ID); err != nil {
+			return fmt.Errorf("couldn't delete feed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Feed deleted: %s", feed.Name)
+	if followers > 0 {
+		msg = fmt.Sprintf("Feed deleted: %s (unfollowed for %d other users)", feed.Name, followers)
+	}
+	return s.emit(messageResult{Message: msg, Item: toAPIFeed(feed)})
+}
+
+// handlerFeedTransfer hands ownership of a feed to another user
+func handlerFeedTransfer(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 {
+		return errors.New("feed transfer requires url and username arguments")
+	}
+
+	feed, err := getOwnedFeed(s, cmd.args[0], user)
+	if err != nil {
+		return err
+	}
+
+	newOwner, err := getUserByName(s, cmd.args[1])
+	if err != nil {
+		return err
+	}
+	if newOwner.ID == user.ID {
+		return fmt.Errorf("you already own %s", feed.Name)
+	}
+
+	err = s.db.SetFeedOwner(s.ctx, database.SetFeedOwnerParams{
+		ID:     feed.ID,
+		UserID: newOwner.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't transfer feed: %w", err)
+	}
+
+	feed.UserID = newOwner.ID
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("%s now belongs to %s", feed.Name, newOwner.Name),
+		Item:    toAPIFeed(feed),
+	})
+}
+
+// handlerFeedMute silences or restores new-post notifications for a followed feed
+func handlerFeedMute(s *state, cmd command, user database.User, muted bool) error {
+	if len(cmd.args) == 0 {
+		return fmt.Errorf("feed %s requires a url argument", cmd.name)
+	}
+
+	feed, err := getFeedByURL(s.ctx, s.db, cmd.args[0])
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("feed %s doesn't exist", cmd.args[0])
+		}
+		return fmt.Errorf("couldn't find feed: %w", err)
+	}
+
+	_, err = s.db.GetFeedFollow(s.ctx, database.GetFeedFollowParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("you aren't following %s", feed.Name)
+		}
+		return fmt.Errorf("couldn't get feed follow: %w", err)
+	}
+
+	err = s.db.SetFeedFollowMuted(s.ctx, database.SetFeedFollowMutedParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+		Muted:  muted,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't update feed follow: %w", err)
+	}
+
+	msg := fmt.Sprintf("Notifications enabled for %s", feed.Name)
+	if muted {
+		msg = fmt.Sprintf("Notifications muted for %s", feed.Name)
+	}
+	return s.emit(messageResult{Message: msg, Item: toAPIFeed(feed)})
+}
+
+// handlerFeedNote shows, sets or clears the user's note on a feed they follow, such as why they
+// follow it. An empty note clears it.
+func handlerFeedNote(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("feed note requires a feed URL, name or ID")
+	}
+	if len(cmd.args) > 2 {
+		return fmt.Errorf("unexpected feed note argument %q (quote the note)", cmd.args[2])
+	}
+
+	feeds, err := followedFeedRefs(s, user)
+	if err != nil {
+		return err
+	}
+	feed, err := findFeed(s, cmd.args[0], feeds)
+	if err != nil {
+		return err
+	}
+
+	if len(cmd.args) == 1 {
+		follow, err := s.db.GetFeedFollow(s.ctx, database.GetFeedFollowParams{
+			UserID: user.ID,
+			FeedID: feed.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't get feed follow: %w", err)
+		}
+		if !follow.Note.Valid {
+			return s.emit(messageResult{Message: fmt.Sprintf("No note on %s", feed.Name), Item: toAPIFeed(feed)})
+		}
+		return s.emit(messageResult{Message: follow.Note.String, Item: toAPIFeed(feed)})
+	}
+
+	text := strings.TrimSpace(cmd.args[1])
+	err = s.db.SetFeedFollowNote(s.ctx, database.SetFeedFollowNoteParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+		Note:   sql.NullString{String: text, Valid: text != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't update feed follow: %w", err)
+	}
+
+	msg := fmt.Sprintf("Noted on %s", feed.Name)
+	if text == "" {
+		msg = fmt.Sprintf("Cleared the note on %s", feed.Name)
+	}
+	return s.emit(messageResult{Message: msg, Item: toAPIFeed(feed)})
+}
+
+// getCategory looks up one of user's categories by name
+func getCategory(s *state, user database.User, name string) (database.Category, error) {
+	category, err := s.db.GetCategoryByName(s.ctx, database.GetCategoryByNameParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return database.Category{}, fmt.Errorf("category %s doesn't exist (create it with 'gator category create %s')", name, name)
+		}
+		return database.Category{}, fmt.Errorf("couldn't get category: %w", err)
+	}
+	return category, nil
+}
+
+// ensureCategory returns user's category called name, creating it if need be
+func ensureCategory(s *state, user database.User, name string) (database.Category, error) {
+	category, err := s.db.GetCategoryByName(s.ctx, database.GetCategoryByNameParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if err == nil {
+		return category, nil
+	}
+	if err != sql.ErrNoRows {
+		return database.Category{}, fmt.Errorf("couldn't get category: %w", err)
+	}
+
+	category, err = s.db.CreateCategory(s.ctx, database.CreateCategoryParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		Name:      name,
+	})
+	if err != nil {
+		return database.Category{}, fmt.Errorf("couldn't create category %s: %w", name, err)
+	}
+	return category, nil
+}
+
+// handlerCategory dispatches category management subcommands
+func handlerCategory(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("category command requires a subcommand: create, list, or delete")
+	}
+
+	sub := command{name: cmd.args[0], args: cmd.args[1:]}
+	switch sub.name {
+	case "create":
+		return handlerCategoryCreate(s, sub, user)
+	case "list":
+		return handlerCategoryList(s, sub, user)
+	case "delete":
+		return handlerCategoryDelete(s, sub, user)
+	default:
+		return fmt.Errorf("unknown category subcommand: %s", sub.name)
+	}
+}
+
+// handlerCategoryCreate creates a new category for the current user
+func handlerCategoryCreate(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("category create requires a name argument")
+	}
+
+	name := cmd.args[0]
+	category, err := s.db.CreateCategory(s.ctx, database.CreateCategoryParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		Name:      name,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return fmt.Errorf("category %s already exists", name)
+		}
+		return fmt.Errorf("couldn't create category: %w", err)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Category created: %s", category.Name),
+		Item:    categoryEntry{ID: category.ID, Name: category.Name},
+	})
+}
+
+// handlerCategoryList lists the current user's categories
+func handlerCategoryList(s *state, cmd command, user database.User) error {
+	categories, err := s.db.GetCategoriesForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get categories: %w", err)
+	}
+
+	res := categoriesResult{UserName: user.Name, Categories: []categoryEntry{}}
+	for _, category := range categories {
+		res.Categories = append(res.Categories, categoryEntry{
+			ID:        category.ID,
+			Name:      category.Name,
+			FeedCount: category.FeedCount,
+		})
+	}
+
+	return s.emit(res)
+}
+
+// categoryEntry is a category with the number of feeds filed under it
+type categoryEntry struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	FeedCount int64     `json:"feed_count"`
+}
+
+// categoriesResult is the output of category list
+type categoriesResult struct {
+	UserName   string          `json:"user_name"`
+	Categories []categoryEntry `json:"categories"`
+}
+
+func (r categoriesResult) writeText(w io.Writer) {
+	if len(r.Categories) == 0 {
+		fmt.Fprintln(w, "No categories found")
+		return
+	}
+
+	fmt.Fprintf(w, "Categories for %s:\n", r.UserName)
+	for _, category := range r.Categories {
+		fmt.Fprintf(w, "* %s (%d feeds)\n", category.Name, category.FeedCount)
+	}
+}
+
+func (r categoriesResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, category := range r.Categories {
+		rows = append(rows, []string{category.Name, strconv.FormatInt(category.FeedCount, 10)})
+	}
+	return []string{"name", "feeds"}, rows
+}
+
+// handlerCategoryDelete deletes a category, leaving its feeds uncategorised
+func handlerCategoryDelete(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("category delete requires a name argument")
+	}
+
+	category, err := getCategory(s, user, cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	err = s.db.DeleteCategory(s.ctx, category.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't delete category: %w", err)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Category deleted: %s", category.Name),
+		Item:    categoryEntry{ID: category.ID, Name: category.Name},
+	})
+}
+
+// getTag looks up one of user's tags by name
+func getTag(s *state, user database.User, name string) (database.Tag, error) {
+	tag, err := s.db.GetTagByName(s.ctx, database.GetTagByNameParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return database.Tag{}, fmt.Errorf("tag %s doesn't exist", name)
+		}
+		return database.Tag{}, fmt.Errorf("couldn't get tag: %w", err)
+	}
+	return tag, nil
+}
+
+// handlerTag labels a post with a tag, creating the tag if needed
+func handlerTag(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 {
+		return errors.New("tag command requires post URL and tag arguments")
+	}
+
+	post, err := getPostByIDOrURL(s, cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	tag, err := s.db.UpsertTag(s.ctx, database.UpsertTagParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		Name:      cmd.args[1],
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create tag: %w", err)
+	}
+
+	err = s.db.TagPost(s.ctx, database.TagPostParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		TagID:     tag.ID,
+		PostID:    post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't tag post: %w", err)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Tagged %s with %s", post.Title, tag.Name),
+		Item:    toAPIPost(post),
+	})
+}
+
+// handlerUntag removes a tag from a post
+func handlerUntag(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 {
+		return errors.New("untag command requires post URL and tag arguments")
+	}
+
+	post, err := getPostByIDOrURL(s, cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	tag, err := getTag(s, user, cmd.args[1])
+	if err != nil {
+		return err
+	}
+
+	err = s.db.UntagPost(s.ctx, database.UntagPostParams{
+		TagID:  tag.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't untag post: %w", err)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Removed tag %s from %s", tag.Name, post.Title),
+		Item:    toAPIPost(post),
+	})
+}
+
+// handlerTags lists the current user's tags, or deletes one with "tags delete <name>"
+func handlerTags(s *state, cmd command, user database.User) error {
+	if len(cmd.args) > 0 {
+		if cmd.args[0] != "delete" || len(cmd.args) < 2 {
+			return errors.New("usage: tags [delete <name>]")
+		}
+
+		tag, err := getTag(s, user, cmd.args[1])
+		if err != nil {
+			return err
+		}
+
+		err = s.db.DeleteTag(s.ctx, tag.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't delete tag: %w", err)
+		}
+
+		return s.emit(messageResult{
+			Message: fmt.Sprintf("Tag deleted: %s", tag.Name),
+			Item:    tagEntry{ID: tag.ID, Name: tag.Name},
+		})
+	}
+
+	tags, err := s.db.GetTagsForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get tags: %w", err)
+	}
+
+	res := tagsResult{UserName: user.Name, Tags: []tagEntry{}}
+	for _, tag := range tags {
+		res.Tags = append(res.Tags, tagEntry{
+			ID:        tag.ID,
+			Name:      tag.Name,
+			PostCount: tag.PostCount,
+		})
+	}
+
+	return s.emit(res)
+}
+
+// tagEntry is a tag with the number of posts carrying it
+type tagEntry struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	PostCount int64     `json:"post_count"`
+}
+
+// tagsResult is the output of tags
+type tagsResult struct {
+	UserName string     `json:"user_name"`
+	Tags     []tagEntry `json:"tags"`
+}
+
+func (r tagsResult) writeText(w io.Writer) {
+	if len(r.Tags) == 0 {
+		fmt.Fprintln(w, "No tags found")
+		return
+	}
+
+	fmt.Fprintf(w, "Tags for %s:\n", r.UserName)
+	for _, tag := range r.Tags {
+		fmt.Fprintf(w, "* %s (%d posts)\n", tag.Name, tag.PostCount)
+	}
+}
+
+func (r tagsResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, tag := range r.Tags {
+		rows = append(rows, []string{tag.Name, strconv.FormatInt(tag.PostCount, 10)})
+	}
+	return []string{"name", "posts"}, rows
+}
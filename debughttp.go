@@ -0,0 +1,115 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// debugHTTP is set by the global --debug-http flag: every request the shared clients make is
+// logged with its headers, status and timing, and a feed that can't be parsed is saved to a
+// temporary file
+var debugHTTP bool
+
+// redactedHeaders are request and response headers whose values debug logging hides
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// debugTransport logs each request as it goes out and each response as it comes back. It sits
+// below the decompressing and rate-limiting transports, so it sees the headers actually sent and
+// received and times the request alone, not the wait for its turn at the host.
+type debugTransport struct {
+	base http.RoundTripper
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target := redactURL(req.URL)
+	slog.Info("http request", "method", req.Method, "url", target, "headers", debugHeaders(req.Header))
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		slog.Info("http request failed", "url", target, "duration", time.Since(start), "error", err)
+		return nil, err
+	}
+
+	slog.Info("http response", "url", target, "status", resp.Status, "proto", resp.Proto,
+		"duration", time.Since(start), "headers", debugHeaders(resp.Header))
+	resp.Body = &debugBody{ReadCloser: resp.Body, url: target, start: start}
+	return resp, nil
+}
+
+// debugBody logs how much of a response body was read, and how long the whole response took,
+// when it's closed
+type debugBody struct {
+	io.ReadCloser
+	url   string
+	start time.Time
+	read  int64
+}
+
+func (b *debugBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	return n, err
+}
+
+func (b *debugBody) Close() error {
+	slog.Info("http response body", "url", b.url, "bytes", b.read, "duration", time.Since(b.start))
+	return b.ReadCloser.Close()
+}
+
+// logRedirect notes each hop of a redirect chain, as the client follows it
+func logRedirect(req *http.Request, via []*http.Request) {
+	status := ""
+	if req.Response != nil {
+		status = req.Response.Status
+	}
+	slog.Info("http redirect", "from", redactURL(via[len(via)-1].URL), "to", redactURL(req.URL), "status", status, "hop", len(via))
+}
+
+// debugHeaders copies h for logging, hiding credentials
+func debugHeaders(h http.Header) http.Header {
+	copied := h.Clone()
+	for name := range copied {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			copied[name] = []string{"xxxxx"}
+		}
+	}
+	return copied
+}
+
+// redactURL hides any password in u, as feeds behind basic auth carry one
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.Redacted()
+}
+
+// dumpFeedBody saves a feed body that couldn't be parsed to a temporary file when debugging HTTP,
+// and logs where, so it can be looked at
+func dumpFeedBody(feedURL string, data []byte, parseErr error) {
+	if !debugHTTP {
+		return
+	}
+
+	f, err := os.CreateTemp("", "gator-feed-*.xml")
+	if err != nil {
+		slog.Warn("couldn't save feed body", "url", feedURL, "error", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		slog.Warn("couldn't save feed body", "url", feedURL, "error", err)
+		return
+	}
+	slog.Info("saved feed that couldn't be parsed", "url", feedURL, "path", f.Name(), "bytes", len(data), "error", parseErr)
+}
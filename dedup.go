@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+// trackingParams are query parameters that only say where a reader came from; any parameter
+// starting with utm_ is dropped too
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "mc_cid": true, "mc_eid": true,
+	"igshid": true, "yclid": true, "_hsenc": true, "_hsmi": true, "ref_src": true,
+}
+
+// canonicalURL normalizes a post link so the same article linked from different feeds compares
+// equal: the scheme and host are lowercased, a leading www., default ports, fragments,
+// tracking parameters and trailing slashes are dropped, and the remaining parameters sorted.
+// Links that don't parse are returned as they are.
+func canonicalURL(link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" {
+		return link
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && !(port == "80" && u.Scheme == "http") && !(port == "443" && u.Scheme == "https") {
+		host += ":" + port
+	}
+	u.Host = host
+	u.Fragment, u.RawFragment = "", ""
+	u.User = nil
+
+	query := u.Query()
+	for name := range query {
+		if strings.HasPrefix(strings.ToLower(name), "utm_") || trackingParams[strings.ToLower(name)] {
+			query.Del(name)
+		}
+	}
+	// Encode sorts by key
+	u.RawQuery = query.Encode()
+	u.ForceQuery = false
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	return u.String()
+}
+
+// contentHash fingerprints a post by its title and the text of its description, catching copies
+// of an article published under different links. Posts without a description aren't hashed:
+// titles alone collide too often.
+func contentHash(title, description string) sql.NullString {
+	text := strings.ToLower(htmltext.RenderInline(description))
+	if text == "" {
+		return sql.NullString{}
+	}
+
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.Join(strings.Fields(title), " ")) + "\n" + text))
+	return sql.NullString{String: hex.EncodeToString(sum[:]), Valid: true}
+}
+
+// hasOwnGUID reports whether a post was saved from an item with a guid other than its link
+func hasOwnGUID(post database.Post) bool {
+	return post.Guid != post.Url
+}
+
+// addAlsoIn fills in the other feeds that carried each post
+func addAlsoIn(ctx context.Context, db *database.Queries, posts []apiPost) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	rows, err := db.GetOtherPostSources(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	sources := map[uuid.UUID][]string{}
+	for _, row := range rows {
+		sources[row.PostID] = append(sources[row.PostID], row.FeedName)
+	}
+	for i := range posts {
+		posts[i].AlsoIn = sources[posts[i].ID]
+	}
+	return nil
+}
+
+// duplicateIndex finds saved posts by link, canonical link or content hash, and the posts of
+// one feed by guid, so a batch of the feed's new posts can be checked for duplicates with one query
+type duplicateIndex struct {
+	feedID      uuid.UUID
+	byGUID      map[string]database.Post
+	byURL       map[string]database.Post
+	byCanonical map[string]database.Post
+	byHash      map[string]database.Post
+}
+
+// findDuplicatePosts indexes the posts feedID saved under the guid of any of posts, and the
+// saved posts sharing a link, canonical link or content hash with any of them
+func findDuplicatePosts(ctx context.Context, db *database.Queries, feedID uuid.UUID, posts []database.Post) (duplicateIndex, error) {
+	index := duplicateIndex{
+		feedID:      feedID,
+		byGUID:      map[string]database.Post{},
+		byURL:       map[string]database.Post{},
+		byCanonical: map[string]database.Post{},
+		byHash:      map[string]database.Post{},
+	}
+	if len(posts) == 0 {
+		return index, nil
+	}
+
+	params := database.FindDuplicatePostsParams{FeedID: feedID}
+	for _, post := range posts {
+		params.Guids = append(params.Guids, post.Guid)
+		params.Urls = append(params.Urls, post.Url)
+		if post.CanonicalUrl.Valid {
+			params.CanonicalUrls = append(params.CanonicalUrls, post.CanonicalUrl.String)
+		}
+		if post.ContentHash.Valid {
+			params.ContentHashes = append(params.ContentHashes, post.ContentHash.String)
+		}
+	}
+	existing, err := db.FindDuplicatePosts(ctx, params)
+	if err != nil {
+		return index, err
+	}
+	for _, post := range existing {
+		index.add(post)
+	}
+	return index, nil
+}
+
+// add indexes post, unless posts saved before it already hold its keys
+func (d duplicateIndex) add(post database.Post) {
+	keys := []struct {
+		m   map[string]database.Post
+		key sql.NullString
+	}{
+		{d.byGUID, sql.NullString{String: post.Guid, Valid: post.FeedID == d.feedID}},
+		{d.byURL, sql.NullString{String: post.Url, Valid: true}},
+		{d.byCanonical, post.CanonicalUrl},
+		{d.byHash, post.ContentHash},
+	}
+	for _, k := range keys {
+		if _, exists := k.m[k.key.String]; k.key.Valid && !exists {
+			k.m[k.key.String] = post
+		}
+	}
+}
+
+// find returns the earliest saved post that post duplicates. A post of the same feed with another
+// guid is a different item, even with the same link, unless one of the two has no guid of its own.
+func (d duplicateIndex) find(post database.Post) (database.Post, bool) {
+	var found database.Post
+	ok := false
+	consider := func(m map[string]database.Post, key sql.NullString) {
+		if !key.Valid {
+			return
+		}
+		match, exists := m[key.String]
+		if !exists || (match.FeedID == post.FeedID && match.Guid != post.Guid && hasOwnGUID(match) && hasOwnGUID(post)) {
+			return
+		}
+		if !ok || match.CreatedAt.Before(found.CreatedAt) {
+			found, ok = match, true
+		}
+	}
+	consider(d.byURL, sql.NullString{String: post.Url, Valid: true})
+	consider(d.byCanonical, post.CanonicalUrl)
+	consider(d.byHash, post.ContentHash)
+	return found, ok
+}
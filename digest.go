@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/config"
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/Utkarsh736/gator/internal/schedule"
+	"github.com/google/uuid"
+)
+
+// uncategorisedName heads the digest section for feeds without a category
+const uncategorisedName = "Uncategorised"
+
+// digestSummaryLength is how much of each post's description the digest shows
+const digestSummaryLength = 240
+
+var digestTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"summary": func(description sql.NullString) string {
+		if !description.Valid {
+			return ""
+		}
+		return truncateText(htmltext.RenderInline(description.String), digestSummaryLength)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; max-width: 640px; margin: auto;">
+<h1>Gator digest for {{.UserName}}</h1>
+<p>{{.PostCount}} new posts since {{.Since.Format "Mon 2 Jan 15:04"}}</p>
+{{range .Categories}}
+<h2>{{.Name}}</h2>
+<ul>
+{{range .Posts}}  <li>{{with .Thumbnail}}<img src="{{.}}" alt="" width="64" style="float: left; margin-right: 8px;">{{end}}<a href="{{.Url}}">{{.Title}}</a> <small>{{.FeedName}}{{if .PublishedAt.Valid}} &middot; {{.PublishedAt.Time.Format "2006-01-02 15:04"}}{{end}}</small>{{with summary .Description}}<br>{{.}}{{end}}<br style="clear: both;"></li>
+{{end}}</ul>
+{{end}}
+</body>
+</html>
+`))
+
+// digest is the data rendered into the digest email
+type digest struct {
+	UserName   string
+	Since      time.Time
+	PostCount  int
+	Categories []digestCategory
+}
+
+// digestCategory is one section of the digest
+type digestCategory struct {
+	Name  string
+	Posts []digestPost
+}
+
+// digestPost is a post in the digest with its thumbnail, if it has one
+type digestPost struct {
+	database.GetDigestPostsForUserRow
+	Thumbnail string
+}
+
+// handlerDigest emails an HTML digest of new posts from followed feeds, grouped by category
+func handlerDigest(s *state, cmd command, user database.User) error {
+	since, err := s.cfg.DigestSince()
+	if err != nil {
+		return err
+	}
+	email := ""
+	if s.cfg.Digest != nil {
+		email = s.cfg.Digest.Email
+	}
+
+	flags := newFlagSet("digest")
+	flags.Func("since", "Include posts from this `duration` back, like 24h or 7d", func(value string) error {
+		d, err := config.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		since = d
+		return nil
+	})
+	flags.StringVar(&email, "email", email, "Send the digest to this `address`")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unknown digest argument %q", args[0])
+	}
+
+	if email == "" {
+		return errors.New("digest command requires --email or digest.email in the config")
+	}
+
+	count, err := sendDigest(s, user, email, since)
+	if err != nil {
+		return err
+	}
+
+	return s.emit(digestResult{Email: email, Since: since.String(), Posts: count})
+}
+
+// digestResult is the output of digest
+type digestResult struct {
+	Email string `json:"email"`
+	Since string `json:"since"`
+	Posts int    `json:"posts"`
+}
+
+func (r digestResult) writeText(w io.Writer) {
+	if r.Posts == 0 {
+		fmt.Fprintf(w, "No new posts in the last %s; digest not sent\n", r.Since)
+		return
+	}
+	fmt.Fprintf(w, "Sent digest of %d posts to %s\n", r.Posts, r.Email)
+}
+
+func (r digestResult) table() ([]string, [][]string) {
+	return []string{"email", "since", "posts"}, [][]string{{r.Email, r.Since, strconv.Itoa(r.Posts)}}
+}
+
+// sendDigest mails user's posts from the last since to email, returning how many were
+// included. Nothing is sent when there are no new posts.
+func sendDigest(s *state, user database.User, email string, since time.Duration) (int, error) {
+	if s.cfg.SMTP == nil || s.cfg.SMTP.Host == "" {
+		return 0, errors.New("no smtp server configured; add an smtp block to the config")
+	}
+
+	d := digest{UserName: user.Name, Since: time.Now().Add(-since)}
+	posts, err := s.db.GetDigestPostsForUser(s.ctx, database.GetDigestPostsForUserParams{
+		UserID: user.ID,
+		Since:  d.Since,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("couldn't get posts: %w", err)
+	}
+	posts = uniqueDigestPosts(posts)
+	if len(posts) == 0 {
+		return 0, nil
+	}
+
+	thumbnails := map[uuid.UUID]string{}
+	if !s.cfg.SkipImages {
+		ids := make([]uuid.UUID, 0, len(posts))
+		for _, post := range posts {
+			ids = append(ids, post.ID)
+		}
+		rows, err := s.db.GetPostThumbnails(s.ctx, ids)
+		if err != nil {
+			return 0, fmt.Errorf("couldn't get thumbnails: %w", err)
+		}
+		for _, row := range rows {
+			thumbnails[row.PostID] = row.Url
+		}
+	}
+
+	// Posts arrive sorted by category, so each section is a contiguous run
+	for _, post := range posts {
+		name := uncategorisedName
+		if post.CategoryName.Valid {
+			name = post.CategoryName.String
+		}
+		if len(d.Categories) == 0 || d.Categories[len(d.Categories)-1].Name != name {
+			d.Categories = append(d.Categories, digestCategory{Name: name})
+		}
+		last := &d.Categories[len(d.Categories)-1]
+		last.Posts = append(last.Posts, digestPost{GetDigestPostsForUserRow: post, Thumbnail: thumbnails[post.ID]})
+	}
+	d.PostCount = len(posts)
+
+	var body bytes.Buffer
+	if err := digestTemplate.Execute(&body, d); err != nil {
+		return 0, fmt.Errorf("couldn't render digest: %w", err)
+	}
+
+	subject := fmt.Sprintf("Gator digest: %d new posts", d.PostCount)
+	if err := sendHTMLMail(s.cfg.SMTP, email, subject, body.String()); err != nil {
+		return 0, fmt.Errorf("couldn't send digest: %w", err)
+	}
+
+	return d.PostCount, nil
+}
+
+// uniqueDigestPosts keeps the first row for each post. A post comes back once for every followed
+// feed that carries it, and the first is in the category listed first.
+func uniqueDigestPosts(posts []database.GetDigestPostsForUserRow) []database.GetDigestPostsForUserRow {
+	seen := map[uuid.UUID]bool{}
+	unique := posts[:0]
+	for _, post := range posts {
+		if seen[post.ID] {
+			continue
+		}
+		seen[post.ID] = true
+		unique = append(unique, post)
+	}
+	return unique
+}
+
+// sendHTMLMail delivers an HTML message through the configured SMTP server
+func sendHTMLMail(cfg *config.SMTPConfig, to, subject, html string) error {
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	from := cfg.From
+	if from == "" {
+		from = cfg.Username
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	msg.WriteString(html)
+
+	return smtp.SendMail(addr, auth, from, []string{to}, msg.Bytes())
+}
+
+// digestScheduler sends the configured digest from the agg loop whenever its cron schedule comes due
+type digestScheduler struct {
+	cron schedule.Cron
+	next time.Time
+}
+
+// newDigestScheduler returns nil if no digest schedule is configured
+func newDigestScheduler(s *state) (*digestScheduler, error) {
+	if s.cfg.Digest == nil || s.cfg.Digest.Schedule == "" {
+		return nil, nil
+	}
+	if s.cfg.Digest.Email == "" {
+		return nil, errors.New("digest.schedule is set but digest.email is empty")
+	}
+
+	cron, err := schedule.ParseCron(s.cfg.Digest.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest schedule: %w", err)
+	}
+
+	return &digestScheduler{cron: cron, next: cron.Next(time.Now())}, nil
+}
+
+// runIfDue sends the current user's digest if its scheduled time has passed
+func (d *digestScheduler) runIfDue(s *state) error {
+	if d == nil || time.Now().Before(d.next) {
+		return nil
+	}
+	d.next = d.cron.Next(time.Now())
+
+	user, err := s.db.GetUser(s.ctx, s.cfg.CurrentUserName)
+	if err != nil {
+		return fmt.Errorf("couldn't get current user: %w", err)
+	}
+
+	since, err := s.cfg.DigestSince()
+	if err != nil {
+		return err
+	}
+
+	count, err := sendDigest(s, user, s.cfg.Digest.Email, since)
+	if err != nil {
+		return err
+	}
+
+	return s.emit(digestResult{Email: s.cfg.Digest.Email, Since: since.String(), Posts: count})
+}
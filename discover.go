@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	linkTagPattern   = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	attributePattern = regexp.MustCompile(`(?is)([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+// discoveredFeed is a feed advertised by a <link rel="alternate"> tag
+type discoveredFeed struct {
+	Title string
+	URL   string
+}
+
+// resolveFeedURL returns pageURL if it is already a feed, otherwise the feed it advertises,
+// normalized either way
+func resolveFeedURL(ctx context.Context, pageURL string) (string, error) {
+	pageURL, err := normalizeFeedURL(pageURL)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := readResponse(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	// Already an RSS feed, nothing to discover
+	var feed RSSFeed
+	if xml.Unmarshal(data, &feed) == nil && feed.Channel.Title != "" {
+		return pageURL, nil
+	}
+
+	feeds, err := discoverFeeds(resp.Request.URL, string(data))
+	if err != nil {
+		return "", err
+	}
+
+	switch len(feeds) {
+	case 0:
+		return "", fmt.Errorf("no RSS feeds found at %s", pageURL)
+	case 1:
+		fmt.Fprintf(os.Stderr, "Discovered feed: %s\n", feeds[0].URL)
+		return normalizeFeedURL(feeds[0].URL)
+	default:
+		feedURL, err := chooseFeed(feeds)
+		if err != nil {
+			return "", err
+		}
+		return normalizeFeedURL(feedURL)
+	}
+}
+
+// discoverFeeds finds RSS <link rel="alternate"> tags in an HTML page
+func discoverFeeds(base *url.URL, page string) ([]discoveredFeed, error) {
+	var feeds []discoveredFeed
+	seen := map[string]bool{}
+
+	for _, tag := range linkTagPattern.FindAllString(page, -1) {
+		attrs := map[string]string{}
+		for _, match := range attributePattern.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(match[1])] = html.UnescapeString(match[2] + match[3] + match[4])
+		}
+
+		if !hasToken(attrs["rel"], "alternate") || strings.ToLower(strings.TrimSpace(attrs["type"])) != "application/rss+xml" {
+			continue
+		}
+
+		href, err := base.Parse(strings.TrimSpace(attrs["href"]))
+		if err != nil || attrs["href"] == "" {
+			continue
+		}
+
+		feedURL := href.String()
+		if seen[feedURL] {
+			continue
+		}
+		seen[feedURL] = true
+
+		feeds = append(feeds, discoveredFeed{Title: attrs["title"], URL: feedURL})
+	}
+
+	return feeds, nil
+}
+
+// hasToken reports whether a space-separated attribute value contains token
+func hasToken(value, token string) bool {
+	for _, field := range strings.Fields(value) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// chooseFeed asks the user to pick one of several discovered feeds
+func chooseFeed(feeds []discoveredFeed) (string, error) {
+	// Prompts go to stderr so stdout stays clean for --json
+	fmt.Fprintln(os.Stderr, "Multiple feeds found:")
+	for i, feed := range feeds {
+		if feed.Title != "" {
+			fmt.Fprintf(os.Stderr, "  %d. %s (%s)\n", i+1, feed.Title, feed.URL)
+		} else {
+			fmt.Fprintf(os.Stderr, "  %d. %s\n", i+1, feed.URL)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Choose a feed [1-%d]: ", len(feeds))
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("couldn't read choice: %w", err)
+	}
+
+	choice, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || choice < 1 || choice > len(feeds) {
+		return "", fmt.Errorf("invalid choice %q", strings.TrimSpace(line))
+	}
+
+	return feeds[choice-1].URL, nil
+}
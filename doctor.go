@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/migrate"
+	"github.com/lib/pq"
+)
+
+// doctorCheck is one check run by doctor; Status is ok, warn, fail, or skip when an earlier
+// check failed
+type doctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// doctorResult is the output of doctor
+type doctorResult struct {
+	Checks []doctorCheck `json:"checks"`
+}
+
+func (r doctorResult) writeText(w io.Writer) {
+	for _, check := range r.Checks {
+		fmt.Fprintf(w, "%-6s %s: %s\n", "["+check.Status+"]", check.Name, check.Message)
+	}
+}
+
+func (r doctorResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, check := range r.Checks {
+		rows = append(rows, []string{check.Name, check.Status, check.Message})
+	}
+	return []string{"check", "status", "message"}, rows
+}
+
+// handlerDoctor checks the config, that the database can be reached, that its schema is up to
+// date and that the connection pool suits it, saying how to fix what isn't right
+func handlerDoctor(s *state, cmd command) error {
+	if len(cmd.args) > 0 {
+		return fmt.Errorf("unexpected doctor argument %q", cmd.args[0])
+	}
+
+	res := doctorResult{Checks: []doctorCheck{}}
+	add := func(name, status, message string) {
+		res.Checks = append(res.Checks, doctorCheck{Name: name, Status: status, Message: message})
+	}
+
+	if err := validateConfig(s.cfg); err != nil {
+		add("config", "fail", fmt.Sprintf("%v; fix it with 'gator config set' or in %s", err, s.cfg.Path()))
+	} else {
+		add("config", "ok", s.cfg.Path())
+	}
+
+	connected := false
+	if s.cfg.DbURL == "" {
+		add("database", "fail", "no db_url; run 'gator config set db_url <url>' or set GATOR_DB_URL")
+	} else {
+		ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+		err := s.conn.PingContext(ctx)
+		cancel()
+		if err != nil {
+			add("database", "fail", diagnoseConnectError(s.cfg.DbURL, err))
+		} else {
+			connected = true
+			add("database", "ok", "connected to "+displaySetting("db_url", s.cfg.DbURL))
+		}
+	}
+
+	if !connected {
+		add("schema", "skip", "needs a database connection")
+		add("pool", "skip", "needs a database connection")
+	} else {
+		add(checkSchema(s))
+		add(checkPool(s))
+	}
+
+	if err := s.emit(res); err != nil {
+		return err
+	}
+	failed := 0
+	for _, check := range res.Checks {
+		if check.Status == "fail" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(res.Checks))
+	}
+	return nil
+}
+
+// diagnoseConnectError turns a failed connection into what to do about it
+func diagnoseConnectError(dbURL string, err error) string {
+	host, name := "the database server", "the database in db_url"
+	if u, parseErr := url.Parse(dbURL); parseErr == nil && u.Host != "" {
+		host = u.Host
+		if n := strings.TrimPrefix(u.Path, "/"); n != "" {
+			name = n
+		}
+	}
+
+	var pqErr *pq.Error
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &pqErr) && (pqErr.Code == "28P01" || pqErr.Code == "28000"):
+		return fmt.Sprintf("%s rejected the user or password in db_url; check them, or the server's pg_hba.conf (%s)", host, pqErr.Message)
+	case errors.As(err, &pqErr) && pqErr.Code == "3D000":
+		return fmt.Sprintf("database %s doesn't exist; create it with 'createdb %s'", name, name)
+	case errors.As(err, &pqErr) && pqErr.Code == "53300":
+		return fmt.Sprintf("%s has no connections left; lower database.max_open_conns or raise max_connections on the server", host)
+	case errors.Is(err, pq.ErrSSLNotSupported):
+		return fmt.Sprintf("%s doesn't support SSL; add sslmode=disable to db_url if it's a local server", host)
+	case errors.As(err, &dnsErr):
+		return fmt.Sprintf("couldn't resolve %s; check the host in db_url", dnsErr.Name)
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Sprintf("nothing is listening at %s; is PostgreSQL running, and on that port?", host)
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Sprintf("timed out connecting to %s; check the host and port, and any firewall in between", host)
+	}
+	return fmt.Sprintf("couldn't connect to %s: %v", displaySetting("db_url", dbURL), err)
+}
+
+// checkSchema compares the database's schema version with the migrations built into gator
+func checkSchema(s *state) (string, string, string) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return "schema", "fail", fmt.Sprintf("couldn't load migrations: %v", err)
+	}
+	current, err := migrate.CurrentVersion(s.ctx, s.conn)
+	if err != nil {
+		return "schema", "fail", fmt.Sprintf("couldn't check schema version: %v; does the db_url user have access to the database?", err)
+	}
+
+	latest := migrate.Latest(migrations)
+	switch {
+	case current == 0:
+		return "schema", "fail", "no tables yet; run 'gator migrate' to create them"
+	case current < latest:
+		return "schema", "fail", fmt.Sprintf("version %d, but this gator needs %d; run 'gator migrate'", current, latest)
+	case current > latest:
+		return "schema", "warn", fmt.Sprintf("version %d is newer than this gator knows (%d); upgrade gator", current, latest)
+	}
+	return "schema", "ok", "version " + strconv.FormatInt(current, 10)
+}
+
+// checkPool compares the connection pool with the server's connection limit and how many feeds
+// agg fetches at once
+func checkPool(s *state) (string, string, string) {
+	pool, err := s.cfg.DatabasePool()
+	if err != nil {
+		return "pool", "fail", err.Error()
+	}
+
+	open := "unlimited"
+	if pool.MaxOpenConns > 0 {
+		open = strconv.Itoa(pool.MaxOpenConns)
+	}
+	summary := fmt.Sprintf("%s open, %d idle, lifetime %s", open, pool.MaxIdleConns, pool.ConnMaxLifetime)
+
+	var setting string
+	err = s.conn.QueryRowContext(s.ctx, "SHOW max_connections").Scan(&setting)
+	serverMax, convErr := strconv.Atoi(setting)
+	switch {
+	case err != nil || convErr != nil:
+		return "pool", "warn", summary + "; couldn't read the server's max_connections"
+	case pool.MaxOpenConns == 0 || pool.MaxOpenConns > serverMax:
+		return "pool", "warn", fmt.Sprintf("%s, but the server allows %d connections; set database.max_open_conns below that", summary, serverMax)
+	case s.cfg.FetchConcurrencyLimit() > pool.MaxOpenConns:
+		return "pool", "warn", fmt.Sprintf("%s, fewer than fetch_concurrency (%d); fetches will wait for connections, so raise database.max_open_conns", summary, s.cfg.FetchConcurrencyLimit())
+	}
+	return "pool", "ok", fmt.Sprintf("%s; the server allows %d", summary, serverMax)
+}
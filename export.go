@@ -0,0 +1,308 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Utkarsh736/gator/internal/config"
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+// Export formats: md writes a Markdown file per post, csv and json one file of every post
+const (
+	exportFormatMarkdown = "md"
+	exportFormatCSV      = "csv"
+	exportFormatJSON     = "json"
+)
+
+// maxSlugLength keeps Markdown file names short enough to read in a file list
+const maxSlugLength = 60
+
+// handlerExport writes data out to files for archiving or other tools: export posts
+func handlerExport(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("export command requires a subcommand: posts")
+	}
+
+	sub := command{name: cmd.args[0], args: cmd.args[1:]}
+	switch sub.name {
+	case "posts":
+		return handlerExportPosts(s, sub, user)
+	default:
+		return fmt.Errorf("unknown export subcommand: %s", sub.name)
+	}
+}
+
+// handlerExportPosts writes the posts from followed feeds, and any others the user has read or
+// saved, with their read and saved state and tags
+func handlerExportPosts(s *state, cmd command, user database.User) error {
+	var format, since, dir string
+	var readOnly, savedOnly bool
+	flags := newFlagSet("export posts")
+	flags.StringVar(&format, "format", exportFormatMarkdown, "File format: `md|csv|json`")
+	flags.StringVar(&since, "since", "", "Only posts published in the last `duration`, like 30d")
+	flags.StringVar(&dir, "out", ".", "Write the files to this `directory`, creating it if needed")
+	flags.BoolVar(&readOnly, "read", false, "Only posts you've read")
+	flags.BoolVar(&savedOnly, "saved", false, "Only posts you've saved")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unknown export posts argument %q", args[0])
+	}
+	if format != exportFormatMarkdown && format != exportFormatCSV && format != exportFormatJSON {
+		return fmt.Errorf("unknown export format %q (expected md, csv or json)", format)
+	}
+
+	params := database.GetPostsForExportParams{UserID: user.ID, ReadOnly: readOnly, SavedOnly: savedOnly}
+	if since != "" {
+		age, err := config.ParseDuration(since)
+		if err != nil {
+			return err
+		}
+		params.Since = sql.NullTime{Time: time.Now().Add(-age), Valid: true}
+	}
+	rows, err := s.db.GetPostsForExport(s.ctx, params)
+	if err != nil {
+		return fmt.Errorf("couldn't get posts: %w", err)
+	}
+
+	posts := make([]exportedPost, 0, len(rows))
+	for _, row := range rows {
+		posts = append(posts, toExportedPost(row))
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("couldn't create export directory: %w", err)
+	}
+	var files []string
+	switch format {
+	case exportFormatMarkdown:
+		files, err = exportMarkdown(dir, posts)
+	case exportFormatCSV:
+		files, err = exportSingleFile(filepath.Join(dir, "posts.csv"), posts, writeExportCSV)
+	case exportFormatJSON:
+		files, err = exportSingleFile(filepath.Join(dir, "posts.json"), posts, writeExportJSON)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.emit(exportResult{Format: format, Directory: dir, Posts: len(posts), Files: files})
+}
+
+// exportedPost is a post as export writes it, with its text rendered from HTML
+type exportedPost struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	Url         string     `json:"url"`
+	Feed        string     `json:"feed"`
+	PublishedAt *time.Time `json:"published_at"`
+	ReadAt      *time.Time `json:"read_at"`
+	Saved       bool       `json:"saved"`
+	Tags        []string   `json:"tags"`
+	Description string     `json:"description,omitempty"`
+	Content     string     `json:"content,omitempty"`
+}
+
+func toExportedPost(row database.GetPostsForExportRow) exportedPost {
+	post := exportedPost{
+		ID:          row.ID,
+		Title:       row.Title,
+		Url:         row.Url,
+		Feed:        row.FeedName,
+		PublishedAt: nullTimePtr(row.PublishedAt),
+		ReadAt:      nullTimePtr(row.ReadAt),
+		Saved:       row.IsSaved,
+		Tags:        row.Tags,
+		Content:     row.Content.String,
+	}
+	if post.Tags == nil {
+		post.Tags = []string{}
+	}
+	if row.Description.Valid {
+		post.Description = htmltext.Render(row.Description.String)
+	}
+	return post
+}
+
+// exportMarkdown writes each post to its own Markdown file, named by date and title, with its
+// details in YAML front matter as note-taking apps like Obsidian read it
+func exportMarkdown(dir string, posts []exportedPost) ([]string, error) {
+	files := make([]string, 0, len(posts))
+	used := map[string]bool{}
+	for _, post := range posts {
+		name := markdownFileName(post)
+		if used[name] {
+			name = strings.TrimSuffix(name, ".md") + "-" + post.ID.String()[:8] + ".md"
+		}
+		used[name] = true
+
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(renderMarkdown(post)), 0o644); err != nil {
+			return files, fmt.Errorf("couldn't write %s: %w", path, err)
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// markdownFileName is a post's publication date and a slug of its title, like
+// 2024-05-01-hello-world.md
+func markdownFileName(post exportedPost) string {
+	date := "undated"
+	if post.PublishedAt != nil {
+		date = post.PublishedAt.Format("2006-01-02")
+	}
+	slug := slugify(post.Title)
+	if slug == "" {
+		slug = post.ID.String()[:8]
+	}
+	return date + "-" + slug + ".md"
+}
+
+// slugify lowercases s and joins its words with dashes, dropping everything but letters and
+// digits
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+		if b.Len() >= maxSlugLength {
+			break
+		}
+	}
+	return b.String()
+}
+
+// renderMarkdown renders a post as front matter followed by its title and text. Front matter
+// strings are written as JSON, which YAML reads as quoted strings.
+func renderMarkdown(post exportedPost) string {
+	var b strings.Builder
+	quote := func(s string) string {
+		data, _ := json.Marshal(s)
+		return string(data)
+	}
+
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", quote(post.Title))
+	fmt.Fprintf(&b, "url: %s\n", quote(post.Url))
+	fmt.Fprintf(&b, "feed: %s\n", quote(post.Feed))
+	if post.PublishedAt != nil {
+		fmt.Fprintf(&b, "published: %s\n", post.PublishedAt.Format(time.RFC3339))
+	}
+	if post.ReadAt != nil {
+		fmt.Fprintf(&b, "read: %s\n", post.ReadAt.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "saved: %t\n", post.Saved)
+	tags, _ := json.Marshal(post.Tags)
+	fmt.Fprintf(&b, "tags: %s\n", tags)
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n\n", post.Title)
+	body := post.Content
+	if body == "" {
+		body = post.Description
+	}
+	if body != "" {
+		b.WriteString(strings.TrimSpace(body) + "\n\n")
+	}
+	fmt.Fprintf(&b, "[Read the original](%s)\n", post.Url)
+	return b.String()
+}
+
+// exportSingleFile writes every post to one file with write
+func exportSingleFile(path string, posts []exportedPost, write func(io.Writer, []exportedPost) error) ([]string, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create %s: %w", path, err)
+	}
+	if err := write(f, posts); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("couldn't write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("couldn't write %s: %w", path, err)
+	}
+	return []string{path}, nil
+}
+
+func writeExportCSV(w io.Writer, posts []exportedPost) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "title", "url", "feed", "published_at", "read_at", "saved", "tags", "description", "content"}); err != nil {
+		return err
+	}
+	for _, post := range posts {
+		err := cw.Write([]string{
+			post.ID.String(),
+			post.Title,
+			post.Url,
+			post.Feed,
+			formatTime(post.PublishedAt),
+			formatTime(post.ReadAt),
+			strconv.FormatBool(post.Saved),
+			strings.Join(post.Tags, ";"),
+			post.Description,
+			post.Content,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeExportJSON(w io.Writer, posts []exportedPost) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(posts)
+}
+
+// exportResult is the output of export posts
+type exportResult struct {
+	Format    string   `json:"format"`
+	Directory string   `json:"directory"`
+	Posts     int      `json:"posts"`
+	Files     []string `json:"files"`
+}
+
+func (r exportResult) writeText(w io.Writer) {
+	switch {
+	case r.Posts == 0 && r.Format == exportFormatMarkdown:
+		fmt.Fprintln(w, "No posts to export")
+	case r.Format == exportFormatMarkdown:
+		fmt.Fprintf(w, "Exported %d posts to %s\n", r.Posts, r.Directory)
+	default:
+		fmt.Fprintf(w, "Exported %d posts to %s\n", r.Posts, r.Files[0])
+	}
+}
+
+func (r exportResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, file := range r.Files {
+		rows = append(rows, []string{file})
+	}
+	return []string{"file"}, rows
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// fetchLogSize is how many fetch attempts are kept per feed
+const fetchLogSize = 50
+
+// failingThreshold is how many failures in a row mark a feed as failing rather than flaky
+const failingThreshold = 3
+
+// recordFetch logs one fetch and updates the feed's failure counters, pausing the feed once it
+// has failed too many times in a row. newPosts and seenPosts count the feed's items that were
+// saved and that were already saved, when they got that far. It returns how many fetches in a
+// row have failed. Problems are only warned about, so bookkeeping never stops agg.
+func recordFetch(s *state, feed database.Feed, status int, elapsed time.Duration, items int, newPosts, seenPosts sql.NullInt32, fetchErr error) int32 {
+	ctx := s.ctx
+
+	var errText sql.NullString
+	if fetchErr != nil {
+		errText = sql.NullString{String: fetchErr.Error(), Valid: true}
+	}
+	err := s.db.CreateFetchLog(ctx, database.CreateFetchLogParams{
+		ID:         uuid.New(),
+		CreatedAt:  time.Now(),
+		FeedID:     feed.ID,
+		StatusCode: sql.NullInt32{Int32: int32(status), Valid: status != 0},
+		Error:      errText,
+		DurationMs: int32(elapsed.Milliseconds()),
+		ItemCount:  int32(items),
+		NewCount:   newPosts,
+		SeenCount:  seenPosts,
+	})
+	if err != nil {
+		slog.Warn("couldn't log fetch", "feed", feed.Name, "error", err)
+	}
+	err = s.db.TrimFetchLog(ctx, database.TrimFetchLogParams{FeedID: feed.ID, Keep: fetchLogSize})
+	if err != nil {
+		slog.Warn("couldn't trim fetch log", "feed", feed.Name, "error", err)
+	}
+
+	if fetchErr == nil {
+		if err := s.db.RecordFeedFetchSuccess(ctx, feed.ID); err != nil {
+			slog.Warn("couldn't update feed health", "feed", feed.Name, "error", err)
+		}
+		return 0
+	}
+
+	failures, err := s.db.RecordFeedFetchFailure(ctx, database.RecordFeedFetchFailureParams{
+		ID:        feed.ID,
+		LastError: errText,
+	})
+	if err != nil {
+		slog.Warn("couldn't update feed health", "feed", feed.Name, "error", err)
+		return feed.ConsecutiveFailures + 1
+	}
+
+	limit, ok := s.cfg.FetchFailureLimit()
+	if !ok || int(failures) < limit {
+		return failures
+	}
+	err = s.db.SetFeedPaused(ctx, database.SetFeedPausedParams{ID: feed.ID, Paused: true})
+	if err != nil {
+		slog.Warn("couldn't pause feed", "feed", feed.Name, "error", err)
+		return failures
+	}
+	slog.Warn("paused feed after failed fetches; run 'gator feed resume' once it's fixed", "feed", feed.Name, "url", feed.Url, "failures", failures)
+	return failures
+}
+
+// feedHealth classifies a feed from its failure counters and recent fetch log
+func feedHealth(feed database.Feed, attempts, failures int64) string {
+	switch {
+	case feed.Paused && feed.ConsecutiveFailures > 0:
+		return "dead"
+	case feed.Paused:
+		return "paused"
+	case feed.ConsecutiveFailures >= failingThreshold:
+		return "failing"
+	case failures > 0 && failures*5 >= attempts:
+		return "flaky"
+	case attempts == 0:
+		return "new"
+	}
+	return "ok"
+}
+
+// handlerFeedStatus shows the fetch health of followed feeds, worst first, or the recent fetch
+// attempts of one feed
+func handlerFeedStatus(s *state, cmd command, user database.User) error {
+	if len(cmd.args) > 1 {
+		return errors.New("feed status takes at most one url argument")
+	}
+	if len(cmd.args) == 1 {
+		return handlerFeedFetchLog(s, cmd.args[0])
+	}
+
+	rows, err := s.db.GetFeedHealthForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed health: %w", err)
+	}
+
+	res := feedStatusResult{Feeds: []feedStatusEntry{}}
+	for _, row := range rows {
+		feed := feedFromHealthRow(row)
+		res.Feeds = append(res.Feeds, feedStatusEntry{
+			apiFeed:       toAPIFeed(feed),
+			Note:          nullStringPtr(row.Note),
+			Health:        feedHealth(feed, row.Attempts, row.Failures),
+			TotalFailures: row.FailureCount,
+			Attempts:      row.Attempts,
+			RecentFailed:  row.Failures,
+		})
+	}
+	return s.emit(res)
+}
+
+// feedFromHealthRow drops the fetch log counts from a GetFeedHealthForUser row
+func feedFromHealthRow(row database.GetFeedHealthForUserRow) database.Feed {
+	return database.Feed{
+		ID:                  row.ID,
+		CreatedAt:           row.CreatedAt,
+		UpdatedAt:           row.UpdatedAt,
+		Name:                row.Name,
+		Url:                 row.Url,
+		UserID:              row.UserID,
+		LastFetchedAt:       row.LastFetchedAt,
+		FeverID:             row.FeverID,
+		FetchInterval:       row.FetchInterval,
+		NextFetchAt:         row.NextFetchAt,
+		AvgPostInterval:     row.AvgPostInterval,
+		ExtractContent:      row.ExtractContent,
+		Paused:              row.Paused,
+		ConsecutiveFailures: row.ConsecutiveFailures,
+		FailureCount:        row.FailureCount,
+		LastError:           row.LastError,
+		LastSuccessAt:       row.LastSuccessAt,
+		MovedTo:             row.MovedTo,
+		ChannelTitle:        row.ChannelTitle,
+		ChannelDescription:  row.ChannelDescription,
+		ChannelLink:         row.ChannelLink,
+		ChannelLanguage:     row.ChannelLanguage,
+	}
+}
+
+// handlerFeedFetchLog shows the most recent fetch attempts of a feed
+func handlerFeedFetchLog(s *state, url string) error {
+	feed, err := getFeedByURL(s.ctx, s.db, url)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("feed %s doesn't exist", url)
+		}
+		return fmt.Errorf("couldn't find feed: %w", err)
+	}
+
+	entries, err := s.db.GetFetchLogForFeed(s.ctx, database.GetFetchLogForFeedParams{
+		FeedID: feed.ID,
+		Limit:  20,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get fetch log: %w", err)
+	}
+
+	res := fetchLogResult{Feed: toAPIFeed(feed), Attempts: []fetchLogEntry{}}
+	for _, entry := range entries {
+		res.Attempts = append(res.Attempts, fetchLogEntry{
+			At:         entry.CreatedAt,
+			StatusCode: nullInt32Ptr(entry.StatusCode),
+			Error:      nullStringPtr(entry.Error),
+			DurationMs: entry.DurationMs,
+			ItemCount:  entry.ItemCount,
+			NewCount:   nullInt32Ptr(entry.NewCount),
+			SeenCount:  nullInt32Ptr(entry.SeenCount),
+		})
+	}
+	return s.emit(res)
+}
+
+// feedStatusEntry is a feed in the feed status listing. RecentFailed and Attempts count the
+// kept fetch log; TotalFailures counts every failure since the feed was added.
+type feedStatusEntry struct {
+	apiFeed
+	Note          *string `json:"note,omitempty"`
+	Health        string  `json:"health"`
+	TotalFailures int32   `json:"total_failures"`
+	Attempts      int64   `json:"recent_attempts"`
+	RecentFailed  int64   `json:"recent_failures"`
+}
+
+// feedStatusResult is the output of feed status
+type feedStatusResult struct {
+	Feeds []feedStatusEntry `json:"feeds"`
+}
+
+func (r feedStatusResult) writeText(w io.Writer) {
+	if len(r.Feeds) == 0 {
+		fmt.Fprintln(w, "You aren't following any feeds")
+		return
+	}
+
+	for _, feed := range r.Feeds {
+		fmt.Fprintf(w, "* [%s] %s (%s)\n", feed.Health, feed.Name, feed.Url)
+		if feed.Note != nil {
+			fmt.Fprintf(w, "  Note: %s\n", *feed.Note)
+		}
+		fmt.Fprintf(w, "  Recent fetches: %d, failed %d", feed.Attempts, feed.RecentFailed)
+		if feed.Failures > 0 {
+			fmt.Fprintf(w, " (%d in a row)", feed.Failures)
+		}
+		fmt.Fprintln(w)
+		if feed.LastError != nil {
+			fmt.Fprintf(w, "  Last error: %s\n", *feed.LastError)
+		}
+		if feed.LastSuccessAt != nil {
+			fmt.Fprintf(w, "  Last success: %s\n", feed.LastSuccessAt.Format("2006-01-02 15:04:05"))
+		}
+		if feed.MovedTo != nil {
+			fmt.Fprintf(w, "  Moved permanently to: %s\n", *feed.MovedTo)
+		}
+	}
+}
+
+func (r feedStatusResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, feed := range r.Feeds {
+		lastError := ""
+		if feed.LastError != nil {
+			lastError = *feed.LastError
+		}
+		rows = append(rows, []string{
+			feed.Name,
+			feed.Url,
+			feed.Health,
+			strconv.FormatInt(feed.Attempts, 10),
+			strconv.FormatInt(feed.RecentFailed, 10),
+			strconv.Itoa(int(feed.Failures)),
+			lastError,
+			formatTime(feed.LastSuccessAt),
+		})
+	}
+	return []string{"name", "url", "health", "recent_attempts", "recent_failures", "consecutive_failures", "last_error", "last_success_at"}, rows
+}
+
+// fetchLogEntry is one fetch attempt; StatusCode is nil when no response was received, and
+// NewCount and SeenCount when the feed's posts weren't saved
+type fetchLogEntry struct {
+	At         time.Time `json:"at"`
+	StatusCode *int32    `json:"status_code"`
+	Error      *string   `json:"error"`
+	DurationMs int32     `json:"duration_ms"`
+	ItemCount  int32     `json:"item_count"`
+	NewCount   *int32    `json:"new_count"`
+	SeenCount  *int32    `json:"seen_count"`
+}
+
+// fetchLogResult is the output of feed status <url>
+type fetchLogResult struct {
+	Feed     apiFeed         `json:"feed"`
+	Attempts []fetchLogEntry `json:"attempts"`
+}
+
+func (r fetchLogResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Fetch log for %s (%s):\n", r.Feed.Name, r.Feed.Url)
+	if len(r.Attempts) == 0 {
+		fmt.Fprintln(w, "  No fetches recorded yet")
+		return
+	}
+
+	for _, entry := range r.Attempts {
+		status := "-"
+		if entry.StatusCode != nil {
+			status = strconv.Itoa(int(*entry.StatusCode))
+		}
+		outcome := fmt.Sprintf("%d items", entry.ItemCount)
+		if entry.NewCount != nil && entry.SeenCount != nil {
+			outcome += fmt.Sprintf(": %d new, %d already seen", *entry.NewCount, *entry.SeenCount)
+		}
+		if entry.Error != nil {
+			outcome = "error: " + *entry.Error
+		}
+		fmt.Fprintf(w, "  %s  %3s  %6dms  %s\n", entry.At.Format("2006-01-02 15:04:05"), status, entry.DurationMs, outcome)
+	}
+}
+
+func (r fetchLogResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, entry := range r.Attempts {
+		status, errText, newCount, seenCount := "", "", "", ""
+		if entry.NewCount != nil {
+			newCount = strconv.Itoa(int(*entry.NewCount))
+		}
+		if entry.SeenCount != nil {
+			seenCount = strconv.Itoa(int(*entry.SeenCount))
+		}
+		if entry.StatusCode != nil {
+			status = strconv.Itoa(int(*entry.StatusCode))
+		}
+		if entry.Error != nil {
+			errText = *entry.Error
+		}
+		rows = append(rows, []string{
+			formatTime(&entry.At),
+			status,
+			errText,
+			strconv.Itoa(int(entry.DurationMs)),
+			strconv.Itoa(int(entry.ItemCount)),
+			newCount,
+			seenCount,
+		})
+	}
+	return []string{"at", "status_code", "error", "duration_ms", "item_count", "new_count", "seen_count"}, rows
+}
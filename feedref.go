@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/fuzzy"
+	"github.com/google/uuid"
+)
+
+// minIDPrefix is the shortest start of a feed ID that is taken as one, so a short name isn't
+// mistaken for the start of an ID
+const minIDPrefix = 4
+
+// feedRef is a feed that follow and unfollow can pick out by its URL, ID or name
+type feedRef struct {
+	ID   uuid.UUID
+	Name string
+	Url  string
+}
+
+// findFeed looks up the feed ref names among candidates: by URL, by ID or the start of one, by
+// name, by part of its name or URL, or failing those by a name close to it. The first of these
+// to match anything decides; when it matches several feeds, the user picks one.
+func findFeed(s *state, ref string, candidates []feedRef) (database.Feed, error) {
+	match, err := matchFeedRef(ref, candidates)
+	if err != nil {
+		return database.Feed{}, err
+	}
+
+	feed, err := getFeedByURL(s.ctx, s.db, match.Url)
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("couldn't find feed: %w", err)
+	}
+	return feed, nil
+}
+
+// matchFeedRef picks the candidate ref names; see findFeed
+func matchFeedRef(ref string, candidates []feedRef) (feedRef, error) {
+	matches := feedRefMatches(ref, candidates)
+	switch len(matches) {
+	case 0:
+		return feedRef{}, fmt.Errorf("no feed matches %q", ref)
+	case 1:
+		return matches[0], nil
+	}
+	return chooseFeedRef(ref, matches)
+}
+
+// feedRefMatches returns every candidate in the first stage of findFeed to match ref
+func feedRefMatches(ref string, candidates []feedRef) []feedRef {
+	lower := strings.ToLower(strings.TrimSpace(ref))
+	normalized, _ := normalizeFeedURL(ref)
+	stages := []func(feedRef) bool{
+		func(f feedRef) bool { return f.Url == ref || (normalized != "" && f.Url == normalized) },
+		func(f feedRef) bool { return len(lower) >= minIDPrefix && strings.HasPrefix(f.ID.String(), lower) },
+		func(f feedRef) bool { return strings.ToLower(f.Name) == lower },
+		func(f feedRef) bool {
+			return strings.Contains(strings.ToLower(f.Name), lower) || strings.Contains(strings.ToLower(f.Url), lower)
+		},
+	}
+
+	var matches []feedRef
+	for _, matchesRef := range stages {
+		for _, f := range candidates {
+			if matchesRef(f) {
+				matches = append(matches, f)
+			}
+		}
+		if len(matches) > 0 {
+			break
+		}
+	}
+
+	// Allow a typo or two, in proportion to the name's length
+	if len(matches) == 0 && lower != "" {
+		names := make([]string, len(candidates))
+		for i, f := range candidates {
+			names[i] = strings.ToLower(f.Name)
+		}
+		if closest, ok := fuzzy.Closest(lower, names, max(1, len(lower)/4)); ok {
+			for _, f := range candidates {
+				if strings.ToLower(f.Name) == closest {
+					matches = append(matches, f)
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// chooseFeedRef asks which of several matching feeds was meant. Without a terminal to ask on,
+// it lists them instead.
+func chooseFeedRef(ref string, matches []feedRef) (feedRef, error) {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		names := make([]string, len(matches))
+		for i, f := range matches {
+			names[i] = fmt.Sprintf("%s (%s)", f.Name, f.Url)
+		}
+		return feedRef{}, fmt.Errorf("%q matches %d feeds: %s; give more of the name, or the URL", ref, len(matches), strings.Join(names, ", "))
+	}
+
+	discovered := make([]discoveredFeed, len(matches))
+	for i, f := range matches {
+		discovered[i] = discoveredFeed{Title: f.Name, URL: f.Url}
+	}
+	url, err := chooseFeed(discovered)
+	if err != nil {
+		return feedRef{}, err
+	}
+	for _, f := range matches {
+		if f.Url == url {
+			return f, nil
+		}
+	}
+	return feedRef{}, fmt.Errorf("no feed matches %q", ref)
+}
+
+// allFeedRefs lists every feed, for follow
+func allFeedRefs(s *state) ([]feedRef, error) {
+	feeds, err := s.db.GetFeeds(s.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get feeds: %w", err)
+	}
+
+	refs := make([]feedRef, 0, len(feeds))
+	for _, feed := range feeds {
+		refs = append(refs, feedRef{ID: feed.ID, Name: feed.Name, Url: feed.Url})
+	}
+	return refs, nil
+}
+
+// followedFeedRefs lists the feeds user follows, for unfollow
+func followedFeedRefs(s *state, user database.User) ([]feedRef, error) {
+	all, err := allFeedRefs(s)
+	if err != nil {
+		return nil, err
+	}
+	follows, err := s.db.GetFeedFollowsForUser(s.ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get feed follows: %w", err)
+	}
+
+	followed := map[uuid.UUID]bool{}
+	for _, follow := range follows {
+		followed[follow.FeedID] = true
+	}
+	refs := []feedRef{}
+	for _, f := range all {
+		if followed[f.ID] {
+			refs = append(refs, f)
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+)
+
+// errPrivateAddress is returned for feeds on loopback, link-local or private addresses when
+// those are blocked
+var errPrivateAddress = errors.New("feeds on local or private network addresses aren't allowed")
+
+// blockPrivateAddresses stops gator from fetching anything on a loopback, link-local or private
+// address; see guardPrivateAddresses
+var blockPrivateAddresses atomic.Bool
+
+// guardPrivateAddresses decides whether this run may fetch from private addresses. With
+// http.private_addresses unset they're blocked while serving, where anyone with an account can
+// make gator fetch a URL, and when the database has several users, whose feeds agg fetches.
+func guardPrivateAddresses(s *state, serving bool) error {
+	var setting string
+	if s.cfg.HTTP != nil {
+		setting = s.cfg.HTTP.PrivateAddresses
+	}
+
+	switch setting {
+	case "allow":
+		blockPrivateAddresses.Store(false)
+	case "block":
+		blockPrivateAddresses.Store(true)
+	default:
+		block := serving
+		if !block {
+			users, err := s.db.GetUsers(s.ctx)
+			if err != nil {
+				return fmt.Errorf("couldn't get users: %w", err)
+			}
+			block = len(users) > 1
+		}
+		blockPrivateAddresses.Store(block)
+	}
+	return nil
+}
+
+// normalizeFeedURL checks that raw is an http or https URL with a host, and returns it in one
+// canonical form, so the same feed isn't added twice under different spellings: the scheme and
+// host lower-cased, the default port and any fragment dropped, and an empty path made "/"
+func normalizeFeedURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("invalid feed URL %q", raw)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported feed URL scheme in %q (expected http or https)", raw)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return "", fmt.Errorf("feed URL %q has no host", raw)
+	}
+
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+
+	u.Fragment, u.RawFragment = "", ""
+	if u.Path == "" && u.RawPath == "" {
+		u.Path = "/"
+	}
+	return u.String(), nil
+}
+
+// validateFeedURL normalizes raw and, when private addresses are blocked, rejects it if its host
+// is or resolves to one. Fetches are checked again as they connect, so this is for a clear
+// error up front rather than the only line of defence.
+func validateFeedURL(ctx context.Context, raw string) (string, error) {
+	normalized, err := normalizeFeedURL(raw)
+	if err != nil || !blockPrivateAddresses.Load() {
+		return normalized, err
+	}
+
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return "", fmt.Errorf("invalid feed URL %q", raw)
+	}
+	if err := checkHostAddress(ctx, u.Hostname()); err != nil {
+		return "", err
+	}
+	return normalized, nil
+}
+
+// checkHostAddress returns errPrivateAddress if host is or resolves to a private address
+func checkHostAddress(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if isPrivateAddress(addr) {
+			return errPrivateAddress
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("couldn't resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if isPrivateAddress(addr) {
+			return errPrivateAddress
+		}
+	}
+	return nil
+}
+
+// isPrivateAddress reports whether addr is loopback, link-local, private (RFC 1918 or an IPv6
+// unique local address), unspecified or multicast
+func isPrivateAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() ||
+		addr.IsUnspecified() || (addr.Is4() && addr.As4()[0] == 0)
+}
+
+// checkDialAddress is a net.Dialer Control function that refuses connections to private
+// addresses while they're blocked. It sees the address after DNS resolution, so it also covers
+// redirects and hosts that resolve differently by the time they're fetched.
+func checkDialAddress(network, address string, _ syscall.RawConn) error {
+	if !blockPrivateAddresses.Load() {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("couldn't parse address %s: %w", address, err)
+	}
+	if isPrivateAddress(addr) {
+		return fmt.Errorf("%w: %s", errPrivateAddress, addr)
+	}
+	return nil
+}
+
+// getFeedByURL looks a feed up by its URL as given, then normalized, so a feed is found however
+// its URL is spelled, including ones added before URLs were normalized
+func getFeedByURL(ctx context.Context, db *database.Queries, rawURL string) (database.Feed, error) {
+	feed, err := db.GetFeedByURL(ctx, rawURL)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return feed, err
+	}
+	normalized, nerr := normalizeFeedURL(rawURL)
+	if nerr != nil || normalized == rawURL {
+		return feed, err
+	}
+	return db.GetFeedByURL(ctx, normalized)
+}
+
+// feedURLVariants returns the spellings of a normalized feed URL that count as the same feed:
+// over http and https, and with and without a trailing slash on the path. The URL itself comes
+// first.
+func feedURLVariants(normalized string) []string {
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return []string{normalized}
+	}
+
+	// Toggle the slash on everything before the query, leaving a bare host's "/" alone
+	rest, query, hasQuery := strings.Cut(strings.TrimPrefix(normalized, u.Scheme+"://"), "?")
+	rests := []string{rest}
+	if trimmed := strings.TrimSuffix(rest, "/"); trimmed != rest && strings.Contains(trimmed, "/") {
+		rests = append(rests, trimmed)
+	} else if trimmed == rest {
+		rests = append(rests, rest+"/")
+	}
+
+	// With an explicit port, the other scheme would be a different server
+	schemes := []string{u.Scheme}
+	if u.Port() == "" {
+		schemes = append(schemes, otherScheme(u.Scheme))
+	}
+
+	var variants []string
+	for _, scheme := range schemes {
+		for _, rest := range rests {
+			v := scheme + "://" + rest
+			if hasQuery {
+				v += "?" + query
+			}
+			variants = append(variants, v)
+		}
+	}
+	return variants
+}
+
+func otherScheme(scheme string) string {
+	if scheme == "https" {
+		return "http"
+	}
+	return "https"
+}
+
+// findEquivalentFeed returns a feed already added under feedURL or a variant of it; see
+// feedURLVariants. An exact match wins over a variant.
+func findEquivalentFeed(ctx context.Context, db *database.Queries, feedURL string) (database.Feed, bool, error) {
+	variants := feedURLVariants(feedURL)
+	feeds, err := db.GetFeedsByURLs(ctx, variants)
+	if err != nil {
+		return database.Feed{}, false, fmt.Errorf("couldn't look for existing feeds: %w", err)
+	}
+	if len(feeds) == 0 {
+		return database.Feed{}, false, nil
+	}
+	for _, variant := range variants {
+		for _, feed := range feeds {
+			if feed.Url == variant {
+				return feed, true, nil
+			}
+		}
+	}
+	return feeds[0], true, nil
+}
+
+// upgradeTimeout bounds the https check in upgradeFeedScheme, so a host that doesn't answer on
+// https doesn't hold up addfeed for the whole HTTP timeout
+const upgradeTimeout = 10 * time.Second
+
+// upgradeFeedScheme returns the https form of an http feed URL when the same feed answers there,
+// and feedURL unchanged otherwise
+func upgradeFeedScheme(ctx context.Context, feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil || u.Scheme != "http" {
+		return feedURL
+	}
+	u.Scheme = "https"
+
+	ctx, cancel := context.WithTimeout(ctx, upgradeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return feedURL
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return feedURL
+	}
+	defer resp.Body.Close()
+
+	data, err := readResponse(resp.Body)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return feedURL
+	}
+	var feed RSSFeed
+	if xml.Unmarshal(data, &feed) != nil || feed.Channel.Title == "" {
+		return feedURL
+	}
+	return u.String()
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// fetch exits with exitFetchPartial when some due feeds were fetched and others failed; a
+// complete failure exits with 1 like any other error
+const exitFetchPartial = 2
+
+// exitError is an error that should end gator with a specific exit code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// handlerFetch fetches every due feed, or the one feed given, once and exits, for scheduling
+// from cron or a systemd timer instead of a long-running agg
+func handlerFetch(s *state, cmd command) error {
+	notify := s.cfg.Notify
+	flags := newFlagSet("fetch")
+	flags.BoolVar(&notify, "notify", notify, "Send a desktop notification for new posts")
+	flags.BoolFunc("no-notify", "Don't send desktop notifications", negatedBool("no-notify", &notify))
+	flags.BoolVar(&s.dryRun, "dry-run", false, "Fetch and parse feeds, showing what would be saved without saving it")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected argument %q", args[1])
+	}
+	if err := guardPrivateAddresses(s, false); err != nil {
+		return err
+	}
+	feedURL := ""
+	if len(args) == 1 {
+		feedURL = args[0]
+	}
+
+	var notifier *postNotifier
+	if notify {
+		user, err := s.db.GetUser(s.ctx, s.cfg.CurrentUserName)
+		if err != nil {
+			return fmt.Errorf("--notify requires a logged-in user: %w", err)
+		}
+		notifier = &postNotifier{user: user}
+	}
+
+	s.webhooks = startWebhookSender()
+	defer s.webhooks.close()
+
+	// Like agg, a feed being fetched when gator is interrupted is finished and announced; fetch
+	// then stops rather than starting on the next feeds
+	work := s.withContext(context.WithoutCancel(s.ctx))
+
+	if feedURL != "" {
+		feed, err := getFeedByURL(s.ctx, s.db, feedURL)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("feed %s doesn't exist", feedURL)
+			}
+			return fmt.Errorf("couldn't find feed: %w", err)
+		}
+
+		res, err := scrapeFeed(work, feed)
+		if err != nil {
+			return err
+		}
+		announceScrape(work, notifier, res)
+		return s.emit(fetchResult{Fetched: []scrapeResult{res}, Failed: []fetchFailure{}})
+	}
+
+	if err := applyRetention(s); err != nil {
+		slog.Error("couldn't prune posts", "error", err)
+	}
+
+	res := fetchResult{Fetched: []scrapeResult{}, Failed: []fetchFailure{}}
+	// A fetched feed is rescheduled, so each feed comes up once; stopping at a repeat keeps a
+	// feed that couldn't be rescheduled from looping forever
+	seen := map[uuid.UUID]bool{}
+	for s.ctx.Err() == nil {
+		// A dry run doesn't reschedule the feeds it fetches, so they stay due; ask for enough to
+		// get past them
+		limit := s.cfg.FetchConcurrencyLimit()
+		if s.dryRun {
+			limit += len(seen)
+		}
+		due, err := nextFeedsToFetch(s, limit)
+		if err != nil {
+			return fmt.Errorf("couldn't get next feeds to fetch: %w", err)
+		}
+		feeds := []database.Feed{}
+		for _, feed := range due {
+			if !seen[feed.ID] {
+				seen[feed.ID] = true
+				feeds = append(feeds, feed)
+			}
+		}
+		if len(feeds) == 0 {
+			break
+		}
+
+		results, errs := scrapeFeeds(work, feeds)
+		for i, feed := range feeds {
+			if errs[i] != nil {
+				slog.Warn("couldn't fetch feed", "feed", feed.Name, "error", errs[i])
+				res.Failed = append(res.Failed, fetchFailure{Feed: toAPIFeed(feed), Error: errs[i].Error()})
+				continue
+			}
+			announceScrape(work, notifier, results[i])
+			res.Fetched = append(res.Fetched, results[i])
+		}
+	}
+
+	if err := s.emit(res); err != nil {
+		return err
+	}
+	if err := s.ctx.Err(); err != nil {
+		return fmt.Errorf("fetch interrupted: %w", err)
+	}
+
+	switch {
+	case len(res.Failed) == 0:
+		return nil
+	case len(res.Fetched) == 0:
+		return fmt.Errorf("all %d due feeds failed to fetch", len(res.Failed))
+	default:
+		return &exitError{
+			code: exitFetchPartial,
+			err:  fmt.Errorf("%d of %d due feeds failed to fetch", len(res.Failed), len(res.Failed)+len(res.Fetched)),
+		}
+	}
+}
+
+// fetchFailure is a due feed that failed to fetch, and why
+type fetchFailure struct {
+	Feed  apiFeed `json:"feed"`
+	Error string  `json:"error"`
+}
+
+// fetchResult is the output of fetch
+type fetchResult struct {
+	Fetched []scrapeResult `json:"fetched"`
+	Failed  []fetchFailure `json:"failed"`
+}
+
+func (r fetchResult) writeText(w io.Writer) {
+	if len(r.Fetched) == 0 && len(r.Failed) == 0 {
+		fmt.Fprintln(w, "No feeds due for fetching")
+		return
+	}
+
+	for _, scraped := range r.Fetched {
+		scraped.writeText(w)
+	}
+	for _, failure := range r.Failed {
+		fmt.Fprintf(w, "Failed to fetch %s (URL: %s): %s\n", failure.Feed.Name, failure.Feed.Url, failure.Error)
+	}
+	fmt.Fprintf(w, "Fetched %d feeds, %d failed\n", len(r.Fetched), len(r.Failed))
+}
+
+func (r fetchResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, scraped := range r.Fetched {
+		rows = append(rows, []string{
+			scraped.Feed.Name,
+			scraped.Feed.Url,
+			strconv.Itoa(scraped.PostsFound),
+			strconv.Itoa(scraped.PostsSaved),
+			strconv.Itoa(scraped.PostsSeen),
+			"",
+		})
+	}
+	for _, failure := range r.Failed {
+		rows = append(rows, []string{failure.Feed.Name, failure.Feed.Url, "", "", "", failure.Error})
+	}
+	return []string{"feed", "url", "posts_found", "posts_saved", "posts_seen", "error"}, rows
+}
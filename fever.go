@@ -0,0 +1,367 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// feverGroupID is the single group every followed feed belongs to
+const feverGroupID = 1
+
+// feverItem is an item in the Fever "items" response
+type feverItem struct {
+	ID            int64  `json:"id"`
+	FeedID        int64  `json:"feed_id"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	HTML          string `json:"html"`
+	URL           string `json:"url"`
+	IsSaved       int    `json:"is_saved"`
+	IsRead        int    `json:"is_read"`
+	CreatedOnTime int64  `json:"created_on_time"`
+}
+
+// feverFeed is a feed in the Fever "feeds" response
+type feverFeed struct {
+	ID                int64  `json:"id"`
+	FaviconID         int64  `json:"favicon_id"`
+	Title             string `json:"title"`
+	URL               string `json:"url"`
+	SiteURL           string `json:"site_url"`
+	IsSpark           int    `json:"is_spark"`
+	LastUpdatedOnTime int64  `json:"last_updated_on_time"`
+}
+
+// handleFever implements the Fever API (https://feedafever.com/api) used by clients like Reeder.
+// Clients sign in with the gator username as the email and the API key as the password.
+func (api *apiServer) handleFever(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid form body")
+		return
+	}
+
+	resp := map[string]interface{}{
+		"api_version": 3,
+		"auth":        0,
+	}
+
+	user, err := api.feverUser(r, r.PostForm.Get("api_key"))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Error("couldn't authenticate Fever client", "error", err)
+		}
+		respondWithJSON(w, http.StatusOK, resp)
+		return
+	}
+	resp["auth"] = 1
+
+	if err := api.feverMark(r, user); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	feeds, err := api.db.GetFeverFeedsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get feeds")
+		return
+	}
+
+	var lastRefreshed int64
+	for _, feed := range feeds {
+		if feed.LastFetchedAt.Valid && feed.LastFetchedAt.Time.Unix() > lastRefreshed {
+			lastRefreshed = feed.LastFetchedAt.Time.Unix()
+		}
+	}
+	resp["last_refreshed_on_time"] = lastRefreshed
+
+	if query.Has("groups") || query.Has("feeds") {
+		feedIDs := make([]string, 0, len(feeds))
+		for _, feed := range feeds {
+			feedIDs = append(feedIDs, strconv.FormatInt(feed.FeverID, 10))
+		}
+		resp["feeds_groups"] = []map[string]interface{}{
+			{"group_id": feverGroupID, "feed_ids": strings.Join(feedIDs, ",")},
+		}
+	}
+
+	if query.Has("groups") {
+		resp["groups"] = []map[string]interface{}{
+			{"id": feverGroupID, "title": "All"},
+		}
+	}
+
+	if query.Has("feeds") {
+		out := make([]feverFeed, 0, len(feeds))
+		for _, feed := range feeds {
+			var updated int64
+			if feed.LastFetchedAt.Valid {
+				updated = feed.LastFetchedAt.Time.Unix()
+			}
+			out = append(out, feverFeed{
+				ID:                feed.FeverID,
+				Title:             feed.Name,
+				URL:               feed.Url,
+				SiteURL:           feed.Url,
+				LastUpdatedOnTime: updated,
+			})
+		}
+		resp["feeds"] = out
+	}
+
+	if query.Has("favicons") {
+		resp["favicons"] = []interface{}{}
+	}
+
+	if query.Has("links") {
+		resp["links"] = []interface{}{}
+	}
+
+	if query.Has("items") {
+		items, err := api.feverItems(r, user)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		total, err := api.db.CountFeverItems(r.Context(), user.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "couldn't count items")
+			return
+		}
+		resp["items"] = items
+		resp["total_items"] = total
+	}
+
+	if query.Has("unread_item_ids") {
+		ids, err := api.db.GetFeverUnreadItemIDs(r.Context(), user.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "couldn't get unread items")
+			return
+		}
+		resp["unread_item_ids"] = joinIDs(ids)
+	}
+
+	if query.Has("saved_item_ids") {
+		ids, err := api.db.GetFeverSavedItemIDs(r.Context(), user.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "couldn't get saved items")
+			return
+		}
+		resp["saved_item_ids"] = joinIDs(ids)
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// feverUser finds the user whose md5("name:api_key") matches the Fever api_key
+func (api *apiServer) feverUser(r *http.Request, feverKey string) (database.User, error) {
+	if feverKey == "" {
+		return database.User{}, sql.ErrNoRows
+	}
+
+	return api.db.GetUserByFeverKey(r.Context(), strings.ToLower(feverKey))
+}
+
+// feverItems returns up to 50 items selected by since_id, max_id or with_ids
+func (api *apiServer) feverItems(r *http.Request, user database.User) ([]feverItem, error) {
+	query := r.URL.Query()
+	items := []feverItem{}
+
+	switch {
+	case query.Get("with_ids") != "":
+		var ids []int64
+		for _, raw := range strings.Split(query.Get("with_ids"), ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid item id %q", raw)
+			}
+			ids = append(ids, id)
+		}
+		rows, err := api.db.GetFeverItemsByIDs(r.Context(), database.GetFeverItemsByIDsParams{
+			UserID: user.ID,
+			Ids:    ids,
+		})
+		if err != nil {
+			return nil, errors.New("couldn't get items")
+		}
+		for _, row := range rows {
+			items = append(items, newFeverItem(database.GetFeverItemsSinceRow(row)))
+		}
+	case query.Get("max_id") != "":
+		maxID, err := strconv.ParseInt(query.Get("max_id"), 10, 64)
+		if err != nil {
+			return nil, errors.New("invalid max_id")
+		}
+		rows, err := api.db.GetFeverItemsBefore(r.Context(), database.GetFeverItemsBeforeParams{
+			UserID: user.ID,
+			MaxID:  maxID,
+		})
+		if err != nil {
+			return nil, errors.New("couldn't get items")
+		}
+		for _, row := range rows {
+			items = append(items, newFeverItem(database.GetFeverItemsSinceRow(row)))
+		}
+	default:
+		var sinceID int64
+		if raw := query.Get("since_id"); raw != "" {
+			parsed, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return nil, errors.New("invalid since_id")
+			}
+			sinceID = parsed
+		}
+		rows, err := api.db.GetFeverItemsSince(r.Context(), database.GetFeverItemsSinceParams{
+			UserID:  user.ID,
+			SinceID: sinceID,
+		})
+		if err != nil {
+			return nil, errors.New("couldn't get items")
+		}
+		for _, row := range rows {
+			items = append(items, newFeverItem(row))
+		}
+	}
+
+	return items, nil
+}
+
+func newFeverItem(row database.GetFeverItemsSinceRow) feverItem {
+	created := row.CreatedAt
+	if row.PublishedAt.Valid {
+		created = row.PublishedAt.Time
+	}
+
+	return feverItem{
+		ID:            row.FeverID,
+		FeedID:        row.FeedFeverID,
+		Title:         row.Title,
+		HTML:          row.Description.String,
+		URL:           row.Url,
+		IsSaved:       boolToInt(row.IsSaved),
+		IsRead:        boolToInt(row.IsRead),
+		CreatedOnTime: created.Unix(),
+	}
+}
+
+// feverMark applies a mark=item|feed|group write request, if any
+func (api *apiServer) feverMark(r *http.Request, user database.User) error {
+	mark := r.PostForm.Get("mark")
+	if mark == "" {
+		return nil
+	}
+
+	id, err := strconv.ParseInt(r.PostForm.Get("id"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid id %q", r.PostForm.Get("id"))
+	}
+	as := r.PostForm.Get("as")
+
+	if mark == "item" {
+		post, err := api.db.GetPostByFeverID(r.Context(), database.GetPostByFeverIDParams{
+			FeverID: id,
+			UserID:  user.ID,
+		})
+		if err != nil {
+			return errors.New("couldn't find item")
+		}
+
+		switch as {
+		case "read":
+			err = api.db.MarkPostRead(r.Context(), database.MarkPostReadParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				UserID:    user.ID,
+				PostID:    post.ID,
+			})
+		case "unread":
+			err = api.db.MarkPostUnread(r.Context(), database.MarkPostUnreadParams{
+				UserID: user.ID,
+				PostID: post.ID,
+			})
+		case "saved":
+			err = api.db.SavePost(r.Context(), database.SavePostParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				UserID:    user.ID,
+				PostID:    post.ID,
+			})
+		case "unsaved":
+			err = api.db.UnsavePost(r.Context(), database.UnsavePostParams{
+				UserID: user.ID,
+				PostID: post.ID,
+			})
+		default:
+			return fmt.Errorf("unsupported item mark %q", as)
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't mark item as %s", as)
+		}
+		if as == "saved" {
+			api.forwardSavedPost(r.Context(), user, post)
+		}
+		return nil
+	}
+
+	if as != "read" {
+		return fmt.Errorf("unsupported %s mark %q", mark, as)
+	}
+
+	before := time.Now()
+	if raw := r.PostForm.Get("before"); raw != "" {
+		ts, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return errors.New("invalid before timestamp")
+		}
+		before = time.Unix(ts, 0)
+	}
+
+	switch mark {
+	case "feed":
+		err = api.db.MarkFeverFeedReadBefore(r.Context(), database.MarkFeverFeedReadBeforeParams{
+			UserID:      user.ID,
+			FeedFeverID: id,
+			Before:      before,
+		})
+	case "group":
+		// Group 0 is Kindling and our one group holds every feed, so both mean everything
+		err = api.db.MarkAllReadBefore(r.Context(), database.MarkAllReadBeforeParams{
+			UserID: user.ID,
+			Before: before,
+		})
+	default:
+		return fmt.Errorf("unsupported mark %q", mark)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't mark %s as read", mark)
+	}
+
+	return nil
+}
+
+// joinIDs formats ids as the comma-separated string Fever expects
+func joinIDs(ids []int64) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+// Filter actions: mute hides posts that match, require hides posts that don't
+const (
+	filterActionMute    = "mute"
+	filterActionRequire = "require"
+)
+
+// postFilter is a filter rule ready to match posts against
+type postFilter struct {
+	database.Filter
+	re *regexp.Regexp
+}
+
+// newPostFilter compiles a filter's pattern. Keywords and regexes both match case-insensitively.
+func newPostFilter(filter database.Filter) (postFilter, error) {
+	f := postFilter{Filter: filter}
+	if filter.IsRegex {
+		re, err := regexp.Compile("(?i)" + filter.Pattern)
+		if err != nil {
+			return postFilter{}, fmt.Errorf("invalid regex %q: %w", filter.Pattern, err)
+		}
+		f.re = re
+	}
+	return f, nil
+}
+
+// hides reports whether the filter keeps a post from feedID with the given text out of view
+func (f postFilter) hides(feedID uuid.UUID, text string) bool {
+	if f.FeedID.Valid && f.FeedID.UUID != feedID {
+		return false
+	}
+
+	var matched bool
+	if f.re != nil {
+		matched = f.re.MatchString(text)
+	} else {
+		matched = strings.Contains(strings.ToLower(text), strings.ToLower(f.Pattern))
+	}
+	if f.Action == filterActionRequire {
+		return !matched
+	}
+	return matched
+}
+
+// postFilters is a user's filter rules
+type postFilters []postFilter
+
+// compileFilters prepares filter rows for matching. Patterns are checked when filters are added,
+// so one that doesn't compile is skipped rather than failing every browse.
+func compileFilters(rows []database.Filter) postFilters {
+	filters := make(postFilters, 0, len(rows))
+	for _, row := range rows {
+		if f, err := newPostFilter(row); err == nil {
+			filters = append(filters, f)
+		}
+	}
+	return filters
+}
+
+// loadFilters gets a user's filter rules
+func loadFilters(ctx context.Context, db *database.Queries, userID uuid.UUID) (postFilters, error) {
+	rows, err := db.GetFiltersForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get filters: %w", err)
+	}
+
+	filters := make([]database.Filter, 0, len(rows))
+	for _, row := range rows {
+		filters = append(filters, database.Filter{
+			ID:        row.ID,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+			UserID:    row.UserID,
+			FeedID:    row.FeedID,
+			Action:    row.Action,
+			Pattern:   row.Pattern,
+			IsRegex:   row.IsRegex,
+			AtIngest:  row.AtIngest,
+		})
+	}
+	return compileFilters(filters), nil
+}
+
+// hides reports whether any rule hides a post; rules are matched against its title and the
+// text of its description
+func (fs postFilters) hides(feedID uuid.UUID, title, description string) bool {
+	if len(fs) == 0 {
+		return false
+	}
+
+	text := title + "\n" + htmltext.RenderInline(description)
+	for _, f := range fs {
+		if f.hides(feedID, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// posts drops the posts the rules hide
+func (fs postFilters) posts(posts []database.Post) []database.Post {
+	if len(fs) == 0 {
+		return posts
+	}
+
+	kept := posts[:0:0]
+	for _, post := range posts {
+		if !fs.hides(post.FeedID, post.Title, post.Description.String) {
+			kept = append(kept, post)
+		}
+	}
+	return kept
+}
+
+// apiPosts drops the posts the rules hide
+func (fs postFilters) apiPosts(posts []apiPost) []apiPost {
+	if len(fs) == 0 {
+		return posts
+	}
+
+	kept := posts[:0:0]
+	for _, post := range posts {
+		description := ""
+		if post.Description != nil {
+			description = *post.Description
+		}
+		if !fs.hides(post.FeedID, post.Title, description) {
+			kept = append(kept, post)
+		}
+	}
+	return kept
+}
+
+// handlerFilter manages keyword and regex filters: filter add|list|remove
+func handlerFilter(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("filter command requires a subcommand: add, list, remove")
+	}
+
+	sub := command{name: cmd.args[0], args: cmd.args[1:]}
+	switch sub.name {
+	case "add":
+		return handlerFilterAdd(s, sub, user)
+	case "list":
+		return handlerFilterList(s, sub, user)
+	case "remove":
+		return handlerFilterRemove(s, sub, user)
+	default:
+		return fmt.Errorf("unknown filter subcommand: %s", sub.name)
+	}
+}
+
+// handlerFilterAdd adds a mute or must-contain rule, optionally limited to one feed
+func handlerFilterAdd(s *state, cmd command, user database.User) error {
+	var action, pattern, feedURL string
+	var isRegex, atIngest bool
+	setAction := func(a string) func(string) error {
+		return func(value string) error {
+			if action != "" {
+				return errors.New("a filter takes one of --mute or --must-contain")
+			}
+			action, pattern = a, value
+			return nil
+		}
+	}
+	flags := newFlagSet("filter add")
+	flags.Func("mute", "Hide posts matching `pattern`", setAction(filterActionMute))
+	flags.Func("must-contain", "Hide posts that don't match `pattern`", setAction(filterActionRequire))
+	flags.StringVar(&feedURL, "feed", "", "Only filter posts from the feed at `feed_url`")
+	flags.BoolVar(&isRegex, "regex", false, "Treat the pattern as a regular expression")
+	flags.BoolVar(&atIngest, "ingest", false, "Drop matching posts when they're fetched instead of hiding them")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected argument %q", args[0])
+	}
+
+	if action == "" {
+		return errors.New("filter add requires --mute <pattern> or --must-contain <pattern>")
+	}
+	if strings.TrimSpace(pattern) == "" {
+		return errors.New("filter pattern can't be empty")
+	}
+
+	var feedID uuid.NullUUID
+	if feedURL != "" {
+		feed, err := getFeedByURL(s.ctx, s.db, feedURL)
+		if err != nil {
+			return fmt.Errorf("couldn't find feed: %w", err)
+		}
+		// Ingest filters drop posts for everyone, so only the feed's owner may set them
+		if atIngest && feed.UserID != user.ID {
+			return errors.New("--ingest filters can only be set on feeds you added")
+		}
+		feedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+
+	filter := database.Filter{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feedID,
+		Action:    action,
+		Pattern:   pattern,
+		IsRegex:   isRegex,
+		AtIngest:  atIngest,
+	}
+	if _, err := newPostFilter(filter); err != nil {
+		return err
+	}
+
+	filter, err = s.db.CreateFilter(s.ctx, database.CreateFilterParams{
+		ID:        filter.ID,
+		CreatedAt: filter.CreatedAt,
+		UpdatedAt: filter.UpdatedAt,
+		UserID:    filter.UserID,
+		FeedID:    filter.FeedID,
+		Action:    filter.Action,
+		Pattern:   filter.Pattern,
+		IsRegex:   filter.IsRegex,
+		AtIngest:  filter.AtIngest,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create filter: %w", err)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Filter created: %s", filter.ID),
+		Item:    toFilterEntry(filter, feedURL),
+	})
+}
+
+// handlerFilterList lists the current user's filters
+func handlerFilterList(s *state, cmd command, user database.User) error {
+	rows, err := s.db.GetFiltersForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get filters: %w", err)
+	}
+
+	res := filtersResult{Filters: []filterEntry{}}
+	for _, row := range rows {
+		res.Filters = append(res.Filters, filterEntry{
+			ID:      row.ID,
+			Action:  row.Action,
+			Pattern: row.Pattern,
+			Regex:   row.IsRegex,
+			Ingest:  row.AtIngest,
+			FeedUrl: row.FeedUrl.String,
+		})
+	}
+	return s.emit(res)
+}
+
+// handlerFilterRemove deletes one of the current user's filters by ID
+func handlerFilterRemove(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("filter remove requires an ID argument")
+	}
+
+	id, err := uuid.Parse(cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("invalid filter ID %q", cmd.args[0])
+	}
+
+	n, err := s.db.DeleteFilter(s.ctx, database.DeleteFilterParams{
+		ID:     id,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't delete filter: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("filter %s doesn't exist", id)
+	}
+
+	return s.emit(messageResult{Message: fmt.Sprintf("Filter removed: %s", id)})
+}
+
+func toFilterEntry(filter database.Filter, feedURL string) filterEntry {
+	return filterEntry{
+		ID:      filter.ID,
+		Action:  filter.Action,
+		Pattern: filter.Pattern,
+		Regex:   filter.IsRegex,
+		Ingest:  filter.AtIngest,
+		FeedUrl: feedURL,
+	}
+}
+
+// filterEntry is a filter in the filter listing; FeedUrl is empty for filters on every feed
+type filterEntry struct {
+	ID      uuid.UUID `json:"id"`
+	Action  string    `json:"action"`
+	Pattern string    `json:"pattern"`
+	Regex   bool      `json:"regex"`
+	Ingest  bool      `json:"ingest"`
+	FeedUrl string    `json:"feed_url,omitempty"`
+}
+
+// filtersResult is the output of filter list
+type filtersResult struct {
+	Filters []filterEntry `json:"filters"`
+}
+
+func (r filtersResult) writeText(w io.Writer) {
+	if len(r.Filters) == 0 {
+		fmt.Fprintln(w, "No filters found")
+		return
+	}
+
+	for _, filter := range r.Filters {
+		kind := "keyword"
+		if filter.Regex {
+			kind = "regex"
+		}
+		fmt.Fprintf(w, "* %s\n", filter.ID)
+		fmt.Fprintf(w, "  %s %s %q\n", filter.Action, kind, filter.Pattern)
+		if filter.FeedUrl != "" {
+			fmt.Fprintf(w, "  Feed: %s\n", filter.FeedUrl)
+		} else {
+			fmt.Fprintln(w, "  Feed: all followed feeds")
+		}
+		if filter.Ingest {
+			fmt.Fprintln(w, "  Applied when posts are fetched")
+		}
+	}
+}
+
+func (r filtersResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, filter := range r.Filters {
+		rows = append(rows, []string{
+			filter.ID.String(),
+			filter.Action,
+			filter.Pattern,
+			strconv.FormatBool(filter.Regex),
+			strconv.FormatBool(filter.Ingest),
+			filter.FeedUrl,
+		})
+	}
+	return []string{"id", "action", "pattern", "regex", "ingest", "feed"}, rows
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Utkarsh736/gator/internal/fuzzy"
+)
+
+// flagSet holds a command's named options. Unlike the flag package on its own, flags can come
+// before, between or after the positional arguments, as --name value or --name=value, and "--"
+// ends them. A single dash only marks a flag the command defines, like -y, so arguments such as
+// a search for "-draft" stay positional.
+type flagSet struct {
+	*flag.FlagSet
+}
+
+// newFlagSet returns an empty flag set for a command, named as it's typed, like "apikey create"
+func newFlagSet(name string) *flagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return &flagSet{FlagSet: fs}
+}
+
+// parse sets the flags in args and returns the positional arguments left over. Errors name the
+// command, and an unknown flag lists the ones it takes.
+func (f *flagSet) parse(args []string) ([]string, error) {
+	positional := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(positional, args[i+1:]...), nil
+		}
+		long := strings.HasPrefix(arg, "--")
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		fl := f.Lookup(name)
+		switch {
+		case long && fl == nil:
+			return nil, f.unknown(name)
+		case !long && (fl == nil || !strings.HasPrefix(arg, "-")):
+			positional = append(positional, arg)
+			continue
+		}
+		if !hasValue {
+			if isBoolFlag(fl) {
+				value = "true"
+			} else {
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("--%s requires %s", name, valueName(fl))
+				}
+				i++
+				value = args[i]
+			}
+		}
+		if err := f.Set(name, value); err != nil {
+			// Flags defined with Func describe their own bad values; the flag package's types
+			// only say "parse error"
+			if _, builtin := fl.Value.(flag.Getter); builtin {
+				return nil, fmt.Errorf("invalid value %q for --%s: expected %s", value, name, valueName(fl))
+			}
+			return nil, err
+		}
+	}
+	return positional, nil
+}
+
+// unknown returns the error for a flag the command doesn't take, suggesting the one it's
+// closest to
+func (f *flagSet) unknown(name string) error {
+	names := []string{}
+	f.VisitAll(func(fl *flag.Flag) {
+		names = append(names, fl.Name)
+	})
+	if len(names) == 0 {
+		return fmt.Errorf("%s doesn't take any flags, but was given --%s", f.Name(), name)
+	}
+
+	msg := fmt.Sprintf("unknown flag --%s for %s", name, f.Name())
+	if match, ok := fuzzy.Closest(name, names, 2); ok {
+		msg += fmt.Sprintf(" (did you mean --%s?)", match)
+	}
+	return errors.New(msg + "\n" + f.usage())
+}
+
+// usage lists the flags with their descriptions, and their defaults where they aren't empty.
+// A name in backquotes in a flag's description is shown as its value, as with the flag package.
+func (f *flagSet) usage() string {
+	var b strings.Builder
+	b.WriteString("Flags:\n")
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	f.VisitAll(func(fl *flag.Flag) {
+		name, description := flag.UnquoteUsage(fl)
+		dashes := "--"
+		if len(fl.Name) == 1 {
+			dashes = "-"
+		}
+		if isBoolFlag(fl) {
+			fmt.Fprintf(tw, "  %s%s\t%s", dashes, fl.Name, description)
+		} else {
+			fmt.Fprintf(tw, "  %s%s <%s>\t%s", dashes, fl.Name, name, description)
+		}
+		if fl.DefValue != "" && fl.DefValue != "0" && fl.DefValue != "false" {
+			fmt.Fprintf(tw, " (default %s)", fl.DefValue)
+		}
+		fmt.Fprintln(tw)
+	})
+	tw.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// negatedBool sets *p to false when the flag is given, for --no-x flags that switch off a
+// setting --x or the config switched on
+func negatedBool(name string, p *bool) func(string) error {
+	return func(value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for --%s: expected true or false", value, name)
+		}
+		*p = !b
+		return nil
+	}
+}
+
+// isBoolFlag reports whether a flag can be given without a value
+func isBoolFlag(fl *flag.Flag) bool {
+	b, ok := fl.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// valueName is how a flag's value is described in errors, like "a duration"
+func valueName(fl *flag.Flag) string {
+	name, _ := flag.UnquoteUsage(fl)
+	switch {
+	case name == "" || name == "value" || strings.Contains(name, "|"):
+		return "a value"
+	case name == "n" || name == "int" || name == "uint":
+		return "a number"
+	}
+	if strings.ContainsAny(name[:1], "aeiou") {
+		return "an " + name
+	}
+	return "a " + name
+}
@@ -3,6 +3,9 @@ module github.com/Utkarsh736/gator
 go 1.25.5
 
 require (
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/lib/pq v1.11.1 // indirect
+	github.com/google/uuid v1.6.0
+	github.com/lib/pq v1.11.1
+	golang.org/x/net v0.58.0
+	golang.org/x/sys v0.47.0
+	golang.org/x/term v0.45.0
 )
@@ -0,0 +1,885 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/auth"
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Stream and tag IDs of the Google Reader API. Clients may name the user in place of "-",
+// which greaderStreamID normalizes away.
+const (
+	greaderReadingList  = "user/-/state/com.google/reading-list"
+	greaderRead         = "user/-/state/com.google/read"
+	greaderStarred      = "user/-/state/com.google/starred"
+	greaderKeptUnread   = "user/-/state/com.google/kept-unread"
+	greaderLabelPrefix  = "user/-/label/"
+	greaderFeedPrefix   = "feed/"
+	greaderItemIDPrefix = "tag:google.com,2005:reader/item/"
+)
+
+// greaderEditToken is handed out by /token. Requests are authenticated by header, which a
+// cross-site form can't set, so the token clients send back as T isn't checked.
+const greaderEditToken = "gator"
+
+// errGReaderFeedNotFound is returned by greaderFeed for a feed gator doesn't have
+var errGReaderFeedNotFound = errors.New("feed not found")
+
+// greaderMaxItems caps the n parameter of stream requests
+const greaderMaxItems = 1000
+
+// greaderItem is an item in stream/contents and stream/items/contents responses
+type greaderItem struct {
+	ID            string             `json:"id"`
+	CrawlTimeMsec string             `json:"crawlTimeMsec"`
+	TimestampUsec string             `json:"timestampUsec"`
+	Published     int64              `json:"published"`
+	Updated       int64              `json:"updated"`
+	Title         string             `json:"title"`
+	Canonical     []greaderLink      `json:"canonical"`
+	Alternate     []greaderLink      `json:"alternate"`
+	Summary       greaderContent     `json:"summary"`
+	Categories    []string           `json:"categories"`
+	Origin        greaderOrigin      `json:"origin"`
+	Author        string             `json:"author"`
+	Enclosure     []greaderEnclosure `json:"enclosure,omitempty"`
+}
+
+type greaderLink struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type greaderContent struct {
+	Direction string `json:"direction"`
+	Content   string `json:"content"`
+}
+
+type greaderOrigin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"htmlUrl"`
+}
+
+type greaderEnclosure struct {
+	Href   string `json:"href"`
+	Type   string `json:"type,omitempty"`
+	Length string `json:"length,omitempty"`
+}
+
+// greaderSubscription is a followed feed in the subscription/list response
+type greaderSubscription struct {
+	ID         string            `json:"id"`
+	Title      string            `json:"title"`
+	Categories []greaderCategory `json:"categories"`
+	URL        string            `json:"url"`
+	HTMLURL    string            `json:"htmlUrl"`
+	IconURL    string            `json:"iconUrl"`
+}
+
+type greaderCategory struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+// greaderAuthenticated accepts the "GoogleLogin auth=<token>" header GReader clients send, where
+// the token is the API key ClientLogin returned, alongside everything authenticated accepts
+func (api *apiServer) greaderAuthenticated(handler func(http.ResponseWriter, *http.Request, database.User)) http.HandlerFunc {
+	next := api.authenticated(handler)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "GoogleLogin auth="); ok {
+			r.Header.Set("Authorization", "ApiKey "+token)
+		}
+		next(w, r)
+	}
+}
+
+// handleGReaderLogin implements ClientLogin: Email is the gator username and Passwd either the
+// user's password or their API key. The API key is returned as the Auth token.
+func (api *apiServer) handleGReaderLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondWithText(w, http.StatusBadRequest, "Error=BadRequest\n")
+		return
+	}
+	name, password := r.Form.Get("Email"), r.Form.Get("Passwd")
+
+	user, err := api.db.GetUser(r.Context(), name)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		respondWithText(w, http.StatusInternalServerError, "Error=Unknown\n")
+		return
+	}
+	valid := err == nil && password != "" &&
+		(subtle.ConstantTimeCompare([]byte(password), []byte(user.ApiKey)) == 1 ||
+			user.PasswordHash.Valid && auth.CheckPassword(user.PasswordHash.String, password) == nil)
+	if !valid {
+		respondWithText(w, http.StatusUnauthorized, "Error=BadAuthentication\n")
+		return
+	}
+
+	respondWithText(w, http.StatusOK, fmt.Sprintf("SID=%s\nLSID=null\nAuth=%s\n", user.ApiKey, user.ApiKey))
+}
+
+func (api *apiServer) handleGReaderToken(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondWithText(w, http.StatusOK, greaderEditToken)
+}
+
+func (api *apiServer) handleGReaderUserInfo(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"userId":        user.ID.String(),
+		"userName":      user.Name,
+		"userProfileId": user.ID.String(),
+		"userEmail":     "",
+	})
+}
+
+// handleGReaderSubscriptions lists the feeds the user follows, with their category as a label
+func (api *apiServer) handleGReaderSubscriptions(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := api.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get feed follows")
+		return
+	}
+	feeds, err := api.feedsByID(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get feeds")
+		return
+	}
+
+	subs := make([]greaderSubscription, 0, len(follows))
+	for _, follow := range follows {
+		sub := greaderSubscription{
+			ID:         greaderFeedPrefix + follow.FeedID.String(),
+			Title:      follow.FeedName,
+			Categories: []greaderCategory{},
+			URL:        feeds[follow.FeedID].Url,
+			HTMLURL:    feeds[follow.FeedID].Url,
+		}
+		if follow.CategoryName.Valid {
+			sub.Categories = append(sub.Categories, greaderCategory{
+				ID:    greaderLabelPrefix + follow.CategoryName.String,
+				Label: follow.CategoryName.String,
+			})
+		}
+		subs = append(subs, sub)
+	}
+	slices.SortFunc(subs, func(a, b greaderSubscription) int {
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	})
+
+	respondWithJSON(w, http.StatusOK, map[string]any{"subscriptions": subs})
+}
+
+// handleGReaderTags lists the starred state and the user's categories, which are folders
+func (api *apiServer) handleGReaderTags(w http.ResponseWriter, r *http.Request, user database.User) {
+	categories, err := api.db.GetCategoriesForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get categories")
+		return
+	}
+
+	tags := []map[string]string{{"id": greaderStarred}}
+	for _, category := range categories {
+		tags = append(tags, map[string]string{"id": greaderLabelPrefix + category.Name, "type": "folder"})
+	}
+	respondWithJSON(w, http.StatusOK, map[string]any{"tags": tags})
+}
+
+// handleGReaderUnreadCount reports unread posts per followed feed, per label and in total
+func (api *apiServer) handleGReaderUnreadCount(w http.ResponseWriter, r *http.Request, user database.User) {
+	counts, err := api.db.GetUnreadCountsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't count unread posts")
+		return
+	}
+	follows, err := api.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get feed follows")
+		return
+	}
+	labels := map[uuid.UUID]string{}
+	for _, follow := range follows {
+		if follow.CategoryName.Valid {
+			labels[follow.FeedID] = follow.CategoryName.String
+		}
+	}
+
+	type unreadCount struct {
+		ID                      string `json:"id"`
+		Count                   int64  `json:"count"`
+		NewestItemTimestampUsec string `json:"newestItemTimestampUsec"`
+	}
+	now := strconv.FormatInt(time.Now().UnixMicro(), 10)
+	out := []unreadCount{}
+	labelCounts := map[string]int64{}
+	var total int64
+	for _, count := range counts {
+		out = append(out, unreadCount{ID: greaderFeedPrefix + count.FeedID.String(), Count: count.UnreadCount, NewestItemTimestampUsec: now})
+		if label, ok := labels[count.FeedID]; ok {
+			labelCounts[label] += count.UnreadCount
+		}
+		total += count.UnreadCount
+	}
+	for label, count := range labelCounts {
+		out = append(out, unreadCount{ID: greaderLabelPrefix + label, Count: count, NewestItemTimestampUsec: now})
+	}
+	out = append(out, unreadCount{ID: greaderReadingList, Count: total, NewestItemTimestampUsec: now})
+
+	respondWithJSON(w, http.StatusOK, map[string]any{"max": total, "unreadcounts": out})
+}
+
+// handleGReaderItemIDs lists the IDs of the items in a stream, for clients that fetch contents
+// separately
+func (api *apiServer) handleGReaderItemIDs(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid form body")
+		return
+	}
+	ids, continuation, err := api.greaderStreamIDs(r, user, r.Form.Get("s"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	type itemRef struct {
+		ID string `json:"id"`
+	}
+	refs := make([]itemRef, 0, len(ids))
+	for _, id := range ids {
+		refs = append(refs, itemRef{ID: strconv.FormatInt(id, 10)})
+	}
+	resp := map[string]any{"itemRefs": refs}
+	if continuation != "" {
+		resp["continuation"] = continuation
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleGReaderItemContents returns the items named by the i parameters
+func (api *apiServer) handleGReaderItemContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid form body")
+		return
+	}
+	var ids []int64
+	for _, raw := range r.Form["i"] {
+		id, err := parseGReaderItemID(raw)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	items, err := api.greaderItems(r.Context(), user, ids)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"id":      greaderReadingList,
+		"updated": time.Now().Unix(),
+		"items":   items,
+	})
+}
+
+// handleGReaderStreamContents returns a stream's items in full. The stream is named in the path,
+// as in stream/contents/feed/<id>, or by the s parameter.
+func (api *apiServer) handleGReaderStreamContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid form body")
+		return
+	}
+	stream := r.PathValue("stream")
+	if stream == "" {
+		stream = r.Form.Get("s")
+	}
+	ids, continuation, err := api.greaderStreamIDs(r, user, stream)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	items, err := api.greaderItems(r.Context(), user, ids)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := map[string]any{
+		"direction": "ltr",
+		"id":        stream,
+		"updated":   time.Now().Unix(),
+		"items":     items,
+	}
+	if continuation != "" {
+		resp["continuation"] = continuation
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleGReaderEditTag marks items read or unread (the read and kept-unread states) and
+// starred or not, which is saved in gator. Other tags are ignored.
+func (api *apiServer) handleGReaderEditTag(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid form body")
+		return
+	}
+
+	for _, raw := range r.Form["i"] {
+		id, err := parseGReaderItemID(raw)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		post, err := api.db.GetPostByFeverID(r.Context(), database.GetPostByFeverIDParams{
+			FeverID: id,
+			UserID:  user.ID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, fmt.Sprintf("item %s not found", raw))
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "couldn't get item")
+			return
+		}
+
+		for _, tag := range r.Form["a"] {
+			if err := api.greaderTag(r.Context(), user, post, greaderStreamID(tag), true); err != nil {
+				respondWithError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+		for _, tag := range r.Form["r"] {
+			if err := api.greaderTag(r.Context(), user, post, greaderStreamID(tag), false); err != nil {
+				respondWithError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+	}
+
+	respondWithText(w, http.StatusOK, "OK")
+}
+
+// greaderTag adds or removes one tag on a post
+func (api *apiServer) greaderTag(ctx context.Context, user database.User, post database.Post, tag string, add bool) error {
+	if tag == greaderKeptUnread {
+		tag, add = greaderRead, !add
+	}
+
+	var err error
+	switch {
+	case tag == greaderRead && add:
+		err = api.db.MarkPostRead(ctx, database.MarkPostReadParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    user.ID,
+			PostID:    post.ID,
+		})
+	case tag == greaderRead:
+		err = api.db.MarkPostUnread(ctx, database.MarkPostUnreadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		})
+	case tag == greaderStarred && add:
+		err = api.db.SavePost(ctx, database.SavePostParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    user.ID,
+			PostID:    post.ID,
+		})
+		if err == nil {
+			api.forwardSavedPost(ctx, user, post)
+		}
+	case tag == greaderStarred:
+		err = api.db.UnsavePost(ctx, database.UnsavePostParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		})
+	}
+	if err != nil {
+		return errors.New("couldn't update item")
+	}
+	return nil
+}
+
+// handleGReaderMarkAllRead marks every post in a feed, a label or the reading list read, up to
+// the ts parameter in microseconds if it's given
+func (api *apiServer) handleGReaderMarkAllRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid form body")
+		return
+	}
+
+	before := time.Now()
+	if raw := r.Form.Get("ts"); raw != "" {
+		ts, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid ts")
+			return
+		}
+		before = time.UnixMicro(ts)
+	}
+	params := database.MarkPostsReadParams{
+		UserID: user.ID,
+		Before: sql.NullTime{Time: before, Valid: true},
+	}
+
+	stream := greaderStreamID(r.Form.Get("s"))
+	var feedIDs []uuid.UUID
+	switch {
+	case stream == greaderReadingList:
+		feedIDs = []uuid.UUID{uuid.Nil}
+	case strings.HasPrefix(stream, greaderFeedPrefix):
+		feed, err := api.greaderFeed(r.Context(), stream)
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		feedIDs = []uuid.UUID{feed.ID}
+	case strings.HasPrefix(stream, greaderLabelPrefix):
+		follows, err := api.db.GetFeedFollowsForUser(r.Context(), user.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "couldn't get feed follows")
+			return
+		}
+		label := strings.TrimPrefix(stream, greaderLabelPrefix)
+		for _, follow := range follows {
+			if follow.CategoryName.Valid && follow.CategoryName.String == label {
+				feedIDs = append(feedIDs, follow.FeedID)
+			}
+		}
+	default:
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("can't mark stream %q read", stream))
+		return
+	}
+
+	for _, feedID := range feedIDs {
+		params.FeedID = uuid.NullUUID{UUID: feedID, Valid: feedID != uuid.Nil}
+		if _, err := api.db.MarkPostsRead(r.Context(), params); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "couldn't mark posts read")
+			return
+		}
+	}
+	respondWithText(w, http.StatusOK, "OK")
+}
+
+// handleGReaderEditSubscription subscribes to, unsubscribes from or relabels the feeds named by
+// the s parameters. Subscribing to a URL gator doesn't know adds the feed, named by t.
+func (api *apiServer) handleGReaderEditSubscription(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid form body")
+		return
+	}
+	action := r.Form.Get("ac")
+
+	for _, stream := range r.Form["s"] {
+		var feed database.Feed
+		var err error
+		switch action {
+		case "subscribe":
+			feed, err = api.greaderSubscribe(r.Context(), user, stream, r.Form.Get("t"))
+		case "unsubscribe", "edit":
+			feed, err = api.greaderFeed(r.Context(), stream)
+		default:
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("unsupported action %q", action))
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if action == "unsubscribe" {
+			err = api.db.DeleteFeedFollow(r.Context(), database.DeleteFeedFollowParams{
+				UserID: user.ID,
+				FeedID: feed.ID,
+			})
+			if err != nil {
+				respondWithError(w, http.StatusInternalServerError, "couldn't unfollow feed")
+				return
+			}
+			continue
+		}
+
+		if r.Form.Get("r") != "" {
+			err = api.greaderSetLabel(r.Context(), user, feed, "")
+		}
+		if label := r.Form.Get("a"); err == nil && label != "" {
+			err = api.greaderSetLabel(r.Context(), user, feed, strings.TrimPrefix(greaderStreamID(label), greaderLabelPrefix))
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	respondWithText(w, http.StatusOK, "OK")
+}
+
+// handleGReaderQuickAdd subscribes to the feed at the quickadd URL
+func (api *apiServer) handleGReaderQuickAdd(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid form body")
+		return
+	}
+	query := strings.TrimPrefix(r.Form.Get("quickadd"), greaderFeedPrefix)
+	if query == "" {
+		respondWithError(w, http.StatusBadRequest, "quickadd requires a feed URL")
+		return
+	}
+
+	feed, err := api.greaderSubscribe(r.Context(), user, greaderFeedPrefix+query, "")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"numResults": 1,
+		"query":      query,
+		"streamId":   greaderFeedPrefix + feed.ID.String(),
+		"streamName": feed.Name,
+	})
+}
+
+// greaderSubscribe follows the feed a stream ID names, adding it first if it's a URL gator
+// doesn't have yet. Following a feed twice isn't an error.
+func (api *apiServer) greaderSubscribe(ctx context.Context, user database.User, stream, title string) (database.Feed, error) {
+	feed, err := api.greaderFeed(ctx, stream)
+	if err != nil {
+		feedURL := strings.TrimPrefix(greaderStreamID(stream), greaderFeedPrefix)
+		if !errors.Is(err, errGReaderFeedNotFound) || !strings.Contains(feedURL, "://") {
+			return database.Feed{}, err
+		}
+		feedURL, err = validateFeedURL(ctx, feedURL)
+		if err != nil {
+			return database.Feed{}, err
+		}
+		feed, err = api.greaderAddFeed(ctx, user, feedURL, title)
+		if err != nil {
+			return database.Feed{}, err
+		}
+	}
+
+	_, err = api.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); !ok || pqErr.Code != "23505" {
+			return database.Feed{}, errors.New("couldn't follow feed")
+		}
+	}
+	return feed, nil
+}
+
+// greaderAddFeed adds the feed at feedURL, or returns the feed already added under another
+// spelling of it
+func (api *apiServer) greaderAddFeed(ctx context.Context, user database.User, feedURL, title string) (database.Feed, error) {
+	feed, found, err := findEquivalentFeed(ctx, api.db, feedURL)
+	if err != nil || found {
+		return feed, err
+	}
+
+	if title == "" {
+		title = feedURL
+	}
+	feed, err = api.db.CreateFeed(ctx, database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      title,
+		Url:       feedURL,
+		UserID:    user.ID,
+	})
+	if err != nil {
+		return database.Feed{}, errors.New("couldn't create feed")
+	}
+	return feed, nil
+}
+
+// greaderSetLabel puts a followed feed in the category named label, creating it if needed, or
+// takes it out of its category when label is empty
+func (api *apiServer) greaderSetLabel(ctx context.Context, user database.User, feed database.Feed, label string) error {
+	var categoryID uuid.NullUUID
+	if label != "" {
+		category, err := api.db.GetCategoryByName(ctx, database.GetCategoryByNameParams{
+			UserID: user.ID,
+			Name:   label,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			category, err = api.db.CreateCategory(ctx, database.CreateCategoryParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				UserID:    user.ID,
+				Name:      label,
+			})
+		}
+		if err != nil {
+			return errors.New("couldn't get category")
+		}
+		categoryID = uuid.NullUUID{UUID: category.ID, Valid: true}
+	}
+
+	err := api.db.SetFeedFollowCategory(ctx, database.SetFeedFollowCategoryParams{
+		UserID:     user.ID,
+		FeedID:     feed.ID,
+		CategoryID: categoryID,
+	})
+	if err != nil {
+		return errors.New("couldn't update feed follow")
+	}
+	return nil
+}
+
+// greaderStreamIDs lists the item IDs in a stream, applying the n, r, xt, it, ot, nt and c
+// parameters. The continuation is empty once the stream has no more items.
+func (api *apiServer) greaderStreamIDs(r *http.Request, user database.User, stream string) ([]int64, string, error) {
+	params := database.GetGReaderItemIDsParams{
+		UserID:      user.ID,
+		OldestFirst: r.Form.Get("r") == "o",
+		Limit:       20,
+	}
+
+	stream = greaderStreamID(stream)
+	switch {
+	case stream == "" || stream == greaderReadingList:
+	case stream == greaderStarred:
+		params.StarredOnly = true
+	case stream == greaderRead:
+		params.ReadOnly = true
+	case strings.HasPrefix(stream, greaderFeedPrefix):
+		feed, err := api.greaderFeed(r.Context(), stream)
+		if err != nil {
+			return nil, "", err
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	case strings.HasPrefix(stream, greaderLabelPrefix):
+		category, err := api.db.GetCategoryByName(r.Context(), database.GetCategoryByNameParams{
+			UserID: user.ID,
+			Name:   strings.TrimPrefix(stream, greaderLabelPrefix),
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("label %q not found", strings.TrimPrefix(stream, greaderLabelPrefix))
+		}
+		params.CategoryID = uuid.NullUUID{UUID: category.ID, Valid: true}
+	default:
+		return nil, "", fmt.Errorf("unsupported stream %q", stream)
+	}
+
+	switch greaderStreamID(r.Form.Get("xt")) {
+	case greaderRead:
+		params.UnreadOnly = true
+	}
+	switch greaderStreamID(r.Form.Get("it")) {
+	case greaderRead:
+		params.ReadOnly = true
+	case greaderStarred:
+		params.StarredOnly = true
+	}
+
+	if raw := r.Form.Get("n"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return nil, "", errors.New("invalid n")
+		}
+		params.Limit = int32(min(n, greaderMaxItems))
+	}
+	for key, field := range map[string]*sql.NullTime{"ot": &params.Since, "nt": &params.Until} {
+		if raw := r.Form.Get(key); raw != "" {
+			ts, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid %s", key)
+			}
+			*field = sql.NullTime{Time: time.Unix(ts, 0), Valid: true}
+		}
+	}
+	if raw := r.Form.Get("c"); raw != "" {
+		c, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, "", errors.New("invalid continuation")
+		}
+		params.Continuation = sql.NullInt64{Int64: c, Valid: true}
+	}
+
+	ids, err := api.db.GetGReaderItemIDs(r.Context(), params)
+	if err != nil {
+		return nil, "", errors.New("couldn't get items")
+	}
+	continuation := ""
+	if len(ids) == int(params.Limit) {
+		continuation = strconv.FormatInt(ids[len(ids)-1], 10)
+	}
+	return ids, continuation, nil
+}
+
+// greaderItems loads items in the order of ids, labelled with the user's read and starred state
+// and their feed's category
+func (api *apiServer) greaderItems(ctx context.Context, user database.User, ids []int64) ([]greaderItem, error) {
+	items := []greaderItem{}
+	if len(ids) == 0 {
+		return items, nil
+	}
+
+	rows, err := api.db.GetGReaderItems(ctx, database.GetGReaderItemsParams{UserID: user.ID, Ids: ids})
+	if err != nil {
+		return nil, errors.New("couldn't get items")
+	}
+	follows, err := api.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, errors.New("couldn't get feed follows")
+	}
+	labels := map[uuid.UUID]string{}
+	for _, follow := range follows {
+		if follow.CategoryName.Valid {
+			labels[follow.FeedID] = follow.CategoryName.String
+		}
+	}
+
+	byID := make(map[int64]database.GetGReaderItemsRow, len(rows))
+	for _, row := range rows {
+		byID[row.FeverID] = row
+	}
+	for _, id := range ids {
+		if row, ok := byID[id]; ok {
+			items = append(items, newGReaderItem(row, labels[row.FeedID]))
+		}
+	}
+	return items, nil
+}
+
+func newGReaderItem(row database.GetGReaderItemsRow, label string) greaderItem {
+	published := row.CreatedAt
+	if row.PublishedAt.Valid {
+		published = row.PublishedAt.Time
+	}
+
+	content := row.Description.String
+	if !row.Description.Valid && row.Content.Valid {
+		content = strings.ReplaceAll(html.EscapeString(row.Content.String), "\n", "<br>\n")
+	}
+
+	categories := []string{greaderReadingList}
+	if row.IsRead {
+		categories = append(categories, greaderRead)
+	}
+	if row.IsSaved {
+		categories = append(categories, greaderStarred)
+	}
+	if label != "" {
+		categories = append(categories, greaderLabelPrefix+label)
+	}
+
+	item := greaderItem{
+		ID:            fmt.Sprintf("%s%016x", greaderItemIDPrefix, row.FeverID),
+		CrawlTimeMsec: strconv.FormatInt(row.CreatedAt.UnixMilli(), 10),
+		TimestampUsec: strconv.FormatInt(row.CreatedAt.UnixMicro(), 10),
+		Published:     published.Unix(),
+		Updated:       row.UpdatedAt.Unix(),
+		Title:         row.Title,
+		Canonical:     []greaderLink{{Href: row.Url}},
+		Alternate:     []greaderLink{{Href: row.Url, Type: "text/html"}},
+		Summary:       greaderContent{Direction: "ltr", Content: content},
+		Categories:    categories,
+		Origin: greaderOrigin{
+			StreamID: greaderFeedPrefix + row.FeedID.String(),
+			Title:    row.FeedName,
+			HTMLURL:  row.FeedUrl,
+		},
+	}
+	if row.EnclosureUrl.Valid {
+		enclosure := greaderEnclosure{Href: row.EnclosureUrl.String, Type: row.EnclosureType.String}
+		if row.EnclosureLength.Valid {
+			enclosure.Length = strconv.FormatInt(row.EnclosureLength.Int64, 10)
+		}
+		item.Enclosure = []greaderEnclosure{enclosure}
+	}
+	return item
+}
+
+// greaderFeed finds the feed a feed/<id> or feed/<url> stream ID names
+func (api *apiServer) greaderFeed(ctx context.Context, stream string) (database.Feed, error) {
+	ref := strings.TrimPrefix(greaderStreamID(stream), greaderFeedPrefix)
+
+	var feed database.Feed
+	var err error
+	if id, parseErr := uuid.Parse(ref); parseErr == nil {
+		feed, err = api.db.GetFeed(ctx, id)
+	} else {
+		feed, err = getFeedByURL(ctx, api.db, ref)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.Feed{}, fmt.Errorf("%w: %s", errGReaderFeedNotFound, ref)
+		}
+		return database.Feed{}, errors.New("couldn't get feed")
+	}
+	return feed, nil
+}
+
+// feedsByID loads every feed, keyed by ID
+func (api *apiServer) feedsByID(ctx context.Context) (map[uuid.UUID]database.GetFeedsRow, error) {
+	feeds, err := api.db.GetFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]database.GetFeedsRow, len(feeds))
+	for _, feed := range feeds {
+		byID[feed.ID] = feed
+	}
+	return byID, nil
+}
+
+// greaderStreamID normalizes user/<id>/... stream and tag IDs to the user/-/... form
+func greaderStreamID(id string) string {
+	rest, ok := strings.CutPrefix(id, "user/")
+	if !ok {
+		return id
+	}
+	if _, after, found := strings.Cut(rest, "/"); found {
+		return "user/-/" + after
+	}
+	return id
+}
+
+// parseGReaderItemID reads an item ID in either its long form, tag:google.com,2005:reader/item/
+// followed by 16 hex digits, or the short decimal form
+func parseGReaderItemID(raw string) (int64, error) {
+	if hex, ok := strings.CutPrefix(raw, greaderItemIDPrefix); ok {
+		id, err := strconv.ParseUint(hex, 16, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid item id %q", raw)
+		}
+		return int64(id), nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid item id %q", raw)
+	}
+	return id, nil
+}
+
+// respondWithText writes a plain-text body, as ClientLogin and the GReader write endpoints return
+func respondWithText(w http.ResponseWriter, code int, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	w.Write([]byte(text))
+}
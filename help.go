@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// globalFlags are accepted before or after any command, and shown by help
+var globalFlags = []commandInfo{
+	{Name: "--output", Usage: "plain|table|csv|json", Description: "How results are printed; --json is short for --output json"},
+	{Name: "--profile", Usage: "<name>", Description: "Use a named config profile"},
+	{Name: "--log-level", Usage: "debug|info|warn|error", Description: "Least severe log messages to write"},
+	{Name: "--log-format", Usage: "text|json", Description: "Format of log messages"},
+	{Name: "--log-file", Usage: "<path>", Description: "Append log messages to a file instead of stderr"},
+	{Name: "--debug-http", Usage: "", Description: "Log every HTTP request and response, and save feeds that can't be parsed"},
+	{Name: "--", Usage: "", Description: "End the flags; the arguments after it are passed on as written"},
+}
+
+// help lists the commands, or shows how to use one: help [command]
+func (c *commands) help(s *state, cmd command) error {
+	if len(cmd.args) == 0 {
+		return s.emit(helpResult{Commands: c.info, Aliases: c.aliasList(), Flags: globalFlags})
+	}
+
+	name := cmd.args[0]
+	if err := c.check(name); err != nil {
+		return err
+	}
+	for _, info := range c.info {
+		if info.Name == name {
+			return s.emit(info)
+		}
+	}
+	expansion, _ := c.alias(name)
+	return s.emit(aliasInfo{Name: name, Expansion: expansion})
+}
+
+func (i commandInfo) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Usage: gator %s\n\n%s\n", strings.TrimSpace(i.Name+" "+i.Usage), i.Description)
+}
+
+func (i commandInfo) table() ([]string, [][]string) {
+	return []string{"name", "usage", "description"}, [][]string{{i.Name, i.Usage, i.Description}}
+}
+
+// helpResult is the output of help without a command
+type helpResult struct {
+	Commands []commandInfo `json:"commands"`
+	Aliases  []aliasInfo   `json:"aliases"`
+	Flags    []commandInfo `json:"flags"`
+}
+
+func (r helpResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, "Usage: gator [flags] <command> [args...]")
+
+	fmt.Fprintln(w, "\nCommands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, info := range r.Commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", info.Name, info.Description)
+	}
+	tw.Flush()
+
+	if len(r.Aliases) > 0 {
+		fmt.Fprintln(w, "\nAliases:")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, alias := range r.Aliases {
+			fmt.Fprintf(tw, "  %s\t%s\n", alias.Name, alias.Expansion)
+		}
+		tw.Flush()
+	}
+
+	fmt.Fprintln(w, "\nFlags:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, flag := range r.Flags {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", flag.Name, flag.Usage, flag.Description)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nRun 'gator help <command>' for how to use one.")
+}
+
+func (r helpResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, info := range r.Commands {
+		rows = append(rows, []string{info.Name, info.Usage, info.Description})
+	}
+	return []string{"name", "usage", "description"}, rows
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/config"
+	"github.com/Utkarsh736/gator/internal/database"
+)
+
+// Events a hook can run on
+const (
+	hookPostIngested = "post_ingested"
+	hookFeedFailed   = "feed_failed"
+	hookPostSaved    = "post_saved"
+)
+
+// postIngestedPayload is what a post_ingested hook gets on stdin, once for each new post
+type postIngestedPayload struct {
+	Event string  `json:"event"`
+	Feed  apiFeed `json:"feed"`
+	Post  apiPost `json:"post"`
+}
+
+// feedFailedPayload is what a feed_failed hook gets on stdin when fetching a feed fails
+type feedFailedPayload struct {
+	Event               string  `json:"event"`
+	Feed                apiFeed `json:"feed"`
+	Error               string  `json:"error"`
+	StatusCode          int     `json:"status_code,omitempty"`
+	ConsecutiveFailures int32   `json:"consecutive_failures"`
+}
+
+// postSavedPayload is what a post_saved hook gets on stdin when a user saves a post
+type postSavedPayload struct {
+	Event string  `json:"event"`
+	User  apiUser `json:"user"`
+	Post  apiPost `json:"post"`
+}
+
+// runHook runs the command configured for event through the shell, with payload as JSON on
+// stdin and the event's name in GATOR_EVENT. It does nothing when no hook is set for event.
+func runHook(ctx context.Context, cfg *config.Config, event string, payload any) error {
+	if cfg == nil {
+		return nil
+	}
+	command, timeout, err := cfg.Hook(event)
+	if err != nil || command == "" {
+		return err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("couldn't encode %s payload: %w", event, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(), "GATOR_EVENT="+event)
+	cmd.Stdin = bytes.NewReader(body)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Don't wait on a process the hook left behind that still holds its output open
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if out := strings.TrimSpace(output.String()); out != "" {
+		slog.Debug("hook output", "event", event, "output", out)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s hook timed out after %s", event, timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%s hook failed: %w", event, err)
+	}
+	return nil
+}
+
+// runIngestedHooks runs the post_ingested hook for each new post of a scrape
+func runIngestedHooks(s *state, res scrapeResult) {
+	if res.Feed == nil {
+		return
+	}
+	for _, post := range res.NewPosts {
+		payload := postIngestedPayload{Event: hookPostIngested, Feed: *res.Feed, Post: post}
+		if err := runHook(s.ctx, s.cfg, hookPostIngested, payload); err != nil {
+			slog.Warn("hook failed", "event", hookPostIngested, "post", post.Title, "error", err)
+		}
+	}
+}
+
+// runFeedFailedHook runs the feed_failed hook after a failed fetch of feed
+func runFeedFailedHook(s *state, feed database.Feed, status int, failures int32, fetchErr error) {
+	payload := feedFailedPayload{
+		Event:               hookFeedFailed,
+		Feed:                toAPIFeed(feed),
+		Error:               fetchErr.Error(),
+		StatusCode:          status,
+		ConsecutiveFailures: failures,
+	}
+	if err := runHook(s.ctx, s.cfg, hookFeedFailed, payload); err != nil {
+		slog.Warn("hook failed", "event", hookFeedFailed, "feed", feed.Name, "error", err)
+	}
+}
+
+// runSavedHook runs the post_saved hook after user saves post
+func runSavedHook(ctx context.Context, cfg *config.Config, user database.User, post database.Post) error {
+	payload := postSavedPayload{Event: hookPostSaved, User: toAPIUser(user), Post: toAPIPost(post)}
+	return runHook(ctx, cfg, hookPostSaved, payload)
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/config"
+	"github.com/Utkarsh736/gator/internal/hostlimit"
+)
+
+// Defaults for the shared HTTP client when the config doesn't set them
+const (
+	defaultUserAgent    = "gator"
+	defaultHTTPTimeout  = 30 * time.Second
+	defaultMaxRedirects = 10
+	defaultMaxResponse  = 10 << 20
+)
+
+// httpClient fetches feeds, article pages and feed discovery pages; downloadClient is the same
+// client without an overall timeout, for podcast episodes. main rebuilds both from the config.
+var httpClient, downloadClient = mustHTTPClients(nil)
+
+// maxResponseSize caps how much of a feed, article or discovery page is read, after decompression
+var maxResponseSize int64 = defaultMaxResponse
+
+// hostLimiter paces requests to each host; main sets its rate from the config
+var hostLimiter = hostlimit.New(hostlimit.DefaultRate)
+
+// configureHTTP rebuilds the shared HTTP clients from the http section of the config
+func configureHTTP(cfg *config.Config) error {
+	hostLimiter.SetRate(cfg.HostRequestRate())
+
+	client, download, err := newHTTPClients(cfg.HTTP)
+	if err != nil {
+		return err
+	}
+	httpClient, downloadClient = client, download
+
+	maxResponseSize = defaultMaxResponse
+	if cfg.HTTP != nil && cfg.HTTP.MaxResponseSize != "" {
+		n, err := config.ParseSize(cfg.HTTP.MaxResponseSize)
+		if err != nil || n == 0 {
+			return fmt.Errorf("invalid http max_response_size %q", cfg.HTTP.MaxResponseSize)
+		}
+		maxResponseSize = n
+	}
+	return nil
+}
+
+// newHTTPClients builds the shared clients from cfg, which may be nil for the defaults
+func newHTTPClients(cfg *config.HTTPConfig) (*http.Client, *http.Client, error) {
+	if cfg == nil {
+		cfg = &config.HTTPConfig{}
+	}
+
+	timeout := defaultHTTPTimeout
+	if cfg.Timeout != "" {
+		d, err := config.ParseDuration(cfg.Timeout)
+		if err != nil || d <= 0 {
+			return nil, nil, fmt.Errorf("invalid http timeout %q", cfg.Timeout)
+		}
+		timeout = d
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// decompressTransport handles compression itself, so it can offer deflate as well
+	transport.DisableCompression = true
+	var proxyHost string
+	if cfg.Proxy != "" {
+		proxy, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid http proxy: %w", err)
+		}
+		switch proxy.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, nil, fmt.Errorf("unsupported proxy scheme %q (expected http, https or socks5)", proxy.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+		proxyHost = proxy.Hostname()
+	}
+
+	// A proxy connects to the target itself, so the dial check below never sees it. Resolve the
+	// target here instead; the proxy may still resolve it differently, but a name that points
+	// somewhere private locally is refused.
+	proxyFor := transport.Proxy
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		u, err := proxyFor(req)
+		if err != nil || u == nil || !blockPrivateAddresses.Load() {
+			return u, err
+		}
+		if err := checkHostAddress(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+		return u, nil
+	}
+
+	// Connections are checked against blockPrivateAddresses once the host is resolved. The
+	// configured proxy is exempt, since proxied targets are checked before they're handed to it.
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	guarded := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: checkDialAddress}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if host, _, _ := net.SplitHostPort(addr); proxyHost != "" && host == proxyHost {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		return guarded.DialContext(ctx, network, addr)
+	}
+
+	agent := defaultUserAgent
+	if cfg.UserAgent != "" {
+		agent = cfg.UserAgent
+	}
+
+	maxRedirects := defaultMaxRedirects
+	if cfg.MaxRedirects != 0 {
+		maxRedirects = max(cfg.MaxRedirects, 0)
+	}
+
+	var base http.RoundTripper = transport
+	if debugHTTP {
+		base = &debugTransport{base: transport}
+	}
+
+	client := &http.Client{
+		// Leave room for waiting on a rate-limited host on top of the request itself
+		Timeout: timeout + hostlimit.DefaultMaxWait,
+		Transport: &userAgentTransport{
+			agent: agent,
+			base: &decompressTransport{
+				base: &hostlimit.Transport{Limiter: hostLimiter, Base: base},
+			},
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if debugHTTP {
+				logRedirect(req, via)
+			}
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}
+
+	download := *client
+	download.Timeout = 0
+	return client, &download, nil
+}
+
+// mustHTTPClients is newHTTPClients for settings that can't be invalid
+func mustHTTPClients(cfg *config.HTTPConfig) (*http.Client, *http.Client) {
+	client, download, err := newHTTPClients(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return client, download
+}
+
+// userAgentTransport identifies gator on requests that don't set a User-Agent themselves
+type userAgentTransport struct {
+	agent string
+	base  http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	// RoundTrippers mustn't modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.agent)
+	return t.base.RoundTrip(req)
+}
+
+// decompressTransport asks for gzip or deflate compressed responses and decompresses them.
+// Range requests, like resumed podcast downloads, are left alone: byte offsets must refer to
+// the file itself.
+type decompressTransport struct {
+	base http.RoundTripper
+}
+
+func (t *decompressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") != "" || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		body, err = gzip.NewReader(resp.Body)
+	case "deflate":
+		body, err = newDeflateReader(resp.Body)
+	default:
+		return resp, nil
+	}
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("couldn't decompress response: %w", err)
+	}
+
+	resp.Body = &decompressedBody{Reader: body, compressed: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// newDeflateReader reads a deflate body. The spec calls for zlib framing, but some servers
+// send raw deflate data, so that's tried when there's no zlib header.
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	// A zlib header's first byte names the deflate method and the pair is a multiple of 31
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+// decompressedBody closes both the decompressor and the underlying response body
+type decompressedBody struct {
+	io.Reader
+	compressed io.Closer
+}
+
+func (b *decompressedBody) Close() error {
+	if c, ok := b.Reader.(io.Closer); ok {
+		c.Close()
+	}
+	return b.compressed.Close()
+}
+
+// readResponse reads a response body, failing rather than reading on past maxResponseSize
+func readResponse(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxResponseSize {
+		return nil, fmt.Errorf("response is larger than %s", formatSize(maxResponseSize))
+	}
+	return data, nil
+}
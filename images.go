@@ -0,0 +1,129 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+// Image kinds, in the order thumbnails are preferred
+const (
+	imageThumbnail   = "thumbnail"
+	imageContent     = "content"
+	imageDescription = "description"
+)
+
+// itemImage is an image found in a feed item
+type itemImage struct {
+	url    string
+	kind   string
+	width  sql.NullInt32
+	height sql.NullInt32
+}
+
+// itemImages collects an item's media:thumbnail, image media:content and itunes:image
+// elements, plus the first <img> in its description. Relative URLs are resolved against the
+// item's link and duplicates are dropped.
+func itemImages(item RSSItem) []itemImage {
+	base, _ := url.Parse(item.Link)
+	seen := map[string]bool{}
+	var images []itemImage
+
+	add := func(src, kind string, media *RSSMedia) {
+		src = strings.TrimSpace(src)
+		if src == "" {
+			return
+		}
+		if u, err := url.Parse(src); err == nil && base != nil {
+			src = base.ResolveReference(u).String()
+		}
+		if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+			return
+		}
+		if seen[src] {
+			return
+		}
+		seen[src] = true
+
+		img := itemImage{url: src, kind: kind}
+		if media != nil {
+			img.width = parseImageSize(media.Width)
+			img.height = parseImageSize(media.Height)
+		}
+		images = append(images, img)
+	}
+
+	thumbnails := item.MediaThumbnail
+	contents := item.MediaContent
+	for _, group := range item.MediaGroup {
+		thumbnails = append(thumbnails, group.Thumbnail...)
+		contents = append(contents, group.Content...)
+	}
+
+	for i := range thumbnails {
+		add(thumbnails[i].URL, imageThumbnail, &thumbnails[i])
+	}
+	if item.ItunesImage != nil {
+		add(item.ItunesImage.Href, imageThumbnail, nil)
+	}
+	for i := range contents {
+		if isImageMedia(contents[i]) {
+			add(contents[i].URL, imageContent, &contents[i])
+		}
+	}
+	add(htmltext.FirstImage(item.Description), imageDescription, nil)
+
+	return images
+}
+
+// isImageMedia reports whether a media:content element is an image rather than audio or video
+func isImageMedia(m RSSMedia) bool {
+	if m.Medium != "" {
+		return m.Medium == "image"
+	}
+	if m.Type != "" {
+		return strings.HasPrefix(m.Type, "image/")
+	}
+	u, err := url.Parse(m.URL)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mime.TypeByExtension(path.Ext(u.Path)), "image/")
+}
+
+func parseImageSize(s string) sql.NullInt32 {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n <= 0 {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: int32(n), Valid: true}
+}
+
+// savePostImages records a new post's images
+func savePostImages(s *state, postID uuid.UUID, images []itemImage) error {
+	for _, img := range images {
+		err := s.db.CreatePostImage(s.ctx, database.CreatePostImageParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			PostID:    postID,
+			Url:       img.url,
+			Kind:      img.kind,
+			Width:     img.width,
+			Height:    img.height,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't save image %s: %w", img.url, err)
+		}
+	}
+	return nil
+}
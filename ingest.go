@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// pendingPost is a feed item ready to be saved, along with the item itself for its images
+type pendingPost struct {
+	post database.Post
+	item RSSItem
+}
+
+// savePosts saves a feed's new posts in one transaction: one query finds duplicates, one inserts
+// every post that isn't one, and one records that the feed carries them, including duplicates
+// that first came in through other feeds. Posts this feed already saved under the same guid but
+// whose title or description has since changed are updated in place by the same insert.
+// It returns the posts it saved and those it updated, in the feed's order. A dry run saves
+// nothing and returns the posts that would have been saved and updated.
+func savePosts(ctx context.Context, s *state, feed database.Feed, pending []pendingPost) (saved, updated []pendingPost, err error) {
+	if s.dryRun {
+		plan, err := planPosts(ctx, s.db, feed, pending)
+		if err != nil {
+			return nil, nil, err
+		}
+		return plan.newPosts, plan.edits, nil
+	}
+	if len(pending) == 0 {
+		return nil, nil, nil
+	}
+
+	// Inserted posts keep the ID they were sent with; updated ones keep the one they had
+	var plan postPlan
+	byID := map[uuid.UUID]database.Post{}
+	byGUID := map[string]database.Post{}
+	err = s.inTx(ctx, func(q *database.Queries) error {
+		// Feeds are scraped at the same time and links aren't unique, so posts are planned and
+		// saved one feed at a time; two feeds carrying the same article can't both insert it
+		if err := q.LockPostIngest(ctx); err != nil {
+			return fmt.Errorf("couldn't lock posts: %w", err)
+		}
+		var err error
+		plan, err = planPosts(ctx, q, feed, pending)
+		if err != nil {
+			return err
+		}
+
+		// Tombstones of posts the feed still carries are kept until it stops carrying them
+		if len(plan.pruned.Guids) > 0 {
+			if err := q.TouchPrunedPosts(ctx, plan.pruned); err != nil {
+				return fmt.Errorf("couldn't record pruned posts: %w", err)
+			}
+		}
+		if len(plan.adopted.Ids) > 0 {
+			if err := q.SetPostGuids(ctx, plan.adopted); err != nil {
+				return fmt.Errorf("couldn't record post guids: %w", err)
+			}
+		}
+		if len(plan.params.Ids) > 0 {
+			returned, err := q.CreatePosts(ctx, plan.params)
+			if err != nil {
+				return err
+			}
+			for _, post := range returned {
+				byID[post.ID] = post
+				byGUID[post.Guid] = post
+			}
+		}
+
+		// Posts saved or updated here get the categories their items are filed under
+		var categories database.AddPostCategoriesParams
+		addCategories := func(id uuid.UUID, item RSSItem) {
+			for _, name := range itemCategories(item) {
+				categories.PostIds = append(categories.PostIds, id)
+				categories.Names = append(categories.Names, name)
+			}
+		}
+		sources := plan.sources
+		var inserted []uuid.UUID
+		for _, p := range plan.newPosts {
+			if post, ok := byID[p.post.ID]; ok {
+				sources = append(sources, post.ID)
+				inserted = append(inserted, post.ID)
+				addCategories(post.ID, p.item)
+			}
+		}
+		for _, p := range plan.edits {
+			if post, ok := byGUID[p.post.Guid]; ok {
+				addCategories(post.ID, p.item)
+			}
+		}
+		if len(sources) > 0 {
+			err := q.AddPostSources(ctx, database.AddPostSourcesParams{PostIds: sources, FeedID: feed.ID})
+			if err != nil {
+				return fmt.Errorf("couldn't record post sources: %w", err)
+			}
+		}
+		if len(categories.PostIds) > 0 {
+			if err := q.AddPostCategories(ctx, categories); err != nil {
+				return fmt.Errorf("couldn't record post categories: %w", err)
+			}
+		}
+
+		// gator watch and the web UI hear of the new posts once they're committed
+		if len(inserted) > 0 {
+			if err := q.NotifyNewPosts(ctx, inserted); err != nil {
+				return fmt.Errorf("couldn't announce new posts: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, p := range plan.newPosts {
+		post, ok := byID[p.post.ID]
+		if !ok {
+			slog.Debug("post already saved", "feed", feed.Name, "post", p.post.Title)
+			continue
+		}
+		saved = append(saved, pendingPost{post: post, item: p.item})
+	}
+	for _, p := range plan.edits {
+		if post, ok := byGUID[p.post.Guid]; ok {
+			updated = append(updated, pendingPost{post: post, item: p.item})
+		}
+	}
+	return saved, updated, nil
+}
+
+// postPlan is what savePosts does with a feed's posts: insert newPosts, update edits in place,
+// and record that the feed carries the posts in sources, which were saved from other feeds.
+// params holds the posts to insert and update, and adopted the guids taken on by posts this
+// feed saved without one. pruned are the posts left out because they were pruned before.
+type postPlan struct {
+	params   database.CreatePostsParams
+	adopted  database.SetPostGuidsParams
+	newPosts []pendingPost
+	edits    []pendingPost
+	sources  []uuid.UUID
+	pruned   database.TouchPrunedPostsParams
+}
+
+// planPosts sorts a feed's posts into new posts, edits of posts the feed saved before, and
+// duplicates of posts already saved. It reads the database but doesn't write to it.
+func planPosts(ctx context.Context, db *database.Queries, feed database.Feed, pending []pendingPost) (postPlan, error) {
+	plan := postPlan{params: database.CreatePostsParams{FeedID: feed.ID}}
+	if len(pending) == 0 {
+		return plan, nil
+	}
+
+	posts := make([]database.Post, 0, len(pending))
+	for _, p := range pending {
+		posts = append(posts, p.post)
+	}
+	duplicates, err := findDuplicatePosts(ctx, db, feed.ID, posts)
+	if err != nil {
+		return plan, fmt.Errorf("couldn't check for duplicate posts: %w", err)
+	}
+	pruned, err := findPrunedPosts(ctx, db, feed.ID, posts)
+	if err != nil {
+		return plan, fmt.Errorf("couldn't check for pruned posts: %w", err)
+	}
+
+	plan.pruned.FeedID = feed.ID
+	seen := map[string]bool{}
+	adopted := map[uuid.UUID]bool{}
+	for _, p := range pending {
+		if seen[p.post.Guid] {
+			continue
+		}
+		seen[p.post.Guid] = true
+
+		// Posts pruned while the feed still carries them would otherwise come back as unread
+		if pruned[p.post.Guid] || pruned[p.post.Url] {
+			plan.pruned.Guids = append(plan.pruned.Guids, p.post.Guid)
+			plan.pruned.Urls = append(plan.pruned.Urls, p.post.Url)
+			continue
+		}
+
+		// A post this feed saved before under the same guid may have been corrected since; it's
+		// sent along with the new posts, and the insert updates it rather than adding another.
+		// One the feed saved without a guid of its own, as all posts were before guids were
+		// kept, takes on this item's guid if it's the same article.
+		existing, ok := duplicates.byGUID[p.post.Guid]
+		if !ok && hasOwnGUID(p.post) {
+			match, found := duplicates.find(p.post)
+			if found && match.FeedID == feed.ID && !hasOwnGUID(match) && !adopted[match.ID] {
+				adopted[match.ID] = true
+				plan.adopted.Ids = append(plan.adopted.Ids, match.ID)
+				plan.adopted.Guids = append(plan.adopted.Guids, p.post.Guid)
+				existing, ok = match, true
+			}
+		}
+		if ok {
+			if existing.Title != p.post.Title || existing.ContentHash != p.post.ContentHash {
+				plan.edits = append(plan.edits, p)
+				appendPost(&plan.params, p.post)
+			}
+			continue
+		}
+
+		// The same article may already have come in through another feed, or earlier in this
+		// one; note that this feed carries it too rather than saving it again
+		if existing, ok := duplicates.find(p.post); ok {
+			if existing.FeedID != feed.ID {
+				plan.sources = append(plan.sources, existing.ID)
+			}
+			continue
+		}
+		duplicates.add(p.post)
+		plan.newPosts = append(plan.newPosts, p)
+		appendPost(&plan.params, p.post)
+	}
+	return plan, nil
+}
+
+// findPrunedPosts returns the guids of posts among posts that were pruned from feed, and the links
+// of those that came in through other feeds. The feed that saved a post knows it by its guid, as
+// a feed can reuse a link for a new item.
+func findPrunedPosts(ctx context.Context, db *database.Queries, feedID uuid.UUID, posts []database.Post) (map[string]bool, error) {
+	params := database.GetPrunedPostsParams{FeedID: feedID}
+	for _, post := range posts {
+		params.Guids = append(params.Guids, post.Guid)
+		params.Urls = append(params.Urls, post.Url)
+	}
+	rows, err := db.GetPrunedPosts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := map[string]bool{}
+	for _, row := range rows {
+		if row.Guid.Valid {
+			pruned[row.Guid.String] = true
+		} else {
+			pruned[row.Url] = true
+		}
+	}
+	return pruned, nil
+}
+
+// appendPost adds post to the batch CreatePosts inserts. Its arrays can't hold NULLs, so a
+// missing value goes in as an empty string, zero or the zero time, which the query stores as NULL.
+func appendPost(params *database.CreatePostsParams, post database.Post) {
+	params.Ids = append(params.Ids, post.ID)
+	params.Titles = append(params.Titles, post.Title)
+	params.Urls = append(params.Urls, post.Url)
+	params.Descriptions = append(params.Descriptions, post.Description.String)
+	params.PublishedAts = append(params.PublishedAts, post.PublishedAt.Time)
+	params.EnclosureUrls = append(params.EnclosureUrls, post.EnclosureUrl.String)
+	params.EnclosureTypes = append(params.EnclosureTypes, post.EnclosureType.String)
+	params.EnclosureLengths = append(params.EnclosureLengths, post.EnclosureLength.Int64)
+	params.DurationSeconds = append(params.DurationSeconds, post.DurationSeconds.Int32)
+	params.PublishedAtEstimated = append(params.PublishedAtEstimated, post.PublishedAtEstimated)
+	params.CanonicalUrls = append(params.CanonicalUrls, post.CanonicalUrl.String)
+	params.ContentHashes = append(params.ContentHashes, post.ContentHash.String)
+	params.Authors = append(params.Authors, post.Author.String)
+	params.Guids = append(params.Guids, post.Guid)
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// hashIterations follows OWASP's current recommendation for PBKDF2-HMAC-SHA256
+const hashIterations = 600_000
+
+const (
+	hashScheme = "pbkdf2-sha256"
+	saltLength = 16
+	keyLength  = 32
+)
+
+// ErrIncorrectPassword is returned when a password doesn't match its stored hash
+var ErrIncorrectPassword = errors.New("incorrect password")
+
+// HashPassword returns a salted hash of password in the form
+// pbkdf2-sha256$<iterations>$<salt>$<key>, with salt and key base64 encoded.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, hashIterations, keyLength)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s$%d$%s$%s", hashScheme, hashIterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// CheckPassword returns nil if password matches hash, or ErrIncorrectPassword if it doesn't
+func CheckPassword(hash, password string) error {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != hashScheme {
+		return errors.New("unsupported password hash")
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return errors.New("invalid password hash")
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errors.New("invalid password hash")
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return errors.New("invalid password hash")
+	}
+
+	got, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(got, want) != 1 {
+		return ErrIncorrectPassword
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package charset
+
+//go:generate python3 gen_tables.py
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// xmlEncoding matches the encoding in an XML declaration
+var xmlEncoding = regexp.MustCompile(`^\s*<\?xml[^>]*\bencoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+
+// aliases maps charset labels to the names used by Decode. ISO-8859-1 and ASCII are read as
+// windows-1252, a superset that feeds labelled with them very often turn out to be, which is
+// what browsers do too.
+var aliases = map[string]string{
+	"utf8":              "utf-8",
+	"unicode-1-1-utf-8": "utf-8",
+	"utf-16":            "utf-16",
+	"utf-16le":          "utf-16le",
+	"utf-16be":          "utf-16be",
+	"ucs-2":             "utf-16",
+	"us-ascii":          "windows-1252",
+	"ascii":             "windows-1252",
+	"iso-8859-1":        "windows-1252",
+	"iso8859-1":         "windows-1252",
+	"iso_8859-1":        "windows-1252",
+	"latin1":            "windows-1252",
+	"l1":                "windows-1252",
+	"cp1252":            "windows-1252",
+	"x-cp1252":          "windows-1252",
+	"cp1250":            "windows-1250",
+	"cp1251":            "windows-1251",
+	"cp1253":            "windows-1253",
+	"cp1254":            "windows-1254",
+	"iso-8859-9":        "windows-1254",
+	"latin5":            "windows-1254",
+	"cp1255":            "windows-1255",
+	"cp1256":            "windows-1256",
+	"cp1257":            "windows-1257",
+	"cp1258":            "windows-1258",
+	"latin2":            "iso-8859-2",
+	"latin9":            "iso-8859-15",
+	"cyrillic":          "iso-8859-5",
+	"greek":             "iso-8859-7",
+	"hebrew":            "iso-8859-8",
+	"koi8":              "koi8-r",
+	"mac":               "macintosh",
+	"x-mac-roman":       "macintosh",
+	"gbk":               "gbk",
+	"gb2312":            "gbk",
+	"gb_2312-80":        "gbk",
+	"cp936":             "gbk",
+	"x-gbk":             "gbk",
+	"euc-cn":            "gbk",
+}
+
+// DecodeXML returns an XML document as UTF-8. Its encoding is taken from a byte order mark,
+// then the charset parameter of contentType, then the document's XML declaration, and is
+// otherwise assumed to be UTF-8. A UTF-8 charset parameter is ignored for documents that
+// aren't valid UTF-8. The XML declaration is left as it is, so the result should be parsed
+// with a decoder that ignores the declared encoding.
+func DecodeXML(data []byte, contentType string) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return Decode(data[3:], "utf-8")
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return Decode(data[2:], "utf-16le")
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return Decode(data[2:], "utf-16be")
+	}
+
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		// Servers often claim UTF-8 by default; the declaration is a better guess when the
+		// document plainly isn't
+		if normalize(params["charset"]) != "utf-8" || utf8.Valid(data) {
+			return Decode(data, params["charset"])
+		}
+	}
+	if m := xmlEncoding.FindSubmatch(data); m != nil {
+		return Decode(data, string(m[1]))
+	}
+	return Decode(data, "utf-8")
+}
+
+// Decode converts data in the named charset to UTF-8. Bytes that don't map to a character
+// become U+FFFD.
+func Decode(data []byte, label string) ([]byte, error) {
+	name := normalize(label)
+	switch name {
+	case "utf-8":
+		return bytes.ToValidUTF8(data, []byte("\uFFFD")), nil
+	case "utf-16", "utf-16be":
+		return decodeUTF16(data, binary.BigEndian), nil
+	case "utf-16le":
+		return decodeUTF16(data, binary.LittleEndian), nil
+	case "gbk":
+		return decodeGBK(data), nil
+	}
+
+	table, ok := singleByteTables[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported charset %q", label)
+	}
+	return decodeSingleByte(data, []rune(table)), nil
+}
+
+// normalize returns the name Decode knows a charset label by
+func normalize(label string) string {
+	name := strings.ToLower(strings.TrimSpace(label))
+	if alias, ok := aliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// decodeSingleByte maps bytes 0x80-0xFF through table; lower bytes are ASCII
+func decodeSingleByte(data []byte, table []rune) []byte {
+	out := make([]byte, 0, len(data)+len(data)/4)
+	for _, b := range data {
+		if b < utf8.RuneSelf {
+			out = append(out, b)
+			continue
+		}
+		out = utf8.AppendRune(out, table[b-0x80])
+	}
+	return out
+}
+
+func decodeUTF16(data []byte, order binary.ByteOrder) []byte {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, order.Uint16(data[i:]))
+	}
+
+	out := make([]byte, 0, len(units))
+	for _, r := range utf16.Decode(units) {
+		out = utf8.AppendRune(out, r)
+	}
+	return out
+}
+
+// gbkTrailBytes is how many trail bytes each GBK lead byte has: 0x40-0x7E and 0x80-0xFE
+const gbkTrailBytes = 190
+
+// gbkIndex returns where a two-byte GBK sequence is in gbkTable, or false if it isn't valid
+func gbkIndex(lead, trail byte) (int, bool) {
+	if lead < 0x81 || lead == 0xFF || trail < 0x40 || trail == 0x7F || trail == 0xFF {
+		return 0, false
+	}
+	t := int(trail) - 0x40
+	if trail > 0x7F {
+		t--
+	}
+	return int(lead-0x81)*gbkTrailBytes + t, true
+}
+
+// gbkRunes indexes gbkTable by character, built the first time a GBK feed turns up
+var gbkRunes = sync.OnceValue(func() []rune { return []rune(gbkTable) })
+
+func decodeGBK(data []byte) []byte {
+	table := gbkRunes()
+
+	out := make([]byte, 0, len(data)+len(data)/2)
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch {
+		case b < utf8.RuneSelf:
+			out = append(out, b)
+		case b == 0x80:
+			out = utf8.AppendRune(out, '€')
+		case i+1 < len(data):
+			if idx, ok := gbkIndex(b, data[i+1]); ok {
+				out = utf8.AppendRune(out, table[idx])
+				i++
+				continue
+			}
+			// Not a valid pair; the next byte is read on its own
+			out = utf8.AppendRune(out, utf8.RuneError)
+		default:
+			out = utf8.AppendRune(out, utf8.RuneError)
+		}
+	}
+	return out
+}
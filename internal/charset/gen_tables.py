@@ -0,0 +1,84 @@
+#!/usr/bin/env python3
+"""Generates tables.go from Python's codecs. Run with go generate in this directory."""
+
+import unicodedata
+
+# Single-byte charsets: canonical name -> Python codec
+SINGLE_BYTE = [
+    ("windows-1250", "cp1250"),
+    ("windows-1251", "cp1251"),
+    ("windows-1252", "cp1252"),
+    ("windows-1253", "cp1253"),
+    ("windows-1254", "cp1254"),
+    ("windows-1255", "cp1255"),
+    ("windows-1256", "cp1256"),
+    ("windows-1257", "cp1257"),
+    ("windows-1258", "cp1258"),
+    ("iso-8859-2", "iso8859_2"),
+    ("iso-8859-3", "iso8859_3"),
+    ("iso-8859-4", "iso8859_4"),
+    ("iso-8859-5", "iso8859_5"),
+    ("iso-8859-6", "iso8859_6"),
+    ("iso-8859-7", "iso8859_7"),
+    ("iso-8859-8", "iso8859_8"),
+    ("iso-8859-10", "iso8859_10"),
+    ("iso-8859-13", "iso8859_13"),
+    ("iso-8859-14", "iso8859_14"),
+    ("iso-8859-15", "iso8859_15"),
+    ("iso-8859-16", "iso8859_16"),
+    ("koi8-r", "koi8_r"),
+    ("koi8-u", "koi8_u"),
+    ("macintosh", "mac_roman"),
+]
+
+
+def decode(codec, raw):
+    try:
+        s = raw.decode(codec)
+    except UnicodeDecodeError:
+        return "�"
+    return s if len(s) == 1 else "�"
+
+
+def quote(chars):
+    out = []
+    for c in chars:
+        printable = unicodedata.category(c)[0] not in "CZ" or c == " "
+        if c in '"\\' or not printable:
+            out.append("\\u%04x" % ord(c))
+        else:
+            out.append(c)
+    return '"' + "".join(out) + '"'
+
+
+def literal(chars, indent, width=64):
+    lines = [quote(chars[i:i + width]) for i in range(0, len(chars), width)]
+    return (" +\n" + indent).join(lines)
+
+
+def main():
+    out = ["// Code generated by gen_tables.py from Python's codecs. DO NOT EDIT.", "", "package charset", ""]
+
+    out.append("// singleByteTables holds the characters for bytes 0x80-0xFF of each single-byte charset")
+    out.append("var singleByteTables = map[string]string{")
+    for name, codec in SINGLE_BYTE:
+        chars = "".join(decode(codec, bytes([b])) for b in range(0x80, 0x100))
+        out.append('\t"%s": %s,' % (name, literal(chars, "\t\t")))
+    out.append("}")
+    out.append("")
+
+    chars = []
+    for lead in range(0x81, 0xFF):
+        for trail in list(range(0x40, 0x7F)) + list(range(0x80, 0xFF)):
+            chars.append(decode("gbk", bytes([lead, trail])))
+    out.append("// gbkTable holds the characters for two-byte GBK sequences, %d trail bytes per lead byte" % 190)
+    out.append("// starting at 0x81; see gbkIndex")
+    out.append("const gbkTable = " + literal("".join(chars), "\t") )
+    out.append("")
+
+    with open("tables.go", "w", encoding="utf-8") as f:
+        f.write("\n".join(out))
+
+
+if __name__ == "__main__":
+    main()
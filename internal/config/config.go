@@ -2,59 +2,634 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/hostlimit"
+	"github.com/Utkarsh736/gator/internal/schedule"
+)
+
+// The config lives at $XDG_CONFIG_HOME/gator/config.json; legacyConfigFileName in the home
+// directory is still used when only it exists
+const (
+	configDirName        = "gator"
+	configFileName       = "config.json"
+	legacyConfigFileName = ".gatorconfig.json"
 )
 
-const configFileName = ".gatorconfig.json"
+// Environment variables that override the config file, for containers and the like
+const (
+	configPathEnv = "GATOR_CONFIG"
+	dbURLEnv      = "GATOR_DB_URL"
+	profileEnv    = "GATOR_PROFILE"
+)
 
 // Config represents the structure of the JSON config file
 type Config struct {
-	DbURL           string `json:"db_url"`
-	CurrentUserName string `json:"current_user_name"`
+	DbURL                 string              `json:"db_url"`
+	CurrentUserName       string              `json:"current_user_name"`
+	MinFetchInterval      string              `json:"min_fetch_interval,omitempty"`
+	MaxFetchInterval      string              `json:"max_fetch_interval,omitempty"`
+	RetentionPeriod       string              `json:"retention_period,omitempty"`
+	PodcastDir            string              `json:"podcast_dir,omitempty"`
+	MaxFetchFailures      int                 `json:"max_fetch_failures,omitempty"`
+	FetchRetries          int                 `json:"fetch_retries,omitempty"`
+	FetchRetryDelay       string              `json:"fetch_retry_delay,omitempty"`
+	HostRequestsPerSecond float64             `json:"host_requests_per_second,omitempty"`
+	FetchConcurrency      int                 `json:"fetch_concurrency,omitempty"`
+	AggInterval           string              `json:"agg_interval,omitempty"`
+	Output                string              `json:"output,omitempty"`
+	Notify                bool                `json:"notify,omitempty"`
+	HTTP                  *HTTPConfig         `json:"http,omitempty"`
+	Database              *DatabaseConfig     `json:"database,omitempty"`
+	SkipImages            bool                `json:"skip_images,omitempty"`
+	AutoUpdateURLs        bool                `json:"auto_update_urls,omitempty"`
+	MetricsAddr           string              `json:"metrics_addr,omitempty"`
+	LogLevel              string              `json:"log_level,omitempty"`
+	LogFormat             string              `json:"log_format,omitempty"`
+	LogFile               string              `json:"log_file,omitempty"`
+	Passwordless          bool                `json:"passwordless,omitempty"`
+	SMTP                  *SMTPConfig         `json:"smtp,omitempty"`
+	Digest                *DigestConfig       `json:"digest,omitempty"`
+	Telegram              *TelegramConfig     `json:"telegram,omitempty"`
+	Instapaper            *InstapaperConfig   `json:"instapaper,omitempty"`
+	Wallabag              *WallabagConfig     `json:"wallabag,omitempty"`
+	SendSavedTo           string              `json:"send_saved_to,omitempty"`
+	PackIndexURL          string              `json:"pack_index_url,omitempty"`
+	Hooks                 *HooksConfig        `json:"hooks,omitempty"`
+	Server                *ServerConfig       `json:"server,omitempty"`
+	Aliases               map[string]string   `json:"aliases,omitempty"`
+	Profiles              map[string]*Profile `json:"profiles,omitempty"`
+
+	// path is the file the config was read from, and fileDbURL the db_url in it, which is what
+	// gets written back even when GATOR_DB_URL overrides it. With a profile selected, DbURL and
+	// CurrentUserName are the profile's, and defaults keeps the top-level ones.
+	path      string
+	fileDbURL string
+	profile   string
+	defaults  Profile
+}
+
+// Profile is a named database and user, so one config can serve several databases. Every
+// other setting is shared between profiles.
+type Profile struct {
+	DbURL           string `json:"db_url,omitempty"`
+	CurrentUserName string `json:"current_user_name,omitempty"`
+}
+
+// HTTPConfig tunes the HTTP client used for feeds, article pages and podcast downloads.
+// MaxRedirects of -1 means redirects aren't followed. MaxResponseSize, e.g. "10MB", caps a
+// feed, article or discovery page after decompression.
+type HTTPConfig struct {
+	Timeout         string `json:"timeout,omitempty"`
+	Proxy           string `json:"proxy,omitempty"`
+	UserAgent       string `json:"user_agent,omitempty"`
+	MaxRedirects    int    `json:"max_redirects,omitempty"`
+	MaxResponseSize string `json:"max_response_size,omitempty"`
+	// PrivateAddresses is "allow" or "block" for feeds on loopback, link-local and private
+	// addresses; unset, they're blocked only when serving or with several users
+	PrivateAddresses string `json:"private_addresses,omitempty"`
+}
+
+// DatabaseConfig tunes the pool of database connections. ConnMaxLifetime, e.g. "30m", closes
+// connections after that long, so a restarted server or a moved failover address is picked up.
+type DatabaseConfig struct {
+	MaxOpenConns    int    `json:"max_open_conns,omitempty"`
+	MaxIdleConns    int    `json:"max_idle_conns,omitempty"`
+	ConnMaxLifetime string `json:"conn_max_lifetime,omitempty"`
 }
 
-// Read loads the config from ~/.gatorconfig.json
+// SMTPConfig is the mail server used to send digests
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	From     string `json:"from"`
+}
+
+// DigestConfig schedules digests sent automatically by the agg daemon
+type DigestConfig struct {
+	Email    string `json:"email"`
+	Schedule string `json:"schedule"`
+	Since    string `json:"since,omitempty"`
+}
+
+// Read loads the config file, then applies overrides from the environment. A missing config
+// file reads as an empty config, so GATOR_DB_URL or 'gator config set' can stand in for it.
 func Read() (Config, error) {
 	configPath, err := getConfigFilePath()
 	if err != nil {
 		return Config{}, err
 	}
 
+	var cfg Config
 	data, err := os.ReadFile(configPath)
-	if err != nil {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
 		return Config{}, err
+	default:
+		if err := decode(data, &cfg); err != nil {
+			return Config{}, fmt.Errorf("couldn't parse %s: %w", configPath, err)
+		}
 	}
 
-	var cfg Config
-	err = json.Unmarshal(data, &cfg)
-	if err != nil {
-		return Config{}, err
-	}
+	cfg.path = configPath
+	cfg.defaults = Profile{DbURL: cfg.DbURL, CurrentUserName: cfg.CurrentUserName}
+	cfg.UseProfile(os.Getenv(profileEnv))
 
 	return cfg, nil
 }
 
+// UseProfile switches to the named profile, or back to the top-level settings for "". A
+// profile that doesn't exist yet starts out empty and is saved once something is set in it.
+func (c *Config) UseProfile(name string) {
+	p := c.defaults
+	if name != "" {
+		p = Profile{}
+		if c.Profiles[name] != nil {
+			p = *c.Profiles[name]
+		}
+	}
+
+	c.profile = name
+	c.CurrentUserName = p.CurrentUserName
+	c.fileDbURL = p.DbURL
+	c.DbURL = p.DbURL
+	if dbURL := os.Getenv(dbURLEnv); dbURL != "" {
+		c.DbURL = dbURL
+	}
+}
+
+// Profile returns the selected profile, or "" for the top-level settings
+func (c *Config) Profile() string {
+	return c.profile
+}
+
+// ProfileNames lists the profiles in the config file, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Path returns the config file in use, which may not exist yet
+func (c *Config) Path() string {
+	return c.path
+}
+
+// TelegramConfig connects gator to a Telegram bot. ChatID is filled in when a chat sends /start
+// with the pairing code gator telegram prints.
+type TelegramConfig struct {
+	BotToken string `json:"bot_token"`
+	ChatID   int64  `json:"chat_id,omitempty"`
+}
+
+// InstapaperConfig holds the account send uses for Instapaper's Simple API
+type InstapaperConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+}
+
+// WallabagConfig holds the server, API client and account send uses for wallabag. The client
+// ID and secret come from creating an API client in wallabag's settings.
+type WallabagConfig struct {
+	URL          string `json:"url"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+}
+
+// HooksConfig holds the shell commands run on events, each given the event as JSON on stdin.
+// Timeout, e.g. "30s", bounds how long a hook may run.
+type HooksConfig struct {
+	PostIngested string `json:"post_ingested,omitempty"`
+	FeedFailed   string `json:"feed_failed,omitempty"`
+	PostSaved    string `json:"post_saved,omitempty"`
+	Timeout      string `json:"timeout,omitempty"`
+}
+
+// ServerConfig tunes gator serve. With OpenRegistration, anyone who can reach the server can
+// create an account through the API; otherwise accounts are only made with gator register.
+type ServerConfig struct {
+	OpenRegistration bool `json:"open_registration,omitempty"`
+}
+
+// defaultHookTimeout is how long a hook may run when hooks.timeout isn't set
+const defaultHookTimeout = 30 * time.Second
+
 // SetUser updates the current_user_name and writes to disk
 func (c *Config) SetUser(username string) error {
 	c.CurrentUserName = username
 	return write(*c)
 }
 
-// getConfigFilePath returns the full path to the config file
+// SetTelegramChat records the chat the Telegram bot talks to and writes to disk
+func (c *Config) SetTelegramChat(chatID int64) error {
+	if c.Telegram == nil {
+		c.Telegram = &TelegramConfig{}
+	}
+	c.Telegram.ChatID = chatID
+	return write(*c)
+}
+
+// FetchIntervalBounds returns the min/max adaptive polling intervals, falling back to defaults
+func (c *Config) FetchIntervalBounds() (time.Duration, time.Duration, error) {
+	min, max := schedule.DefaultMinInterval, schedule.DefaultMaxInterval
+
+	if c.MinFetchInterval != "" {
+		d, err := ParseDuration(c.MinFetchInterval)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid min_fetch_interval: %w", err)
+		}
+		min = d
+	}
+
+	if c.MaxFetchInterval != "" {
+		d, err := ParseDuration(c.MaxFetchInterval)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid max_fetch_interval: %w", err)
+		}
+		max = d
+	}
+
+	if min > max {
+		return 0, 0, fmt.Errorf("min_fetch_interval (%s) is greater than max_fetch_interval (%s)", min, max)
+	}
+
+	return min, max, nil
+}
+
+// Retention returns the configured post retention period, or false if pruning is disabled
+func (c *Config) Retention() (time.Duration, bool, error) {
+	if c.RetentionPeriod == "" {
+		return 0, false, nil
+	}
+
+	d, err := ParseDuration(c.RetentionPeriod)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid retention_period: %w", err)
+	}
+	return d, true, nil
+}
+
+// DigestSince returns how far back scheduled digests look, defaulting to a day
+func (c *Config) DigestSince() (time.Duration, error) {
+	if c.Digest == nil || c.Digest.Since == "" {
+		return 24 * time.Hour, nil
+	}
+
+	d, err := ParseDuration(c.Digest.Since)
+	if err != nil {
+		return 0, fmt.Errorf("invalid digest since: %w", err)
+	}
+	return d, nil
+}
+
+// PodcastDirectory returns where downloaded episodes are saved, defaulting to ~/Podcasts
+func (c *Config) PodcastDirectory() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	if c.PodcastDir == "" {
+		return filepath.Join(homeDir, "Podcasts"), nil
+	}
+	if rest, ok := strings.CutPrefix(c.PodcastDir, "~/"); ok {
+		return filepath.Join(homeDir, rest), nil
+	}
+	return c.PodcastDir, nil
+}
+
+// defaultMaxFetchFailures is how many fetches in a row may fail before agg pauses a feed
+const defaultMaxFetchFailures = 10
+
+// FetchFailureLimit returns how many consecutive failed fetches pause a feed. A negative
+// max_fetch_failures turns automatic pausing off, reported as ok being false.
+func (c *Config) FetchFailureLimit() (limit int, ok bool) {
+	switch {
+	case c.MaxFetchFailures < 0:
+		return 0, false
+	case c.MaxFetchFailures == 0:
+		return defaultMaxFetchFailures, true
+	}
+	return c.MaxFetchFailures, true
+}
+
+// Defaults for retrying transient fetch failures within one agg pass
+const (
+	defaultFetchRetries    = 3
+	defaultFetchRetryDelay = 2 * time.Second
+)
+
+// FetchRetryPolicy returns how many times a failed fetch is retried and the delay before the
+// first retry. A negative fetch_retries turns retrying off.
+func (c *Config) FetchRetryPolicy() (int, time.Duration, error) {
+	retries := c.FetchRetries
+	switch {
+	case retries < 0:
+		retries = 0
+	case retries == 0:
+		retries = defaultFetchRetries
+	}
+
+	delay := defaultFetchRetryDelay
+	if c.FetchRetryDelay != "" {
+		d, err := ParseDuration(c.FetchRetryDelay)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid fetch_retry_delay: %w", err)
+		}
+		delay = d
+	}
+	return retries, delay, nil
+}
+
+// HostRequestRate returns how many requests per second agg makes to one host. A negative
+// host_requests_per_second turns the limit off, reported as zero.
+func (c *Config) HostRequestRate() float64 {
+	switch {
+	case c.HostRequestsPerSecond < 0:
+		return 0
+	case c.HostRequestsPerSecond == 0:
+		return hostlimit.DefaultRate
+	}
+	return c.HostRequestsPerSecond
+}
+
+// defaultFetchConcurrency is how many feeds agg fetches at once
+const defaultFetchConcurrency = 1
+
+// FetchConcurrencyLimit returns how many due feeds agg and fetch work on at once
+func (c *Config) FetchConcurrencyLimit() int {
+	if c.FetchConcurrency <= 0 {
+		return defaultFetchConcurrency
+	}
+	return c.FetchConcurrency
+}
+
+// Defaults for the database connection pool
+const (
+	defaultMaxOpenConns    = 10
+	defaultMaxIdleConns    = 2
+	defaultConnMaxLifetime = 30 * time.Minute
+)
+
+// DatabasePool is the connection pool gator opens, with defaults filled in. Zero values mean no
+// limit, as they do for database/sql: no cap on open connections, no idle connections kept,
+// connections kept for ever.
+type DatabasePool struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// DatabasePool returns the connection pool settings. A negative max_open_conns lifts the cap on
+// open connections, a negative max_idle_conns keeps none idle, and a conn_max_lifetime of 0
+// keeps connections open for ever.
+func (c *Config) DatabasePool() (DatabasePool, error) {
+	pool := DatabasePool{
+		MaxOpenConns:    defaultMaxOpenConns,
+		MaxIdleConns:    defaultMaxIdleConns,
+		ConnMaxLifetime: defaultConnMaxLifetime,
+	}
+	if c.Database == nil {
+		return pool, nil
+	}
+
+	switch {
+	case c.Database.MaxOpenConns < 0:
+		pool.MaxOpenConns = 0
+	case c.Database.MaxOpenConns > 0:
+		pool.MaxOpenConns = c.Database.MaxOpenConns
+	}
+	switch {
+	case c.Database.MaxIdleConns < 0:
+		pool.MaxIdleConns = 0
+	case c.Database.MaxIdleConns > 0:
+		pool.MaxIdleConns = c.Database.MaxIdleConns
+	}
+	if c.Database.ConnMaxLifetime != "" {
+		d, err := ParseDuration(c.Database.ConnMaxLifetime)
+		if err != nil || d < 0 {
+			return DatabasePool{}, fmt.Errorf("invalid database conn_max_lifetime %q", c.Database.ConnMaxLifetime)
+		}
+		pool.ConnMaxLifetime = d
+	}
+
+	if pool.MaxOpenConns > 0 && pool.MaxIdleConns > pool.MaxOpenConns {
+		return DatabasePool{}, fmt.Errorf("database max_idle_conns (%d) is more than max_open_conns (%d)", pool.MaxIdleConns, pool.MaxOpenConns)
+	}
+	return pool, nil
+}
+
+// Hook returns the command to run for event, one of post_ingested, feed_failed or post_saved,
+// and how long it may run; the command is "" when there is no hook for the event
+func (c *Config) Hook(event string) (string, time.Duration, error) {
+	if c.Hooks == nil {
+		return "", 0, nil
+	}
+
+	var command string
+	switch event {
+	case "post_ingested":
+		command = c.Hooks.PostIngested
+	case "feed_failed":
+		command = c.Hooks.FeedFailed
+	case "post_saved":
+		command = c.Hooks.PostSaved
+	default:
+		return "", 0, fmt.Errorf("unknown hook event %q", event)
+	}
+
+	timeout := defaultHookTimeout
+	if c.Hooks.Timeout != "" {
+		d, err := ParseDuration(c.Hooks.Timeout)
+		if err != nil || d <= 0 {
+			return "", 0, fmt.Errorf("invalid hooks timeout %q", c.Hooks.Timeout)
+		}
+		timeout = d
+	}
+	return command, timeout, nil
+}
+
+// DefaultAggInterval returns how long agg waits between passes when not given a duration, and
+// false if agg_interval isn't set
+func (c *Config) DefaultAggInterval() (time.Duration, bool, error) {
+	if c.AggInterval == "" {
+		return 0, false, nil
+	}
+
+	d, err := ParseDuration(c.AggInterval)
+	if err != nil || d <= 0 {
+		return 0, false, fmt.Errorf("invalid agg_interval %q", c.AggInterval)
+	}
+	return d, true, nil
+}
+
+// Validate checks every setting that has to parse, so mistakes show up as soon as gator starts
+// rather than when a command first needs the setting
+func (c *Config) Validate() error {
+	if _, _, err := c.FetchIntervalBounds(); err != nil {
+		return err
+	}
+	if _, _, err := c.Retention(); err != nil {
+		return err
+	}
+	if _, _, err := c.FetchRetryPolicy(); err != nil {
+		return err
+	}
+	if _, _, err := c.DefaultAggInterval(); err != nil {
+		return err
+	}
+	if _, err := c.DatabasePool(); err != nil {
+		return err
+	}
+	if _, err := c.DigestSince(); err != nil {
+		return err
+	}
+	if c.Digest != nil && c.Digest.Schedule != "" {
+		if _, err := schedule.ParseCron(c.Digest.Schedule); err != nil {
+			return fmt.Errorf("invalid digest schedule: %w", err)
+		}
+	}
+	if c.HTTP != nil && c.HTTP.MaxResponseSize != "" {
+		if n, err := ParseSize(c.HTTP.MaxResponseSize); err != nil || n == 0 {
+			return fmt.Errorf("invalid http max_response_size %q", c.HTTP.MaxResponseSize)
+		}
+	}
+	if c.HTTP != nil {
+		switch c.HTTP.PrivateAddresses {
+		case "", "allow", "block":
+		default:
+			return fmt.Errorf("invalid http private_addresses %q (expected allow or block)", c.HTTP.PrivateAddresses)
+		}
+	}
+	if c.FetchConcurrency < 0 {
+		return fmt.Errorf("invalid fetch_concurrency %d (expected 1 or more)", c.FetchConcurrency)
+	}
+	if c.SMTP != nil && (c.SMTP.Port < 0 || c.SMTP.Port > 65535) {
+		return fmt.Errorf("invalid smtp port %d", c.SMTP.Port)
+	}
+	switch c.SendSavedTo {
+	case "", "instapaper", "wallabag":
+	default:
+		return fmt.Errorf("invalid send_saved_to %q (expected instapaper or wallabag)", c.SendSavedTo)
+	}
+	if c.Hooks != nil && c.Hooks.Timeout != "" {
+		if d, err := ParseDuration(c.Hooks.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid hooks timeout %q", c.Hooks.Timeout)
+		}
+	}
+	for name, expansion := range c.Aliases {
+		if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\n.") {
+			return fmt.Errorf("invalid alias name %q: use a single word", name)
+		}
+		if strings.TrimSpace(expansion) == "" {
+			return fmt.Errorf("alias %s is empty", name)
+		}
+	}
+	return nil
+}
+
+// ParseDuration is time.ParseDuration with additional d (day) and w (week) units, e.g. "90d"
+func ParseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+// ParseSize reads a byte count with an optional B, KB, MB or GB suffix (powers of 1024), e.g. "10MB"
+func ParseSize(s string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, suffix := range []struct {
+		name string
+		size int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if n, ok := strings.CutSuffix(upper, suffix.name); ok {
+			upper, unit = strings.TrimSpace(n), suffix.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * unit, nil
+}
+
+// getConfigFilePath returns the full path to the config file: GATOR_CONFIG if set, otherwise
+// the XDG location unless only the legacy ~/.gatorconfig.json exists
 func getConfigFilePath() (string, error) {
+	if configPath := os.Getenv(configPathEnv); configPath != "" {
+		return configPath, nil
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(homeDir, configFileName), nil
+
+	// Relative values of XDG_CONFIG_HOME are invalid under the XDG spec and ignored
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if !filepath.IsAbs(configHome) {
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	configPath := filepath.Join(configHome, configDirName, configFileName)
+	if _, err := os.Stat(configPath); err == nil {
+		return configPath, nil
+	}
+
+	legacyPath := filepath.Join(homeDir, legacyConfigFileName)
+	if _, err := os.Stat(legacyPath); err == nil {
+		return legacyPath, nil
+	}
+	return configPath, nil
 }
 
-// write saves the config to disk
+// write saves the config to disk, leaving out environment overrides and putting the selected
+// profile's settings back under its name
 func write(cfg Config) error {
-	configPath, err := getConfigFilePath()
-	if err != nil {
-		return err
+	configPath := cfg.path
+	if configPath == "" {
+		var err error
+		configPath, err = getConfigFilePath()
+		if err != nil {
+			return err
+		}
+	}
+
+	cfg.DbURL = cfg.fileDbURL
+	if cfg.profile != "" {
+		profiles := maps.Clone(cfg.Profiles)
+		if profiles == nil {
+			profiles = map[string]*Profile{}
+		}
+		profiles[cfg.profile] = &Profile{DbURL: cfg.DbURL, CurrentUserName: cfg.CurrentUserName}
+		cfg.Profiles = profiles
+		cfg.DbURL, cfg.CurrentUserName = cfg.defaults.DbURL, cfg.defaults.CurrentUserName
 	}
 
 	data, err := json.MarshalIndent(cfg, "", "  ")
@@ -62,6 +637,13 @@ func write(cfg Config) error {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0644)
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return err
+	}
+	// The file holds passwords and tokens, so it's readable by its owner only. WriteFile only
+	// sets the mode of a new file, so an older, world-readable one is tightened first.
+	if err := os.Chmod(configPath, 0600); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return os.WriteFile(configPath, data, 0600)
 }
-
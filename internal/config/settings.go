@@ -0,0 +1,284 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Utkarsh736/gator/internal/fuzzy"
+)
+
+// Setting is one value in the config file. Keys are JSON names, with sections joined by dots,
+// like "db_url" or "http.timeout"; an alias is "aliases.<name>".
+type Setting struct {
+	Key   string
+	Value string
+}
+
+// Keys lists every setting the config file accepts
+func Keys() []string {
+	return keys(reflect.TypeOf(Config{}), "")
+}
+
+func keys(t reflect.Type, prefix string) []string {
+	var out []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name := jsonName(field)
+		if name == "" || field.Type.Kind() == reflect.Map {
+			continue
+		}
+		if isSection(field.Type) {
+			out = append(out, keys(field.Type.Elem(), prefix+name+".")...)
+			continue
+		}
+		out = append(out, prefix+name)
+	}
+	return out
+}
+
+// Settings lists the settings that have a value, in the order Keys gives, followed by the
+// aliases by name
+func (c *Config) Settings() []Setting {
+	settings := []Setting{}
+	for _, key := range Keys() {
+		if value, ok, _ := c.Get(key); ok {
+			settings = append(settings, Setting{Key: key, Value: value})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Aliases)) {
+		settings = append(settings, Setting{Key: "aliases." + name, Value: c.Aliases[name]})
+	}
+	return settings
+}
+
+// Get returns a setting's value, and false if it isn't set. The database URL and current user
+// are the selected profile's, and the database URL is the one in use, which GATOR_DB_URL may
+// have overridden.
+func (c *Config) Get(key string) (string, bool, error) {
+	if name, ok := strings.CutPrefix(key, "aliases."); ok {
+		expansion, ok := c.Aliases[name]
+		return expansion, ok, nil
+	}
+
+	path, err := lookup(key)
+	if err != nil {
+		return "", false, err
+	}
+
+	v := reflect.ValueOf(c).Elem()
+	for _, index := range path {
+		v = v.Field(index)
+		if isSection(v.Type()) {
+			if v.IsNil() {
+				return "", false, nil
+			}
+			v = v.Elem()
+		}
+	}
+	if v.IsZero() {
+		return "", false, nil
+	}
+	return formatValue(v), true, nil
+}
+
+// Set changes a setting in memory, parsing value for the setting's type; an empty value unsets
+// it. Call Save to write the change to disk.
+func (c *Config) Set(key, value string) error {
+	if name, ok := strings.CutPrefix(key, "aliases."); ok {
+		c.setAlias(name, value)
+		return nil
+	}
+
+	path, err := lookup(key)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(c).Elem()
+	var sections []reflect.Value
+	for _, index := range path {
+		v = v.Field(index)
+		if isSection(v.Type()) {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			sections = append(sections, v)
+			v = v.Elem()
+		}
+	}
+
+	if value == "" {
+		v.SetZero()
+		// Drop sections left empty, innermost first, rather than writing them out as {}
+		for i := len(sections) - 1; i >= 0; i-- {
+			if sections[i].Elem().IsZero() {
+				sections[i].SetZero()
+			}
+		}
+	} else if err := parseValue(v, value); err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	if key == "db_url" {
+		c.fileDbURL = c.DbURL
+	}
+	return nil
+}
+
+// setAlias adds, changes or, with an empty expansion, removes an alias
+func (c *Config) setAlias(name, expansion string) {
+	if expansion == "" {
+		delete(c.Aliases, name)
+		if len(c.Aliases) == 0 {
+			c.Aliases = nil
+		}
+		return
+	}
+	if c.Aliases == nil {
+		c.Aliases = map[string]string{}
+	}
+	c.Aliases[name] = expansion
+}
+
+// Save writes the config to disk
+func (c *Config) Save() error {
+	return write(*c)
+}
+
+// decode parses a config file, rejecting keys gator doesn't know so that a typo doesn't
+// silently leave a setting at its default
+func decode(data []byte, cfg *Config) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(cfg)
+	if err == nil {
+		return nil
+	}
+
+	quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return err
+	}
+	name, err := strconv.Unquote(quoted)
+	if err != nil {
+		name = quoted
+	}
+	if suggestion := closestKey(name); suggestion != "" {
+		return fmt.Errorf("unknown setting %q; did you mean %s?", name, suggestion)
+	}
+	return fmt.Errorf("unknown setting %q; 'gator config list --all' shows every setting", name)
+}
+
+// closestKey suggests the setting someone probably meant by name: one a typo or two away, one
+// it's the start of, or one of the same name in a section
+func closestKey(name string) string {
+	best, bestDistance := "", 3
+	for _, key := range Keys() {
+		leaf := key[strings.LastIndex(key, ".")+1:]
+		if len(name) >= 4 && strings.HasPrefix(leaf, name) {
+			return key
+		}
+		if d := fuzzy.Distance(name, leaf); d < bestDistance {
+			best, bestDistance = key, d
+		}
+	}
+	return best
+}
+
+// lookup returns the field indexes leading from Config to key's field
+func lookup(key string) ([]int, error) {
+	t := reflect.TypeOf(Config{})
+	parts := strings.Split(key, ".")
+	path := make([]int, 0, len(parts))
+	for i, part := range parts {
+		index := -1
+		for j := range t.NumField() {
+			if jsonName(t.Field(j)) == part {
+				index = j
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("unknown setting %q", key)
+		}
+		path = append(path, index)
+
+		fieldType := t.Field(index).Type
+		last := i == len(parts)-1
+		switch {
+		case part == "aliases":
+			return nil, errors.New("name the alias, like aliases.news")
+		case fieldType.Kind() == reflect.Map:
+			return nil, fmt.Errorf("%s isn't a single setting; select a profile with --profile to see or change its db_url", key)
+		case isSection(fieldType) && last:
+			return nil, fmt.Errorf("%s is a section; name one of its settings, like %s", key, keys(fieldType.Elem(), key+".")[0])
+		case isSection(fieldType):
+			t = fieldType.Elem()
+		case !last:
+			return nil, fmt.Errorf("unknown setting %q", key)
+		}
+	}
+	return path, nil
+}
+
+// jsonName returns the name a field has in the config file, or "" if it isn't in the file
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// isSection reports whether t is a nested section of the config, like http or smtp
+func isSection(t reflect.Type) bool {
+	return t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct
+}
+
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	}
+	return v.String()
+}
+
+func parseValue(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		v.SetFloat(f)
+	default:
+		v.SetString(value)
+	}
+	return nil
+}
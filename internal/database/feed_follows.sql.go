@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,10 +17,10 @@ const createFeedFollow = `-- name: CreateFeedFollow :one
 WITH inserted_feed_follow AS (
     INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
     VALUES ($1, $2, $3, $4, $5)
-    RETURNING id, created_at, updated_at, user_id, feed_id
+    RETURNING id, created_at, updated_at, user_id, feed_id, category_id, muted, note
 )
 SELECT 
-    inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id,
+    inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, inserted_feed_follow.category_id, inserted_feed_follow.muted, inserted_feed_follow.note,
     feeds.name AS feed_name,
     users.name AS user_name
 FROM inserted_feed_follow
@@ -36,13 +37,16 @@ type CreateFeedFollowParams struct {
 }
 
 type CreateFeedFollowRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	FeedName  string
-	UserName  string
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	FeedID     uuid.UUID
+	CategoryID uuid.NullUUID
+	Muted      bool
+	Note       sql.NullString
+	FeedName   string
+	UserName   string
 }
 
 func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error) {
@@ -60,6 +64,9 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.CategoryID,
+		&i.Muted,
+		&i.Note,
 		&i.FeedName,
 		&i.UserName,
 	)
@@ -81,25 +88,57 @@ func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowPara
 	return err
 }
 
+const getFeedFollow = `-- name: GetFeedFollow :one
+SELECT id, created_at, updated_at, user_id, feed_id, category_id, muted, note FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type GetFeedFollowParams struct {
+	UserID uuid.UUID
+	FeedID uuid.UUID
+}
+
+func (q *Queries) GetFeedFollow(ctx context.Context, arg GetFeedFollowParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedFollow, arg.UserID, arg.FeedID)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.CategoryID,
+		&i.Muted,
+		&i.Note,
+	)
+	return i, err
+}
+
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 SELECT 
-    feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id,
+    feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.category_id, feed_follows.muted, feed_follows.note,
     feeds.name AS feed_name,
-    users.name AS user_name
+    users.name AS user_name,
+    categories.name AS category_name
 FROM feed_follows
 INNER JOIN feeds ON feed_follows.feed_id = feeds.id
 INNER JOIN users ON feed_follows.user_id = users.id
+LEFT JOIN categories ON feed_follows.category_id = categories.id
 WHERE feed_follows.user_id = $1
 `
 
 type GetFeedFollowsForUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	FeedName  string
-	UserName  string
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uuid.UUID
+	FeedID       uuid.UUID
+	CategoryID   uuid.NullUUID
+	Muted        bool
+	Note         sql.NullString
+	FeedName     string
+	UserName     string
+	CategoryName sql.NullString
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -117,8 +156,12 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.CategoryID,
+			&i.Muted,
+			&i.Note,
 			&i.FeedName,
 			&i.UserName,
+			&i.CategoryName,
 		); err != nil {
 			return nil, err
 		}
@@ -132,3 +175,127 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	}
 	return items, nil
 }
+
+const getFeedsFollowedBySimilarUsers = `-- name: GetFeedsFollowedBySimilarUsers :many
+WITH mine AS (
+    SELECT feed_follows.feed_id,
+        1 + LN(1
+            + (SELECT COUNT(*) FROM post_reads
+               INNER JOIN post_sources ON post_sources.post_id = post_reads.post_id
+               WHERE post_reads.user_id = $1 AND post_sources.feed_id = feed_follows.feed_id)
+            + 2 * (SELECT COUNT(*) FROM saved_posts
+               INNER JOIN post_sources ON post_sources.post_id = saved_posts.post_id
+               WHERE saved_posts.user_id = $1 AND post_sources.feed_id = feed_follows.feed_id)
+        ) AS weight
+    FROM feed_follows
+    WHERE feed_follows.user_id = $1
+),
+peers AS (
+    SELECT feed_follows.user_id, SUM(mine.weight) AS similarity
+    FROM feed_follows
+    INNER JOIN mine ON mine.feed_id = feed_follows.feed_id
+    WHERE feed_follows.user_id <> $1
+    GROUP BY feed_follows.user_id
+)
+SELECT feeds.id, feeds.name, feeds.url, COUNT(*) AS followers, SUM(peers.similarity)::float8 AS score
+FROM peers
+INNER JOIN feed_follows ON feed_follows.user_id = peers.user_id
+INNER JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.feed_id NOT IN (SELECT feed_id FROM mine)
+GROUP BY feeds.id
+ORDER BY score DESC, followers DESC, feeds.name
+LIMIT $2
+`
+
+type GetFeedsFollowedBySimilarUsersParams struct {
+	UserID      uuid.UUID
+	ResultLimit int32
+}
+
+type GetFeedsFollowedBySimilarUsersRow struct {
+	ID        uuid.UUID
+	Name      string
+	Url       string
+	Followers int64
+	Score     float64
+}
+
+func (q *Queries) GetFeedsFollowedBySimilarUsers(ctx context.Context, arg GetFeedsFollowedBySimilarUsersParams) ([]GetFeedsFollowedBySimilarUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsFollowedBySimilarUsers, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedsFollowedBySimilarUsersRow
+	for rows.Next() {
+		var i GetFeedsFollowedBySimilarUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.Followers,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setFeedFollowCategory = `-- name: SetFeedFollowCategory :exec
+UPDATE feed_follows
+SET category_id = $3, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type SetFeedFollowCategoryParams struct {
+	UserID     uuid.UUID
+	FeedID     uuid.UUID
+	CategoryID uuid.NullUUID
+}
+
+func (q *Queries) SetFeedFollowCategory(ctx context.Context, arg SetFeedFollowCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedFollowCategory, arg.UserID, arg.FeedID, arg.CategoryID)
+	return err
+}
+
+const setFeedFollowMuted = `-- name: SetFeedFollowMuted :exec
+UPDATE feed_follows
+SET muted = $3, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type SetFeedFollowMutedParams struct {
+	UserID uuid.UUID
+	FeedID uuid.UUID
+	Muted  bool
+}
+
+func (q *Queries) SetFeedFollowMuted(ctx context.Context, arg SetFeedFollowMutedParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedFollowMuted, arg.UserID, arg.FeedID, arg.Muted)
+	return err
+}
+
+const setFeedFollowNote = `-- name: SetFeedFollowNote :exec
+UPDATE feed_follows
+SET note = $3, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type SetFeedFollowNoteParams struct {
+	UserID uuid.UUID
+	FeedID uuid.UUID
+	Note   sql.NullString
+}
+
+func (q *Queries) SetFeedFollowNote(ctx context.Context, arg SetFeedFollowNoteParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedFollowNote, arg.UserID, arg.FeedID, arg.Note)
+	return err
+}
@@ -11,8 +11,116 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const claimNextFeedsToFetch = `-- name: ClaimNextFeedsToFetch :many
+UPDATE feeds
+SET claimed_until = NOW() + $1::int * INTERVAL '1 second'
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE NOT paused AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    AND (claimed_until IS NULL OR claimed_until <= NOW())
+    ORDER BY COALESCE(next_fetch_at, last_fetched_at + COALESCE(fetch_interval, 0) * INTERVAL '1 second') ASC NULLS FIRST, random()
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, fever_id, fetch_interval, next_fetch_at, avg_post_interval, extract_content, paused, consecutive_failures, failure_count, last_error, last_success_at, moved_to, channel_title, channel_description, channel_link, channel_language, claimed_until
+`
+
+type ClaimNextFeedsToFetchParams struct {
+	LeaseSeconds int32
+	MaxFeeds     int32
+}
+
+func (q *Queries) ClaimNextFeedsToFetch(ctx context.Context, arg ClaimNextFeedsToFetchParams) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, claimNextFeedsToFetch, arg.LeaseSeconds, arg.MaxFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.FeverID,
+			&i.FetchInterval,
+			&i.NextFetchAt,
+			&i.AvgPostInterval,
+			&i.ExtractContent,
+			&i.Paused,
+			&i.ConsecutiveFailures,
+			&i.FailureCount,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.MovedTo,
+			&i.ChannelTitle,
+			&i.ChannelDescription,
+			&i.ChannelLink,
+			&i.ChannelLanguage,
+			&i.ClaimedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countDueFeeds = `-- name: CountDueFeeds :one
+SELECT COUNT(*) FROM feeds
+WHERE NOT paused AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+`
+
+func (q *Queries) CountDueFeeds(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDueFeeds)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countOtherFeedFollowers = `-- name: CountOtherFeedFollowers :one
+SELECT COUNT(*) FROM feed_follows
+WHERE feed_id = $1 AND user_id <> $2
+`
+
+type CountOtherFeedFollowersParams struct {
+	FeedID uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) CountOtherFeedFollowers(ctx context.Context, arg CountOtherFeedFollowersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOtherFeedFollowers, arg.FeedID, arg.UserID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPausedFeeds = `-- name: CountPausedFeeds :one
+SELECT COUNT(*) FROM feeds
+WHERE paused
+`
+
+func (q *Queries) CountPausedFeeds(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPausedFeeds)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
 VALUES (
@@ -23,7 +131,7 @@ VALUES (
     $5,
     $6
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, fever_id, fetch_interval, next_fetch_at, avg_post_interval, extract_content, paused, consecutive_failures, failure_count, last_error, last_success_at, moved_to, channel_title, channel_description, channel_link, channel_language, claimed_until
 `
 
 type CreateFeedParams struct {
@@ -53,12 +161,74 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.FeverID,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.AvgPostInterval,
+		&i.ExtractContent,
+		&i.Paused,
+		&i.ConsecutiveFailures,
+		&i.FailureCount,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.MovedTo,
+		&i.ChannelTitle,
+		&i.ChannelDescription,
+		&i.ChannelLink,
+		&i.ChannelLanguage,
+		&i.ClaimedUntil,
+	)
+	return i, err
+}
+
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
+const getFeed = `-- name: GetFeed :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fever_id, fetch_interval, next_fetch_at, avg_post_interval, extract_content, paused, consecutive_failures, failure_count, last_error, last_success_at, moved_to, channel_title, channel_description, channel_link, channel_language, claimed_until FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) GetFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeed, id)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.FeverID,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.AvgPostInterval,
+		&i.ExtractContent,
+		&i.Paused,
+		&i.ConsecutiveFailures,
+		&i.FailureCount,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.MovedTo,
+		&i.ChannelTitle,
+		&i.ChannelDescription,
+		&i.ChannelLink,
+		&i.ChannelLanguage,
+		&i.ClaimedUntil,
 	)
 	return i, err
 }
 
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fever_id, fetch_interval, next_fetch_at, avg_post_interval, extract_content, paused, consecutive_failures, failure_count, last_error, last_success_at, moved_to, channel_title, channel_description, channel_link, channel_language, claimed_until FROM feeds
 WHERE url = $1
 `
 
@@ -73,25 +243,85 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.FeverID,
+		&i.FetchInterval,
+		&i.NextFetchAt,
+		&i.AvgPostInterval,
+		&i.ExtractContent,
+		&i.Paused,
+		&i.ConsecutiveFailures,
+		&i.FailureCount,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.MovedTo,
+		&i.ChannelTitle,
+		&i.ChannelDescription,
+		&i.ChannelLink,
+		&i.ChannelLanguage,
+		&i.ClaimedUntil,
 	)
 	return i, err
 }
 
+const getFeedIDsAddedByUser = `-- name: GetFeedIDsAddedByUser :many
+SELECT id FROM feeds
+WHERE user_id = $1
+`
+
+func (q *Queries) GetFeedIDsAddedByUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedIDsAddedByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeeds = `-- name: GetFeeds :many
-SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, users.name as user_name
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.fever_id, feeds.fetch_interval, feeds.next_fetch_at, feeds.avg_post_interval, feeds.extract_content, feeds.paused, feeds.consecutive_failures, feeds.failure_count, feeds.last_error, feeds.last_success_at, feeds.moved_to, feeds.channel_title, feeds.channel_description, feeds.channel_link, feeds.channel_language, feeds.claimed_until, users.name as user_name
 FROM feeds
 INNER JOIN users ON feeds.user_id = users.id
 `
 
 type GetFeedsRow struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
-	UserName      string
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	LastFetchedAt       sql.NullTime
+	FeverID             int64
+	FetchInterval       sql.NullInt32
+	NextFetchAt         sql.NullTime
+	AvgPostInterval     sql.NullInt32
+	ExtractContent      bool
+	Paused              bool
+	ConsecutiveFailures int32
+	FailureCount        int32
+	LastError           sql.NullString
+	LastSuccessAt       sql.NullTime
+	MovedTo             sql.NullString
+	ChannelTitle        sql.NullString
+	ChannelDescription  sql.NullString
+	ChannelLink         sql.NullString
+	ChannelLanguage     sql.NullString
+	ClaimedUntil        sql.NullTime
+	UserName            string
 }
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
@@ -111,6 +341,22 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.FeverID,
+			&i.FetchInterval,
+			&i.NextFetchAt,
+			&i.AvgPostInterval,
+			&i.ExtractContent,
+			&i.Paused,
+			&i.ConsecutiveFailures,
+			&i.FailureCount,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.MovedTo,
+			&i.ChannelTitle,
+			&i.ChannelDescription,
+			&i.ChannelLink,
+			&i.ChannelLanguage,
+			&i.ClaimedUntil,
 			&i.UserName,
 		); err != nil {
 			return nil, err
@@ -126,25 +372,112 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 	return items, nil
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1
+const getFeedsByURLs = `-- name: GetFeedsByURLs :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fever_id, fetch_interval, next_fetch_at, avg_post_interval, extract_content, paused, consecutive_failures, failure_count, last_error, last_success_at, moved_to, channel_title, channel_description, channel_link, channel_language, claimed_until FROM feeds
+WHERE url = ANY($1::text[])
+ORDER BY created_at
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
-	var i Feed
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Url,
-		&i.UserID,
-		&i.LastFetchedAt,
-	)
-	return i, err
+func (q *Queries) GetFeedsByURLs(ctx context.Context, urls []string) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsByURLs, pq.Array(urls))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.FeverID,
+			&i.FetchInterval,
+			&i.NextFetchAt,
+			&i.AvgPostInterval,
+			&i.ExtractContent,
+			&i.Paused,
+			&i.ConsecutiveFailures,
+			&i.FailureCount,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.MovedTo,
+			&i.ChannelTitle,
+			&i.ChannelDescription,
+			&i.ChannelLink,
+			&i.ChannelLanguage,
+			&i.ClaimedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNextFeedsToFetch = `-- name: GetNextFeedsToFetch :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, fever_id, fetch_interval, next_fetch_at, avg_post_interval, extract_content, paused, consecutive_failures, failure_count, last_error, last_success_at, moved_to, channel_title, channel_description, channel_link, channel_language, claimed_until FROM feeds
+WHERE NOT paused AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+AND (claimed_until IS NULL OR claimed_until <= NOW())
+ORDER BY COALESCE(next_fetch_at, last_fetched_at + COALESCE(fetch_interval, 0) * INTERVAL '1 second') ASC NULLS FIRST, random()
+LIMIT $1
+`
+
+func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getNextFeedsToFetch, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.FeverID,
+			&i.FetchInterval,
+			&i.NextFetchAt,
+			&i.AvgPostInterval,
+			&i.ExtractContent,
+			&i.Paused,
+			&i.ConsecutiveFailures,
+			&i.FailureCount,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.MovedTo,
+			&i.ChannelTitle,
+			&i.ChannelDescription,
+			&i.ChannelLink,
+			&i.ChannelLanguage,
+			&i.ClaimedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const markFeedFetched = `-- name: MarkFeedFetched :exec
@@ -157,3 +490,202 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
 	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
 	return err
 }
+
+const reassignFeedPosts = `-- name: ReassignFeedPosts :execrows
+UPDATE posts SET feed_id = other.feed_id
+FROM (
+    SELECT DISTINCT ON (post_sources.post_id) post_sources.post_id, post_sources.feed_id
+    FROM post_sources
+    INNER JOIN posts AS moved ON moved.id = post_sources.post_id
+    WHERE moved.feed_id = $1
+    AND NOT post_sources.feed_id = ANY($2::uuid[])
+    AND NOT EXISTS (
+        SELECT 1 FROM posts AS taken
+        WHERE taken.feed_id = post_sources.feed_id AND taken.guid = moved.guid
+    )
+    ORDER BY post_sources.post_id, post_sources.created_at
+) AS other
+WHERE posts.id = other.post_id
+`
+
+type ReassignFeedPostsParams struct {
+	FeedID         uuid.UUID
+	DeletedFeedIds []uuid.UUID
+}
+
+func (q *Queries) ReassignFeedPosts(ctx context.Context, arg ReassignFeedPostsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reassignFeedPosts, arg.FeedID, pq.Array(arg.DeletedFeedIds))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const renameFeed = `-- name: RenameFeed :exec
+UPDATE feeds
+SET name = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type RenameFeedParams struct {
+	ID   uuid.UUID
+	Name string
+}
+
+func (q *Queries) RenameFeed(ctx context.Context, arg RenameFeedParams) error {
+	_, err := q.db.ExecContext(ctx, renameFeed, arg.ID, arg.Name)
+	return err
+}
+
+const scheduleFeedFetch = `-- name: ScheduleFeedFetch :exec
+UPDATE feeds
+SET next_fetch_at = $2, avg_post_interval = $3, claimed_until = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+type ScheduleFeedFetchParams struct {
+	ID              uuid.UUID
+	NextFetchAt     sql.NullTime
+	AvgPostInterval sql.NullInt32
+}
+
+func (q *Queries) ScheduleFeedFetch(ctx context.Context, arg ScheduleFeedFetchParams) error {
+	_, err := q.db.ExecContext(ctx, scheduleFeedFetch, arg.ID, arg.NextFetchAt, arg.AvgPostInterval)
+	return err
+}
+
+const setFeedChannel = `-- name: SetFeedChannel :exec
+UPDATE feeds
+SET channel_title = $2, channel_description = $3, channel_link = $4, channel_language = $5, updated_at = NOW()
+WHERE id = $1
+  AND (channel_title, channel_description, channel_link, channel_language) IS DISTINCT FROM ($2, $3, $4, $5)
+`
+
+type SetFeedChannelParams struct {
+	ID                 uuid.UUID
+	ChannelTitle       sql.NullString
+	ChannelDescription sql.NullString
+	ChannelLink        sql.NullString
+	ChannelLanguage    sql.NullString
+}
+
+func (q *Queries) SetFeedChannel(ctx context.Context, arg SetFeedChannelParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedChannel,
+		arg.ID,
+		arg.ChannelTitle,
+		arg.ChannelDescription,
+		arg.ChannelLink,
+		arg.ChannelLanguage,
+	)
+	return err
+}
+
+const setFeedExtractContent = `-- name: SetFeedExtractContent :exec
+UPDATE feeds
+SET extract_content = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedExtractContentParams struct {
+	ID             uuid.UUID
+	ExtractContent bool
+}
+
+func (q *Queries) SetFeedExtractContent(ctx context.Context, arg SetFeedExtractContentParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedExtractContent, arg.ID, arg.ExtractContent)
+	return err
+}
+
+const setFeedFetchInterval = `-- name: SetFeedFetchInterval :exec
+UPDATE feeds
+SET fetch_interval = $2, next_fetch_at = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedFetchIntervalParams struct {
+	ID            uuid.UUID
+	FetchInterval sql.NullInt32
+}
+
+func (q *Queries) SetFeedFetchInterval(ctx context.Context, arg SetFeedFetchIntervalParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedFetchInterval, arg.ID, arg.FetchInterval)
+	return err
+}
+
+const setFeedMovedTo = `-- name: SetFeedMovedTo :exec
+UPDATE feeds
+SET moved_to = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedMovedToParams struct {
+	ID      uuid.UUID
+	MovedTo sql.NullString
+}
+
+func (q *Queries) SetFeedMovedTo(ctx context.Context, arg SetFeedMovedToParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedMovedTo, arg.ID, arg.MovedTo)
+	return err
+}
+
+const setFeedOwner = `-- name: SetFeedOwner :exec
+UPDATE feeds
+SET user_id = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedOwnerParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) SetFeedOwner(ctx context.Context, arg SetFeedOwnerParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedOwner, arg.ID, arg.UserID)
+	return err
+}
+
+const setFeedPaused = `-- name: SetFeedPaused :exec
+UPDATE feeds
+SET paused = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedPausedParams struct {
+	ID     uuid.UUID
+	Paused bool
+}
+
+func (q *Queries) SetFeedPaused(ctx context.Context, arg SetFeedPausedParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedPaused, arg.ID, arg.Paused)
+	return err
+}
+
+const setFeedURL = `-- name: SetFeedURL :exec
+UPDATE feeds
+SET url = $2, moved_to = NULL, next_fetch_at = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedURLParams struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) SetFeedURL(ctx context.Context, arg SetFeedURLParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedURL, arg.ID, arg.Url)
+	return err
+}
+
+const spreadUnscheduledFeeds = `-- name: SpreadUnscheduledFeeds :execrows
+UPDATE feeds
+SET next_fetch_at = NOW() + random() * $1::int * INTERVAL '1 second', updated_at = NOW()
+WHERE next_fetch_at IS NULL AND NOT paused
+`
+
+func (q *Queries) SpreadUnscheduledFeeds(ctx context.Context, spreadSeconds int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, spreadUnscheduledFeeds, spreadSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -11,38 +11,232 @@ import (
 	"github.com/google/uuid"
 )
 
+type ApiKey struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Name       string
+	TokenHash  string
+	Scope      string
+	LastUsedAt sql.NullTime
+}
+
+type Category struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	LastFetchedAt       sql.NullTime
+	FeverID             int64
+	FetchInterval       sql.NullInt32
+	NextFetchAt         sql.NullTime
+	AvgPostInterval     sql.NullInt32
+	ExtractContent      bool
+	Paused              bool
+	ConsecutiveFailures int32
+	FailureCount        int32
+	LastError           sql.NullString
+	LastSuccessAt       sql.NullTime
+	MovedTo             sql.NullString
+	ChannelTitle        sql.NullString
+	ChannelDescription  sql.NullString
+	ChannelLink         sql.NullString
+	ChannelLanguage     sql.NullString
+	ClaimedUntil        sql.NullTime
 }
 
 type FeedFollow struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	FeedID     uuid.UUID
+	CategoryID uuid.NullUUID
+	Muted      bool
+	Note       sql.NullString
+}
+
+type FetchJob struct {
+	FeedID       uuid.UUID
+	CreatedAt    time.Time
+	ClaimedUntil sql.NullTime
+}
+
+type FetchLog struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	FeedID     uuid.UUID
+	StatusCode sql.NullInt32
+	Error      sql.NullString
+	DurationMs int32
+	ItemCount  int32
+	NewCount   sql.NullInt32
+	SeenCount  sql.NullInt32
+}
+
+type Filter struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	UserID    uuid.UUID
-	FeedID    uuid.UUID
+	FeedID    uuid.NullUUID
+	Action    string
+	Pattern   string
+	IsRegex   bool
+	AtIngest  bool
+}
+
+type LastBrowse struct {
+	UserID   uuid.UUID
+	Position int32
+	PostID   uuid.UUID
 }
 
 type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	FeverID              int64
+	Content              sql.NullString
+	SearchVector         interface{}
+	EnclosureUrl         sql.NullString
+	EnclosureType        sql.NullString
+	EnclosureLength      sql.NullInt64
+	DurationSeconds      sql.NullInt32
+	PublishedAtEstimated bool
+	CanonicalUrl         sql.NullString
+	ContentHash          sql.NullString
+	Author               sql.NullString
+	Guid                 string
+	WordCount            sql.NullInt32
 }
 
-type User struct {
+type PostCategory struct {
+	PostID uuid.UUID
+	Name   string
+}
+
+type PostImage struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	PostID    uuid.UUID
+	Url       string
+	Kind      string
+	Width     sql.NullInt32
+	Height    sql.NullInt32
+}
+
+type PostRead struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+}
+
+type PostSource struct {
+	PostID    uuid.UUID
+	FeedID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type PostTag struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	TagID     uuid.UUID
+	PostID    uuid.UUID
+}
+
+type PrunedPost struct {
+	FeedID uuid.UUID
+	Guid   sql.NullString
+	Url    string
+	SeenAt time.Time
+}
+
+type QueuedPost struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	Position  int64
+}
+
+type Rule struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Expression string
+	Action     string
+	Tag        sql.NullString
+}
+
+type SavedPost struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+}
+
+type SavedSearch struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
 	Name      string
+	Keywords  sql.NullString
+	FeedIds   []uuid.UUID
+	TagIds    []uuid.UUID
+	Since     sql.NullString
+	Until     sql.NullString
+}
+
+type Tag struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+}
+
+type User struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Name         string
+	ApiKey       string
+	PasswordHash sql.NullString
+	FeverKey     string
+}
+
+type Webhook struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Url        string
+	FeedID     uuid.NullUUID
+	Kind       string
+	CategoryID uuid.NullUUID
 }
@@ -11,36 +11,520 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+const createPosts = `-- name: CreatePosts :many
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, enclosure_url, enclosure_type, enclosure_length, duration_seconds, published_at_estimated, canonical_url, content_hash, author, guid)
+SELECT
+    new_posts.id, NOW(), NOW(), new_posts.title, new_posts.url, NULLIF(new_posts.description, ''),
+    NULLIF(new_posts.published_at, '0001-01-01 00:00:00'), $1::uuid, NULLIF(new_posts.enclosure_url, ''),
+    NULLIF(new_posts.enclosure_type, ''), NULLIF(new_posts.enclosure_length, 0), NULLIF(new_posts.duration_seconds, 0),
+    new_posts.published_at_estimated, NULLIF(new_posts.canonical_url, ''), NULLIF(new_posts.content_hash, ''),
+    NULLIF(new_posts.author, ''), new_posts.guid
+FROM unnest(
+    $2::uuid[],
+    $3::text[],
+    $4::text[],
+    $5::text[],
+    $6::timestamp[],
+    $7::text[],
+    $8::text[],
+    $9::bigint[],
+    $10::integer[],
+    $11::boolean[],
+    $12::text[],
+    $13::text[],
+    $14::text[],
+    $15::text[]
+) AS new_posts (
+    id, title, url, description, published_at, enclosure_url, enclosure_type, enclosure_length,
+    duration_seconds, published_at_estimated, canonical_url, content_hash, author, guid
+)
+ON CONFLICT (feed_id, guid) DO UPDATE SET
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    published_at = CASE WHEN EXCLUDED.published_at_estimated THEN posts.published_at ELSE EXCLUDED.published_at END,
+    canonical_url = EXCLUDED.canonical_url,
+    content_hash = EXCLUDED.content_hash,
+    author = EXCLUDED.author,
+    updated_at = NOW()
+WHERE posts.content_hash IS DISTINCT FROM EXCLUDED.content_hash OR posts.title IS DISTINCT FROM EXCLUDED.title
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, fever_id, content, search_vector, enclosure_url, enclosure_type, enclosure_length, duration_seconds, published_at_estimated, canonical_url, content_hash, author, guid, word_count
 `
 
-type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+type CreatePostsParams struct {
+	FeedID               uuid.UUID
+	Ids                  []uuid.UUID
+	Titles               []string
+	Urls                 []string
+	Descriptions         []string
+	PublishedAts         []time.Time
+	EnclosureUrls        []string
+	EnclosureTypes       []string
+	EnclosureLengths     []int64
+	DurationSeconds      []int32
+	PublishedAtEstimated []bool
+	CanonicalUrls        []string
+	ContentHashes        []string
+	Authors              []string
+	Guids                []string
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost,
-		arg.ID,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.Title,
-		arg.Url,
-		arg.Description,
-		arg.PublishedAt,
+func (q *Queries) CreatePosts(ctx context.Context, arg CreatePostsParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, createPosts,
 		arg.FeedID,
+		pq.Array(arg.Ids),
+		pq.Array(arg.Titles),
+		pq.Array(arg.Urls),
+		pq.Array(arg.Descriptions),
+		pq.Array(arg.PublishedAts),
+		pq.Array(arg.EnclosureUrls),
+		pq.Array(arg.EnclosureTypes),
+		pq.Array(arg.EnclosureLengths),
+		pq.Array(arg.DurationSeconds),
+		pq.Array(arg.PublishedAtEstimated),
+		pq.Array(arg.CanonicalUrls),
+		pq.Array(arg.ContentHashes),
+		pq.Array(arg.Authors),
+		pq.Array(arg.Guids),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.FeverID,
+			&i.Content,
+			&i.SearchVector,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.DurationSeconds,
+			&i.PublishedAtEstimated,
+			&i.CanonicalUrl,
+			&i.ContentHash,
+			&i.Author,
+			&i.Guid,
+			&i.WordCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteAllPosts = `-- name: DeleteAllPosts :execrows
+DELETE FROM posts
+`
+
+func (q *Queries) DeleteAllPosts(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAllPosts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deletePostsForFeedsOfUser = `-- name: DeletePostsForFeedsOfUser :execrows
+WITH user_feeds AS (
+    SELECT id FROM feeds
+    WHERE user_id = $1
+), unlinked AS (
+    DELETE FROM post_sources
+    WHERE feed_id IN (SELECT id FROM user_feeds)
+)
+DELETE FROM posts
+WHERE (posts.feed_id IN (SELECT id FROM user_feeds) OR EXISTS (
+    SELECT 1 FROM post_sources
+    WHERE post_sources.post_id = posts.id AND post_sources.feed_id IN (SELECT id FROM user_feeds)
+))
+AND NOT EXISTS (
+    SELECT 1 FROM post_sources
+    WHERE post_sources.post_id = posts.id AND post_sources.feed_id NOT IN (SELECT id FROM user_feeds)
+)
+`
+
+func (q *Queries) DeletePostsForFeedsOfUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePostsForFeedsOfUser, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getDigestPostsForUser = `-- name: GetDigestPostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.fever_id, posts.content, posts.search_vector, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.duration_seconds, posts.published_at_estimated, posts.canonical_url, posts.content_hash, posts.author, posts.guid, posts.word_count, feeds.name AS feed_name, categories.name AS category_name
+FROM posts
+INNER JOIN post_sources ON post_sources.post_id = posts.id
+INNER JOIN feeds ON post_sources.feed_id = feeds.id
+INNER JOIN feed_follows ON feed_follows.feed_id = feeds.id
+LEFT JOIN categories ON feed_follows.category_id = categories.id
+WHERE feed_follows.user_id = $1
+AND posts.created_at >= $2
+ORDER BY categories.name ASC NULLS LAST, feeds.name ASC, posts.published_at DESC NULLS LAST
+`
+
+type GetDigestPostsForUserParams struct {
+	UserID uuid.UUID
+	Since  time.Time
+}
+
+type GetDigestPostsForUserRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	FeverID              int64
+	Content              sql.NullString
+	SearchVector         interface{}
+	EnclosureUrl         sql.NullString
+	EnclosureType        sql.NullString
+	EnclosureLength      sql.NullInt64
+	DurationSeconds      sql.NullInt32
+	PublishedAtEstimated bool
+	CanonicalUrl         sql.NullString
+	ContentHash          sql.NullString
+	Author               sql.NullString
+	Guid                 string
+	WordCount            sql.NullInt32
+	FeedName             string
+	CategoryName         sql.NullString
+}
+
+func (q *Queries) GetDigestPostsForUser(ctx context.Context, arg GetDigestPostsForUserParams) ([]GetDigestPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDigestPostsForUser, arg.UserID, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDigestPostsForUserRow
+	for rows.Next() {
+		var i GetDigestPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.FeverID,
+			&i.Content,
+			&i.SearchVector,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.DurationSeconds,
+			&i.PublishedAtEstimated,
+			&i.CanonicalUrl,
+			&i.ContentHash,
+			&i.Author,
+			&i.Guid,
+			&i.WordCount,
+			&i.FeedName,
+			&i.CategoryName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLastPostTimes = `-- name: GetLastPostTimes :many
+SELECT post_sources.feed_id, MAX(COALESCE(posts.published_at, posts.created_at))::timestamp AS last_post_at
+FROM post_sources
+INNER JOIN posts ON posts.id = post_sources.post_id
+GROUP BY post_sources.feed_id
+`
+
+type GetLastPostTimesRow struct {
+	FeedID     uuid.UUID
+	LastPostAt time.Time
+}
+
+func (q *Queries) GetLastPostTimes(ctx context.Context) ([]GetLastPostTimesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getLastPostTimes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLastPostTimesRow
+	for rows.Next() {
+		var i GetLastPostTimesRow
+		if err := rows.Scan(
+			&i.FeedID,
+			&i.LastPostAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNewPostsForUser = `-- name: GetNewPostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.fever_id, posts.content, posts.search_vector, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.duration_seconds, posts.published_at_estimated, posts.canonical_url, posts.content_hash, posts.author, posts.guid, posts.word_count, feeds.name AS feed_name FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.id = ANY($1::uuid[])
+AND EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = $2
+)
+ORDER BY COALESCE(posts.published_at, posts.created_at), posts.id
+`
+
+type GetNewPostsForUserParams struct {
+	PostIds []uuid.UUID
+	UserID  uuid.UUID
+}
+
+type GetNewPostsForUserRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	FeverID              int64
+	Content              sql.NullString
+	SearchVector         interface{}
+	EnclosureUrl         sql.NullString
+	EnclosureType        sql.NullString
+	EnclosureLength      sql.NullInt64
+	DurationSeconds      sql.NullInt32
+	PublishedAtEstimated bool
+	CanonicalUrl         sql.NullString
+	ContentHash          sql.NullString
+	Author               sql.NullString
+	Guid                 string
+	WordCount            sql.NullInt32
+	FeedName             string
+}
+
+func (q *Queries) GetNewPostsForUser(ctx context.Context, arg GetNewPostsForUserParams) ([]GetNewPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getNewPostsForUser, pq.Array(arg.PostIds), arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetNewPostsForUserRow
+	for rows.Next() {
+		var i GetNewPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.FeverID,
+			&i.Content,
+			&i.SearchVector,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.DurationSeconds,
+			&i.PublishedAtEstimated,
+			&i.CanonicalUrl,
+			&i.ContentHash,
+			&i.Author,
+			&i.Guid,
+			&i.WordCount,
+			&i.FeedName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPodcastEpisodesForUser = `-- name: GetPodcastEpisodesForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.fever_id, posts.content, posts.search_vector, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.duration_seconds, posts.published_at_estimated, posts.canonical_url, posts.content_hash, posts.author, posts.guid, posts.word_count, feeds.name AS feed_name
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = $1
+)
+AND posts.enclosure_url IS NOT NULL
+AND ($2::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM post_sources
+    WHERE post_sources.post_id = posts.id AND post_sources.feed_id = $2
+))
+ORDER BY posts.published_at DESC NULLS LAST, posts.created_at DESC
+LIMIT $3
+`
+
+type GetPodcastEpisodesForUserParams struct {
+	UserID      uuid.UUID
+	FeedID      uuid.NullUUID
+	ResultLimit int32
+}
+
+type GetPodcastEpisodesForUserRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	FeverID              int64
+	Content              sql.NullString
+	SearchVector         interface{}
+	EnclosureUrl         sql.NullString
+	EnclosureType        sql.NullString
+	EnclosureLength      sql.NullInt64
+	DurationSeconds      sql.NullInt32
+	PublishedAtEstimated bool
+	CanonicalUrl         sql.NullString
+	ContentHash          sql.NullString
+	Author               sql.NullString
+	Guid                 string
+	WordCount            sql.NullInt32
+	FeedName             string
+}
+
+func (q *Queries) GetPodcastEpisodesForUser(ctx context.Context, arg GetPodcastEpisodesForUserParams) ([]GetPodcastEpisodesForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPodcastEpisodesForUser, arg.UserID, arg.FeedID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPodcastEpisodesForUserRow
+	for rows.Next() {
+		var i GetPodcastEpisodesForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.FeverID,
+			&i.Content,
+			&i.SearchVector,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.DurationSeconds,
+			&i.PublishedAtEstimated,
+			&i.CanonicalUrl,
+			&i.ContentHash,
+			&i.Author,
+			&i.Guid,
+			&i.WordCount,
+			&i.FeedName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPost = `-- name: GetPost :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, fever_id, content, search_vector, enclosure_url, enclosure_type, enclosure_length, duration_seconds, published_at_estimated, canonical_url, content_hash, author, guid, word_count FROM posts
+WHERE id = $1
+`
+
+func (q *Queries) GetPost(ctx context.Context, id uuid.UUID) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPost, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.FeverID,
+		&i.Content,
+		&i.SearchVector,
+		&i.EnclosureUrl,
+		&i.EnclosureType,
+		&i.EnclosureLength,
+		&i.DurationSeconds,
+		&i.PublishedAtEstimated,
+		&i.CanonicalUrl,
+		&i.ContentHash,
+		&i.Author,
+		&i.Guid,
+		&i.WordCount,
 	)
+	return i, err
+}
+
+const getPostByURL = `-- name: GetPostByURL :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, fever_id, content, search_vector, enclosure_url, enclosure_type, enclosure_length, duration_seconds, published_at_estimated, canonical_url, content_hash, author, guid, word_count FROM posts
+WHERE url = $1
+ORDER BY created_at
+LIMIT 1
+`
+
+func (q *Queries) GetPostByURL(ctx context.Context, url string) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByURL, url)
 	var i Post
 	err := row.Scan(
 		&i.ID,
@@ -51,32 +535,234 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.FeverID,
+		&i.Content,
+		&i.SearchVector,
+		&i.EnclosureUrl,
+		&i.EnclosureType,
+		&i.EnclosureLength,
+		&i.DurationSeconds,
+		&i.PublishedAtEstimated,
+		&i.CanonicalUrl,
+		&i.ContentHash,
+		&i.Author,
+		&i.Guid,
+		&i.WordCount,
 	)
 	return i, err
 }
 
-const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id FROM posts
-INNER JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
-WHERE feed_follows.user_id = $1
-ORDER BY posts.published_at DESC NULLS LAST
-LIMIT $2
+const getPostDetail = `-- name: GetPostDetail :one
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.fever_id, posts.content, posts.search_vector, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.duration_seconds, posts.published_at_estimated, posts.canonical_url, posts.content_hash, posts.author, posts.guid, posts.word_count,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = $1
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = $1
+    ) AS is_saved
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.id = $2
 `
 
-type GetPostsForUserParams struct {
+type GetPostDetailParams struct {
 	UserID uuid.UUID
-	Limit  int32
+	ID     uuid.UUID
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+type GetPostDetailRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	FeverID              int64
+	Content              sql.NullString
+	SearchVector         interface{}
+	EnclosureUrl         sql.NullString
+	EnclosureType        sql.NullString
+	EnclosureLength      sql.NullInt64
+	DurationSeconds      sql.NullInt32
+	PublishedAtEstimated bool
+	CanonicalUrl         sql.NullString
+	ContentHash          sql.NullString
+	Author               sql.NullString
+	Guid                 string
+	WordCount            sql.NullInt32
+	FeedName             string
+	FeedUrl              string
+	IsRead               bool
+	IsSaved              bool
+}
+
+func (q *Queries) GetPostDetail(ctx context.Context, arg GetPostDetailParams) (GetPostDetailRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostDetail, arg.UserID, arg.ID)
+	var i GetPostDetailRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.FeverID,
+		&i.Content,
+		&i.SearchVector,
+		&i.EnclosureUrl,
+		&i.EnclosureType,
+		&i.EnclosureLength,
+		&i.DurationSeconds,
+		&i.PublishedAtEstimated,
+		&i.CanonicalUrl,
+		&i.ContentHash,
+		&i.Author,
+		&i.Guid,
+		&i.WordCount,
+		&i.FeedName,
+		&i.FeedUrl,
+		&i.IsRead,
+		&i.IsSaved,
+	)
+	return i, err
+}
+
+const getPostForUser = `-- name: GetPostForUser :one
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.fever_id, posts.content, posts.search_vector, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.duration_seconds, posts.published_at_estimated, posts.canonical_url, posts.content_hash, posts.author, posts.guid, posts.word_count FROM posts
+WHERE posts.id = $1
+AND (EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = $2
+) OR EXISTS (
+    SELECT 1 FROM saved_posts
+    WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = $2
+))
+`
+
+type GetPostForUserParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetPostForUser(ctx context.Context, arg GetPostForUserParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostForUser, arg.ID, arg.UserID)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.FeverID,
+		&i.Content,
+		&i.SearchVector,
+		&i.EnclosureUrl,
+		&i.EnclosureType,
+		&i.EnclosureLength,
+		&i.DurationSeconds,
+		&i.PublishedAtEstimated,
+		&i.CanonicalUrl,
+		&i.ContentHash,
+		&i.Author,
+		&i.Guid,
+		&i.WordCount,
+	)
+	return i, err
+}
+
+const getPostsForExport = `-- name: GetPostsForExport :many
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.fever_id, posts.content, posts.search_vector, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.duration_seconds, posts.published_at_estimated, posts.canonical_url, posts.content_hash, posts.author, posts.guid, posts.word_count,
+    feeds.name AS feed_name,
+    post_reads.created_at AS read_at,
+    saved_posts.id IS NOT NULL AS is_saved,
+    ARRAY(
+        SELECT tags.name FROM tags
+        INNER JOIN post_tags ON post_tags.tag_id = tags.id
+        WHERE post_tags.post_id = posts.id AND tags.user_id = $1
+        ORDER BY tags.name
+    )::text[] AS tags
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+LEFT JOIN post_reads ON post_reads.post_id = posts.id AND post_reads.user_id = $1
+LEFT JOIN saved_posts ON saved_posts.post_id = posts.id AND saved_posts.user_id = $1
+WHERE (
+    post_reads.id IS NOT NULL
+    OR saved_posts.id IS NOT NULL
+    OR EXISTS (
+        SELECT 1 FROM post_sources
+        INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+        WHERE post_sources.post_id = posts.id AND feed_follows.user_id = $1
+    )
+)
+AND ($2::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) >= $2)
+AND (NOT $3::bool OR post_reads.id IS NOT NULL)
+AND (NOT $4::bool OR saved_posts.id IS NOT NULL)
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+`
+
+type GetPostsForExportParams struct {
+	UserID    uuid.UUID
+	Since     sql.NullTime
+	ReadOnly  bool
+	SavedOnly bool
+}
+
+type GetPostsForExportRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	FeverID              int64
+	Content              sql.NullString
+	SearchVector         interface{}
+	EnclosureUrl         sql.NullString
+	EnclosureType        sql.NullString
+	EnclosureLength      sql.NullInt64
+	DurationSeconds      sql.NullInt32
+	PublishedAtEstimated bool
+	CanonicalUrl         sql.NullString
+	ContentHash          sql.NullString
+	Author               sql.NullString
+	Guid                 string
+	WordCount            sql.NullInt32
+	FeedName             string
+	ReadAt               sql.NullTime
+	IsSaved              bool
+	Tags                 []string
+}
+
+func (q *Queries) GetPostsForExport(ctx context.Context, arg GetPostsForExportParams) ([]GetPostsForExportRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForExport,
+		arg.UserID,
+		arg.Since,
+		arg.ReadOnly,
+		arg.SavedOnly,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Post
+	var items []GetPostsForExportRow
 	for rows.Next() {
-		var i Post
+		var i GetPostsForExportRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
@@ -86,6 +772,23 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.FeverID,
+			&i.Content,
+			&i.SearchVector,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.DurationSeconds,
+			&i.PublishedAtEstimated,
+			&i.CanonicalUrl,
+			&i.ContentHash,
+			&i.Author,
+			&i.Guid,
+			&i.WordCount,
+			&i.FeedName,
+			&i.ReadAt,
+			&i.IsSaved,
+			pq.Array(&i.Tags),
 		); err != nil {
 			return nil, err
 		}
@@ -99,3 +802,794 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const getPostsForFeedWithState = `-- name: GetPostsForFeedWithState :many
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.fever_id, posts.content, posts.search_vector, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.duration_seconds, posts.published_at_estimated, posts.canonical_url, posts.content_hash, posts.author, posts.guid, posts.word_count,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = $1
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = $1
+    ) AS is_saved
+FROM posts
+INNER JOIN post_sources ON post_sources.post_id = posts.id AND post_sources.feed_id = $2
+ORDER BY posts.published_at DESC NULLS LAST
+LIMIT $3
+`
+
+type GetPostsForFeedWithStateParams struct {
+	UserID      uuid.UUID
+	FeedID      uuid.UUID
+	ResultLimit int32
+}
+
+type GetPostsForFeedWithStateRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	FeverID              int64
+	Content              sql.NullString
+	SearchVector         interface{}
+	EnclosureUrl         sql.NullString
+	EnclosureType        sql.NullString
+	EnclosureLength      sql.NullInt64
+	DurationSeconds      sql.NullInt32
+	PublishedAtEstimated bool
+	CanonicalUrl         sql.NullString
+	ContentHash          sql.NullString
+	Author               sql.NullString
+	Guid                 string
+	WordCount            sql.NullInt32
+	IsRead               bool
+	IsSaved              bool
+}
+
+func (q *Queries) GetPostsForFeedWithState(ctx context.Context, arg GetPostsForFeedWithStateParams) ([]GetPostsForFeedWithStateRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForFeedWithState, arg.UserID, arg.FeedID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForFeedWithStateRow
+	for rows.Next() {
+		var i GetPostsForFeedWithStateRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.FeverID,
+			&i.Content,
+			&i.SearchVector,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.DurationSeconds,
+			&i.PublishedAtEstimated,
+			&i.CanonicalUrl,
+			&i.ContentHash,
+			&i.Author,
+			&i.Guid,
+			&i.WordCount,
+			&i.IsRead,
+			&i.IsSaved,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUser = `-- name: GetPostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.fever_id, posts.content, posts.search_vector, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.duration_seconds, posts.published_at_estimated, posts.canonical_url, posts.content_hash, posts.author, posts.guid, posts.word_count FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = $1
+    AND ($2::uuid IS NULL OR feed_follows.category_id = $2)
+)
+AND ($3::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM post_tags
+    WHERE post_tags.post_id = posts.id AND post_tags.tag_id = $3
+))
+AND ($4::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) >= $4)
+AND ($5::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) < $5)
+AND ($6::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', $6))
+AND ($7::text IS NULL OR strpos(lower(posts.author), lower($7)) > 0)
+AND ($8::int IS NULL OR posts.word_count BETWEEN 1 AND $8)
+AND ($9::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_categories
+    WHERE post_categories.post_id = posts.id AND lower(post_categories.name) = lower($9)
+))
+AND (COALESCE(cardinality($10::uuid[]), 0) = 0 OR EXISTS (
+    SELECT 1 FROM post_sources
+    WHERE post_sources.post_id = posts.id AND post_sources.feed_id = ANY($10::uuid[])
+))
+AND (COALESCE(cardinality($11::uuid[]), 0) = 0 OR EXISTS (
+    SELECT 1 FROM post_tags
+    WHERE post_tags.post_id = posts.id AND post_tags.tag_id = ANY($11::uuid[])
+))
+ORDER BY
+    CASE WHEN $12::text = 'published' AND $13::bool THEN posts.published_at END DESC NULLS LAST,
+    CASE WHEN $12::text = 'published' AND NOT $13::bool THEN posts.published_at END ASC NULLS LAST,
+    CASE WHEN $12::text = 'added' AND $13::bool THEN posts.created_at END DESC,
+    CASE WHEN $12::text = 'added' AND NOT $13::bool THEN posts.created_at END ASC,
+    CASE WHEN $12::text = 'updated' AND $13::bool THEN posts.updated_at END DESC,
+    CASE WHEN $12::text = 'updated' AND NOT $13::bool THEN posts.updated_at END ASC,
+    CASE WHEN $12::text = 'feed' AND $13::bool THEN feeds.name END DESC,
+    CASE WHEN $12::text = 'feed' AND NOT $13::bool THEN feeds.name END ASC,
+    CASE WHEN $12::text = 'title' AND $13::bool THEN posts.title END DESC,
+    CASE WHEN $12::text = 'title' AND NOT $13::bool THEN posts.title END ASC,
+    posts.published_at DESC NULLS LAST
+LIMIT $14 OFFSET $15
+`
+
+type GetPostsForUserParams struct {
+	UserID       uuid.UUID
+	CategoryID   uuid.NullUUID
+	TagID        uuid.NullUUID
+	Since        sql.NullTime
+	Until        sql.NullTime
+	Keywords     sql.NullString
+	Author       sql.NullString
+	MaxWords     sql.NullInt32
+	FeedCategory sql.NullString
+	FeedIds      []uuid.UUID
+	TagIds       []uuid.UUID
+	SortBy       string
+	SortDesc     bool
+	Limit        int32
+	Offset       int32
+}
+
+func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.CategoryID,
+		arg.TagID,
+		arg.Since,
+		arg.Until,
+		arg.Keywords,
+		arg.Author,
+		arg.MaxWords,
+		arg.FeedCategory,
+		pq.Array(arg.FeedIds),
+		pq.Array(arg.TagIds),
+		arg.SortBy,
+		arg.SortDesc,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.FeverID,
+			&i.Content,
+			&i.SearchVector,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.DurationSeconds,
+			&i.PublishedAtEstimated,
+			&i.CanonicalUrl,
+			&i.ContentHash,
+			&i.Author,
+			&i.Guid,
+			&i.WordCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserByFeed = `-- name: GetPostsForUserByFeed :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.fever_id, posts.content, posts.search_vector, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.duration_seconds, posts.published_at_estimated, posts.canonical_url, posts.content_hash, posts.author, posts.guid, posts.word_count FROM posts
+INNER JOIN post_sources ON post_sources.post_id = posts.id AND post_sources.feed_id = $1
+INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id AND feed_follows.user_id = $2
+WHERE ($3::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) >= $3)
+AND ($4::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) < $4)
+AND ($5::text IS NULL OR strpos(lower(posts.author), lower($5)) > 0)
+AND ($6::int IS NULL OR posts.word_count BETWEEN 1 AND $6)
+AND ($7::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_categories
+    WHERE post_categories.post_id = posts.id AND lower(post_categories.name) = lower($7)
+))
+AND (NOT $8::bool OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id AND post_reads.user_id = $2
+))
+ORDER BY
+    CASE WHEN $9::text = 'published' AND $10::bool THEN posts.published_at END DESC NULLS LAST,
+    CASE WHEN $9::text = 'published' AND NOT $10::bool THEN posts.published_at END ASC NULLS LAST,
+    CASE WHEN $9::text = 'added' AND $10::bool THEN posts.created_at END DESC,
+    CASE WHEN $9::text = 'added' AND NOT $10::bool THEN posts.created_at END ASC,
+    CASE WHEN $9::text = 'updated' AND $10::bool THEN posts.updated_at END DESC,
+    CASE WHEN $9::text = 'updated' AND NOT $10::bool THEN posts.updated_at END ASC,
+    CASE WHEN $9::text = 'title' AND $10::bool THEN posts.title END DESC,
+    CASE WHEN $9::text = 'title' AND NOT $10::bool THEN posts.title END ASC,
+    posts.published_at DESC NULLS LAST
+LIMIT $11 OFFSET $12
+`
+
+type GetPostsForUserByFeedParams struct {
+	FeedID       uuid.UUID
+	UserID       uuid.UUID
+	Since        sql.NullTime
+	Until        sql.NullTime
+	Author       sql.NullString
+	MaxWords     sql.NullInt32
+	FeedCategory sql.NullString
+	UnreadOnly   bool
+	SortBy       string
+	SortDesc     bool
+	Limit        int32
+	Offset       int32
+}
+
+func (q *Queries) GetPostsForUserByFeed(ctx context.Context, arg GetPostsForUserByFeedParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByFeed,
+		arg.FeedID,
+		arg.UserID,
+		arg.Since,
+		arg.Until,
+		arg.Author,
+		arg.MaxWords,
+		arg.FeedCategory,
+		arg.UnreadOnly,
+		arg.SortBy,
+		arg.SortDesc,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.FeverID,
+			&i.Content,
+			&i.SearchVector,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.DurationSeconds,
+			&i.PublishedAtEstimated,
+			&i.CanonicalUrl,
+			&i.ContentHash,
+			&i.Author,
+			&i.Guid,
+			&i.WordCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPrunedPosts = `-- name: GetPrunedPosts :many
+SELECT guid, url FROM pruned_posts
+WHERE feed_id = $1
+AND (guid = ANY($2::text[]) OR url = ANY($3::text[]))
+`
+
+type GetPrunedPostsParams struct {
+	FeedID uuid.UUID
+	Guids  []string
+	Urls   []string
+}
+
+type GetPrunedPostsRow struct {
+	Guid sql.NullString
+	Url  string
+}
+
+func (q *Queries) GetPrunedPosts(ctx context.Context, arg GetPrunedPostsParams) ([]GetPrunedPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPrunedPosts, arg.FeedID, pq.Array(arg.Guids), pq.Array(arg.Urls))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPrunedPostsRow
+	for rows.Next() {
+		var i GetPrunedPostsRow
+		if err := rows.Scan(
+			&i.Guid,
+			&i.Url,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentPublishTimesForFeed = `-- name: GetRecentPublishTimesForFeed :many
+SELECT published_at FROM posts
+WHERE feed_id = $1 AND published_at IS NOT NULL
+ORDER BY published_at DESC
+LIMIT $2
+`
+
+type GetRecentPublishTimesForFeedParams struct {
+	FeedID uuid.UUID
+	Limit  int32
+}
+
+func (q *Queries) GetRecentPublishTimesForFeed(ctx context.Context, arg GetRecentPublishTimesForFeedParams) ([]sql.NullTime, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentPublishTimesForFeed, arg.FeedID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []sql.NullTime
+	for rows.Next() {
+		var published_at sql.NullTime
+		if err := rows.Scan(&published_at); err != nil {
+			return nil, err
+		}
+		items = append(items, published_at)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnreadPostsForUser = `-- name: GetUnreadPostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.fever_id, posts.content, posts.search_vector, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.duration_seconds, posts.published_at_estimated, posts.canonical_url, posts.content_hash, posts.author, posts.guid, posts.word_count FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = $1
+    AND ($2::uuid IS NULL OR feed_follows.category_id = $2)
+)
+AND ($3::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM post_tags
+    WHERE post_tags.post_id = posts.id AND post_tags.tag_id = $3
+))
+AND ($4::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) >= $4)
+AND ($5::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) < $5)
+AND ($6::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', $6))
+AND ($7::text IS NULL OR strpos(lower(posts.author), lower($7)) > 0)
+AND ($8::int IS NULL OR posts.word_count BETWEEN 1 AND $8)
+AND ($9::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_categories
+    WHERE post_categories.post_id = posts.id AND lower(post_categories.name) = lower($9)
+))
+AND (COALESCE(cardinality($10::uuid[]), 0) = 0 OR EXISTS (
+    SELECT 1 FROM post_sources
+    WHERE post_sources.post_id = posts.id AND post_sources.feed_id = ANY($10::uuid[])
+))
+AND (COALESCE(cardinality($11::uuid[]), 0) = 0 OR EXISTS (
+    SELECT 1 FROM post_tags
+    WHERE post_tags.post_id = posts.id AND post_tags.tag_id = ANY($11::uuid[])
+))
+AND NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id AND post_reads.user_id = $1
+)
+ORDER BY
+    CASE WHEN $12::text = 'published' AND $13::bool THEN posts.published_at END DESC NULLS LAST,
+    CASE WHEN $12::text = 'published' AND NOT $13::bool THEN posts.published_at END ASC NULLS LAST,
+    CASE WHEN $12::text = 'added' AND $13::bool THEN posts.created_at END DESC,
+    CASE WHEN $12::text = 'added' AND NOT $13::bool THEN posts.created_at END ASC,
+    CASE WHEN $12::text = 'updated' AND $13::bool THEN posts.updated_at END DESC,
+    CASE WHEN $12::text = 'updated' AND NOT $13::bool THEN posts.updated_at END ASC,
+    CASE WHEN $12::text = 'feed' AND $13::bool THEN feeds.name END DESC,
+    CASE WHEN $12::text = 'feed' AND NOT $13::bool THEN feeds.name END ASC,
+    CASE WHEN $12::text = 'title' AND $13::bool THEN posts.title END DESC,
+    CASE WHEN $12::text = 'title' AND NOT $13::bool THEN posts.title END ASC,
+    posts.published_at DESC NULLS LAST
+LIMIT $14 OFFSET $15
+`
+
+type GetUnreadPostsForUserParams struct {
+	UserID       uuid.UUID
+	CategoryID   uuid.NullUUID
+	TagID        uuid.NullUUID
+	Since        sql.NullTime
+	Until        sql.NullTime
+	Keywords     sql.NullString
+	Author       sql.NullString
+	MaxWords     sql.NullInt32
+	FeedCategory sql.NullString
+	FeedIds      []uuid.UUID
+	TagIds       []uuid.UUID
+	SortBy       string
+	SortDesc     bool
+	Limit        int32
+	Offset       int32
+}
+
+func (q *Queries) GetUnreadPostsForUser(ctx context.Context, arg GetUnreadPostsForUserParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreadPostsForUser,
+		arg.UserID,
+		arg.CategoryID,
+		arg.TagID,
+		arg.Since,
+		arg.Until,
+		arg.Keywords,
+		arg.Author,
+		arg.MaxWords,
+		arg.FeedCategory,
+		pq.Array(arg.FeedIds),
+		pq.Array(arg.TagIds),
+		arg.SortBy,
+		arg.SortDesc,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.FeverID,
+			&i.Content,
+			&i.SearchVector,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.DurationSeconds,
+			&i.PublishedAtEstimated,
+			&i.CanonicalUrl,
+			&i.ContentHash,
+			&i.Author,
+			&i.Guid,
+			&i.WordCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const notifyNewPosts = `-- name: NotifyNewPosts :exec
+SELECT pg_notify('gator_new_posts', id::text)
+FROM unnest($1::uuid[]) AS id
+`
+
+func (q *Queries) NotifyNewPosts(ctx context.Context, postIds []uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, notifyNewPosts, pq.Array(postIds))
+	return err
+}
+
+const prunePosts = `-- name: PrunePosts :one
+WITH pruned AS (
+    DELETE FROM posts
+    WHERE COALESCE(published_at, created_at) < $1
+    AND NOT EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM queued_posts
+        WHERE queued_posts.post_id = posts.id
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.created_at >= $1
+    )
+    RETURNING id, feed_id, guid, url
+), tombstones AS (
+    INSERT INTO pruned_posts (feed_id, guid, url, seen_at)
+    SELECT DISTINCT ON (gone.feed_id, gone.url) gone.feed_id, gone.guid, gone.url, NOW()
+    FROM (
+        SELECT pruned.feed_id, pruned.guid, pruned.url FROM pruned
+        UNION ALL
+        SELECT post_sources.feed_id, NULL, pruned.url FROM pruned
+        INNER JOIN post_sources ON post_sources.post_id = pruned.id
+        WHERE post_sources.feed_id <> pruned.feed_id
+    ) AS gone
+    ORDER BY gone.feed_id, gone.url, gone.guid NULLS LAST
+    ON CONFLICT (feed_id, url) DO UPDATE SET
+        guid = COALESCE(EXCLUDED.guid, pruned_posts.guid),
+        seen_at = EXCLUDED.seen_at
+), expired AS (
+    DELETE FROM pruned_posts
+    WHERE seen_at < NOW() - INTERVAL '30 days'
+)
+SELECT COUNT(*) FROM pruned
+`
+
+func (q *Queries) PrunePosts(ctx context.Context, cutoff time.Time) (int64, error) {
+	row := q.db.QueryRowContext(ctx, prunePosts, cutoff)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const searchPosts = `-- name: SearchPosts :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.fever_id, posts.content, posts.search_vector, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.duration_seconds, posts.published_at_estimated, posts.canonical_url, posts.content_hash, posts.author, posts.guid, posts.word_count, ts_rank(posts.search_vector, websearch_to_tsquery('english', $1)) AS rank
+FROM posts
+WHERE posts.search_vector @@ websearch_to_tsquery('english', $1)
+ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+LIMIT $2
+`
+
+type SearchPostsParams struct {
+	Query       string
+	ResultLimit int32
+}
+
+type SearchPostsRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	FeverID              int64
+	Content              sql.NullString
+	SearchVector         interface{}
+	EnclosureUrl         sql.NullString
+	EnclosureType        sql.NullString
+	EnclosureLength      sql.NullInt64
+	DurationSeconds      sql.NullInt32
+	PublishedAtEstimated bool
+	CanonicalUrl         sql.NullString
+	ContentHash          sql.NullString
+	Author               sql.NullString
+	Guid                 string
+	WordCount            sql.NullInt32
+	Rank                 float32
+}
+
+func (q *Queries) SearchPosts(ctx context.Context, arg SearchPostsParams) ([]SearchPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPosts, arg.Query, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsRow
+	for rows.Next() {
+		var i SearchPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.FeverID,
+			&i.Content,
+			&i.SearchVector,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.DurationSeconds,
+			&i.PublishedAtEstimated,
+			&i.CanonicalUrl,
+			&i.ContentHash,
+			&i.Author,
+			&i.Guid,
+			&i.WordCount,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchPostsForUser = `-- name: SearchPostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.fever_id, posts.content, posts.search_vector, posts.enclosure_url, posts.enclosure_type, posts.enclosure_length, posts.duration_seconds, posts.published_at_estimated, posts.canonical_url, posts.content_hash, posts.author, posts.guid, posts.word_count, ts_rank(posts.search_vector, websearch_to_tsquery('english', $1)) AS rank
+FROM posts
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = $2
+)
+AND posts.search_vector @@ websearch_to_tsquery('english', $1)
+ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+LIMIT $3
+`
+
+type SearchPostsForUserParams struct {
+	Query       string
+	UserID      uuid.UUID
+	ResultLimit int32
+}
+
+type SearchPostsForUserRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Title                string
+	Url                  string
+	Description          sql.NullString
+	PublishedAt          sql.NullTime
+	FeedID               uuid.UUID
+	FeverID              int64
+	Content              sql.NullString
+	SearchVector         interface{}
+	EnclosureUrl         sql.NullString
+	EnclosureType        sql.NullString
+	EnclosureLength      sql.NullInt64
+	DurationSeconds      sql.NullInt32
+	PublishedAtEstimated bool
+	CanonicalUrl         sql.NullString
+	ContentHash          sql.NullString
+	Author               sql.NullString
+	Guid                 string
+	WordCount            sql.NullInt32
+	Rank                 float32
+}
+
+func (q *Queries) SearchPostsForUser(ctx context.Context, arg SearchPostsForUserParams) ([]SearchPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPostsForUser, arg.Query, arg.UserID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsForUserRow
+	for rows.Next() {
+		var i SearchPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.FeverID,
+			&i.Content,
+			&i.SearchVector,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+			&i.EnclosureLength,
+			&i.DurationSeconds,
+			&i.PublishedAtEstimated,
+			&i.CanonicalUrl,
+			&i.ContentHash,
+			&i.Author,
+			&i.Guid,
+			&i.WordCount,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPostContent = `-- name: SetPostContent :exec
+UPDATE posts
+SET content = $2, word_count = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetPostContentParams struct {
+	ID        uuid.UUID
+	Content   sql.NullString
+	WordCount sql.NullInt32
+}
+
+func (q *Queries) SetPostContent(ctx context.Context, arg SetPostContentParams) error {
+	_, err := q.db.ExecContext(ctx, setPostContent, arg.ID, arg.Content, arg.WordCount)
+	return err
+}
+
+const touchPrunedPosts = `-- name: TouchPrunedPosts :exec
+UPDATE pruned_posts SET seen_at = NOW()
+WHERE feed_id = $1
+AND (guid = ANY($2::text[]) OR url = ANY($3::text[]))
+`
+
+type TouchPrunedPostsParams struct {
+	FeedID uuid.UUID
+	Guids  []string
+	Urls   []string
+}
+
+func (q *Queries) TouchPrunedPosts(ctx context.Context, arg TouchPrunedPostsParams) error {
+	_, err := q.db.ExecContext(ctx, touchPrunedPosts, arg.FeedID, pq.Array(arg.Guids), pq.Array(arg.Urls))
+	return err
+}
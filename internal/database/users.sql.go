@@ -7,20 +7,22 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, api_key)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5
 )
-RETURNING id, created_at, updated_at, name
+RETURNING id, created_at, updated_at, name, api_key, password_hash, fever_key
 `
 
 type CreateUserParams struct {
@@ -28,6 +30,7 @@ type CreateUserParams struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Name      string
+	ApiKey    string
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -36,6 +39,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.CreatedAt,
 		arg.UpdatedAt,
 		arg.Name,
+		arg.ApiKey,
 	)
 	var i User
 	err := row.Scan(
@@ -43,6 +47,9 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.ApiKey,
+		&i.PasswordHash,
+		&i.FeverKey,
 	)
 	return i, err
 }
@@ -56,8 +63,21 @@ func (q *Queries) DeleteAllUsers(ctx context.Context) error {
 	return err
 }
 
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getUser = `-- name: GetUser :one
-SELECT id, created_at, updated_at, name FROM users
+SELECT id, created_at, updated_at, name, api_key, password_hash, fever_key FROM users
 WHERE name = $1
 `
 
@@ -69,12 +89,84 @@ func (q *Queries) GetUser(ctx context.Context, name string) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.ApiKey,
+		&i.PasswordHash,
+		&i.FeverKey,
+	)
+	return i, err
+}
+
+const getUserByAPIKey = `-- name: GetUserByAPIKey :one
+SELECT id, created_at, updated_at, name, api_key, password_hash, fever_key FROM users
+WHERE api_key = $1
+`
+
+func (q *Queries) GetUserByAPIKey(ctx context.Context, apiKey string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByAPIKey, apiKey)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.ApiKey,
+		&i.PasswordHash,
+		&i.FeverKey,
+	)
+	return i, err
+}
+
+const getUserByFeverKey = `-- name: GetUserByFeverKey :one
+SELECT id, created_at, updated_at, name, api_key, password_hash, fever_key FROM users
+WHERE fever_key = $1
+`
+
+func (q *Queries) GetUserByFeverKey(ctx context.Context, feverKey string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByFeverKey, feverKey)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.ApiKey,
+		&i.PasswordHash,
+		&i.FeverKey,
+	)
+	return i, err
+}
+
+const getUserDeletionSummary = `-- name: GetUserDeletionSummary :one
+SELECT
+    (SELECT COUNT(*) FROM feeds WHERE feeds.user_id = $1) AS feeds,
+    (SELECT COUNT(*) FROM feed_follows
+        INNER JOIN feeds ON feed_follows.feed_id = feeds.id
+        WHERE feeds.user_id = $1 AND feed_follows.user_id <> $1) AS other_follows,
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.user_id = $1) AS follows,
+    (SELECT COUNT(*) FROM post_reads WHERE post_reads.user_id = $1) AS reads
+`
+
+type GetUserDeletionSummaryRow struct {
+	Feeds        int64
+	OtherFollows int64
+	Follows      int64
+	Reads        int64
+}
+
+func (q *Queries) GetUserDeletionSummary(ctx context.Context, userID uuid.UUID) (GetUserDeletionSummaryRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserDeletionSummary, userID)
+	var i GetUserDeletionSummaryRow
+	err := row.Scan(
+		&i.Feeds,
+		&i.OtherFollows,
+		&i.Follows,
+		&i.Reads,
 	)
 	return i, err
 }
 
 const getUsers = `-- name: GetUsers :many
-SELECT id, created_at, updated_at, name FROM users
+SELECT id, created_at, updated_at, name, api_key, password_hash, fever_key FROM users
 `
 
 func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
@@ -91,6 +183,9 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Name,
+			&i.ApiKey,
+			&i.PasswordHash,
+			&i.FeverKey,
 		); err != nil {
 			return nil, err
 		}
@@ -104,3 +199,46 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 	}
 	return items, nil
 }
+
+const renameUser = `-- name: RenameUser :one
+UPDATE users
+SET name = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, api_key, password_hash, fever_key
+`
+
+type RenameUserParams struct {
+	ID   uuid.UUID
+	Name string
+}
+
+func (q *Queries) RenameUser(ctx context.Context, arg RenameUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, renameUser, arg.ID, arg.Name)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.ApiKey,
+		&i.PasswordHash,
+		&i.FeverKey,
+	)
+	return i, err
+}
+
+const setUserPassword = `-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetUserPasswordParams struct {
+	ID           uuid.UUID
+	PasswordHash sql.NullString
+}
+
+func (q *Queries) SetUserPassword(ctx context.Context, arg SetUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPassword, arg.ID, arg.PasswordHash)
+	return err
+}
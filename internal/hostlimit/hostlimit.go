@@ -0,0 +1,144 @@
+package hostlimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRate is how many requests per second are made to one host when the config doesn't say
+const DefaultRate = 1.0
+
+// DefaultMaxWait is the longest a request waits for its turn before giving up
+const DefaultMaxWait = time.Minute
+
+// DelayError is returned instead of waiting when a host can't be contacted again until Until,
+// usually because it answered with a Retry-After further out than the limiter's MaxWait
+type DelayError struct {
+	Host  string
+	Until time.Time
+}
+
+func (e *DelayError) Error() string {
+	return fmt.Sprintf("%s asked to wait until %s", e.Host, e.Until.Format("2006-01-02 15:04:05"))
+}
+
+// Limiter spaces out requests to each host and keeps hosts that asked to be left alone waiting.
+// It's safe for concurrent use.
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	maxWait  time.Duration
+	next     map[string]time.Time
+}
+
+// New returns a Limiter allowing perSecond requests per second to each host. Zero or less
+// means no spacing, though Retry-After is still honored.
+func New(perSecond float64) *Limiter {
+	l := &Limiter{maxWait: DefaultMaxWait, next: map[string]time.Time{}}
+	l.SetRate(perSecond)
+	return l
+}
+
+// SetRate changes how many requests per second are allowed to each host
+func (l *Limiter) SetRate(perSecond float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.interval = 0
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+}
+
+// Wait blocks until a request to host may be made, or returns a *DelayError if that's more
+// than the maximum wait away
+func (l *Limiter) Wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := now
+	if next, ok := l.next[host]; ok && next.After(now) {
+		at = next
+	}
+	if at.Sub(now) > l.maxWait {
+		l.mu.Unlock()
+		return &DelayError{Host: host, Until: at}
+	}
+	l.next[host] = at.Add(l.interval)
+	l.mu.Unlock()
+
+	if at.Equal(now) {
+		return nil
+	}
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Delay holds back requests to host until until, unless they're already held back longer
+func (l *Limiter) Delay(host string, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until.After(l.next[host]) {
+		l.next[host] = until
+	}
+}
+
+// Transport is an http.RoundTripper that waits its turn with Limiter before each request and
+// honors Retry-After on 429 and 503 responses
+type Transport struct {
+	Limiter *Limiter
+	// Base makes the requests; http.DefaultTransport if nil
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Hostname()
+	if err := t.Limiter.Wait(req.Context(), host); err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if until, ok := RetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			t.Limiter.Delay(host, until)
+		}
+	}
+	return resp, nil
+}
+
+// RetryAfter parses a Retry-After header, given either as seconds or an HTTP date
+func RetryAfter(header string, now time.Time) (time.Time, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return time.Time{}, false
+		}
+		return now.Add(time.Duration(seconds) * time.Second), true
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
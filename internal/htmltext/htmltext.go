@@ -0,0 +1,233 @@
+package htmltext
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+var (
+	tagPattern = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)([^>]*)>`)
+	hrefAttr   = regexp.MustCompile(`(?i)\bhref\s*=\s*("([^"]*)"|'([^']*)'|([^\s>]+))`)
+	imgSrc     = regexp.MustCompile(`(?i)<img\b[^>]*?\ssrc\s*=\s*("([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+// allowedTags are the elements Sanitize keeps, with the attributes each may carry. Anything
+// else is dropped, keeping its text.
+var allowedTags = map[string]map[string]bool{
+	"a":          {"href": true, "title": true},
+	"img":        {"src": true, "alt": true, "title": true, "width": true, "height": true},
+	"abbr":       {"title": true},
+	"time":       {"datetime": true},
+	"ol":         {"start": true},
+	"td":         {"colspan": true, "rowspan": true},
+	"th":         {"colspan": true, "rowspan": true},
+	"blockquote": {"cite": true},
+	"q":          {"cite": true},
+	"del":        {"cite": true},
+	"ins":        {"cite": true},
+
+	"p": {}, "br": {}, "hr": {}, "div": {}, "span": {}, "pre": {}, "code": {}, "kbd": {},
+	"samp": {}, "h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {}, "ul": {}, "li": {},
+	"dl": {}, "dt": {}, "dd": {}, "b": {}, "strong": {}, "i": {}, "em": {}, "u": {}, "s": {},
+	"mark": {}, "small": {}, "sub": {}, "sup": {}, "cite": {}, "dfn": {}, "figure": {},
+	"figcaption": {}, "table": {}, "caption": {}, "thead": {}, "tbody": {}, "tfoot": {}, "tr": {},
+	"details": {}, "summary": {},
+}
+
+// droppedTags are removed along with everything inside them
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true, "applet": true,
+	"frame": true, "frameset": true, "noframes": true, "noscript": true, "noembed": true,
+	"template": true, "svg": true, "math": true, "title": true, "textarea": true, "select": true,
+	"xmp": true, "plaintext": true, "head": true,
+}
+
+// urlAttrs hold URLs, which must be relative or use a scheme in urlSchemes
+var urlAttrs = map[string]bool{"href": true, "src": true, "cite": true}
+
+var urlSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// blockTags start a new paragraph when they open or close
+var blockTags = map[string]bool{
+	"p": true, "div": true, "blockquote": true, "pre": true, "table": true,
+	"ul": true, "ol": true, "dl": true, "section": true, "article": true, "figure": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true,
+}
+
+// Sanitize rewrites HTML before it is stored so that only allowedTags and their attributes are
+// left, with links and images restricted to http, https and mailto or relative URLs. Scripts,
+// styles, frames, embedded objects and inline SVG are removed with their contents; comments and
+// other unknown markup are dropped, keeping their text.
+func Sanitize(s string) string {
+	var b strings.Builder
+	z := nethtml.NewTokenizer(strings.NewReader(s))
+	// dropping counts the droppedTags open around the current token
+	dropping := 0
+	for {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			return b.String()
+		}
+
+		tok := z.Token()
+		switch tt {
+		case nethtml.TextToken:
+			if dropping == 0 {
+				b.WriteString(html.EscapeString(tok.Data))
+			}
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			if droppedTags[tok.Data] {
+				if tt == nethtml.StartTagToken {
+					dropping++
+				}
+				continue
+			}
+			if dropping == 0 {
+				writeStartTag(&b, tok)
+			}
+		case nethtml.EndTagToken:
+			if droppedTags[tok.Data] {
+				dropping = max(dropping-1, 0)
+				continue
+			}
+			if _, ok := allowedTags[tok.Data]; ok && dropping == 0 {
+				b.WriteString("</" + tok.Data + ">")
+			}
+		}
+	}
+}
+
+// writeStartTag writes tok if it's an allowed tag, keeping only its allowed attributes
+func writeStartTag(b *strings.Builder, tok nethtml.Token) {
+	allowed, ok := allowedTags[tok.Data]
+	if !ok {
+		return
+	}
+
+	b.WriteString("<" + tok.Data)
+	for _, attr := range tok.Attr {
+		// The tokenizer has already decoded entities, so jav&#x61;script: is seen as javascript:
+		if attr.Namespace != "" || !allowed[attr.Key] || (urlAttrs[attr.Key] && !safeURL(attr.Val)) {
+			continue
+		}
+		b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	b.WriteString(">")
+}
+
+// safeURL reports whether u is relative or uses one of urlSchemes. Browsers ignore whitespace
+// and control characters in a scheme, so they are removed before checking it.
+func safeURL(u string) bool {
+	u = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, u)
+
+	end := strings.IndexAny(u, ":/?#")
+	if end == -1 || u[end] != ':' {
+		return true
+	}
+	return urlSchemes[strings.ToLower(u[:end])]
+}
+
+// Render converts HTML to plain text for the terminal. Tags are stripped, entities decoded,
+// paragraphs and list items kept on their own lines, and links followed by their URL.
+func Render(s string) string {
+	s = Sanitize(s)
+
+	var b strings.Builder
+	var href string
+	linkStart := 0
+	last := 0
+	for _, m := range tagPattern.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(s[last:m[0]])
+		last = m[1]
+
+		closing := m[3] > m[2]
+		name := strings.ToLower(s[m[4]:m[5]])
+		attrs := s[m[6]:m[7]]
+
+		switch {
+		case name == "br" || name == "tr":
+			b.WriteString("\n")
+		case name == "li" && !closing:
+			b.WriteString("\n• ")
+		case blockTags[name]:
+			b.WriteString("\n\n")
+		case name == "a" && !closing:
+			href = ""
+			if a := hrefAttr.FindStringSubmatch(attrs); a != nil {
+				href = a[2] + a[3] + a[4]
+			}
+			linkStart = b.Len()
+		case name == "a" && closing:
+			// Only add the URL when the link text doesn't already show it
+			text := strings.TrimSpace(html.UnescapeString(b.String()[linkStart:]))
+			target := html.UnescapeString(href)
+			if target != "" && !strings.HasPrefix(target, "#") && text != target {
+				b.WriteString(" (" + target + ")")
+			}
+			href = ""
+		}
+	}
+	b.WriteString(s[last:])
+
+	return tidy(html.UnescapeString(b.String()))
+}
+
+// FirstImage returns the src of the first <img> in s, or "" if there is none
+func FirstImage(s string) string {
+	m := imgSrc.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	return html.UnescapeString(strings.TrimSpace(m[2] + m[3] + m[4]))
+}
+
+// Links returns the href of every <a> in s, in order. Fragment-only links are left out.
+func Links(s string) []string {
+	var links []string
+	for _, tag := range tagPattern.FindAllStringSubmatch(s, -1) {
+		if tag[1] != "" || !strings.EqualFold(tag[2], "a") {
+			continue
+		}
+		m := hrefAttr.FindStringSubmatch(tag[3])
+		if m == nil {
+			continue
+		}
+		href := html.UnescapeString(strings.TrimSpace(m[2] + m[3] + m[4]))
+		if href != "" && !strings.HasPrefix(href, "#") {
+			links = append(links, href)
+		}
+	}
+	return links
+}
+
+// RenderInline renders s as a single line, for summaries and notifications
+func RenderInline(s string) string {
+	return strings.Join(strings.Fields(Render(s)), " ")
+}
+
+// tidy collapses runs of whitespace within lines and of blank lines between paragraphs
+func tidy(s string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,251 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// versionTable is shared with goose so databases migrated by hand keep working
+const versionTable = "goose_db_version"
+
+// Migration is a single numbered schema change with its up and down SQL
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load reads goose-style NNN_name.sql migrations from fsys, sorted by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		prefix, _, found := strings.Cut(entry.Name(), "_")
+		if !found {
+			return nil, fmt.Errorf("migration %s has no version prefix", entry.Name())
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has invalid version: %w", entry.Name(), err)
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		up, down := parse(string(data))
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    entry.Name(),
+			Up:      up,
+			Down:    down,
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// parse splits a migration file into its -- +goose Up and -- +goose Down sections
+func parse(contents string) (string, string) {
+	var up, down strings.Builder
+	var current *strings.Builder
+
+	for _, line := range strings.Split(contents, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "-- +goose Up"):
+			current = &up
+			continue
+		case strings.HasPrefix(trimmed, "-- +goose Down"):
+			current = &down
+			continue
+		case strings.HasPrefix(trimmed, "-- +goose"):
+			// StatementBegin/End markers aren't needed since each section runs as one exec
+			continue
+		}
+
+		if current != nil {
+			current.WriteString(line)
+			current.WriteString("\n")
+		}
+	}
+
+	return strings.TrimSpace(up.String()), strings.TrimSpace(down.String())
+}
+
+// Latest returns the highest version in migrations
+func Latest(migrations []Migration) int64 {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// CurrentVersion returns the latest applied version, or 0 if nothing has been applied
+func CurrentVersion(ctx context.Context, db *sql.DB) (int64, error) {
+	var exists bool
+	err := db.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", versionTable).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't check for version table: %w", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	var current int64
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	return current, nil
+}
+
+// appliedVersions returns the set of versions whose most recent record is applied
+func appliedVersions(ctx context.Context, db *sql.DB) (map[int64]bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version_id, is_applied FROM "+versionTable+" ORDER BY id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read version table: %w", err)
+	}
+	defer rows.Close()
+
+	seen := map[int64]bool{}
+	applied := map[int64]bool{}
+	for rows.Next() {
+		var version int64
+		var isApplied bool
+		if err := rows.Scan(&version, &isApplied); err != nil {
+			return nil, err
+		}
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		if isApplied {
+			applied[version] = true
+		}
+	}
+
+	return applied, rows.Err()
+}
+
+// ensureVersionTable creates the version table the same way goose does
+func ensureVersionTable(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+versionTable+` (
+		id SERIAL PRIMARY KEY,
+		version_id BIGINT NOT NULL,
+		is_applied BOOLEAN NOT NULL,
+		tstamp TIMESTAMP DEFAULT NOW()
+	)`)
+	if err != nil {
+		return fmt.Errorf("couldn't create version table: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT INTO `+versionTable+` (version_id, is_applied)
+		SELECT 0, TRUE WHERE NOT EXISTS (SELECT 1 FROM `+versionTable+`)`)
+	if err != nil {
+		return fmt.Errorf("couldn't initialise version table: %w", err)
+	}
+
+	return nil
+}
+
+// Up applies every pending migration in order and returns the ones it ran
+func Up(ctx context.Context, db *sql.DB, migrations []Migration) ([]Migration, error) {
+	if err := ensureVersionTable(ctx, db); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		if err := apply(ctx, db, m.Version, m.Up, true); err != nil {
+			return ran, fmt.Errorf("couldn't apply %s: %w", m.Name, err)
+		}
+		ran = append(ran, m)
+	}
+
+	return ran, nil
+}
+
+// Down rolls back the most recently applied migration
+func Down(ctx context.Context, db *sql.DB, migrations []Migration) (*Migration, error) {
+	if err := ensureVersionTable(ctx, db); err != nil {
+		return nil, err
+	}
+
+	current, err := CurrentVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if current == 0 {
+		return nil, nil
+	}
+
+	for i := range migrations {
+		m := migrations[i]
+		if m.Version != current {
+			continue
+		}
+		if err := apply(ctx, db, m.Version, m.Down, false); err != nil {
+			return nil, fmt.Errorf("couldn't roll back %s: %w", m.Name, err)
+		}
+		return &m, nil
+	}
+
+	return nil, fmt.Errorf("no migration file for applied version %d", current)
+}
+
+// apply runs a migration section and records it in one transaction
+func apply(ctx context.Context, db *sql.DB, version int64, statements string, isApplied bool) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if statements != "" {
+		if _, err := tx.ExecContext(ctx, statements); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO "+versionTable+" (version_id, is_applied) VALUES ($1, $2)", version, isApplied)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
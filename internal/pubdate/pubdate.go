@@ -0,0 +1,157 @@
+package pubdate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownFormat is returned for dates that don't match any known layout
+var ErrUnknownFormat = errors.New("unrecognised date format")
+
+var (
+	// weekday matches a leading day name, which is dropped: it adds nothing and is often wrong
+	weekday = regexp.MustCompile(`(?i)^(mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?,?\s*`)
+	// monthName matches full, abbreviated and dotted month names
+	monthName = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?`)
+	// ordinal matches day numbers like 1st and 22nd
+	ordinal = regexp.MustCompile(`(?i)\b(\d{1,2})(st|nd|rd|th)\b`)
+	// zoneComment matches a zone name repeated in brackets after an offset, e.g. "-0800 (PST)"
+	zoneComment = regexp.MustCompile(`\s*\([A-Za-z ]+\)$`)
+	// prefixedOffset matches offsets written after a zone name, e.g. GMT+0000, UTC-5 or GMT+05:30
+	prefixedOffset = regexp.MustCompile(`(?i)\b(?:GMT|UTC|UT)\s*([+-])(\d{1,2}):?(\d{2})?\b`)
+	// zoneName matches a zone abbreviation standing on its own
+	zoneName = regexp.MustCompile(`\b[A-Z]{1,5}\b`)
+	// meridiem matches am and pm in any case, with or without dots
+	meridiem = regexp.MustCompile(`(?i)\b([ap])\.?m\b\.?`)
+	// shortOffset matches an offset without minutes straight after a time, e.g. 10:00:00+05
+	shortOffset = regexp.MustCompile(`(:\d\d) ?([+-])(\d{1,2})$`)
+)
+
+// zoneOffsets maps zone abbreviations seen in feeds to their UTC offsets in minutes. It covers
+// RFC 822's zones, including its single-letter military zones, and common regional ones.
+// Ambiguous abbreviations take their most common meaning in feeds: IST is India, CST is
+// US Central.
+var zoneOffsets = map[string]int{
+	"UT": 0, "UTC": 0, "GMT": 0, "Z": 0, "WET": 0, "BST": 60, "IST": 330, "WEST": 60,
+	"EST": -300, "EDT": -240, "CST": -360, "CDT": -300, "MST": -420, "MDT": -360,
+	"PST": -480, "PDT": -420, "AKST": -540, "AKDT": -480, "HST": -600, "AST": -240, "ADT": -180,
+	"NST": -210, "NDT": -150,
+	"CET": 60, "CEST": 120, "MET": 60, "MEST": 120, "EET": 120, "EEST": 180, "MSK": 180,
+	"SAST": 120, "PKT": 300, "ICT": 420, "WIB": 420, "HKT": 480, "SGT": 480, "PHT": 480,
+	"AWST": 480, "KST": 540, "JST": 540, "ACST": 570, "ACDT": 630, "AEST": 600, "AEDT": 660,
+	"NZST": 720, "NZDT": 780, "BRT": -180, "ART": -180,
+	// RFC 822 military zones: A-I and K-M are east of UTC, N-Y west
+	"A": 60, "B": 120, "C": 180, "D": 240, "E": 300, "F": 360, "G": 420, "H": 480, "I": 540,
+	"K": 600, "L": 660, "M": 720, "N": -60, "O": -120, "P": -180, "Q": -240, "R": -300,
+	"S": -360, "T": -420, "U": -480, "V": -540, "W": -600, "X": -660, "Y": -720,
+}
+
+// dateLayouts are tried after normalize, which leaves month names as three-letter
+// abbreviations, AM and PM in capitals, no weekday or commas, and any zone as a numeric offset. Fractional seconds
+// are accepted after any seconds field.
+var dateLayouts = []string{
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006 3:04:05 PM",
+	"2 Jan 2006 3:04 PM",
+	"2 Jan 06 15:04:05",
+	"2 Jan 06 15:04",
+	"Jan 2 2006 15:04:05",
+	"Jan 2 2006 15:04",
+	"Jan 2 2006 3:04:05 PM",
+	"Jan 2 2006 3:04 PM",
+	"Jan 2 06 15:04:05",
+	"Jan 2 15:04:05 2006",
+	"Jan 2 15:04:05 Z0700 2006",
+	"2006 Jan 2 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"20060102T150405",
+	"2 Jan 2006",
+	"Jan 2 2006",
+	"2006-01-02",
+	"2006/01/02",
+	"Jan 2006",
+}
+
+// zoneLayouts are appended to each date layout; the last one means no zone, taken as UTC
+var zoneLayouts = []string{" Z0700", " Z07:00", "Z0700", "Z07:00", ""}
+
+// Parse reads a date as found in RSS pubDate and similar elements: RFC 822 and 1123 with or
+// without weekday, seconds or a four-digit year, ISO 8601 and its common variations, and
+// assorted human-written forms like "March 3rd, 2024 10:00 AM EST". Dates without a zone are
+// taken as UTC, and all dates are returned in UTC.
+func Parse(s string) (time.Time, error) {
+	normalized := normalize(s)
+	if normalized == "" {
+		return time.Time{}, ErrUnknownFormat
+	}
+
+	for _, layout := range dateLayouts {
+		for _, zone := range zoneLayouts {
+			if t, err := time.Parse(layout+zone, normalized); err == nil {
+				return t.UTC(), nil
+			}
+		}
+	}
+
+	// Unix timestamps turn up now and then
+	if n, err := strconv.ParseInt(normalized, 10, 64); err == nil && n > 100000000 && n < 1e10 {
+		return time.Unix(n, 0).UTC(), nil
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q", ErrUnknownFormat, s)
+}
+
+// normalize rewrites a date into the shape dateLayouts expect
+func normalize(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = weekday.ReplaceAllString(s, "")
+	s = zoneComment.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(s, ",", " ")
+	s = ordinal.ReplaceAllString(s, "$1")
+	s = meridiem.ReplaceAllStringFunc(s, func(m string) string {
+		return strings.ToUpper(m[:1]) + "M"
+	})
+	s = monthName.ReplaceAllStringFunc(s, func(m string) string {
+		return strings.ToUpper(m[:1]) + strings.ToLower(m[1:3])
+	})
+
+	s = prefixedOffset.ReplaceAllStringFunc(s, func(m string) string {
+		parts := prefixedOffset.FindStringSubmatch(m)
+		hours, _ := strconv.Atoi(parts[2])
+		minutes, _ := strconv.Atoi(parts[3])
+		if parts[1] == "-" {
+			return formatOffset(-(hours*60 + minutes))
+		}
+		return formatOffset(hours*60 + minutes)
+	})
+	s = zoneName.ReplaceAllStringFunc(s, func(m string) string {
+		if offset, ok := zoneOffsets[m]; ok {
+			return formatOffset(offset)
+		}
+		return m
+	})
+	s = shortOffset.ReplaceAllStringFunc(s, func(m string) string {
+		parts := shortOffset.FindStringSubmatch(m)
+		hours, _ := strconv.Atoi(parts[3])
+		return fmt.Sprintf("%s %s%02d00", parts[1], parts[2], hours)
+	})
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func formatOffset(minutes int) string {
+	sign := "+"
+	if minutes < 0 {
+		sign, minutes = "-", -minutes
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, minutes/60, minutes%60)
+}
@@ -0,0 +1,236 @@
+package readability
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ErrNoContent is returned when a page has no recognisable article text
+var ErrNoContent = errors.New("no article content found")
+
+// minParagraphLength is the shortest block of text that counts towards a container's score
+const minParagraphLength = 25
+
+// skipTags hold page furniture rather than article text
+var skipTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"nav": true, "header": true, "footer": true, "aside": true,
+	"form": true, "button": true, "select": true, "svg": true, "iframe": true,
+	"figcaption": true,
+}
+
+// blockTags delimit the paragraphs of text that get scored and extracted
+var blockTags = map[string]bool{
+	"p": true, "pre": true, "blockquote": true, "li": true, "td": true, "dd": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// rawText matches elements whose contents aren't markup; their scripts trip up the XML tokenizer
+var rawText = regexp.MustCompile(`(?is)<script\b.*?</script\s*>|<style\b.*?</style\s*>`)
+
+var (
+	positiveHint = regexp.MustCompile(`(?i)article|body|content|entry|main|post|story|text`)
+	negativeHint = regexp.MustCompile(`(?i)comment|footer|sidebar|widget|nav|menu|share|social|related|promo|sponsor|advert|\bad\b|banner|popup|cookie`)
+)
+
+// node is an open element; score accumulates from the paragraphs it contains
+type node struct {
+	tag    string
+	parent *node
+	score  float64
+	weight float64
+	skip   bool
+}
+
+// paragraph is a block of text and the element that contains it
+type paragraph struct {
+	text     string
+	heading  bool
+	parent   *node
+	depth    int
+	linkText int
+}
+
+// Extract returns the main article text of an HTML page, as paragraphs separated by blank lines.
+//
+// It is a small take on Arc90's readability: each paragraph scores its parent (and, at half
+// weight, its grandparent) by length and comma count, class and id names nudge the scores,
+// and the text under the best-scoring element is kept.
+func Extract(r io.Reader) (string, error) {
+	page, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	page = rawText.ReplaceAll(page, nil)
+
+	d := xml.NewDecoder(bytes.NewReader(page))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	root := &node{tag: "#root"}
+	stack := []*node{root}
+	var paragraphs []paragraph
+	var current *paragraph
+	var text strings.Builder
+	linkDepth := 0
+
+	finish := func() {
+		current.text = strings.Join(strings.Fields(text.String()), " ")
+		if current.text != "" {
+			paragraphs = append(paragraphs, *current)
+		}
+		current = nil
+	}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			// Real-world HTML often isn't well formed; keep whatever parsed cleanly
+			break
+		}
+
+		top := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			tag := strings.ToLower(t.Name.Local)
+			n := &node{tag: tag, parent: top, skip: top.skip || skipTags[tag]}
+			n.weight = classWeight(t.Attr)
+			stack = append(stack, n)
+
+			if n.skip {
+				continue
+			}
+			if tag == "a" {
+				linkDepth++
+			}
+			if tag == "br" {
+				text.WriteByte(' ')
+			}
+			// A <p> can't hold other blocks, so HTML treats a new block as closing it
+			if blockTags[tag] && current != nil && stack[current.depth].tag == "p" {
+				finish()
+			}
+			if blockTags[tag] && current == nil {
+				current = &paragraph{parent: top, depth: len(stack) - 1, heading: tag[0] == 'h'}
+				text.Reset()
+			}
+
+		case xml.EndElement:
+			tag := strings.ToLower(t.Name.Local)
+			// Pop back to the matching element, closing anything left open inside it
+			i := len(stack) - 1
+			for i > 0 && stack[i].tag != tag {
+				i--
+			}
+			if i == 0 {
+				continue
+			}
+			closed := stack[i]
+			stack = stack[:i]
+
+			if closed.skip {
+				continue
+			}
+			if tag == "a" && linkDepth > 0 {
+				linkDepth--
+			}
+			if current != nil && current.depth >= i {
+				finish()
+			}
+
+		case xml.CharData:
+			if top.skip || current == nil {
+				continue
+			}
+			text.Write(t)
+			if linkDepth > 0 {
+				current.linkText += len(strings.TrimSpace(string(t)))
+			}
+		}
+	}
+
+	// Score each paragraph's container and grandparent container
+	for _, p := range paragraphs {
+		if p.heading || len(p.text) < minParagraphLength {
+			continue
+		}
+		score := (1 + float64(strings.Count(p.text, ",")) + min(float64(len(p.text))/100, 3)) * (1 - linkDensity(p))
+		p.parent.score += score
+		if p.parent.parent != nil {
+			p.parent.parent.score += score / 2
+		}
+	}
+
+	var best *node
+	bestScore := 0.0
+	seen := map[*node]bool{}
+	for _, p := range paragraphs {
+		for n := p.parent; n != nil && n != root; n = n.parent {
+			if seen[n] || n.score == 0 {
+				continue
+			}
+			seen[n] = true
+			if total := n.score + n.weight; total > bestScore {
+				best, bestScore = n, total
+			}
+		}
+	}
+	if best == nil {
+		return "", ErrNoContent
+	}
+
+	var out []string
+	for _, p := range paragraphs {
+		if linkDensity(p) > 0.5 || !within(p.parent, best) {
+			continue
+		}
+		out = append(out, p.text)
+	}
+	if len(out) == 0 {
+		return "", ErrNoContent
+	}
+
+	return strings.Join(out, "\n\n"), nil
+}
+
+// classWeight scores an element's class and id for how article-like they sound
+func classWeight(attrs []xml.Attr) float64 {
+	weight := 0.0
+	for _, attr := range attrs {
+		if attr.Name.Local != "class" && attr.Name.Local != "id" {
+			continue
+		}
+		if negativeHint.MatchString(attr.Value) {
+			weight -= 25
+		}
+		if positiveHint.MatchString(attr.Value) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+func linkDensity(p paragraph) float64 {
+	if len(p.text) == 0 {
+		return 0
+	}
+	return min(float64(p.linkText)/float64(len(p.text)), 1)
+}
+
+// within reports whether ancestor is n or one of its ancestors
+func within(n, ancestor *node) bool {
+	for ; n != nil; n = n.parent {
+		if n == ancestor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,334 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Post is what a rule's expression can refer to
+type Post struct {
+	Title       string
+	Description string
+	URL         string
+	Feed        string
+	Author      string
+	Categories  []string
+}
+
+// values returns the value of a field, a list for categories
+func (p Post) values(field string) []string {
+	switch field {
+	case "title":
+		return []string{p.Title}
+	case "description":
+		return []string{p.Description}
+	case "url":
+		return []string{p.URL}
+	case "feed":
+		return []string{p.Feed}
+	case "author":
+		return []string{p.Author}
+	default:
+		return p.Categories
+	}
+}
+
+// Fields are the names an expression can compare
+var Fields = []string{"title", "description", "url", "feed", "author", "categories"}
+
+// Operators are the comparisons an expression can make between a field and a string
+var Operators = []string{"==", "!=", "contains", "startswith", "endswith", "matches"}
+
+// Expr is a parsed rule expression
+type Expr struct {
+	root node
+}
+
+// Match reports whether post satisfies the expression
+func (e Expr) Match(post Post) bool {
+	return e.root.match(post)
+}
+
+// Parse parses expressions like `title contains "rust" and not feed == "HN"` or
+// `"security" in categories or title matches "CVE-\d+"`. A comparison is a field, an operator and
+// a double-quoted string; "x" in field is field contains "x". Comparisons join with and, or and
+// not (also &&, || and !) and group with parentheses. Strings compare case-insensitively, and a
+// comparison on categories holds if it holds for any of them.
+func Parse(expr string) (Expr, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return Expr{}, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.or()
+	if err != nil {
+		return Expr{}, err
+	}
+	if tok := p.peek(); tok.kind != tokenEnd {
+		return Expr{}, fmt.Errorf("unexpected %s at position %d", tok, tok.pos)
+	}
+	return Expr{root: root}, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenWord
+	tokenString
+	tokenSymbol
+)
+
+// token is one word, string or symbol of an expression; pos is where it starts, counting from 1
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEnd:
+		return "end of expression"
+	case tokenString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// is reports whether t is the word or symbol text; words are case-insensitive
+func (t token) is(text string) bool {
+	return (t.kind == tokenWord || t.kind == tokenSymbol) && strings.EqualFold(t.text, text)
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(expr) && expr[end] != '"' {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at position %d", i+1)
+			}
+			text, err := strconv.Unquote(expr[i : end+1])
+			if err != nil {
+				// Keep backslashes that aren't escapes, so regexes like "\d+" needn't double them
+				text = expr[i+1 : end]
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: i + 1})
+			i = end + 1
+		case strings.HasPrefix(expr[i:], "==") || strings.HasPrefix(expr[i:], "!=") ||
+			strings.HasPrefix(expr[i:], "&&") || strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, token{kind: tokenSymbol, text: expr[i : i+2], pos: i + 1})
+			i += 2
+		case c == '(' || c == ')' || c == '!':
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(c), pos: i + 1})
+			i++
+		case unicode.IsLetter(rune(c)) || c == '_':
+			end := i
+			for end < len(expr) && (unicode.IsLetter(rune(expr[end])) || expr[end] == '_') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: strings.ToLower(expr[i:end]), pos: i + 1})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", c, i+1)
+		}
+	}
+	return append(tokens, token{kind: tokenEnd, pos: len(expr) + 1}), nil
+}
+
+// parser reads an expression by recursive descent, with not binding tighter than and, and and
+// tighter than or
+type parser struct {
+	tokens []token
+	next   int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) take() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEnd {
+		p.next++
+	}
+	return tok
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("or") || p.peek().is("||") {
+		p.take()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("and") || p.peek().is("&&") {
+		p.take()
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) not() (node, error) {
+	if p.peek().is("not") || p.peek().is("!") {
+		p.take()
+		operand, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (node, error) {
+	tok := p.take()
+	switch {
+	case tok.is("("):
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.take(); !closing.is(")") {
+			return nil, fmt.Errorf("expected \")\" at position %d, got %s", closing.pos, closing)
+		}
+		return inner, nil
+
+	case tok.kind == tokenString:
+		// "x" in field
+		if in := p.take(); !in.is("in") {
+			return nil, fmt.Errorf("expected \"in\" at position %d, got %s", in.pos, in)
+		}
+		field, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		return newComparison(field, "contains", tok.text)
+
+	case tok.kind == tokenWord:
+		p.next--
+		field, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		op := p.take()
+		if (op.kind != tokenWord && op.kind != tokenSymbol) || !slices.Contains(Operators, op.text) {
+			return nil, fmt.Errorf("expected one of %s at position %d, got %s", strings.Join(Operators, ", "), op.pos, op)
+		}
+		value := p.take()
+		if value.kind != tokenString {
+			return nil, fmt.Errorf("expected a quoted string at position %d, got %s", value.pos, value)
+		}
+		return newComparison(field, op.text, value.text)
+
+	default:
+		return nil, fmt.Errorf("expected a comparison at position %d, got %s", tok.pos, tok)
+	}
+}
+
+func (p *parser) field() (string, error) {
+	tok := p.take()
+	if tok.kind == tokenWord && tok.text == "category" {
+		return "categories", nil
+	}
+	if tok.kind != tokenWord || !slices.Contains(Fields, tok.text) {
+		return "", fmt.Errorf("expected one of %s at position %d, got %s", strings.Join(Fields, ", "), tok.pos, tok)
+	}
+	return tok.text, nil
+}
+
+// node is a part of an expression that holds or doesn't for a post
+type node interface {
+	match(post Post) bool
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) match(post Post) bool { return n.left.match(post) || n.right.match(post) }
+
+type andNode struct{ left, right node }
+
+func (n andNode) match(post Post) bool { return n.left.match(post) && n.right.match(post) }
+
+type notNode struct{ operand node }
+
+func (n notNode) match(post Post) bool { return !n.operand.match(post) }
+
+// comparison compares a field with a lower-cased string; != is kept as a negated ==, so that on
+// categories it means none of them are equal
+type comparison struct {
+	field  string
+	op     string
+	value  string
+	re     *regexp.Regexp
+	negate bool
+}
+
+func newComparison(field, op, value string) (node, error) {
+	c := comparison{field: field, op: op, value: strings.ToLower(value)}
+	if op == "!=" {
+		c.op, c.negate = "==", true
+	}
+	if op == "matches" {
+		re, err := regexp.Compile("(?i)" + value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", value, err)
+		}
+		c.re = re
+	}
+	return c, nil
+}
+
+func (c comparison) match(post Post) bool {
+	matched := slices.ContainsFunc(post.values(c.field), func(v string) bool {
+		if c.re != nil {
+			return c.re.MatchString(v)
+		}
+		v = strings.ToLower(v)
+		switch c.op {
+		case "contains":
+			return strings.Contains(v, c.value)
+		case "startswith":
+			return strings.HasPrefix(v, c.value)
+		case "endswith":
+			return strings.HasSuffix(v, c.value)
+		default:
+			return v == c.value
+		}
+	})
+	return matched != c.negate
+}
@@ -0,0 +1,80 @@
+package schedule
+
+import (
+	"math/rand/v2"
+	"sort"
+	"time"
+)
+
+// Default bounds for adaptive polling when the config doesn't set them
+const (
+	DefaultMinInterval = 10 * time.Minute
+	DefaultMaxInterval = 24 * time.Hour
+)
+
+// AverageInterval returns the mean gap between publish times, and false if there are fewer than two
+func AverageInterval(times []time.Time) (time.Duration, bool) {
+	if len(times) < 2 {
+		return 0, false
+	}
+
+	sorted := make([]time.Time, len(times))
+	copy(sorted, times)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Before(sorted[j])
+	})
+
+	span := sorted[len(sorted)-1].Sub(sorted[0])
+	return span / time.Duration(len(sorted)-1), true
+}
+
+// NextInterval picks how long to wait before fetching a feed again.
+// Feeds are polled at half their average publishing gap so new posts are
+// usually picked up before the next one arrives, clamped to [min, max].
+// Feeds without enough history are polled at the minimum until they have some.
+func NextInterval(avg time.Duration, known bool, min, max time.Duration) time.Duration {
+	if !known {
+		return min
+	}
+
+	interval := avg / 2
+	if interval < min {
+		return min
+	}
+	if interval > max {
+		return max
+	}
+	return interval
+}
+
+// Backoff returns how long to wait before retry number attempt (counting from 1): base doubled
+// for each earlier attempt and capped at max. Up to half of the wait is randomised so clients
+// that failed together don't all retry together.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	wait := base
+	for i := 1; i < attempt && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	if wait <= 0 {
+		return 0
+	}
+
+	half := wait / 2
+	return half + rand.N(wait-half+1)
+}
+
+// JitterFraction is how far Jitter may move an interval either way, as a fraction of it
+const JitterFraction = 0.1
+
+// Jitter moves interval randomly by up to JitterFraction either way, so feeds fetched together,
+// like every feed on a fresh start, drift apart instead of falling due together every time
+func Jitter(interval time.Duration) time.Duration {
+	spread := time.Duration(float64(interval) * JitterFraction)
+	if spread <= 0 {
+		return interval
+	}
+	return interval - spread + rand.N(2*spread+1)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// logOptions holds the global logging flags; empty fields fall back to the config, then to
+// info-level text on stderr
+type logOptions struct {
+	level  string
+	format string
+	file   string
+}
+
+// set applies one logging flag
+func (o *logOptions) set(name, value string) {
+	switch name {
+	case "--log-level":
+		o.level = value
+	case "--log-format":
+		o.format = value
+	case "--log-file":
+		o.file = value
+	}
+}
+
+// setupLogging installs the default slog logger. The returned function closes the log file,
+// if there is one.
+func setupLogging(opts logOptions) (func(), error) {
+	level, err := parseLogLevel(opts.level)
+	if err != nil {
+		return nil, err
+	}
+
+	var out io.Writer = os.Stderr
+	closeFile := func() {}
+	if opts.file != "" {
+		f, err := os.OpenFile(opts.file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't open log file: %w", err)
+		}
+		out = f
+		closeFile = func() { f.Close() }
+	}
+
+	handler, err := newLogHandler(out, opts.format, level)
+	if err != nil {
+		closeFile()
+		return nil, err
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return closeFile, nil
+}
+
+// newLogHandler returns a handler writing format, text or json, to out
+func newLogHandler(out io.Writer, format string, level slog.Level) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: level}
+	switch format {
+	case "", "text":
+		return slog.NewTextHandler(out, opts), nil
+	case "json":
+		return slog.NewJSONHandler(out, opts), nil
+	}
+	return nil, fmt.Errorf("unknown log format %q (expected text or json)", format)
+}
+
+// parseLogLevel reads debug, info, warn or error; empty means info
+func parseLogLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", s)
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/Utkarsh736/gator/internal/config"
 	"github.com/Utkarsh736/gator/internal/database"
@@ -18,7 +23,18 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Open database connection
+	// Pick the profile before anything reads db_url; --profile beats GATOR_PROFILE
+	profile, ok, err := profileFlag(os.Args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if ok {
+		cfg.UseProfile(profile)
+	}
+
+	// Open database connection; sql.Open doesn't connect, so an unusable db_url is only reported
+	// once we know the command needs the database
 	db, err := sql.Open("postgres", cfg.DbURL)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
@@ -31,34 +47,132 @@ func main() {
 
 	// Initialize application state
 	appState := &state{
-		db:  dbQueries,
-		cfg: &cfg,
+		db:   dbQueries,
+		conn: db,
+		cfg:  &cfg,
 	}
 
 	// Initialize commands registry
 	cmds := &commands{
 		handlers: make(map[string]func(*state, command) error),
+		aliases:  cfg.Aliases,
 	}
 
-	
 	// Register command handlers
-	cmds.register("login", handlerLogin)
-	cmds.register("register", handlerRegister)
-	cmds.register("reset", handlerReset)
-	cmds.register("users", handlerUsers)
-	cmds.register("agg", handlerAgg)
-	cmds.register("addfeed", middlewareLoggedIn(handlerAddFeed))
-	cmds.register("feeds", handlerFeeds)
-	cmds.register("follow", middlewareLoggedIn(handlerFollow))
-	cmds.register("following", middlewareLoggedIn(handlerFollowing))
-	cmds.register("unfollow", middlewareLoggedIn(handlerUnfollow))
-	cmds.register("browse", middlewareLoggedIn(handlerBrowse))
-
-	// Parse command-line arguments
-	args := os.Args
+	cmds.register("help", "[command]", "List commands, or show how to use one", cmds.help)
+	cmds.register("login", "<username>", "Log in as a user", handlerLogin)
+	cmds.register("passwd", "", "Change the current user's password", middlewareLoggedIn(handlerPasswd))
+	cmds.register("apikey", "create <name> [--scope read|write] | list | revoke <name> | show", "Manage API keys for the REST API", middlewareLoggedIn(handlerAPIKey))
+	cmds.register("register", "<username>", "Create a user and log in as them", handlerRegister)
+	cmds.register("reset", "--yes [--user <username>] [--posts-only]", "Delete users, feeds and posts", handlerReset)
+	cmds.register("users", "", "List users", handlerUsers)
+	cmds.register("user", "delete <username> [--yes|-y] | rename <old_name> <new_name>", "Delete or rename a user", handlerUser)
+	cmds.register("agg", "[duration] [--once] [--notify|--no-notify] [--no-images] [--auto-update-urls] [--pidfile <path>] [--metrics-addr <addr>] [--dry-run] [--coordinator]", "Fetch due feeds continuously, one pass per duration", handlerAgg)
+	cmds.register("fetch", "[feed_url] [--notify|--no-notify] [--dry-run]", "Fetch every due feed, or one feed, once and exit", handlerFetch)
+	cmds.register("worker", "[--notify|--no-notify] [--no-images] [--auto-update-urls] [--metrics-addr <addr>]", "Fetch the feeds agg --coordinator queues, until interrupted", handlerWorker)
+	cmds.register("prune", "[--older-than <duration>]", "Delete old posts nobody has saved or recently read", handlerPrune)
+	cmds.register("config", "get <setting> | set <setting> <value> | unset <setting> | list [--all]", "Read and change settings in the config file", handlerConfig)
+	cmds.register("addfeed", "<feed_name> <feed_url>", "Add a feed and follow it", middlewareLoggedIn(handlerAddFeed))
+	cmds.register("preview", "<feed_url|site_url> [--limit <n>]", "Show what's in a feed without adding it", handlerPreview)
+	cmds.register("feeds", "", "List all feeds", handlerFeeds)
+	cmds.register("feed", "status|pause|resume|mute|unmute|note|extract|interval|rename|set-url|transfer|delete ...", "Manage a feed's settings and health", middlewareLoggedIn(handlerFeed))
+	cmds.register("follow", "<feed_url|name|id> [--category <name>] | --from-file <file|->", "Follow a feed", middlewareLoggedIn(handlerFollow))
+	cmds.register("discover", "[pack] [--follow] [--category <name>]", "List starter feed packs, or follow one", middlewareLoggedIn(handlerDiscover))
+	cmds.register("following", "", "List the feeds you follow", middlewareLoggedIn(handlerFollowing))
+	cmds.register("unfollow", "<feed_url|name|id> | --from-file <file|->", "Stop following a feed", middlewareLoggedIn(handlerUnfollow))
+	cmds.register("category", "create <name> | delete <name> | list", "Manage categories for followed feeds", middlewareLoggedIn(handlerCategory))
+	cmds.register("browse", "[limit] [--limit <n>] [--all] [--clustered] [--feed <feed>] [--category <name>] [--tag <name>] [--smart <name>] [--author <name>] [--feed-category <name>] [--max-read-time <duration>] [--since <when>] [--until <when>] [--sort <field>] [--page <n>]", "Show recent posts from the feeds you follow", middlewareLoggedIn(handlerBrowse))
+	cmds.register("watch", "", "Show new posts from followed feeds as they arrive, until interrupted", middlewareLoggedIn(handlerWatch))
+	cmds.register("surprise", "[n]", "Pick unread posts at random, favouring feeds you rarely read", middlewareLoggedIn(handlerSurprise))
+	cmds.register("show", "<post_id|post_url|number> [--fetch]", "Show a post in full, with its feed, tags and state", middlewareLoggedIn(handlerShow))
+	cmds.register("read", "<post_id|post_url>", "Mark a post read", middlewareLoggedIn(handlerRead))
+	cmds.register("open", "<post_id|post_url|number>", "Open a post in the browser and mark it read", middlewareLoggedIn(handlerOpen))
+	cmds.register("unread", "<post_id|post_url>", "Mark a post unread", middlewareLoggedIn(handlerUnread))
+	cmds.register("catchup", "[--feed <feed_url>] [--older-than <duration>]", "Mark posts from followed feeds read in bulk", middlewareLoggedIn(handlerCatchup))
+	cmds.register("save", "<post_url>", "Save a post for later", middlewareLoggedIn(handlerSave))
+	cmds.register("unsave", "<post_url>", "Remove a post from your saved posts", middlewareLoggedIn(handlerUnsave))
+	cmds.register("send", "<post_id|post_url|number> --to instapaper|wallabag | --auto --to instapaper|wallabag", "Send a post to a read-later service, or every post you save", middlewareLoggedIn(handlerSend))
+	cmds.register("saved", "", "List your saved posts", middlewareLoggedIn(handlerSaved))
+	cmds.register("queue", "[add <post_id|post_url|number> [--next] | remove <post_id|post_url|number> | pop]", "List your read-later queue, or add to it, remove from it or read the next post", middlewareLoggedIn(handlerQueue))
+	cmds.register("search", "<query> [--all-feeds] [--limit <n>]", "Search posts", middlewareLoggedIn(handlerSearch))
+	cmds.register("tag", "<post_url> <tag>", "Tag a post", middlewareLoggedIn(handlerTag))
+	cmds.register("untag", "<post_url> <tag>", "Remove a tag from a post", middlewareLoggedIn(handlerUntag))
+	cmds.register("tags", "[delete <tag>]", "List or delete your tags", middlewareLoggedIn(handlerTags))
+	cmds.register("filter", "add (--mute|--must-contain) <pattern> [--feed <feed_url>] [--regex] [--ingest] | list | remove <filter_id>", "Hide posts by keyword or pattern", middlewareLoggedIn(handlerFilter))
+	cmds.register("rule", "add <expression> (--tag <name>|--mark-read|--drop|--notify) | list | remove <rule_id>", "Tag, mark read, drop or notify on new posts matching an expression", middlewareLoggedIn(handlerRule))
+	cmds.register("smart", "create <name> [--query <terms>] [--feed <url>] [--tag <name>] [--since <when>] [--until <when>] | list | delete <name>", "Manage smart folders of saved searches", middlewareLoggedIn(handlerSmart))
+	cmds.register("stats", "", "Summarise your feeds and reading", middlewareLoggedIn(handlerStats))
+	cmds.register("recommend", "[--limit <n>]", "Suggest feeds from similar users and the sites your reading links to", middlewareLoggedIn(handlerRecommend))
+	cmds.register("trends", "[--since <when>] [--limit <n>]", "List the words and phrases most used in recent post titles", middlewareLoggedIn(handlerTrends))
+	cmds.register("podcasts", "[limit] [--limit <n>] [--feed <feed_url>]", "List podcast episodes", middlewareLoggedIn(handlerPodcasts))
+	cmds.register("download", "<post_id|post_url|number>", "Download a podcast episode", middlewareLoggedIn(handlerDownload))
+	cmds.register("export", "posts [--format md|csv|json] [--since <duration>] [--out <dir>] [--read] [--saved]", "Export posts to Markdown, CSV or JSON files", middlewareLoggedIn(handlerExport))
+	cmds.register("digest", "[--since <duration>] [--email <address>]", "Email a digest of recent posts", middlewareLoggedIn(handlerDigest))
+	cmds.register("webhook", "add <url> [--type <type>] [--feed <feed_url>] [--category <name>] | list | remove <webhook_id>", "Send new posts to webhooks", middlewareLoggedIn(handlerWebhook))
+	cmds.register("telegram", "", "Run the Telegram bot", handlerTelegram)
+	cmds.register("init", "", "Set up the config, database and first user step by step", handlerInit)
+	cmds.register("doctor", "", "Check the config, database connection and schema", handlerDoctor)
+	cmds.register("migrate", "[status|down]", "Apply or roll back database migrations", handlerMigrate)
+	cmds.register("serve", "[--addr <addr>] [--metrics-addr <addr>]", "Serve the REST and Fever APIs and the web UI", handlerServe)
+	cmds.register("tui", "", "Browse posts in an interactive terminal UI", middlewareLoggedIn(handlerTui))
+
+	// Parse command-line arguments, pulling out global flags first. "--" ends them; after the
+	// command name it's passed on so the command's own flags stop there too.
+	args := []string{}
+	logOpts := logOptions{level: cfg.LogLevel, format: cfg.LogFormat, file: cfg.LogFile}
+	for i := 0; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		if arg == "--" {
+			if len(args) < 2 {
+				i++
+			}
+			args = append(args, os.Args[i:]...)
+			break
+		}
+		switch {
+		case arg == "--json":
+			appState.output = "json"
+		case arg == "--debug-http":
+			debugHTTP = true
+		case arg == "--profile":
+			// Already applied by profileFlag
+			i++
+		case strings.HasPrefix(arg, "--profile="):
+		case arg == "--log-level" || arg == "--log-format" || arg == "--log-file":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", arg)
+				os.Exit(1)
+			}
+			i++
+			logOpts.set(arg, os.Args[i])
+		case strings.HasPrefix(arg, "--log-level="), strings.HasPrefix(arg, "--log-format="), strings.HasPrefix(arg, "--log-file="):
+			name, value, _ := strings.Cut(arg, "=")
+			logOpts.set(name, value)
+		case arg == "--output":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintln(os.Stderr, "Error: --output requires a format")
+				os.Exit(1)
+			}
+			i++
+			appState.output = os.Args[i]
+		case strings.HasPrefix(arg, "--output="):
+			appState.output = strings.TrimPrefix(arg, "--output=")
+		default:
+			args = append(args, arg)
+		}
+	}
+	// The config's output format applies when --output isn't given; validateConfig reports a bad one
+	if _, ok := formatters[cfg.Output]; appState.output == "" && ok {
+		appState.output = cfg.Output
+	}
+	if _, ok := formatters[appState.output]; appState.output != "" && !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown output format %q (expected one of %s)\n", appState.output, strings.Join(outputFormats(), ", "))
+		os.Exit(1)
+	}
 	if len(args) < 2 {
 		fmt.Fprintln(os.Stderr, "Error: not enough arguments provided")
 		fmt.Fprintln(os.Stderr, "Usage: gator <command> [args...]")
+		fmt.Fprintln(os.Stderr, "Run 'gator help' to list commands")
 		os.Exit(1)
 	}
 
@@ -74,11 +188,123 @@ func main() {
 		args: cmdArgs,
 	}
 
+	// Expand aliases first, so that the checks below see the command they stand for
+	cmd, err = cmds.expand(cmd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// --help anywhere before a "--" shows help for the command it's given with
+	if cmd.name == "--help" || cmd.name == "-h" {
+		cmd = command{name: "help", args: []string{}}
+	}
+	for _, arg := range cmd.args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--help" || arg == "-h" {
+			cmd = command{name: "help", args: []string{cmd.name}}
+			break
+		}
+	}
+
+	if err := cmds.check(cmd.name); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// help, config, doctor and init work without a database or a valid config, so they can help
+	// fix either
+	standalone := cmd.name == "help" || cmd.name == "config" || cmd.name == "doctor" || cmd.name == "init"
+
+	// help and config don't log, and mustn't be stopped by the bad log settings config is there to fix
+	if standalone {
+		logOpts = logOptions{}
+	}
+	closeLog, err := setupLogging(logOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer closeLog()
+
+	// A broken config stops everything but help and config, which is how it gets fixed
+	if !standalone {
+		if err := validateConfig(&cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error in config %s: %v\n", cfg.Path(), err)
+			os.Exit(1)
+		}
+
+		// Set up the HTTP client used for feeds, articles and podcasts
+		if err := configureHTTP(&cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error in http config: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Size the connection pool; doctor reports a bad database config rather than stopping on it
+	if pool, err := cfg.DatabasePool(); err == nil {
+		configureDatabasePool(db, pool)
+	}
+
+	// Everything but help and config needs a database
+	if cfg.DbURL == "" && !standalone {
+		if cfg.Profile() != "" {
+			fmt.Fprintf(os.Stderr, "Error: no db_url for profile %s in %s; run 'gator --profile %s config set db_url <url>'\n", cfg.Profile(), cfg.Path(), cfg.Profile())
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: no db_url in %s; run 'gator config set db_url <url>' or set GATOR_DB_URL\n", cfg.Path())
+		}
+		os.Exit(1)
+	}
+
+	// The first SIGINT or SIGTERM cancels the running command; a second one ends gator at once
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+
+	// Warn about pending migrations before running anything else
+	if cmd.name != "migrate" && !standalone {
+		warnIfSchemaOutdated(ctx, db)
+	}
+
 	// Run the command
-	err = cmds.run(appState, cmd)
+	err = cmds.run(ctx, appState, cmd)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// profileFlag finds a global --profile flag before any "--", reporting whether there was one
+func profileFlag(args []string) (string, bool, error) {
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return "", false, nil
+		case arg == "--profile":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return "", false, errors.New("--profile requires a name")
+			}
+			return args[i+1], true, nil
+		case strings.HasPrefix(arg, "--profile="):
+			name := strings.TrimPrefix(arg, "--profile=")
+			if name == "" {
+				return "", false, errors.New("--profile requires a name")
+			}
+			return name, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// configureDatabasePool applies the connection pool settings from the config
+func configureDatabasePool(db *sql.DB, pool config.DatabasePool) {
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+}
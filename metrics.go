@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/metrics"
+)
+
+// registry holds the metrics served at /metrics by serve and by agg --metrics-addr. Counters
+// only cover work done by the process serving them.
+var registry = metrics.NewRegistry()
+
+var (
+	fetchesTotal = registry.NewCounterVec("gator_feed_fetches_total",
+		"Feed fetches by result: success, failure, or delayed by the host.", "result", "success", "failure", "delayed")
+	fetchDuration = registry.NewHistogram("gator_feed_fetch_duration_seconds",
+		"Time taken to fetch a feed, including retries.", metrics.DefaultBuckets)
+	postsIngested = registry.NewCounter("gator_posts_ingested_total", "New posts saved.")
+	postsFiltered = registry.NewCounter("gator_posts_filtered_total", "Posts dropped by ingest filters.")
+)
+
+// databaseMetrics registers gauges read from the database the first time metrics are served
+var databaseMetrics sync.Once
+
+// metricsHandler serves the metrics, including gauges read from db
+func metricsHandler(db *database.Queries) http.Handler {
+	databaseMetrics.Do(func() {
+		registry.NewGaugeFunc("gator_feed_queue_depth", "Feeds due for fetching now.", func() (float64, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			n, err := db.CountDueFeeds(ctx)
+			return float64(n), err
+		})
+		registry.NewGaugeFunc("gator_feeds_paused", "Feeds that are paused.", func() (float64, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			n, err := db.CountPausedFeeds(ctx)
+			return float64(n), err
+		})
+	})
+	return registry.Handler()
+}
+
+// serveMetrics serves /metrics on addr in the background until the returned function is called
+func serveMetrics(addr string, db *database.Queries) func() {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metricsHandler(db))
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("metrics server stopped", "error", err)
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+)
+
+// postNotifier raises desktop notifications for new posts in user's unmuted follows
+type postNotifier struct {
+	user database.User
+}
+
+// notify announces a scrape's new posts; a nil notifier does nothing
+func (n *postNotifier) notify(s *state, res scrapeResult) error {
+	if n == nil || res.Feed == nil || len(res.NewPosts) == 0 {
+		return nil
+	}
+
+	follow, err := s.db.GetFeedFollow(s.ctx, database.GetFeedFollowParams{
+		UserID: n.user.ID,
+		FeedID: res.Feed.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("couldn't get feed follow: %w", err)
+	}
+	if follow.Muted {
+		return nil
+	}
+
+	filters, err := loadFilters(s.ctx, s.db, n.user.ID)
+	if err != nil {
+		return err
+	}
+	posts := filters.apiPosts(res.NewPosts)
+	if len(posts) == 0 {
+		return nil
+	}
+
+	body := htmltext.RenderInline(posts[0].Title)
+	if len(posts) > 1 {
+		body = fmt.Sprintf("%d new posts, including %s", len(posts), body)
+	}
+	return sendDesktopNotification(res.Feed.Name, body)
+}
+
+// sendDesktopNotification shows a notification with notify-send on Linux or osascript on macOS
+func sendDesktopNotification(title, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("notify-send", "--app-name=gator", title, body)
+	default:
+		return fmt.Errorf("desktop notifications aren't supported on %s", runtime.GOOS)
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", cmd.Args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+
+	"github.com/Utkarsh736/gator/internal/database"
+)
+
+// opmlOutline is an outline element: a feed when it has an xmlUrl, otherwise a folder of them
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlDocument struct {
+	Body struct {
+		Outlines []opmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+// opmlFeed is a feed listed in an OPML file, with the folder it was in
+type opmlFeed struct {
+	Name     string
+	URL      string
+	Category string
+}
+
+// readOPML lists the feeds in the OPML file at path, as exported by most feed readers
+func readOPML(path string) ([]opmlFeed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read OPML file: %w", err)
+	}
+
+	var doc opmlDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("couldn't parse OPML file: %w", err)
+	}
+
+	feeds := []opmlFeed{}
+	var walk func(outlines []opmlOutline, category string)
+	walk = func(outlines []opmlOutline, category string) {
+		for _, o := range outlines {
+			name := o.Title
+			if name == "" {
+				name = o.Text
+			}
+			if o.XMLURL == "" {
+				// Nested folders go in the innermost one
+				walk(o.Outlines, name)
+				continue
+			}
+			if name == "" {
+				name = o.XMLURL
+			}
+			feeds = append(feeds, opmlFeed{Name: name, URL: o.XMLURL, Category: category})
+		}
+	}
+	walk(doc.Body.Outlines, "")
+
+	if len(feeds) == 0 {
+		return nil, fmt.Errorf("no feeds in %s", path)
+	}
+	return feeds, nil
+}
+
+// opmlImport counts what importOPML did with the feeds in a file
+type opmlImport struct {
+	Added    int      `json:"added"`
+	Followed int      `json:"followed"`
+	Already  int      `json:"already_following"`
+	Failed   []string `json:"failed"`
+}
+
+// importOPML follows every feed in the OPML file at path, adding those gator doesn't know yet
+// and putting each in a category named after its folder. A feed that fails is noted and skipped.
+func importOPML(s *state, user database.User, path string) (opmlImport, error) {
+	feeds, err := readOPML(path)
+	if err != nil {
+		return opmlImport{}, err
+	}
+
+	res := opmlImport{Failed: []string{}}
+	categories := map[string]database.Category{}
+	for _, f := range feeds {
+		var category database.Category
+		if f.Category != "" {
+			if _, ok := categories[f.Category]; !ok {
+				categories[f.Category], err = ensureCategory(s, user, f.Category)
+				if err != nil {
+					return res, err
+				}
+			}
+			category = categories[f.Category]
+		}
+
+		added, alreadyFollowing, err := addAndFollowFeed(s, user, f.Name, f.URL, category)
+		switch {
+		case err != nil:
+			res.Failed = append(res.Failed, fmt.Sprintf("%s: %v", f.URL, err))
+		case added:
+			res.Added++
+		case alreadyFollowing:
+			res.Already++
+		default:
+			res.Followed++
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// result is structured handler output. writeText renders the human-readable
+// form and table returns the same data as columns for the table and CSV formats.
+type result interface {
+	writeText(w io.Writer)
+	table() (headers []string, rows [][]string)
+}
+
+// formatter renders a result in one output format
+type formatter func(w io.Writer, r result) error
+
+// defaultOutputFormat is used when --output isn't given
+const defaultOutputFormat = "plain"
+
+var formatters = map[string]formatter{
+	"plain": formatPlain,
+	"json":  formatJSON,
+	"table": formatTable,
+	"csv":   formatCSV,
+}
+
+// outputFormats lists the names accepted by --output
+func outputFormats() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// emit writes a handler's result to s.out (stdout by default) in the selected output format
+func (s *state) emit(r result) error {
+	name := s.output
+	if name == "" {
+		name = defaultOutputFormat
+	}
+
+	format, ok := formatters[name]
+	if !ok {
+		return fmt.Errorf("unknown output format %q: expected one of %s", name, strings.Join(outputFormats(), ", "))
+	}
+	w := s.out
+	if w == nil {
+		w = os.Stdout
+	}
+	return format(w, r)
+}
+
+func formatPlain(w io.Writer, r result) error {
+	r.writeText(w)
+	return nil
+}
+
+func formatJSON(w io.Writer, r result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func formatTable(w io.Writer, r result) error {
+	headers, rows := r.table()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(headers, "\t")))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func formatCSV(w io.Writer, r result) error {
+	headers, rows := r.table()
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// formatTime renders an optional timestamp as a table cell
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// messageResult reports a completed action, with the object it acted on for JSON consumers
+type messageResult struct {
+	Message string      `json:"message"`
+	Item    interface{} `json:"item,omitempty"`
+}
+
+func (r messageResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, r.Message)
+}
+
+func (r messageResult) table() ([]string, [][]string) {
+	return []string{"message"}, [][]string{{r.Message}}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Utkarsh736/gator/internal/database"
+)
+
+//go:embed packs/*.json
+var packFiles embed.FS
+
+// feedPack is a curated bundle of feeds on one topic, as discover lists them
+type feedPack struct {
+	Name        string     `json:"name"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Feeds       []packFeed `json:"feeds"`
+}
+
+type packFeed struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// loadPacks returns the packs built into gator, with those from pack_index_url, if it's set,
+// added to them or replacing the ones of the same name. Packs are sorted by name.
+func loadPacks(s *state) ([]feedPack, error) {
+	packs := map[string]feedPack{}
+
+	files, err := fs.Glob(packFiles, "packs/*.json")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := packFiles.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var pack feedPack
+		if err := json.Unmarshal(data, &pack); err != nil {
+			return nil, fmt.Errorf("couldn't parse %s: %w", file, err)
+		}
+		packs[pack.Name] = pack
+	}
+
+	if s.cfg.PackIndexURL != "" {
+		remote, err := fetchPackIndex(s, s.cfg.PackIndexURL)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get packs from %s: %w", s.cfg.PackIndexURL, err)
+		}
+		for _, pack := range remote {
+			packs[pack.Name] = pack
+		}
+	}
+
+	res := make([]feedPack, 0, len(packs))
+	for _, pack := range packs {
+		res = append(res, pack)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// fetchPackIndex downloads a pack index: a JSON array of packs laid out like those in packs/
+func fetchPackIndex(s *state, indexURL string) ([]feedPack, error) {
+	req, err := http.NewRequestWithContext(s.ctx, "GET", indexURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := readResponse(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var packs []feedPack
+	if err := json.Unmarshal(data, &packs); err != nil {
+		return nil, fmt.Errorf("couldn't parse pack index: %w", err)
+	}
+	for _, pack := range packs {
+		if pack.Name == "" {
+			return nil, errors.New("pack index has a pack with no name")
+		}
+	}
+	return packs, nil
+}
+
+// packsResult is the output of discover without a pack
+type packsResult struct {
+	Packs []feedPack `json:"packs"`
+}
+
+func (r packsResult) writeText(w io.Writer) {
+	if len(r.Packs) == 0 {
+		fmt.Fprintln(w, "No feed packs")
+		return
+	}
+
+	fmt.Fprintln(w, "Feed packs:")
+	for _, pack := range r.Packs {
+		fmt.Fprintf(w, "* %s - %s (%d feeds)\n", pack.Name, pack.Description, len(pack.Feeds))
+	}
+	fmt.Fprintln(w, "Run 'gator discover <pack>' to see its feeds, and add --follow to follow them all")
+}
+
+func (r packsResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, pack := range r.Packs {
+		rows = append(rows, []string{pack.Name, pack.Title, pack.Description, strconv.Itoa(len(pack.Feeds))})
+	}
+	return []string{"name", "title", "description", "feeds"}, rows
+}
+
+// packResult is the output of discover with a pack
+type packResult struct {
+	Pack feedPack `json:"pack"`
+}
+
+func (r packResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "%s: %s\n", r.Pack.Title, r.Pack.Description)
+	for _, feed := range r.Pack.Feeds {
+		fmt.Fprintf(w, "* %s - %s\n", feed.Name, feed.URL)
+	}
+	fmt.Fprintf(w, "Run 'gator discover %s --follow' to follow them all\n", r.Pack.Name)
+}
+
+func (r packResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, feed := range r.Pack.Feeds {
+		rows = append(rows, []string{feed.Name, feed.URL})
+	}
+	return []string{"name", "url"}, rows
+}
+
+// handlerDiscover lists the starter feed packs, shows the feeds in one, or follows them all
+func handlerDiscover(s *state, cmd command, user database.User) error {
+	var follow bool
+	var categoryName string
+	flags := newFlagSet("discover")
+	flags.BoolVar(&follow, "follow", false, "Follow every feed in the pack")
+	flags.StringVar(&categoryName, "category", "", "Put the pack's feeds in this category `name` rather than one named after the pack")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected discover argument %q", args[1])
+	}
+
+	packs, err := loadPacks(s)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		if follow {
+			return errors.New("discover --follow requires a pack name")
+		}
+		return s.emit(packsResult{Packs: packs})
+	}
+
+	var pack *feedPack
+	names := make([]string, len(packs))
+	for i := range packs {
+		names[i] = packs[i].Name
+		if strings.EqualFold(packs[i].Name, args[0]) {
+			pack = &packs[i]
+		}
+	}
+	if pack == nil {
+		return fmt.Errorf("unknown pack %q (expected one of %s)", args[0], strings.Join(names, ", "))
+	}
+	if !follow {
+		return s.emit(packResult{Pack: *pack})
+	}
+	return followPack(s, user, *pack, categoryName)
+}
+
+// followPack follows every feed in pack, adding those gator doesn't know yet, and puts them in
+// a category, creating it if need be. Feeds that fail are reported and skipped.
+func followPack(s *state, user database.User, pack feedPack, categoryName string) error {
+	if categoryName == "" {
+		categoryName = pack.Title
+	}
+	if categoryName == "" {
+		categoryName = pack.Name
+	}
+	category, err := ensureCategory(s, user, categoryName)
+	if err != nil {
+		return err
+	}
+
+	res := bulkResult{Action: "follow", Entries: []bulkEntry{}}
+	for _, feed := range pack.Feeds {
+		entry := bulkEntry{Ref: feed.URL, Feed: feed.Name, Status: "followed"}
+		_, alreadyFollowing, err := addAndFollowFeed(s, user, feed.Name, feed.URL, category)
+		switch {
+		case err != nil:
+			entry.Status = "failed"
+			entry.Error = err.Error()
+		case alreadyFollowing:
+			entry.Status = "already"
+		}
+		res.Entries = append(res.Entries, entry)
+	}
+	return finishBulk(s, res)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Utkarsh736/gator/internal/auth"
+	"github.com/Utkarsh736/gator/internal/database"
+	"golang.org/x/term"
+)
+
+// minPasswordLength is the shortest password register and passwd accept
+const minPasswordLength = 8
+
+// stdin is shared by password prompts so a piped password and its confirmation aren't lost between reads
+var stdin = bufio.NewReader(os.Stdin)
+
+// readPassword prompts on stderr and reads a line from stdin, hiding input when it's a terminal
+func readPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("couldn't read password: %w", err)
+		}
+		return string(password), nil
+	}
+
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("couldn't read password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// promptNewPassword asks for a password twice. An empty answer means no password.
+func promptNewPassword(prompt string) (string, error) {
+	password, err := readPassword(prompt)
+	if err != nil || password == "" {
+		return "", err
+	}
+	if len(password) < minPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	again, err := readPassword("Confirm password: ")
+	if err != nil {
+		return "", err
+	}
+	if again != password {
+		return "", errors.New("passwords don't match")
+	}
+	return password, nil
+}
+
+// verifyUserPassword prompts for user's password if they have one and passwords are in use
+func verifyUserPassword(s *state, user database.User) error {
+	if !user.PasswordHash.Valid || s.cfg.Passwordless {
+		return nil
+	}
+
+	password, err := readPassword(fmt.Sprintf("Password for %s: ", user.Name))
+	if err != nil {
+		return err
+	}
+	return auth.CheckPassword(user.PasswordHash.String, password)
+}
+
+// confirm asks a yes/no question on stderr, defaulting to no
+func confirm(question string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return false, fmt.Errorf("couldn't read answer: %w", err)
+	}
+
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes", nil
+}
+
+// setUserPassword stores a hash of password for user, or clears it if password is empty
+func setUserPassword(ctx context.Context, db *database.Queries, user database.User, password string) error {
+	var hash sql.NullString
+	if password != "" {
+		h, err := auth.HashPassword(password)
+		if err != nil {
+			return fmt.Errorf("couldn't hash password: %w", err)
+		}
+		hash = sql.NullString{String: h, Valid: true}
+	}
+
+	err := db.SetUserPassword(ctx, database.SetUserPasswordParams{
+		ID:           user.ID,
+		PasswordHash: hash,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't set password: %w", err)
+	}
+	return nil
+}
+
+// handlerPasswd sets, changes or removes the current user's password
+func handlerPasswd(s *state, cmd command, user database.User) error {
+	if user.PasswordHash.Valid {
+		current, err := readPassword("Current password: ")
+		if err != nil {
+			return err
+		}
+		if err := auth.CheckPassword(user.PasswordHash.String, current); err != nil {
+			return err
+		}
+	}
+
+	password, err := promptNewPassword("New password (leave empty to remove): ")
+	if err != nil {
+		return err
+	}
+	if err := setUserPassword(s.ctx, s.db, user, password); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Password updated for %s", user.Name)
+	if password == "" {
+		msg = fmt.Sprintf("Password removed for %s", user.Name)
+	}
+	return s.emit(messageResult{Message: msg, Item: toAPIUser(user)})
+}
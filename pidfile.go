@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// errPidFileLocked is what lockFile returns when another process holds the lock
+var errPidFileLocked = errors.New("pidfile is locked")
+
+// writePidFile writes the process ID to path and holds an exclusive lock on the file while gator
+// runs, so a second agg given the same path refuses to start instead of taking it over. The
+// returned function removes the file and releases the lock.
+func writePidFile(path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+		if err := lockFile(f); err != nil {
+			f.Close()
+			if errors.Is(err, errPidFileLocked) {
+				holder, _ := os.ReadFile(path)
+				return nil, fmt.Errorf("%s is held by another agg (pid %s)", path, strings.TrimSpace(string(holder)))
+			}
+			return nil, err
+		}
+
+		// The agg that held the lock may have removed the file just before letting go of it;
+		// then the lock is on a file nobody else can find, so start again with a new one
+		opened, err1 := f.Stat()
+		current, err2 := os.Stat(path)
+		if err1 != nil || err2 != nil || !os.SameFile(opened, current) {
+			f.Close()
+			continue
+		}
+
+		if err := f.Truncate(0); err != nil {
+			f.Close()
+			return nil, err
+		}
+		if _, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return func() {
+			// Removing the file before unlocking it keeps another agg from locking it on its way out
+			os.Remove(path)
+			f.Close()
+		}, nil
+	}
+}
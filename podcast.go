@@ -0,0 +1,357 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// podcastDefaultLimit is how many episodes podcasts lists without a limit argument
+const podcastDefaultLimit = 10
+
+// handlerPodcasts lists recent audio episodes from followed feeds
+func handlerPodcasts(s *state, cmd command, user database.User) error {
+	var limit int
+	var feedURL string
+	flags := newFlagSet("podcasts")
+	flags.IntVar(&limit, "limit", podcastDefaultLimit, "Show at most `n` episodes")
+	flags.StringVar(&feedURL, "feed", "", "Only episodes from the feed at `feed_url`")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	// The limit can also be given on its own, as in 'podcasts 5'
+	switch len(args) {
+	case 0:
+	case 1:
+		if limit, err = strconv.Atoi(args[0]); err != nil {
+			return fmt.Errorf("invalid limit %q", args[0])
+		}
+	default:
+		return fmt.Errorf("unexpected podcasts argument %q", args[1])
+	}
+	if limit <= 0 {
+		return fmt.Errorf("invalid limit %d: must be at least 1", limit)
+	}
+
+	var feedID uuid.NullUUID
+	if feedURL != "" {
+		feed, err := getFeedByURL(s.ctx, s.db, feedURL)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("feed %s doesn't exist", feedURL)
+			}
+			return fmt.Errorf("couldn't find feed: %w", err)
+		}
+		feedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+
+	episodes, err := s.db.GetPodcastEpisodesForUser(s.ctx, database.GetPodcastEpisodesForUserParams{
+		UserID:      user.ID,
+		FeedID:      feedID,
+		ResultLimit: int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get episodes: %w", err)
+	}
+
+	dir, err := s.cfg.PodcastDirectory()
+	if err != nil {
+		return fmt.Errorf("couldn't find podcast directory: %w", err)
+	}
+
+	res := podcastsResult{Episodes: []podcastEntry{}}
+	ids := make([]uuid.UUID, 0, len(episodes))
+	for _, episode := range episodes {
+		_, statErr := os.Stat(episodePath(dir, episode.FeedName, episode.ID, episode.Title, episode.EnclosureUrl.String, episode.EnclosureType.String))
+		res.Episodes = append(res.Episodes, podcastEntry{
+			ID:              episode.ID,
+			Title:           episode.Title,
+			FeedName:        episode.FeedName,
+			PublishedAt:     nullTimePtr(episode.PublishedAt),
+			EnclosureURL:    episode.EnclosureUrl.String,
+			EnclosureType:   episode.EnclosureType.String,
+			Size:            nullInt64Ptr(episode.EnclosureLength),
+			DurationSeconds: nullInt32Ptr(episode.DurationSeconds),
+			Downloaded:      statErr == nil,
+		})
+		ids = append(ids, episode.ID)
+	}
+
+	if err := saveLastBrowse(s, user, ids); err != nil {
+		return err
+	}
+
+	return s.emit(res)
+}
+
+// podcastEntry is one episode in the podcasts output
+type podcastEntry struct {
+	ID              uuid.UUID  `json:"id"`
+	Title           string     `json:"title"`
+	FeedName        string     `json:"feed_name"`
+	PublishedAt     *time.Time `json:"published_at"`
+	EnclosureURL    string     `json:"enclosure_url"`
+	EnclosureType   string     `json:"enclosure_type,omitempty"`
+	Size            *int64     `json:"size_bytes"`
+	DurationSeconds *int32     `json:"duration_seconds"`
+	Downloaded      bool       `json:"downloaded"`
+}
+
+// podcastsResult is the output of podcasts
+type podcastsResult struct {
+	Episodes []podcastEntry `json:"episodes"`
+}
+
+func (r podcastsResult) writeText(w io.Writer) {
+	if len(r.Episodes) == 0 {
+		fmt.Fprintln(w, "No podcast episodes found. Follow a podcast feed and run 'gator agg' to fetch episodes.")
+		return
+	}
+
+	for i, episode := range r.Episodes {
+		fmt.Fprintf(w, "[%d] %s (%s)\n", i+1, episode.Title, episode.FeedName)
+
+		var details []string
+		if episode.PublishedAt != nil {
+			details = append(details, episode.PublishedAt.Format("2006-01-02"))
+		}
+		if episode.DurationSeconds != nil {
+			details = append(details, formatEpisodeDuration(*episode.DurationSeconds))
+		}
+		if episode.Size != nil {
+			details = append(details, formatSize(*episode.Size))
+		}
+		if episode.Downloaded {
+			details = append(details, "downloaded")
+		}
+		if len(details) > 0 {
+			fmt.Fprintf(w, "    %s\n", strings.Join(details, " · "))
+		}
+	}
+	fmt.Fprintln(w, "\nDownload an episode with 'gator download <n>'")
+}
+
+func (r podcastsResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for i, episode := range r.Episodes {
+		duration, size := "", ""
+		if episode.DurationSeconds != nil {
+			duration = formatEpisodeDuration(*episode.DurationSeconds)
+		}
+		if episode.Size != nil {
+			size = strconv.FormatInt(*episode.Size, 10)
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1),
+			episode.ID.String(),
+			episode.FeedName,
+			episode.Title,
+			formatTime(episode.PublishedAt),
+			duration,
+			size,
+			strconv.FormatBool(episode.Downloaded),
+			episode.EnclosureURL,
+		})
+	}
+	return []string{"n", "id", "feed", "title", "published_at", "duration", "size_bytes", "downloaded", "enclosure_url"}, rows
+}
+
+// handlerDownload saves a post's audio enclosure to the podcast directory, resuming partial downloads
+func handlerDownload(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("download command requires a post number, ID, or URL argument")
+	}
+
+	post, err := getListedPost(s, user, cmd.args[0])
+	if err != nil {
+		return err
+	}
+	if !post.EnclosureUrl.Valid {
+		return fmt.Errorf("%s has no audio to download", post.Title)
+	}
+
+	feed, err := s.db.GetFeed(s.ctx, post.FeedID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed: %w", err)
+	}
+
+	dir, err := s.cfg.PodcastDirectory()
+	if err != nil {
+		return fmt.Errorf("couldn't find podcast directory: %w", err)
+	}
+	dest := episodePath(dir, feed.Name, post.ID, post.Title, post.EnclosureUrl.String, post.EnclosureType.String)
+
+	if info, err := os.Stat(dest); err == nil {
+		return s.emit(downloadResult{Title: post.Title, Path: dest, Bytes: info.Size(), AlreadyDownloaded: true})
+	}
+
+	// Ctrl-C stops the download cleanly, leaving the partial file to resume from
+	written, resumed, err := downloadEnclosure(s.ctx, post.EnclosureUrl.String, dest)
+	if err != nil {
+		return err
+	}
+
+	return s.emit(downloadResult{Title: post.Title, Path: dest, Bytes: written, Resumed: resumed})
+}
+
+// downloadResult is the output of download
+type downloadResult struct {
+	Title             string `json:"title"`
+	Path              string `json:"path"`
+	Bytes             int64  `json:"bytes"`
+	Resumed           bool   `json:"resumed"`
+	AlreadyDownloaded bool   `json:"already_downloaded"`
+}
+
+func (r downloadResult) writeText(w io.Writer) {
+	switch {
+	case r.AlreadyDownloaded:
+		fmt.Fprintf(w, "Already downloaded: %s\n", r.Path)
+	case r.Resumed:
+		fmt.Fprintf(w, "Resumed and finished %s (%s)\n", r.Path, formatSize(r.Bytes))
+	default:
+		fmt.Fprintf(w, "Downloaded %s (%s)\n", r.Path, formatSize(r.Bytes))
+	}
+}
+
+func (r downloadResult) table() ([]string, [][]string) {
+	return []string{"title", "path", "bytes", "resumed", "already_downloaded"}, [][]string{{
+		r.Title, r.Path, strconv.FormatInt(r.Bytes, 10), strconv.FormatBool(r.Resumed), strconv.FormatBool(r.AlreadyDownloaded),
+	}}
+}
+
+// downloadEnclosure fetches src into dest via dest.part, continuing from an existing partial
+// file with a Range request. It returns the final size and whether the download was resumed.
+func downloadEnclosure(ctx context.Context, src, dest string) (int64, bool, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return 0, false, fmt.Errorf("couldn't create podcast directory: %w", err)
+	}
+
+	part := dest + ".part"
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return 0, false, fmt.Errorf("couldn't download episode: %w", err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the range, so start over
+		flags |= os.O_TRUNC
+		offset = 0
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file already holds everything
+		return offset, true, os.Rename(part, dest)
+	default:
+		return 0, false, fmt.Errorf("couldn't download episode: unexpected status %s", resp.Status)
+	}
+
+	f, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return 0, false, err
+	}
+	written, copyErr := io.Copy(f, resp.Body)
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		return 0, false, fmt.Errorf("download stopped after %s; run download again to resume: %w", formatSize(offset+written), copyErr)
+	}
+
+	if err := os.Rename(part, dest); err != nil {
+		return 0, false, err
+	}
+	return offset + written, offset > 0, nil
+}
+
+// episodePath is where an episode is saved: <dir>/<feed name>/<episode title><ext>
+func episodePath(dir, feedName string, id uuid.UUID, title, enclosureURL, enclosureType string) string {
+	name := safeFileName(title)
+	if name == "" {
+		name = "episode-" + id.String()[:8]
+	}
+	return filepath.Join(dir, safeFileName(feedName), name+enclosureExt(enclosureURL, enclosureType))
+}
+
+// enclosureExt picks a file extension from the enclosure URL, falling back to its MIME type
+func enclosureExt(enclosureURL, enclosureType string) string {
+	if u, err := url.Parse(enclosureURL); err == nil {
+		if ext := path.Ext(u.Path); len(ext) > 1 && len(ext) <= 5 {
+			return strings.ToLower(ext)
+		}
+	}
+	if exts, err := mime.ExtensionsByType(enclosureType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// safeFileName replaces characters that aren't allowed in file names and caps the length
+func safeFileName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < ' ' || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, s)
+	s = strings.Trim(s, " .")
+	if runes := []rune(s); len(runes) > 100 {
+		s = strings.TrimSpace(string(runes[:100]))
+	}
+	return s
+}
+
+// formatEpisodeDuration renders seconds as H:MM:SS or M:SS
+func formatEpisodeDuration(seconds int32) string {
+	h, m, sec := seconds/3600, seconds/60%60, seconds%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+	}
+	return fmt.Sprintf("%d:%02d", m, sec)
+}
+
+// formatSize renders a byte count with a binary unit, e.g. 38.1 MB
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/Utkarsh736/gator/internal/pubdate"
+)
+
+// previewItem is one of the latest posts preview shows
+type previewItem struct {
+	Title       string     `json:"title"`
+	Link        string     `json:"link"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// previewResult is the output of preview
+type previewResult struct {
+	URL         string        `json:"url"`
+	Title       string        `json:"title"`
+	Link        string        `json:"link,omitempty"`
+	Description string        `json:"description,omitempty"`
+	ItemCount   int           `json:"item_count"`
+	Latest      []previewItem `json:"latest"`
+	// KnownAs is the name of the feed in gator, if someone has already added it
+	KnownAs string `json:"known_as,omitempty"`
+}
+
+func (r previewResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "%s\n", r.Title)
+	fmt.Fprintf(w, "  URL: %s\n", r.URL)
+	if r.Link != "" {
+		fmt.Fprintf(w, "  Site: %s\n", r.Link)
+	}
+	if r.Description != "" {
+		fmt.Fprintf(w, "  Description: %s\n", truncateText(r.Description, 200))
+	}
+	fmt.Fprintf(w, "  Posts in feed: %d\n", r.ItemCount)
+
+	if len(r.Latest) > 0 {
+		fmt.Fprintln(w, "Latest posts:")
+		for _, item := range r.Latest {
+			if item.PublishedAt != nil {
+				fmt.Fprintf(w, "* %s (%s)\n", item.Title, item.PublishedAt.Format("2006-01-02"))
+			} else {
+				fmt.Fprintf(w, "* %s\n", item.Title)
+			}
+			if item.Link != "" {
+				fmt.Fprintf(w, "  %s\n", item.Link)
+			}
+		}
+	}
+
+	if r.KnownAs != "" {
+		fmt.Fprintf(w, "Already in gator as %s; follow it with 'gator follow %s'\n", r.KnownAs, r.URL)
+	} else {
+		fmt.Fprintf(w, "Add it with 'gator addfeed <name> %s'\n", r.URL)
+	}
+}
+
+func (r previewResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, item := range r.Latest {
+		rows = append(rows, []string{item.Title, item.Link, formatTime(item.PublishedAt)})
+	}
+	return []string{"title", "link", "published_at"}, rows
+}
+
+// handlerPreview fetches a feed and shows what's in it without storing anything, so a feed can
+// be looked over before it's added
+func handlerPreview(s *state, cmd command) error {
+	var limit int
+	flags := newFlagSet("preview")
+	flags.IntVar(&limit, "limit", 5, "Show the latest `n` posts")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return errors.New("preview command requires a feed or site URL")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected preview argument %q", args[1])
+	}
+	if limit < 0 {
+		return errors.New("--limit can't be negative")
+	}
+
+	// Accept a site URL that advertises a feed, as addfeed does
+	url, err := resolveFeedURL(s.ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't find feed: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, feedFetchTimeout)
+	defer cancel()
+	rssFeed, _, err := fetchFeed(ctx, url)
+	if err != nil {
+		return fmt.Errorf("couldn't fetch feed: %w", err)
+	}
+
+	res := previewResult{
+		URL:         url,
+		Title:       rssFeed.Channel.Title,
+		Link:        rssFeed.Channel.Link,
+		Description: htmltext.RenderInline(rssFeed.Channel.Description),
+		ItemCount:   len(rssFeed.Channel.Item),
+		Latest:      []previewItem{},
+	}
+	if res.Title == "" {
+		res.Title = url
+	}
+	if rssFeed.MovedTo != "" {
+		res.URL = rssFeed.MovedTo
+	}
+	if feed, err := getFeedByURL(s.ctx, s.db, res.URL); err == nil {
+		res.KnownAs = feed.Name
+	}
+
+	for _, item := range rssFeed.Channel.Item {
+		entry := previewItem{Title: item.Title, Link: item.Link}
+		for _, raw := range []string{item.PubDate, item.DCDate} {
+			if t, err := pubdate.Parse(raw); raw != "" && err == nil {
+				entry.PublishedAt = &t
+				break
+			}
+		}
+		if entry.Title == "" {
+			entry.Title = item.ItunesTitle
+		}
+		res.Latest = append(res.Latest, entry)
+	}
+
+	// Most feeds list their newest posts first, but not all; undated posts keep their place after
+	// the dated ones
+	sort.SliceStable(res.Latest, func(i, j int) bool {
+		a, b := res.Latest[i].PublishedAt, res.Latest[j].PublishedAt
+		return a != nil && (b == nil || a.After(*b))
+	})
+	if len(res.Latest) > limit {
+		res.Latest = res.Latest[:limit]
+	}
+
+	return s.emit(res)
+}
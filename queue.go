@@ -0,0 +1,205 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// handlerQueue manages the current user's read-later queue: queue lists it, and queue
+// add|remove|pop change it. Unlike saved posts, the queue has an order and is worked through.
+func handlerQueue(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return handlerQueueList(s, user)
+	}
+
+	sub := command{name: cmd.args[0], args: cmd.args[1:]}
+	switch sub.name {
+	case "add":
+		return handlerQueueAdd(s, sub, user)
+	case "remove":
+		return handlerQueueRemove(s, sub, user)
+	case "pop":
+		return handlerQueuePop(s, sub, user)
+	default:
+		return fmt.Errorf("unknown queue subcommand: %s", sub.name)
+	}
+}
+
+// handlerQueueAdd puts a post at the end of the queue, or with --next at the front, moving it
+// there if it's already queued
+func handlerQueueAdd(s *state, cmd command, user database.User) error {
+	var next bool
+	flags := newFlagSet("queue add")
+	flags.BoolVar(&next, "next", false, "Put the post at the front of the queue")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return errors.New("queue add requires a post number, ID, or URL argument")
+	}
+
+	post, err := getListedPost(s, user, args[0])
+	if err != nil {
+		return err
+	}
+
+	n, err := s.db.QueuePost(s.ctx, database.QueuePostParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		PostID:    post.ID,
+		First:     next,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't queue post: %w", err)
+	}
+
+	message := fmt.Sprintf("Queued: %s", post.Title)
+	switch {
+	case n == 0:
+		message = fmt.Sprintf("Already queued: %s", post.Title)
+	case next:
+		message = fmt.Sprintf("Queued next: %s", post.Title)
+	}
+	return s.emit(messageResult{Message: message, Item: toAPIPost(post)})
+}
+
+// handlerQueueRemove takes a post out of the queue without marking it read. Numbers are
+// positions in the queue, as queue lists it.
+func handlerQueueRemove(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 1 {
+		return errors.New("queue remove requires a post number, ID, or URL argument")
+	}
+
+	post, err := getQueuedPost(s, user, cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	n, err := s.db.DequeuePost(s.ctx, database.DequeuePostParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't remove post from queue: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("post isn't in your queue: %s", post.Title)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Removed from queue: %s", post.Title),
+		Item:    toAPIPost(post),
+	})
+}
+
+// getQueuedPost finds a post by its number in the queue listing, or by ID or URL
+func getQueuedPost(s *state, user database.User, ref string) (database.Post, error) {
+	n, err := strconv.Atoi(ref)
+	if err != nil {
+		return getPostByIDOrURL(s, ref)
+	}
+
+	posts, err := s.db.GetQueuedPostsForUser(s.ctx, user.ID)
+	if err != nil {
+		return database.Post{}, fmt.Errorf("couldn't get queued posts: %w", err)
+	}
+	if n < 1 || n > len(posts) {
+		return database.Post{}, fmt.Errorf("no post %d in your queue", n)
+	}
+	return posts[n-1], nil
+}
+
+// handlerQueuePop takes the post at the front of the queue, marks it read and shows it in full,
+// as show does
+func handlerQueuePop(s *state, cmd command, user database.User) error {
+	if len(cmd.args) > 0 {
+		return fmt.Errorf("unexpected queue pop argument %q", cmd.args[0])
+	}
+
+	var postID uuid.UUID
+	err := s.inTx(s.ctx, func(q *database.Queries) error {
+		var err error
+		postID, err = q.PopQueuedPost(s.ctx, user.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("your queue is empty")
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't take post from queue: %w", err)
+		}
+
+		err = q.MarkPostRead(s.ctx, database.MarkPostReadParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    user.ID,
+			PostID:    postID,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't mark post as read: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	post, err := s.db.GetPostDetail(s.ctx, database.GetPostDetailParams{
+		UserID: user.ID,
+		ID:     postID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get post: %w", err)
+	}
+	res, err := toPostDetail(s.ctx, s.db, user.ID, post)
+	if err != nil {
+		return err
+	}
+	return s.emit(res)
+}
+
+// handlerQueueList lists the queue in the order pop takes it
+func handlerQueueList(s *state, user database.User) error {
+	posts, err := s.db.GetQueuedPostsForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get queued posts: %w", err)
+	}
+
+	return s.emit(queueResult{UserName: user.Name, Posts: toAPIPosts(posts)})
+}
+
+// queueResult is the output of queue
+type queueResult struct {
+	UserName string    `json:"user_name"`
+	Posts    []apiPost `json:"posts"`
+}
+
+func (r queueResult) writeText(w io.Writer) {
+	if len(r.Posts) == 0 {
+		fmt.Fprintln(w, "Your queue is empty")
+		return
+	}
+
+	fmt.Fprintf(w, "Queue for %s:\n", r.UserName)
+	for i, post := range r.Posts {
+		fmt.Fprintf(w, "%d. %s\n", i+1, post.Title)
+		fmt.Fprintf(w, "   URL: %s\n", post.Url)
+	}
+}
+
+func (r queueResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for i, post := range r.Posts {
+		rows = append(rows, []string{strconv.Itoa(i + 1), post.ID.String(), post.Title, post.Url})
+	}
+	return []string{"n", "id", "title", "url"}, rows
+}
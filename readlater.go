@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/config"
+	"github.com/Utkarsh736/gator/internal/database"
+)
+
+// Read-later services send can push posts to
+const (
+	readLaterInstapaper = "instapaper"
+	readLaterWallabag   = "wallabag"
+)
+
+const instapaperAddURL = "https://www.instapaper.com/api/add"
+
+var readLaterClient = &http.Client{Timeout: 15 * time.Second}
+
+// handlerSend pushes a post to a read-later service: send <post> --to <service>. With --auto it
+// instead makes every post saved from then on go to the service.
+func handlerSend(s *state, cmd command, user database.User) error {
+	var service string
+	var auto bool
+	flags := newFlagSet("send")
+	flags.StringVar(&service, "to", s.cfg.SendSavedTo, "Read-later `service`: instapaper or wallabag")
+	flags.BoolVar(&auto, "auto", false, "Send every post you save from now on, instead of one post")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	if service == "" {
+		return errors.New("send requires --to instapaper or --to wallabag")
+	}
+	if err := checkReadLaterService(s.cfg, service); err != nil {
+		return err
+	}
+
+	if auto {
+		if len(args) > 0 {
+			return fmt.Errorf("send --auto doesn't take a post, but was given %q", args[0])
+		}
+		if err := s.cfg.Set("send_saved_to", service); err != nil {
+			return err
+		}
+		if err := s.cfg.Save(); err != nil {
+			return fmt.Errorf("couldn't save config: %w", err)
+		}
+		return s.emit(messageResult{
+			Message: fmt.Sprintf("Posts you save will be sent to %s; run 'gator config unset send_saved_to' to stop", service),
+		})
+	}
+
+	if len(args) != 1 {
+		return errors.New("send command requires a post number, ID, or URL argument")
+	}
+	post, err := getListedPost(s, user, args[0])
+	if err != nil {
+		return err
+	}
+	if err := sendToReadLater(s.ctx, s.cfg, service, post); err != nil {
+		return err
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Sent to %s: %s", service, post.Title),
+		Item:    toAPIPost(post),
+	})
+}
+
+// forwardSavedPost sends a newly saved post on to the service in send_saved_to, if there is one,
+// and runs the post_saved hook. Saving has already succeeded, so a failure is only logged.
+func forwardSavedPost(ctx context.Context, cfg *config.Config, user database.User, post database.Post) {
+	for _, err := range sendSavedPost(ctx, cfg, user, post) {
+		slog.Warn("couldn't forward saved post", "post", post.Title, "error", err)
+	}
+}
+
+// sendSavedPost does what forwardSavedPost does, returning the failures for callers that can't log
+// them, like the TUI, whose screen logging would draw over
+func sendSavedPost(ctx context.Context, cfg *config.Config, user database.User, post database.Post) []error {
+	if cfg == nil {
+		return nil
+	}
+	var errs []error
+	if cfg.SendSavedTo != "" {
+		if err := sendToReadLater(ctx, cfg, cfg.SendSavedTo, post); err != nil {
+			errs = append(errs, fmt.Errorf("couldn't send to %s: %w", cfg.SendSavedTo, err))
+		}
+	}
+	if err := runSavedHook(ctx, cfg, user, post); err != nil {
+		errs = append(errs, fmt.Errorf("%s hook failed: %w", hookPostSaved, err))
+	}
+	return errs
+}
+
+// checkReadLaterService reports an unknown service, or one without credentials in the config
+func checkReadLaterService(cfg *config.Config, service string) error {
+	switch service {
+	case readLaterInstapaper:
+		if cfg.Instapaper == nil || cfg.Instapaper.Username == "" {
+			return errors.New("instapaper isn't set up; run 'gator config set instapaper.username <email>' and set instapaper.password if your account has one")
+		}
+	case readLaterWallabag:
+		w := cfg.Wallabag
+		if w == nil || w.URL == "" || w.ClientID == "" || w.ClientSecret == "" || w.Username == "" || w.Password == "" {
+			return errors.New("wallabag isn't set up; set wallabag.url, wallabag.client_id, wallabag.client_secret, wallabag.username and wallabag.password with 'gator config set'")
+		}
+	case "pocket":
+		return errors.New("pocket shut down in July 2025; use instapaper or wallabag")
+	default:
+		return fmt.Errorf("unknown read-later service %q (expected instapaper or wallabag)", service)
+	}
+	return nil
+}
+
+// sendToReadLater adds a post to a read-later service
+func sendToReadLater(ctx context.Context, cfg *config.Config, service string, post database.Post) error {
+	if err := checkReadLaterService(cfg, service); err != nil {
+		return err
+	}
+
+	var err error
+	switch service {
+	case readLaterInstapaper:
+		err = sendToInstapaper(ctx, cfg.Instapaper, post)
+	case readLaterWallabag:
+		err = sendToWallabag(ctx, cfg.Wallabag, post)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't send to %s: %w", service, err)
+	}
+	return nil
+}
+
+// sendToInstapaper adds a post with Instapaper's Simple API, which takes the account's
+// credentials with each request
+func sendToInstapaper(ctx context.Context, c *config.InstapaperConfig, post database.Post) error {
+	form := url.Values{"url": {post.Url}, "title": {post.Title}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, instapaperAddURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err := readLaterClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return nil
+	case http.StatusForbidden:
+		return errors.New("invalid username or password")
+	default:
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+}
+
+// sendToWallabag adds a post to a wallabag server, first getting an access token with the
+// account's password, as wallabag's API has clients do
+func sendToWallabag(ctx context.Context, c *config.WallabagConfig, post database.Post) error {
+	base := strings.TrimSuffix(c.URL, "/")
+
+	form := url.Values{
+		"grant_type":    {"password"},
+		"client_id":     {c.ClientID},
+		"client_secret": {c.ClientSecret},
+		"username":      {c.Username},
+		"password":      {c.Password},
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := wallabagPost(ctx, base+"/oauth/v2/token", "", form, &token); err != nil {
+		return fmt.Errorf("couldn't log in: %w", err)
+	}
+
+	form = url.Values{"url": {post.Url}, "title": {post.Title}}
+	return wallabagPost(ctx, base+"/api/entries.json", token.AccessToken, form, nil)
+}
+
+// wallabagPost posts a form to wallabag's API and decodes the JSON response into v, if non-nil
+func wallabagPost(ctx context.Context, endpoint, token string, form url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := readLaterClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	if v == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/config"
+)
+
+// readingWordsPerMinute is the reading speed reading time estimates assume
+const readingWordsPerMinute = 230
+
+// wordCount counts the words of a post's extracted content, to be stored alongside it
+func wordCount(content string) sql.NullInt32 {
+	return sql.NullInt32{Int32: int32(len(strings.Fields(content))), Valid: true}
+}
+
+// readingMinutes estimates how long a post of words takes to read, rounded up to a whole minute.
+// Posts without extracted content have no estimate.
+func readingMinutes(words sql.NullInt32) *int32 {
+	if !words.Valid || words.Int32 <= 0 {
+		return nil
+	}
+	minutes := (words.Int32 + readingWordsPerMinute - 1) / readingWordsPerMinute
+	return &minutes
+}
+
+// maxReadWords turns a --max-read-time duration into the most words a post can have to be read
+// in that time
+func maxReadWords(value string) (sql.NullInt32, error) {
+	d, err := config.ParseDuration(value)
+	if err != nil {
+		return sql.NullInt32{}, err
+	}
+	if d < time.Minute {
+		return sql.NullInt32{}, fmt.Errorf("invalid --max-read-time %q: must be at least 1m", value)
+	}
+	// Long enough durations allow any post, so the count is capped rather than overflowing
+	words := min(int64(d/time.Minute)*readingWordsPerMinute, math.MaxInt32)
+	return sql.NullInt32{Int32: int32(words), Valid: true}, nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+// recommendPostsScanned is how many of the posts a user most recently read or saved recommend
+// looks through for links
+const recommendPostsScanned = 500
+
+// unrecommendedHosts are sites linked to so often, for reasons other than their writing, that
+// suggesting them as sources isn't useful
+var unrecommendedHosts = wordSet(`
+	twitter.com x.com t.co facebook.com instagram.com linkedin.com youtube.com youtu.be
+	reddit.com news.ycombinator.com bit.ly goo.gl amzn.to amazon.com web.archive.org
+	archive.org wikipedia.org en.wikipedia.org mastodon.social bsky.app t.me
+`)
+
+// similarFeed is a feed followed by users whose feeds overlap with the current user's. Score
+// adds up how similar each of those users is, counting the feeds the current user reads and
+// saves from most.
+type similarFeed struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Url       string    `json:"url"`
+	Followers int64     `json:"followers"`
+	Score     float64   `json:"score"`
+}
+
+// citedSource is a site the posts a user read or saved link to, and the feed gator has for it,
+// if any
+type citedSource struct {
+	Host     string  `json:"host"`
+	Posts    int     `json:"posts"`
+	FeedName *string `json:"feed_name,omitempty"`
+	FeedURL  *string `json:"feed_url,omitempty"`
+}
+
+// recommendResult is the output of recommend
+type recommendResult struct {
+	Similar []similarFeed `json:"similar"`
+	Cited   []citedSource `json:"cited"`
+}
+
+func (r recommendResult) writeText(w io.Writer) {
+	if len(r.Similar) == 0 && len(r.Cited) == 0 {
+		fmt.Fprintln(w, "Nothing to recommend yet. Follow and read a few more feeds first.")
+		return
+	}
+
+	if len(r.Similar) > 0 {
+		fmt.Fprintln(w, "Followed by users who follow what you do:")
+		for _, feed := range r.Similar {
+			fmt.Fprintf(w, "* %s (%d followers)\n", feed.Name, feed.Followers)
+			fmt.Fprintf(w, "  gator follow %s\n", feed.Url)
+		}
+	}
+
+	if len(r.Cited) > 0 {
+		if len(r.Similar) > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, "Often linked from posts you read:")
+		for _, source := range r.Cited {
+			fmt.Fprintf(w, "* %s (linked from %d posts)\n", source.Host, source.Posts)
+			if source.FeedURL != nil {
+				fmt.Fprintf(w, "  gator follow %s\n", *source.FeedURL)
+			} else {
+				fmt.Fprintf(w, "  gator preview https://%s\n", source.Host)
+			}
+		}
+	}
+}
+
+func (r recommendResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, feed := range r.Similar {
+		rows = append(rows, []string{"similar_users", feed.Name, feed.Url, strconv.FormatInt(feed.Followers, 10)})
+	}
+	for _, source := range r.Cited {
+		feedURL := ""
+		if source.FeedURL != nil {
+			feedURL = *source.FeedURL
+		}
+		rows = append(rows, []string{"cited", source.Host, feedURL, strconv.Itoa(source.Posts)})
+	}
+	return []string{"reason", "name", "url", "count"}, rows
+}
+
+// handlerRecommend suggests feeds to follow: those followed by users who follow the same feeds,
+// and the sites most linked to from the posts the user reads and saves
+func handlerRecommend(s *state, cmd command, user database.User) error {
+	var limit int
+	flags := newFlagSet("recommend")
+	flags.IntVar(&limit, "limit", 5, "Suggest at most `n` feeds of each kind")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected recommend argument %q", args[0])
+	}
+	if limit < 1 {
+		return errors.New("--limit must be at least 1")
+	}
+
+	similar, err := s.db.GetFeedsFollowedBySimilarUsers(s.ctx, database.GetFeedsFollowedBySimilarUsersParams{
+		UserID:      user.ID,
+		ResultLimit: int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get feeds of similar users: %w", err)
+	}
+	res := recommendResult{Similar: []similarFeed{}}
+	for _, feed := range similar {
+		res.Similar = append(res.Similar, similarFeed{
+			ID:        feed.ID,
+			Name:      feed.Name,
+			Url:       feed.Url,
+			Followers: feed.Followers,
+			Score:     feed.Score,
+		})
+	}
+
+	res.Cited, err = citedSources(s, user, limit)
+	if err != nil {
+		return err
+	}
+	return s.emit(res)
+}
+
+// citedSources counts the sites linked to from the posts user most recently read or saved, by
+// how many posts link to each, leaving out the sites of the feeds they already follow and the
+// site a post itself is on
+func citedSources(s *state, user database.User, limit int) ([]citedSource, error) {
+	feeds, err := s.db.GetFeeds(s.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get feeds: %w", err)
+	}
+	follows, err := s.db.GetFeedFollowsForUser(s.ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get feed follows: %w", err)
+	}
+	followed := map[uuid.UUID]bool{}
+	for _, follow := range follows {
+		followed[follow.FeedID] = true
+	}
+
+	// A feed stands for the site it's served from and the one it links to
+	known := map[string]database.GetFeedsRow{}
+	skip := map[string]bool{}
+	for _, feed := range feeds {
+		for _, link := range []string{feed.Url, feed.ChannelLink.String} {
+			host := linkHost(link)
+			if host == "" {
+				continue
+			}
+			if followed[feed.ID] {
+				skip[host] = true
+			}
+			if _, ok := known[host]; !ok {
+				known[host] = feed
+			}
+		}
+	}
+
+	posts, err := s.db.GetEngagedPostsForUser(s.ctx, database.GetEngagedPostsForUserParams{
+		UserID:      user.ID,
+		ResultLimit: recommendPostsScanned,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get read posts: %w", err)
+	}
+
+	counts := map[string]int{}
+	for _, post := range posts {
+		own := linkHost(post.Url)
+		cited := map[string]bool{}
+		for _, link := range htmltext.Links(post.Description.String + post.Content.String) {
+			host := linkHost(link)
+			if host == "" || host == own || skip[host] || unrecommendedHosts[host] {
+				continue
+			}
+			cited[host] = true
+		}
+		for host := range cited {
+			counts[host]++
+		}
+	}
+
+	sources := []citedSource{}
+	for host, n := range counts {
+		// A site linked to once is a passing reference, not a source
+		if n < 2 {
+			continue
+		}
+		source := citedSource{Host: host, Posts: n}
+		if feed, ok := known[host]; ok {
+			source.FeedName, source.FeedURL = &feed.Name, &feed.Url
+		}
+		sources = append(sources, source)
+	}
+	slices.SortFunc(sources, func(a, b citedSource) int {
+		return cmp.Or(cmp.Compare(b.Posts, a.Posts), cmp.Compare(a.Host, b.Host))
+	})
+	if len(sources) > limit {
+		sources = sources[:limit]
+	}
+	return sources, nil
+}
+
+// linkHost returns the host of an absolute http(s) link, lower-cased and without a leading www.,
+// or "" for anything else
+func linkHost(link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// riverFeedLimit is how many posts a generated feed carries unless ?limit= asks for another number
+const riverFeedLimit = 50
+
+// riverRSS is the RSS 2.0 document served for a user's followed posts
+type riverRSS struct {
+	XMLName xml.Name     `xml:"rss"`
+	Version string       `xml:"version,attr"`
+	Atom    string       `xml:"xmlns:atom,attr"`
+	Channel riverChannel `xml:"channel"`
+}
+
+type riverChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Self          riverLink   `xml:"atom:link"`
+	Description   string      `xml:"description"`
+	LastBuildDate string      `xml:"lastBuildDate"`
+	Generator     string      `xml:"generator"`
+	Items         []riverItem `xml:"item"`
+}
+
+type riverLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type riverItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        riverGUID     `xml:"guid"`
+	Description string        `xml:"description,omitempty"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	Source      *riverSource  `xml:"source"`
+	Enclosure   *RSSEnclosure `xml:"enclosure"`
+}
+
+type riverGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// riverSource names the feed an item came from, as RSS's source element does
+type riverSource struct {
+	URL  string `xml:"url,attr"`
+	Name string `xml:",chardata"`
+}
+
+// riverFeedToken lets feed readers, which can't set headers, authenticate with ?token=<token>
+// in the feed's URL, passing it on as a bearer token
+func riverFeedToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		next(w, r)
+	}
+}
+
+// handleRiverFeed serves the posts from the feeds a user follows as one RSS feed, newest first:
+// /feeds/{user}/all.xml for every followed feed, /feeds/{user}/{category}.xml for one category
+func (api *apiServer) handleRiverFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	if r.PathValue("user") != user.Name {
+		respondWithError(w, http.StatusForbidden, "feeds can only be read by their own user")
+		return
+	}
+	name, ok := strings.CutSuffix(r.PathValue("file"), ".xml")
+	if !ok || name == "" {
+		respondWithError(w, http.StatusNotFound, "feed not found; use all.xml or <category>.xml")
+		return
+	}
+
+	limit, ok := queryLimit(w, r, riverFeedLimit)
+	if !ok {
+		return
+	}
+
+	params := database.GetPostsForUserParams{
+		UserID:   user.ID,
+		SortBy:   "published",
+		SortDesc: true,
+		Limit:    int32(limit),
+	}
+	title := fmt.Sprintf("gator: %s", user.Name)
+	if name != "all" {
+		category, err := api.db.GetCategoryByName(r.Context(), database.GetCategoryByNameParams{
+			UserID: user.ID,
+			Name:   name,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, "category not found")
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "couldn't get category")
+			return
+		}
+		params.CategoryID = uuid.NullUUID{UUID: category.ID, Valid: true}
+		title = fmt.Sprintf("gator: %s / %s", user.Name, category.Name)
+	}
+
+	posts, err := api.db.GetPostsForUser(r.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get posts")
+		return
+	}
+	filters, err := loadFilters(r.Context(), api.db, user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get filters")
+		return
+	}
+	feeds, err := api.feedsByID(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get feeds")
+		return
+	}
+
+	self := requestURL(r)
+	doc := riverRSS{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: riverChannel{
+			Title:         title,
+			Link:          self,
+			Self:          riverLink{Href: self, Rel: "self", Type: "application/rss+xml"},
+			Description:   fmt.Sprintf("Posts from the feeds %s follows", user.Name),
+			LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+			Generator:     "gator",
+			Items:         []riverItem{},
+		},
+	}
+	for _, post := range filters.posts(posts) {
+		var source *riverSource
+		if feed, ok := feeds[post.FeedID]; ok {
+			source = &riverSource{URL: feed.Url, Name: feed.Name}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, toRiverItem(post, source))
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		slog.Error("couldn't marshal feed", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+}
+
+// toRiverItem converts a post to an RSS item. The feed's HTML description is used when there is
+// one, and otherwise the extracted article text.
+func toRiverItem(post database.Post, source *riverSource) riverItem {
+	item := riverItem{
+		Title:  post.Title,
+		Link:   post.Url,
+		GUID:   riverGUID{Value: post.ID.String()},
+		Source: source,
+	}
+	switch {
+	case post.Description.Valid:
+		item.Description = post.Description.String
+	case post.Content.Valid:
+		item.Description = post.Content.String
+	}
+	if post.PublishedAt.Valid {
+		item.PubDate = post.PublishedAt.Time.UTC().Format(time.RFC1123Z)
+	}
+	if post.EnclosureUrl.Valid {
+		item.Enclosure = &RSSEnclosure{
+			URL:    post.EnclosureUrl.String,
+			Type:   post.EnclosureType.String,
+			Length: strconv.FormatInt(post.EnclosureLength.Int64, 10),
+		}
+	}
+	return item
+}
+
+// requestURL rebuilds the URL a request was made to, without its query so tokens aren't echoed
+// back into the feed
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
@@ -1,58 +1,184 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/xml"
+	"errors"
+	"fmt"
 	"html"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/charset"
+	"github.com/Utkarsh736/gator/internal/schedule"
 )
 
 type RSSFeed struct {
+	// MovedTo is where the feed was permanently redirected to, if it was; it isn't part of the document
+	MovedTo string `xml:"-"`
+	// Base is an xml:base on the rss element, which relative links are resolved against
+	Base    string `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		Base          string    `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+		AtomLinks     []RSSLink `xml:"http://www.w3.org/2005/Atom link"`
+		Title         string    `xml:"title"`
+		Link          string    `xml:"link"`
+		Description   string    `xml:"description"`
+		Language      string    `xml:"language"`
+		PubDate       string    `xml:"pubDate"`
+		LastBuildDate string    `xml:"lastBuildDate"`
+		Item          []RSSItem `xml:"item"`
 	} `xml:"channel"`
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	// Declared ahead of Title so itunes:title doesn't overwrite the item's own title
+	ItunesTitle string        `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd title"`
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        RSSGUID       `xml:"guid"`
+	Description string        `xml:"description"`
+	PubDate     string        `xml:"pubDate"`
+	DCDate      string        `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Author      string        `xml:"author"`
+	DCCreator   string        `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string      `xml:"category"`
+	Enclosure   *RSSEnclosure `xml:"enclosure"`
+	Duration    string        `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	Summary     string        `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd summary"`
+	ItunesImage *struct {
+		Href string `xml:"href,attr"`
+	} `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	MediaContent   []RSSMedia `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaThumbnail []RSSMedia `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	MediaGroup     []struct {
+		Content   []RSSMedia `xml:"http://search.yahoo.com/mrss/ content"`
+		Thumbnail []RSSMedia `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	} `xml:"http://search.yahoo.com/mrss/ group"`
+}
+
+// RSSGUID is an item's guid. Unless IsPermaLink is "false", it's also the item's address.
+type RSSGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink string `xml:"isPermaLink,attr"`
+}
+
+// RSSLink is an atom:link in an RSS channel, such as the feed's own address with rel="self"
+type RSSLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+// itemGUID identifies an item within its feed: its guid, or its link when it has none
+func itemGUID(item RSSItem) string {
+	if guid := strings.TrimSpace(item.GUID.Value); guid != "" {
+		return guid
+	}
+	return item.Link
+}
+
+// itemAuthor is who wrote an item: its dc:creator, which is usually a name, or else its author,
+// which RSS has as an email address
+func itemAuthor(item RSSItem) string {
+	if author := strings.TrimSpace(item.DCCreator); author != "" {
+		return author
+	}
+	return strings.TrimSpace(item.Author)
+}
+
+// itemCategories is the distinct, non-empty categories an item is filed under
+func itemCategories(item RSSItem) []string {
+	categories := []string{}
+	for _, category := range item.Categories {
+		category = strings.TrimSpace(html.UnescapeString(category))
+		if category != "" && !slices.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+	return categories
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+// RSSMedia is a Media RSS content or thumbnail element. Sizes are kept as text because
+// feeds don't always fill them in with numbers.
+type RSSMedia struct {
+	URL    string `xml:"url,attr"`
+	Medium string `xml:"medium,attr"`
+	Type   string `xml:"type,attr"`
+	Width  string `xml:"width,attr"`
+	Height string `xml:"height,attr"`
+}
+
+// RSSEnclosure is an item's attached media file, such as a podcast episode
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// maxFetchRetryDelay caps the backoff between retries of a failed fetch
+const maxFetchRetryDelay = time.Minute
+
+// feedFetchTimeout bounds one fetch of a feed, retries included, so a server that trickles its
+// response can't hold up a pass
+const feedFetchTimeout = 5 * time.Minute
+
+// fetchFeed downloads and parses a feed. It also returns the HTTP status code, or 0 if no
+// response was received, so callers can record how the fetch went.
+func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, int, error) {
 	// Create HTTP request with context
 	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	// Set User-Agent header
-	req.Header.Set("User-Agent", "gator")
-
 	// Execute the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
 	// Read response body
-	data, err := io.ReadAll(resp.Body)
+	data, err := readResponse(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, resp.StatusCode, err
+	}
+
+	// Transcode feeds in legacy encodings to UTF-8
+	raw := data
+	data, err = charset.DecodeXML(data, resp.Header.Get("Content-Type"))
+	if err != nil {
+		dumpFeedBody(redactURL(resp.Request.URL), raw, err)
+		return nil, resp.StatusCode, err
 	}
 
 	// Unmarshal XML into RSSFeed struct
 	var feed RSSFeed
-	err = xml.Unmarshal(data, &feed)
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// The document is UTF-8 by now, whatever its declaration says
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	err = decoder.Decode(&feed)
 	if err != nil {
-		return nil, err
+		dumpFeedBody(redactURL(resp.Request.URL), raw, err)
+		return nil, resp.StatusCode, err
+	}
+
+	if movedTo, ok := permanentRedirect(resp); ok && movedTo != feedURL {
+		feed.MovedTo = movedTo
 	}
 
 	// Unescape HTML entities in channel fields
@@ -63,8 +189,122 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 	for i := range feed.Channel.Item {
 		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
 		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
+		feed.Channel.Item[i].Summary = html.UnescapeString(feed.Channel.Item[i].Summary)
+	}
+	feed.resolveLinks(resp.Request.URL)
+
+	return &feed, resp.StatusCode, nil
+}
+
+// resolveLinks makes the channel's and items' links absolute. Relative links are resolved
+// against the feed's xml:base, or else the address it declares as its own with atom:link
+// rel="self", or else the address it was fetched from. An item without a link uses its guid when
+// that's a permalink. Links that still aren't absolute http(s) URLs are left empty.
+func (f *RSSFeed) resolveLinks(fetched *url.URL) {
+	base := fetched
+	for _, link := range f.Channel.AtomLinks {
+		if link.Rel == "self" {
+			if self, err := base.Parse(strings.TrimSpace(link.Href)); err == nil {
+				base = self
+			}
+			break
+		}
+	}
+	for _, xmlBase := range []string{f.Base, f.Channel.Base} {
+		if xmlBase = strings.TrimSpace(xmlBase); xmlBase != "" {
+			if u, err := base.Parse(xmlBase); err == nil {
+				base = u
+			}
+		}
+	}
+
+	f.Channel.Link = resolveLink(base, f.Channel.Link)
+	for i := range f.Channel.Item {
+		item := &f.Channel.Item[i]
+		link := item.Link
+		if strings.TrimSpace(link) == "" && item.GUID.IsPermaLink != "false" {
+			link = item.GUID.Value
+		}
+		item.Link = resolveLink(base, link)
 	}
+}
 
-	return &feed, nil
+// resolveLink resolves link against base, returning "" unless the result is an http(s) URL
+func resolveLink(base *url.URL, link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+	u, err := base.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return u.String()
 }
 
+// permanentRedirect returns the URL a response was finally fetched from if every redirect on
+// the way there was permanent (301 or 308). A temporary redirect anywhere means the original
+// URL should be kept.
+func permanentRedirect(resp *http.Response) (string, bool) {
+	if resp.Request.Response == nil {
+		return "", false
+	}
+	for r := resp.Request.Response; r != nil; r = r.Request.Response {
+		if r.StatusCode != http.StatusMovedPermanently && r.StatusCode != http.StatusPermanentRedirect {
+			return "", false
+		}
+	}
+	return resp.Request.URL.String(), true
+}
+
+// fetchFeedWithRetry is fetchFeed, retried up to retries times after transient failures with
+// exponential backoff starting at delay
+func fetchFeedWithRetry(ctx context.Context, feedURL string, retries int, delay time.Duration) (*RSSFeed, int, error) {
+	for attempt := 1; ; attempt++ {
+		feed, status, err := fetchFeed(ctx, feedURL)
+		if err == nil || attempt > retries || !isTransientFetchError(status, err) {
+			return feed, status, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, status, err
+		case <-time.After(schedule.Backoff(attempt, delay, maxFetchRetryDelay)):
+		}
+	}
+}
+
+// isTransientFetchError reports whether a failed fetch is worth retrying: server errors, rate
+// limiting, timeouts and dropped connections. Bad URLs, 404s and invalid XML won't fix themselves.
+func isTransientFetchError(status int, err error) bool {
+	switch {
+	case status >= 500, status == http.StatusTooManyRequests, status == http.StatusRequestTimeout:
+		return true
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	case status == 0 && errors.Is(err, io.EOF):
+		// The server closed the connection before responding
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// parseItunesDuration reads an itunes:duration, given as seconds, MM:SS or HH:MM:SS
+func parseItunesDuration(s string) (int32, bool) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) > 3 {
+		return 0, false
+	}
+
+	total := 0
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		total = total*60 + n
+	}
+	return int32(total), true
+}
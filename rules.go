@@ -0,0 +1,292 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/Utkarsh736/gator/internal/rules"
+	"github.com/google/uuid"
+)
+
+// Rule actions: tag labels matching posts, mark_read marks them read, drop keeps them out of
+// the database and notify raises a desktop notification
+const (
+	ruleActionTag      = "tag"
+	ruleActionMarkRead = "mark_read"
+	ruleActionDrop     = "drop"
+	ruleActionNotify   = "notify"
+)
+
+// ingestRule is a rule of a user who follows or added a feed, ready to match its new posts
+type ingestRule struct {
+	database.GetRulesForFeedRow
+	expr rules.Expr
+}
+
+// loadIngestRules gets the rules that apply to a feed's new posts. Expressions are checked when
+// rules are added, so one that doesn't parse is skipped rather than failing the scrape.
+func loadIngestRules(s *state, feed database.Feed) []ingestRule {
+	rows, err := s.db.GetRulesForFeed(s.ctx, feed.ID)
+	if err != nil {
+		slog.Warn("couldn't get rules", "feed", feed.Name, "error", err)
+		return nil
+	}
+
+	var loaded []ingestRule
+	for _, row := range rows {
+		if expr, err := rules.Parse(row.Expression); err == nil {
+			loaded = append(loaded, ingestRule{GetRulesForFeedRow: row, expr: expr})
+		}
+	}
+	return loaded
+}
+
+// rulePost is what rules see of a feed item about to be saved as post
+func rulePost(feed database.Feed, item RSSItem, post database.Post) rules.Post {
+	return rules.Post{
+		Title:       post.Title,
+		Description: htmltext.RenderInline(post.Description.String),
+		URL:         post.Url,
+		Feed:        feed.Name,
+		Author:      post.Author.String,
+		Categories:  itemCategories(item),
+	}
+}
+
+// dropsPost reports whether a drop rule keeps a post out of the database. Dropping a post hides
+// it from everyone, so only the drop rules of the feed's owner count, as with --ingest filters.
+func dropsPost(ingestRules []ingestRule, post rules.Post) bool {
+	for _, rule := range ingestRules {
+		if rule.Action == ruleActionDrop && rule.OwnsFeed && rule.expr.Match(post) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRules runs the tag, mark_read and notify rules that match a newly saved post. Saving
+// has already succeeded, so a failure is only logged.
+func applyRules(s *state, ingestRules []ingestRule, feed database.Feed, post database.Post, matched rules.Post) {
+	for _, rule := range ingestRules {
+		if rule.Action == ruleActionDrop || !rule.expr.Match(matched) {
+			continue
+		}
+
+		var err error
+		switch rule.Action {
+		case ruleActionTag:
+			err = tagPostByRule(s, rule, post)
+		case ruleActionMarkRead:
+			err = s.db.MarkPostRead(s.ctx, database.MarkPostReadParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				UserID:    rule.UserID,
+				PostID:    post.ID,
+			})
+		case ruleActionNotify:
+			// Notifications show on the machine running agg, so only its own user's rules raise them
+			if rule.UserName == s.cfg.CurrentUserName {
+				err = sendDesktopNotification(feed.Name, htmltext.RenderInline(post.Title))
+			}
+		}
+		if err != nil {
+			slog.Warn("couldn't apply rule", "rule", rule.ID, "post", post.Title, "error", err)
+		}
+	}
+}
+
+// tagPostByRule labels post with a tag rule's tag, creating the tag if needed
+func tagPostByRule(s *state, rule ingestRule, post database.Post) error {
+	tag, err := s.db.UpsertTag(s.ctx, database.UpsertTagParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    rule.UserID,
+		Name:      rule.Tag.String,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create tag: %w", err)
+	}
+
+	return s.db.TagPost(s.ctx, database.TagPostParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		TagID:     tag.ID,
+		PostID:    post.ID,
+	})
+}
+
+// handlerRule manages ingest rules: rule add|list|remove
+func handlerRule(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("rule command requires a subcommand: add, list, remove")
+	}
+
+	sub := command{name: cmd.args[0], args: cmd.args[1:]}
+	switch sub.name {
+	case "add":
+		return handlerRuleAdd(s, sub, user)
+	case "list":
+		return handlerRuleList(s, sub, user)
+	case "remove":
+		return handlerRuleRemove(s, sub, user)
+	default:
+		return fmt.Errorf("unknown rule subcommand: %s", sub.name)
+	}
+}
+
+// handlerRuleAdd adds a rule that tags, marks read, drops or notifies about new posts matching
+// an expression
+func handlerRuleAdd(s *state, cmd command, user database.User) error {
+	var action, tag string
+	setAction := func(a string) error {
+		if action != "" {
+			return errors.New("a rule takes one of --tag, --mark-read, --drop or --notify")
+		}
+		action = a
+		return nil
+	}
+	flags := newFlagSet("rule add")
+	flags.Func("tag", "Tag matching posts with `name`", func(value string) error {
+		tag = strings.TrimSpace(value)
+		return setAction(ruleActionTag)
+	})
+	flags.BoolFunc("mark-read", "Mark matching posts read", func(string) error {
+		return setAction(ruleActionMarkRead)
+	})
+	flags.BoolFunc("drop", "Keep matching posts out of the database (feeds you added only)", func(string) error {
+		return setAction(ruleActionDrop)
+	})
+	flags.BoolFunc("notify", "Raise a desktop notification for matching posts while agg runs", func(string) error {
+		return setAction(ruleActionNotify)
+	})
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return errors.New("rule add requires one expression argument, e.g. 'title contains \"rust\"'")
+	}
+	if action == "" {
+		return errors.New("rule add requires --tag <name>, --mark-read, --drop or --notify")
+	}
+	if action == ruleActionTag && tag == "" {
+		return errors.New("tag name can't be empty")
+	}
+	if _, err := rules.Parse(args[0]); err != nil {
+		return fmt.Errorf("invalid rule: %w", err)
+	}
+
+	rule, err := s.db.CreateRule(s.ctx, database.CreateRuleParams{
+		ID:         uuid.New(),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+		UserID:     user.ID,
+		Expression: args[0],
+		Action:     action,
+		Tag:        sql.NullString{String: tag, Valid: tag != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create rule: %w", err)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Rule created: %s", rule.ID),
+		Item:    toRuleEntry(rule),
+	})
+}
+
+// handlerRuleList lists the current user's rules
+func handlerRuleList(s *state, cmd command, user database.User) error {
+	rows, err := s.db.GetRulesForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get rules: %w", err)
+	}
+
+	res := rulesResult{Rules: []ruleEntry{}}
+	for _, row := range rows {
+		res.Rules = append(res.Rules, toRuleEntry(row))
+	}
+	return s.emit(res)
+}
+
+// handlerRuleRemove deletes one of the current user's rules by ID
+func handlerRuleRemove(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("rule remove requires an ID argument")
+	}
+
+	id, err := uuid.Parse(cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("invalid rule ID %q", cmd.args[0])
+	}
+
+	n, err := s.db.DeleteRule(s.ctx, database.DeleteRuleParams{
+		ID:     id,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't delete rule: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("rule %s doesn't exist", id)
+	}
+
+	return s.emit(messageResult{Message: fmt.Sprintf("Rule removed: %s", id)})
+}
+
+func toRuleEntry(rule database.Rule) ruleEntry {
+	return ruleEntry{
+		ID:         rule.ID,
+		Expression: rule.Expression,
+		Action:     rule.Action,
+		Tag:        rule.Tag.String,
+	}
+}
+
+// ruleEntry is a rule in the rule listing; Tag is only set for tag rules
+type ruleEntry struct {
+	ID         uuid.UUID `json:"id"`
+	Expression string    `json:"expression"`
+	Action     string    `json:"action"`
+	Tag        string    `json:"tag,omitempty"`
+}
+
+// rulesResult is the output of rule list
+type rulesResult struct {
+	Rules []ruleEntry `json:"rules"`
+}
+
+func (r rulesResult) writeText(w io.Writer) {
+	if len(r.Rules) == 0 {
+		fmt.Fprintln(w, "No rules found")
+		return
+	}
+
+	for _, rule := range r.Rules {
+		action := rule.Action
+		if rule.Tag != "" {
+			action = fmt.Sprintf("%s %s", rule.Action, rule.Tag)
+		}
+		fmt.Fprintf(w, "* %s\n", rule.ID)
+		fmt.Fprintf(w, "  When: %s\n", rule.Expression)
+		fmt.Fprintf(w, "  Then: %s\n", action)
+	}
+}
+
+func (r rulesResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, rule := range r.Rules {
+		rows = append(rows, []string{rule.ID.String(), rule.Expression, rule.Action, rule.Tag})
+	}
+	return []string{"id", "expression", "action", "tag"}, rows
+}
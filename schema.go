@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"strconv"
+
+	"github.com/Utkarsh736/gator/internal/migrate"
+)
+
+//go:embed sql/schema/*.sql
+var schemaFiles embed.FS
+
+// loadMigrations returns the migrations embedded in the binary
+func loadMigrations() ([]migrate.Migration, error) {
+	schemaDir, err := fs.Sub(schemaFiles, "sql/schema")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.Load(schemaDir)
+}
+
+// warnIfSchemaOutdated prints a warning when the database is behind the embedded migrations
+func warnIfSchemaOutdated(ctx context.Context, db *sql.DB) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		slog.Warn("couldn't load migrations", "error", err)
+		return
+	}
+
+	current, err := migrate.CurrentVersion(ctx, db)
+	if err != nil {
+		slog.Warn("couldn't check schema version", "error", err)
+		return
+	}
+
+	if latest := migrate.Latest(migrations); current < latest {
+		slog.Warn("database schema is out of date; run 'gator migrate' to update", "version", current, "latest", latest)
+	}
+}
+
+// handlerMigrate applies, rolls back, or reports on schema migrations
+func handlerMigrate(s *state, cmd command) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return fmt.Errorf("couldn't load migrations: %w", err)
+	}
+
+	action := "up"
+	if len(cmd.args) > 0 {
+		action = cmd.args[0]
+	}
+
+	res := migrateResult{Action: action, Migrations: []migrationEntry{}}
+	switch action {
+	case "up":
+		ran, err := migrate.Up(s.ctx, s.conn, migrations)
+		for _, m := range ran {
+			res.Migrations = append(res.Migrations, migrationEntry{Version: m.Version, Name: m.Name, Status: "applied"})
+		}
+		if err != nil {
+			if emitErr := s.emit(res); emitErr != nil {
+				return emitErr
+			}
+			return err
+		}
+	case "down":
+		m, err := migrate.Down(s.ctx, s.conn, migrations)
+		if err != nil {
+			return err
+		}
+		if m != nil {
+			res.Migrations = append(res.Migrations, migrationEntry{Version: m.Version, Name: m.Name, Status: "rolled back"})
+		}
+	case "status":
+		current, err := migrate.CurrentVersion(s.ctx, s.conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			status := "pending"
+			if m.Version <= current {
+				status = "applied"
+			}
+			res.Migrations = append(res.Migrations, migrationEntry{Version: m.Version, Name: m.Name, Status: status})
+		}
+	default:
+		return fmt.Errorf("unknown migrate action %q: expected up, down, or status", action)
+	}
+
+	return s.emit(res)
+}
+
+// migrationEntry is one migration touched or listed by migrate
+type migrationEntry struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+}
+
+// migrateResult is the output of migrate
+type migrateResult struct {
+	Action     string           `json:"action"`
+	Migrations []migrationEntry `json:"migrations"`
+}
+
+func (r migrateResult) writeText(w io.Writer) {
+	switch r.Action {
+	case "up":
+		for _, m := range r.Migrations {
+			fmt.Fprintf(w, "Applied %s\n", m.Name)
+		}
+		if len(r.Migrations) == 0 {
+			fmt.Fprintln(w, "Database schema is up to date")
+		}
+	case "down":
+		if len(r.Migrations) == 0 {
+			fmt.Fprintln(w, "No migrations to roll back")
+			return
+		}
+		fmt.Fprintf(w, "Rolled back %s\n", r.Migrations[0].Name)
+	case "status":
+		for _, m := range r.Migrations {
+			fmt.Fprintf(w, "* %-40s %s\n", m.Name, m.Status)
+		}
+	}
+}
+
+func (r migrateResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, m := range r.Migrations {
+		rows = append(rows, []string{strconv.FormatInt(m.Version, 10), m.Name, m.Status})
+	}
+	return []string{"version", "name", "status"}, rows
+}
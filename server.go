@@ -0,0 +1,960 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/auth"
+	"github.com/Utkarsh736/gator/internal/config"
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// maxPostLimit is the most posts one listing returns, in the API and in browse
+const maxPostLimit = 1000
+
+// apiServer serves the JSON REST API on top of the same queries the CLI uses
+type apiServer struct {
+	db  *database.Queries
+	cfg *config.Config
+	// inTx runs queries in one transaction, as state.inTx does for the CLI
+	inTx func(ctx context.Context, fn func(q *database.Queries) error) error
+	// posts passes new posts and read changes on to streams, which end when closing is cancelled
+	posts   *postHub
+	closing context.Context
+}
+
+// apiUser is the JSON representation of a user
+type apiUser struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+	ApiKey    string    `json:"api_key,omitempty"`
+}
+
+// apiFeed is the JSON representation of a feed
+type apiFeed struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Name          string     `json:"name"`
+	Url           string     `json:"url"`
+	UserID        uuid.UUID  `json:"user_id"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	NextFetchAt   *time.Time `json:"next_fetch_at"`
+	FetchInterval *int32     `json:"fetch_interval_seconds"`
+	Extract       bool       `json:"extract_content"`
+	Paused        bool       `json:"paused"`
+	Failures      int32      `json:"consecutive_failures"`
+	LastError     *string    `json:"last_error"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	MovedTo       *string    `json:"moved_to"`
+	// Channel details are what the feed says about itself, as of its last fetch
+	ChannelTitle       *string `json:"channel_title,omitempty"`
+	ChannelDescription *string `json:"channel_description,omitempty"`
+	ChannelLink        *string `json:"channel_link,omitempty"`
+	ChannelLanguage    *string `json:"channel_language,omitempty"`
+}
+
+// apiFeedFollow is the JSON representation of a feed follow. UnreadCount is only filled in when
+// listing follows.
+type apiFeedFollow struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	UserID      uuid.UUID `json:"user_id"`
+	FeedID      uuid.UUID `json:"feed_id"`
+	FeedName    string    `json:"feed_name"`
+	UnreadCount int64     `json:"unread_count"`
+}
+
+// apiFeedPost is a post in a feed's listing, with the user's read and saved state
+type apiFeedPost struct {
+	apiPost
+	Read  bool `json:"read"`
+	Saved bool `json:"saved"`
+}
+
+// apiPost is the JSON representation of a post
+type apiPost struct {
+	ID                   uuid.UUID     `json:"id"`
+	CreatedAt            time.Time     `json:"created_at"`
+	UpdatedAt            time.Time     `json:"updated_at"`
+	Title                string        `json:"title"`
+	Url                  string        `json:"url"`
+	Description          *string       `json:"description"`
+	Content              *string       `json:"content,omitempty"`
+	PublishedAt          *time.Time    `json:"published_at"`
+	PublishedAtEstimated bool          `json:"published_at_estimated"`
+	FeedID               uuid.UUID     `json:"feed_id"`
+	Author               *string       `json:"author,omitempty"`
+	WordCount            *int32        `json:"word_count,omitempty"`
+	ReadingMinutes       *int32        `json:"reading_minutes,omitempty"`
+	Enclosure            *apiEnclosure `json:"enclosure,omitempty"`
+	AlsoIn               []string      `json:"also_in,omitempty"`
+	// Related is the other coverage of the same story, when posts are listed clustered
+	Related []apiRelatedPost `json:"related,omitempty"`
+}
+
+// apiRelatedPost is a post folded into another one covering the same story
+type apiRelatedPost struct {
+	ID     uuid.UUID `json:"id"`
+	Title  string    `json:"title"`
+	Url    string    `json:"url"`
+	FeedID uuid.UUID `json:"feed_id"`
+}
+
+// apiEnclosure is the JSON representation of a post's media file, such as a podcast episode
+type apiEnclosure struct {
+	Url             string `json:"url"`
+	Type            string `json:"type,omitempty"`
+	Length          *int64 `json:"length"`
+	DurationSeconds *int32 `json:"duration_seconds"`
+}
+
+func toAPIUser(user database.User) apiUser {
+	return apiUser{
+		ID:        user.ID,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		Name:      user.Name,
+	}
+}
+
+func toAPIFeed(feed database.Feed) apiFeed {
+	return apiFeed{
+		ID:                 feed.ID,
+		CreatedAt:          feed.CreatedAt,
+		UpdatedAt:          feed.UpdatedAt,
+		Name:               feed.Name,
+		Url:                feed.Url,
+		UserID:             feed.UserID,
+		LastFetchedAt:      nullTimePtr(feed.LastFetchedAt),
+		NextFetchAt:        nullTimePtr(feed.NextFetchAt),
+		FetchInterval:      nullInt32Ptr(feed.FetchInterval),
+		Extract:            feed.ExtractContent,
+		Paused:             feed.Paused,
+		Failures:           feed.ConsecutiveFailures,
+		LastError:          nullStringPtr(feed.LastError),
+		LastSuccessAt:      nullTimePtr(feed.LastSuccessAt),
+		MovedTo:            nullStringPtr(feed.MovedTo),
+		ChannelTitle:       nullStringPtr(feed.ChannelTitle),
+		ChannelDescription: nullStringPtr(feed.ChannelDescription),
+		ChannelLink:        nullStringPtr(feed.ChannelLink),
+		ChannelLanguage:    nullStringPtr(feed.ChannelLanguage),
+	}
+}
+
+func toAPIPost(post database.Post) apiPost {
+	var description *string
+	if post.Description.Valid {
+		description = &post.Description.String
+	}
+	var content *string
+	if post.Content.Valid {
+		content = &post.Content.String
+	}
+	var enclosure *apiEnclosure
+	if post.EnclosureUrl.Valid {
+		enclosure = &apiEnclosure{
+			Url:             post.EnclosureUrl.String,
+			Type:            post.EnclosureType.String,
+			Length:          nullInt64Ptr(post.EnclosureLength),
+			DurationSeconds: nullInt32Ptr(post.DurationSeconds),
+		}
+	}
+
+	return apiPost{
+		ID:                   post.ID,
+		CreatedAt:            post.CreatedAt,
+		UpdatedAt:            post.UpdatedAt,
+		Title:                post.Title,
+		Url:                  post.Url,
+		Description:          description,
+		Content:              content,
+		PublishedAt:          nullTimePtr(post.PublishedAt),
+		PublishedAtEstimated: post.PublishedAtEstimated,
+		FeedID:               post.FeedID,
+		Author:               nullStringPtr(post.Author),
+		WordCount:            nullInt32Ptr(post.WordCount),
+		ReadingMinutes:       readingMinutes(post.WordCount),
+		Enclosure:            enclosure,
+	}
+}
+
+func toAPIPosts(posts []database.Post) []apiPost {
+	out := make([]apiPost, 0, len(posts))
+	for _, post := range posts {
+		out = append(out, toAPIPost(post))
+	}
+	return out
+}
+
+// nullInt32Ptr converts a nullable integer into a JSON-friendly pointer
+func nullInt32Ptr(n sql.NullInt32) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
+
+// nullStringPtr converts a nullable text column into a JSON-friendly pointer
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+// nullInt64Ptr converts a nullable bigint into a JSON-friendly pointer
+func nullInt64Ptr(n sql.NullInt64) *int64 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int64
+}
+
+// nullTimePtr converts a nullable timestamp into a JSON-friendly pointer
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// handlerServe runs the JSON REST API and web UI until interrupted
+func handlerServe(s *state, cmd command) error {
+	var addr, metricsAddr string
+	flags := newFlagSet("serve")
+	flags.StringVar(&addr, "addr", ":8080", "Listen on `addr`")
+	flags.StringVar(&metricsAddr, "metrics-addr", s.cfg.MetricsAddr, "Serve Prometheus metrics on `addr`")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unknown serve argument: %s", args[0])
+	}
+	if err := guardPrivateAddresses(s, true); err != nil {
+		return err
+	}
+
+	// One connection listens for new posts and read changes on behalf of every stream
+	listener, err := listenDatabase(s, newPostsChannel, postReadsChannel)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	// Metrics aren't authenticated, so they get their own listener rather than sharing the API's
+	if metricsAddr != "" {
+		stopMetrics := serveMetrics(metricsAddr, s.db)
+		defer stopMetrics()
+	}
+
+	closing, closeStreams := context.WithCancel(context.Background())
+	defer closeStreams()
+	api := &apiServer{db: s.db, cfg: s.cfg, inTx: s.inTx, posts: newPostHub(listener), closing: closing}
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           api.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// Streams never finish on their own, so shutting down ends them
+	server.RegisterOnShutdown(closeStreams)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	if err := s.emit(messageResult{Message: fmt.Sprintf("Serving API and web UI on %s", addr)}); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("server stopped: %w", err)
+	case <-s.ctx.Done():
+	}
+
+	if err := s.emit(messageResult{Message: "Shutting down server"}); err != nil {
+		return err
+	}
+	// Requests in flight get a while to finish; gator's own context is already cancelled
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(s.ctx), 10*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// routes registers the API endpoints
+func (api *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /api/users", api.handleCreateUser)
+	mux.HandleFunc("GET /api/users", api.authenticated(api.handleListUsers))
+	mux.HandleFunc("GET /api/users/me", api.authenticated(api.handleGetMe))
+
+	mux.HandleFunc("GET /api/feeds", api.authenticated(api.handleListFeeds))
+	mux.HandleFunc("POST /api/feeds", api.authenticated(api.handleCreateFeed))
+	mux.HandleFunc("GET /api/feeds/{feedID}/posts", api.authenticated(api.handleListFeedPosts))
+
+	mux.HandleFunc("GET /api/feed_follows", api.authenticated(api.handleListFollows))
+	mux.HandleFunc("POST /api/feed_follows", api.authenticated(api.handleCreateFollow))
+	mux.HandleFunc("DELETE /api/feed_follows/{feedID}", api.authenticated(api.handleDeleteFollow))
+
+	mux.HandleFunc("GET /api/posts", api.authenticated(api.handleListPosts))
+	mux.HandleFunc("GET /api/posts/saved", api.authenticated(api.handleListSaved))
+	mux.HandleFunc("GET /api/posts/{postID}", api.authenticated(api.handleGetPost))
+	mux.HandleFunc("POST /api/posts/{postID}/read", api.authenticated(api.handleMarkRead))
+	mux.HandleFunc("DELETE /api/posts/{postID}/read", api.authenticated(api.handleMarkUnread))
+	mux.HandleFunc("POST /api/posts/{postID}/save", api.authenticated(api.handleSave))
+	mux.HandleFunc("DELETE /api/posts/{postID}/save", api.authenticated(api.handleUnsave))
+
+	mux.HandleFunc("GET /api/stream", api.authenticated(api.handleStream))
+
+	mux.HandleFunc("/fever/", api.handleFever)
+
+	mux.HandleFunc("GET /feeds/{user}/{file}", riverFeedToken(api.authenticated(api.handleRiverFeed)))
+
+	mux.HandleFunc("/accounts/ClientLogin", api.handleGReaderLogin)
+	mux.HandleFunc("/reader/api/0/token", api.greaderAuthenticated(api.handleGReaderToken))
+	mux.HandleFunc("/reader/api/0/user-info", api.greaderAuthenticated(api.handleGReaderUserInfo))
+	mux.HandleFunc("/reader/api/0/subscription/list", api.greaderAuthenticated(api.handleGReaderSubscriptions))
+	mux.HandleFunc("POST /reader/api/0/subscription/edit", api.greaderAuthenticated(api.handleGReaderEditSubscription))
+	mux.HandleFunc("POST /reader/api/0/subscription/quickadd", api.greaderAuthenticated(api.handleGReaderQuickAdd))
+	mux.HandleFunc("/reader/api/0/tag/list", api.greaderAuthenticated(api.handleGReaderTags))
+	mux.HandleFunc("/reader/api/0/unread-count", api.greaderAuthenticated(api.handleGReaderUnreadCount))
+	mux.HandleFunc("/reader/api/0/stream/items/ids", api.greaderAuthenticated(api.handleGReaderItemIDs))
+	mux.HandleFunc("/reader/api/0/stream/items/contents", api.greaderAuthenticated(api.handleGReaderItemContents))
+	mux.HandleFunc("/reader/api/0/stream/contents/{stream...}", api.greaderAuthenticated(api.handleGReaderStreamContents))
+	mux.HandleFunc("POST /reader/api/0/edit-tag", api.greaderAuthenticated(api.handleGReaderEditTag))
+	mux.HandleFunc("POST /reader/api/0/mark-all-as-read", api.greaderAuthenticated(api.handleGReaderMarkAllRead))
+
+	web := webHandler()
+	mux.Handle("GET /{$}", web)
+	mux.Handle("GET /static/", web)
+
+	return mux
+}
+
+// authenticated wraps handlers that require a bearer token from 'gator apikey create', the
+// user's own API key or, for users with a password, HTTP basic auth, like middlewareLoggedIn
+// does for the CLI
+func (api *apiServer) authenticated(handler func(http.ResponseWriter, *http.Request, database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			row, err := api.db.GetUserByAPIToken(r.Context(), hashAPIToken(token))
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					respondWithError(w, http.StatusUnauthorized, "invalid token")
+					return
+				}
+				respondWithError(w, http.StatusInternalServerError, "couldn't get user")
+				return
+			}
+			if row.Scope == apiKeyScopeRead && r.Method != http.MethodGet && r.Method != http.MethodHead {
+				respondWithError(w, http.StatusForbidden, "this token is read-only")
+				return
+			}
+			if err := api.db.TouchAPIKey(r.Context(), row.KeyID); err != nil {
+				slog.Warn("couldn't record API key use", "error", err)
+			}
+
+			// Leave out the user's main API key so a scoped token can't be traded up for it
+			handler(w, r, database.User{
+				ID:           row.ID,
+				CreatedAt:    row.CreatedAt,
+				UpdatedAt:    row.UpdatedAt,
+				Name:         row.Name,
+				PasswordHash: row.PasswordHash,
+			})
+			return
+		}
+
+		if name, password, ok := r.BasicAuth(); ok {
+			user, err := api.db.GetUser(r.Context(), name)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusInternalServerError, "couldn't get user")
+				return
+			}
+			if err != nil || !user.PasswordHash.Valid || auth.CheckPassword(user.PasswordHash.String, password) != nil {
+				respondWithError(w, http.StatusUnauthorized, "invalid username or password")
+				return
+			}
+			handler(w, r, user)
+			return
+		}
+
+		apiKey, ok := strings.CutPrefix(r.Header.Get("Authorization"), "ApiKey ")
+		if !ok || apiKey == "" {
+			respondWithError(w, http.StatusUnauthorized, "missing credentials: use 'Authorization: Bearer <token>', 'Authorization: ApiKey <key>' or basic auth")
+			return
+		}
+
+		user, err := api.db.GetUserByAPIKey(r.Context(), apiKey)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusUnauthorized, "invalid API key")
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "couldn't get user")
+			return
+		}
+
+		handler(w, r, user)
+	}
+}
+
+func (api *apiServer) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	// Anyone who can reach the server could otherwise sign up and have it fetch feeds for them
+	if api.cfg.Server == nil || !api.cfg.Server.OpenRegistration {
+		respondWithError(w, http.StatusForbidden, "registration is closed; ask the server's admin to run gator register")
+		return
+	}
+	var params struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "request body must include a name")
+		return
+	}
+	if params.Password != "" && len(params.Password) < minPasswordLength {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("password must be at least %d characters", minPasswordLength))
+		return
+	}
+
+	apiKey, err := newUserAPIKey()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't generate API key")
+		return
+	}
+	user, err := api.db.CreateUser(r.Context(), database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      params.Name,
+		ApiKey:    apiKey,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			respondWithError(w, http.StatusConflict, fmt.Sprintf("user %s already exists", params.Name))
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "couldn't create user")
+		return
+	}
+	if params.Password != "" {
+		if err := setUserPassword(r.Context(), api.db, user, params.Password); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "couldn't set password")
+			return
+		}
+	}
+
+	// The key is only returned to the caller that created the user
+	resp := toAPIUser(user)
+	resp.ApiKey = user.ApiKey
+	respondWithJSON(w, http.StatusCreated, resp)
+}
+
+// handleListUsers lists the instance's users. Only signed-in users may see who else has an account.
+func (api *apiServer) handleListUsers(w http.ResponseWriter, r *http.Request, user database.User) {
+	users, err := api.db.GetUsers(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get users")
+		return
+	}
+
+	out := make([]apiUser, 0, len(users))
+	for _, u := range users {
+		out = append(out, toAPIUser(u))
+	}
+	respondWithJSON(w, http.StatusOK, out)
+}
+
+func (api *apiServer) handleGetMe(w http.ResponseWriter, r *http.Request, user database.User) {
+	resp := toAPIUser(user)
+	resp.ApiKey = user.ApiKey
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleListFeeds lists every feed on the instance, for signed-in users to find ones to follow
+func (api *apiServer) handleListFeeds(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := api.db.GetFeeds(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get feeds")
+		return
+	}
+
+	out := make([]apiFeed, 0, len(feeds))
+	for _, feed := range feeds {
+		out = append(out, toAPIFeed(feedFromRow(feed)))
+	}
+	respondWithJSON(w, http.StatusOK, out)
+}
+
+func (api *apiServer) handleCreateFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	var params struct {
+		Name string `json:"name"`
+		Url  string `json:"url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Name == "" || params.Url == "" {
+		respondWithError(w, http.StatusBadRequest, "request body must include name and url")
+		return
+	}
+	feedURL, err := validateFeedURL(r.Context(), params.Url)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if existing, found, err := findEquivalentFeed(r.Context(), api.db, feedURL); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't create feed")
+		return
+	} else if found {
+		respondWithError(w, http.StatusConflict, fmt.Sprintf("feed already exists as %s; follow it with feed_id %s", existing.Url, existing.ID))
+		return
+	}
+
+	// Create the feed and follow it together, as addfeed does, so a failed follow doesn't leave
+	// behind a feed nobody follows
+	var feed database.Feed
+	err = api.inTx(r.Context(), func(q *database.Queries) error {
+		feed, err = q.CreateFeed(r.Context(), database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Name:      params.Name,
+			Url:       feedURL,
+			UserID:    user.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+		})
+		return err
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			respondWithError(w, http.StatusConflict, fmt.Sprintf("feed with URL %s already exists", feedURL))
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "couldn't create feed")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toAPIFeed(feed))
+}
+
+func (api *apiServer) handleListFollows(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := api.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get feed follows")
+		return
+	}
+	counts, err := api.db.GetUnreadCountsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't count unread posts")
+		return
+	}
+	unread := make(map[uuid.UUID]int64, len(counts))
+	for _, count := range counts {
+		unread[count.FeedID] = count.UnreadCount
+	}
+
+	out := make([]apiFeedFollow, 0, len(follows))
+	for _, follow := range follows {
+		out = append(out, apiFeedFollow{
+			ID:          follow.ID,
+			CreatedAt:   follow.CreatedAt,
+			UpdatedAt:   follow.UpdatedAt,
+			UserID:      follow.UserID,
+			FeedID:      follow.FeedID,
+			FeedName:    follow.FeedName,
+			UnreadCount: unread[follow.FeedID],
+		})
+	}
+	respondWithJSON(w, http.StatusOK, out)
+}
+
+func (api *apiServer) handleCreateFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	var params struct {
+		FeedID  uuid.UUID `json:"feed_id"`
+		FeedUrl string    `json:"feed_url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || (params.FeedID == uuid.Nil && params.FeedUrl == "") {
+		respondWithError(w, http.StatusBadRequest, "request body must include feed_id or feed_url")
+		return
+	}
+
+	var feed database.Feed
+	var err error
+	if params.FeedID != uuid.Nil {
+		feed, err = api.db.GetFeed(r.Context(), params.FeedID)
+	} else {
+		feed, err = getFeedByURL(r.Context(), api.db, params.FeedUrl)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "feed not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "couldn't get feed")
+		return
+	}
+
+	follow, err := api.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			respondWithError(w, http.StatusConflict, "already following this feed")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "couldn't follow feed")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, apiFeedFollow{
+		ID:        follow.ID,
+		CreatedAt: follow.CreatedAt,
+		UpdatedAt: follow.UpdatedAt,
+		UserID:    follow.UserID,
+		FeedID:    follow.FeedID,
+		FeedName:  follow.FeedName,
+	})
+}
+
+func (api *apiServer) handleDeleteFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedID, err := uuid.Parse(r.PathValue("feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid feed ID")
+		return
+	}
+
+	err = api.db.DeleteFeedFollow(r.Context(), database.DeleteFeedFollowParams{
+		UserID: user.ID,
+		FeedID: feedID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't unfollow feed")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *apiServer) handleListPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	limit, ok := queryLimit(w, r, 20)
+	if !ok {
+		return
+	}
+
+	offset := 0
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 || parsed > math.MaxInt32 {
+			respondWithError(w, http.StatusBadRequest, "invalid offset")
+			return
+		}
+		offset = parsed
+	}
+
+	var posts []database.Post
+	var err error
+	if r.URL.Query().Get("all") == "true" {
+		posts, err = api.db.GetPostsForUser(r.Context(), database.GetPostsForUserParams{
+			UserID:   user.ID,
+			SortBy:   "published",
+			SortDesc: true,
+			Limit:    int32(limit),
+			Offset:   int32(offset),
+		})
+	} else {
+		posts, err = api.db.GetUnreadPostsForUser(r.Context(), database.GetUnreadPostsForUserParams{
+			UserID:   user.ID,
+			SortBy:   "published",
+			SortDesc: true,
+			Limit:    int32(limit),
+			Offset:   int32(offset),
+		})
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get posts")
+		return
+	}
+
+	filters, err := loadFilters(r.Context(), api.db, user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get filters")
+		return
+	}
+
+	res := toAPIPosts(filters.posts(posts))
+	if err := addAlsoIn(r.Context(), api.db, res); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get post sources")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+// handleListFeedPosts lists a feed's newest posts with whether the user has read or saved each
+func (api *apiServer) handleListFeedPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedID, err := uuid.Parse(r.PathValue("feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid feed ID")
+		return
+	}
+	limit, ok := queryLimit(w, r, 50)
+	if !ok {
+		return
+	}
+
+	if _, err := api.db.GetFeed(r.Context(), feedID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "feed not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "couldn't get feed")
+		return
+	}
+	rows, err := api.db.GetPostsForFeedWithState(r.Context(), database.GetPostsForFeedWithStateParams{
+		UserID:      user.ID,
+		FeedID:      feedID,
+		ResultLimit: int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get posts")
+		return
+	}
+
+	out := make([]apiFeedPost, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, apiFeedPost{
+			apiPost: toAPIPost(database.Post{
+				ID:                   row.ID,
+				CreatedAt:            row.CreatedAt,
+				UpdatedAt:            row.UpdatedAt,
+				Title:                row.Title,
+				Url:                  row.Url,
+				Description:          row.Description,
+				PublishedAt:          row.PublishedAt,
+				FeedID:               row.FeedID,
+				Content:              row.Content,
+				EnclosureUrl:         row.EnclosureUrl,
+				EnclosureType:        row.EnclosureType,
+				EnclosureLength:      row.EnclosureLength,
+				DurationSeconds:      row.DurationSeconds,
+				PublishedAtEstimated: row.PublishedAtEstimated,
+				WordCount:            row.WordCount,
+			}),
+			Read:  row.IsRead,
+			Saved: row.IsSaved,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, out)
+}
+
+// handleGetPost returns one post with its feed, tags and the user's read and saved state, as
+// 'gator show' prints it
+func (api *apiServer) handleGetPost(w http.ResponseWriter, r *http.Request, user database.User) {
+	found, ok := api.postFromPath(w, r, user)
+	if !ok {
+		return
+	}
+
+	post, err := api.db.GetPostDetail(r.Context(), database.GetPostDetailParams{
+		UserID: user.ID,
+		ID:     found.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get post")
+		return
+	}
+	res, err := toPostDetail(r.Context(), api.db, user.ID, post)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get post details")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+func (api *apiServer) handleListSaved(w http.ResponseWriter, r *http.Request, user database.User) {
+	posts, err := api.db.GetSavedPostsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't get saved posts")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toAPIPosts(posts))
+}
+
+// forwardSavedPost forwards a post saved through the API as the CLI does, but only for the user
+// the config belongs to: send_saved_to, the read-later credentials and the post_saved hook are the
+// operator's, and other users' saves mustn't reach their account or run their commands
+func (api *apiServer) forwardSavedPost(ctx context.Context, user database.User, post database.Post) {
+	if api.cfg == nil || user.Name != api.cfg.CurrentUserName {
+		return
+	}
+	forwardSavedPost(ctx, api.cfg, user, post)
+}
+
+// queryLimit reads the ?limit parameter, defaulting to def and capped at maxPostLimit, writing an
+// error response when it's invalid
+func queryLimit(w http.ResponseWriter, r *http.Request, def int) (int, bool) {
+	raw := r.URL.Query().Get("limit")
+	if raw == "" {
+		return def, true
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		respondWithError(w, http.StatusBadRequest, "invalid limit")
+		return 0, false
+	}
+	return min(limit, maxPostLimit), true
+}
+
+// postFromPath loads the post named by the {postID} path segment, writing an error response on
+// failure. Posts that don't come from a feed user follows, and that user hasn't saved, aren't found.
+func (api *apiServer) postFromPath(w http.ResponseWriter, r *http.Request, user database.User) (database.Post, bool) {
+	postID, err := uuid.Parse(r.PathValue("postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid post ID")
+		return database.Post{}, false
+	}
+
+	post, err := api.db.GetPostForUser(r.Context(), database.GetPostForUserParams{
+		ID:     postID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "post not found")
+			return database.Post{}, false
+		}
+		respondWithError(w, http.StatusInternalServerError, "couldn't get post")
+		return database.Post{}, false
+	}
+
+	return post, true
+}
+
+func (api *apiServer) handleMarkRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	post, ok := api.postFromPath(w, r, user)
+	if !ok {
+		return
+	}
+
+	err := api.db.MarkPostRead(r.Context(), database.MarkPostReadParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		PostID:    post.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't mark post as read")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *apiServer) handleMarkUnread(w http.ResponseWriter, r *http.Request, user database.User) {
+	post, ok := api.postFromPath(w, r, user)
+	if !ok {
+		return
+	}
+
+	err := api.db.MarkPostUnread(r.Context(), database.MarkPostUnreadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't mark post as unread")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *apiServer) handleSave(w http.ResponseWriter, r *http.Request, user database.User) {
+	post, ok := api.postFromPath(w, r, user)
+	if !ok {
+		return
+	}
+
+	err := api.db.SavePost(r.Context(), database.SavePostParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		PostID:    post.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't save post")
+		return
+	}
+	api.forwardSavedPost(r.Context(), user, post)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *apiServer) handleUnsave(w http.ResponseWriter, r *http.Request, user database.User) {
+	post, ok := api.postFromPath(w, r, user)
+	if !ok {
+		return
+	}
+
+	err := api.db.UnsavePost(r.Context(), database.UnsavePostParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't unsave post")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondWithError writes a JSON error body
+func respondWithError(w http.ResponseWriter, code int, msg string) {
+	respondWithJSON(w, code, map[string]string{"error": msg})
+}
+
+// respondWithJSON writes payload as JSON with the given status code
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("couldn't marshal JSON", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
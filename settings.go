@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/config"
+)
+
+// secretSettings are masked when config list or set prints them; config get still shows them
+var secretSettings = map[string]bool{
+	"smtp.password":          true,
+	"telegram.bot_token":     true,
+	"instapaper.password":    true,
+	"wallabag.client_secret": true,
+	"wallabag.password":      true,
+}
+
+// settingDefaults are the values settings take when the config leaves them out, as config get
+// and config list --all show them. They mirror the defaults where each setting is read.
+var settingDefaults = map[string]string{
+	"min_fetch_interval":         "10m",
+	"max_fetch_interval":         "24h",
+	"podcast_dir":                "~/Podcasts",
+	"max_fetch_failures":         "10",
+	"fetch_retries":              "3",
+	"fetch_retry_delay":          "2s",
+	"host_requests_per_second":   "1",
+	"fetch_concurrency":          "1",
+	"output":                     defaultOutputFormat,
+	"log_level":                  "info",
+	"log_format":                 "text",
+	"http.timeout":               defaultHTTPTimeout.String(),
+	"http.user_agent":            defaultUserAgent,
+	"http.max_redirects":         strconv.Itoa(defaultMaxRedirects),
+	"http.max_response_size":     "10MB",
+	"database.max_open_conns":    "10",
+	"database.max_idle_conns":    "2",
+	"database.conn_max_lifetime": "30m",
+	"digest.since":               "24h",
+}
+
+// handlerConfig reads and changes the config file: config get|set|unset|list
+func handlerConfig(s *state, cmd command) error {
+	if len(cmd.args) == 0 {
+		return errors.New("config command requires a subcommand: get, set, unset, list")
+	}
+
+	sub := command{name: cmd.args[0], args: cmd.args[1:]}
+	switch sub.name {
+	case "get":
+		return handlerConfigGet(s, sub)
+	case "set":
+		return handlerConfigSet(s, sub)
+	case "unset":
+		return handlerConfigUnset(s, sub)
+	case "list":
+		return handlerConfigList(s, sub)
+	default:
+		return fmt.Errorf("unknown config subcommand: %s", sub.name)
+	}
+}
+
+// handlerConfigGet prints one setting's value, or its default if it isn't set
+func handlerConfigGet(s *state, cmd command) error {
+	if len(cmd.args) != 1 {
+		return errors.New("config get requires a setting name, like db_url or http.timeout")
+	}
+
+	key := cmd.args[0]
+	value, ok, err := s.cfg.Get(key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		value, ok = settingDefaults[key]
+		if !ok {
+			return fmt.Errorf("%s is not set", key)
+		}
+		return s.emit(configSettingEntry{Key: key, Value: value, Default: true})
+	}
+	return s.emit(configSettingEntry{Key: key, Value: value})
+}
+
+// handlerConfigSet changes one setting, checking the config still works before saving it
+func handlerConfigSet(s *state, cmd command) error {
+	if len(cmd.args) != 2 {
+		return errors.New("config set requires a setting name and a value")
+	}
+
+	key, value := cmd.args[0], cmd.args[1]
+	// Switching users goes through login, which asks for the user's password
+	if key == "current_user_name" {
+		return fmt.Errorf("current_user_name can't be set directly; run 'gator login %s' instead", value)
+	}
+	if err := s.cfg.Set(key, value); err != nil {
+		return err
+	}
+	if err := validateConfig(s.cfg); err != nil {
+		return err
+	}
+	if key == "db_url" {
+		if err := pingDatabase(s.ctx, value); err != nil {
+			return err
+		}
+	}
+
+	if err := s.cfg.Save(); err != nil {
+		return fmt.Errorf("couldn't save config: %w", err)
+	}
+
+	value = displaySetting(key, value)
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Set %s to %s", key, value),
+		Item:    configSettingEntry{Key: key, Value: value},
+	})
+}
+
+// handlerConfigUnset removes a setting, so its default applies again
+func handlerConfigUnset(s *state, cmd command) error {
+	if len(cmd.args) != 1 {
+		return errors.New("config unset requires a setting name")
+	}
+
+	key := cmd.args[0]
+	if err := s.cfg.Set(key, ""); err != nil {
+		return err
+	}
+	if err := validateConfig(s.cfg); err != nil {
+		return err
+	}
+	if err := s.cfg.Save(); err != nil {
+		return fmt.Errorf("couldn't save config: %w", err)
+	}
+
+	return s.emit(messageResult{Message: fmt.Sprintf("Unset %s", key)})
+}
+
+// handlerConfigList prints every setting that has a value, with secrets masked. With --all it
+// includes the rest too, with their defaults.
+func handlerConfigList(s *state, cmd command) error {
+	var all bool
+	flags := newFlagSet("config list")
+	flags.BoolVar(&all, "all", false, "Include unset settings, with their defaults")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unknown config list argument: %s", args[0])
+	}
+
+	res := configSettingsResult{
+		Path:     s.cfg.Path(),
+		Profile:  s.cfg.Profile(),
+		Profiles: s.cfg.ProfileNames(),
+		Settings: []configSettingEntry{},
+	}
+	if !all {
+		for _, setting := range s.cfg.Settings() {
+			res.Settings = append(res.Settings, configSettingEntry{
+				Key:   setting.Key,
+				Value: displaySetting(setting.Key, setting.Value),
+			})
+		}
+		return s.emit(res)
+	}
+
+	for _, key := range config.Keys() {
+		value, ok, err := s.cfg.Get(key)
+		if err != nil {
+			return err
+		}
+		entry := configSettingEntry{Key: key, Value: displaySetting(key, value)}
+		if !ok {
+			entry.Value, entry.Default = settingDefaults[key], true
+		}
+		res.Settings = append(res.Settings, entry)
+	}
+	return s.emit(res)
+}
+
+// validateConfig checks the whole config, including the settings only main knows how to read,
+// so a bad value is caught when it's set or when gator starts
+func validateConfig(cfg *config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	for name, expansion := range cfg.Aliases {
+		if _, err := splitArgs(expansion); err != nil {
+			return fmt.Errorf("invalid alias %s: %w", name, err)
+		}
+	}
+	if _, _, err := newHTTPClients(cfg.HTTP); err != nil {
+		return err
+	}
+	if _, ok := formatters[cfg.Output]; cfg.Output != "" && !ok {
+		return fmt.Errorf("unknown output format %q (expected one of %s)", cfg.Output, strings.Join(outputFormats(), ", "))
+	}
+	level, err := parseLogLevel(cfg.LogLevel)
+	if err != nil {
+		return err
+	}
+	if _, err := newLogHandler(io.Discard, cfg.LogFormat, level); err != nil {
+		return err
+	}
+	return nil
+}
+
+// pingDatabase checks that gator can connect to the database at dbURL
+func pingDatabase(ctx context.Context, dbURL string) error {
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("couldn't connect to %s: %w", displaySetting("db_url", dbURL), err)
+	}
+	return nil
+}
+
+// displaySetting returns a value safe to print: secrets are masked, and so is the password in
+// the database URL
+func displaySetting(key, value string) string {
+	switch {
+	case secretSettings[key] && len(value) <= 8:
+		return "********"
+	case secretSettings[key]:
+		return "********" + value[len(value)-4:]
+	case key == "db_url":
+		if u, err := url.Parse(value); err == nil {
+			return u.Redacted()
+		}
+	}
+	return value
+}
+
+// configSettingEntry is one setting in config output; Default is set when the value isn't in
+// the config, and is empty when the setting has no default
+type configSettingEntry struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Default bool   `json:"default,omitempty"`
+}
+
+func (e configSettingEntry) writeText(w io.Writer) {
+	fmt.Fprintln(w, e.Value)
+}
+
+func (e configSettingEntry) table() ([]string, [][]string) {
+	return []string{"key", "value"}, [][]string{{e.Key, e.Value}}
+}
+
+// configSettingsResult is the output of config list; Profile is the selected profile, if any
+type configSettingsResult struct {
+	Path     string               `json:"path"`
+	Profile  string               `json:"profile,omitempty"`
+	Profiles []string             `json:"profiles"`
+	Settings []configSettingEntry `json:"settings"`
+}
+
+func (r configSettingsResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Config file: %s\n", r.Path)
+	if r.Profile != "" {
+		fmt.Fprintf(w, "Profile: %s\n", r.Profile)
+	}
+	if len(r.Profiles) > 0 {
+		fmt.Fprintf(w, "Profiles: %s\n", strings.Join(r.Profiles, ", "))
+	}
+	if len(r.Settings) == 0 {
+		fmt.Fprintln(w, "No settings found")
+		return
+	}
+	for _, setting := range r.Settings {
+		switch {
+		case setting.Default && setting.Value == "":
+			fmt.Fprintf(w, "%s (not set)\n", setting.Key)
+		case setting.Default:
+			fmt.Fprintf(w, "%s = %s (default)\n", setting.Key, setting.Value)
+		default:
+			fmt.Fprintf(w, "%s = %s\n", setting.Key, setting.Value)
+		}
+	}
+}
+
+func (r configSettingsResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, setting := range r.Settings {
+		rows = append(rows, []string{setting.Key, setting.Value, strconv.FormatBool(setting.Default)})
+	}
+	return []string{"key", "value", "default"}, rows
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+// handlerShow prints one post in full: its content, where and when it was published, its tags,
+// and whether it's read or saved. With --fetch, a post without stored content has its article
+// downloaded and extracted first.
+func handlerShow(s *state, cmd command, user database.User) error {
+	var fetch bool
+	flags := newFlagSet("show")
+	flags.BoolVar(&fetch, "fetch", false, "Download and extract the article when no full content is stored")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return errors.New("show command requires a post number, ID, or URL argument")
+	}
+
+	listed, err := getListedPost(s, user, args[0])
+	if err != nil {
+		return err
+	}
+	post, err := s.db.GetPostDetail(s.ctx, database.GetPostDetailParams{
+		UserID: user.ID,
+		ID:     listed.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get post: %w", err)
+	}
+
+	if fetch && !post.Content.Valid {
+		content, err := fetchArticleContent(s.ctx, post.Url)
+		if err != nil {
+			return fmt.Errorf("couldn't extract article: %w", err)
+		}
+		post.Content = sql.NullString{String: content, Valid: true}
+		post.WordCount = wordCount(content)
+		err = s.db.SetPostContent(s.ctx, database.SetPostContentParams{
+			ID:        post.ID,
+			Content:   post.Content,
+			WordCount: post.WordCount,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't save article: %w", err)
+		}
+	}
+
+	res, err := toPostDetail(s.ctx, s.db, user.ID, post)
+	if err != nil {
+		return err
+	}
+	return s.emit(res)
+}
+
+// toPostDetail adds a post's tags and other sources to what GetPostDetail returned
+func toPostDetail(ctx context.Context, db *database.Queries, userID uuid.UUID, post database.GetPostDetailRow) (postDetail, error) {
+	tags, err := db.GetTagNamesForPost(ctx, database.GetTagNamesForPostParams{
+		PostID: post.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		return postDetail{}, fmt.Errorf("couldn't get tags: %w", err)
+	}
+	categories, err := db.GetPostCategories(ctx, post.ID)
+	if err != nil {
+		return postDetail{}, fmt.Errorf("couldn't get post categories: %w", err)
+	}
+	sources, err := db.GetOtherPostSources(ctx, []uuid.UUID{post.ID})
+	if err != nil {
+		return postDetail{}, fmt.Errorf("couldn't get post sources: %w", err)
+	}
+
+	res := postDetail{
+		apiPost: toAPIPost(database.Post{
+			ID:                   post.ID,
+			CreatedAt:            post.CreatedAt,
+			UpdatedAt:            post.UpdatedAt,
+			Title:                post.Title,
+			Url:                  post.Url,
+			Description:          post.Description,
+			PublishedAt:          post.PublishedAt,
+			FeedID:               post.FeedID,
+			Content:              post.Content,
+			EnclosureUrl:         post.EnclosureUrl,
+			EnclosureType:        post.EnclosureType,
+			EnclosureLength:      post.EnclosureLength,
+			DurationSeconds:      post.DurationSeconds,
+			PublishedAtEstimated: post.PublishedAtEstimated,
+			Author:               post.Author,
+			WordCount:            post.WordCount,
+		}),
+		FeedName:   post.FeedName,
+		FeedURL:    post.FeedUrl,
+		Tags:       []string{},
+		Categories: []string{},
+		Read:       post.IsRead,
+		Saved:      post.IsSaved,
+	}
+	res.Tags = append(res.Tags, tags...)
+	res.Categories = append(res.Categories, categories...)
+	for _, source := range sources {
+		res.AlsoIn = append(res.AlsoIn, source.FeedName)
+	}
+	return res, nil
+}
+
+// postDetail is the output of show
+type postDetail struct {
+	apiPost
+	FeedName string   `json:"feed_name"`
+	FeedURL  string   `json:"feed_url"`
+	Tags     []string `json:"tags"`
+	// Categories are what the feed filed the post under, unlike tags, which are the user's own
+	Categories []string `json:"categories"`
+	Read       bool     `json:"read"`
+	Saved      bool     `json:"saved"`
+}
+
+func (p postDetail) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Title: %s\n", p.Title)
+	fmt.Fprintf(w, "URL: %s\n", p.Url)
+	fmt.Fprintf(w, "Feed: %s (%s)\n", p.FeedName, p.FeedURL)
+	if p.Author != nil {
+		fmt.Fprintf(w, "Author: %s\n", *p.Author)
+	}
+	if len(p.AlsoIn) > 0 {
+		fmt.Fprintf(w, "Also in: %s\n", strings.Join(p.AlsoIn, ", "))
+	}
+	if p.PublishedAt != nil {
+		estimate := ""
+		if p.PublishedAtEstimated {
+			estimate = " (estimated)"
+		}
+		fmt.Fprintf(w, "Published: %s%s\n", p.PublishedAt.Format("2006-01-02 15:04:05"), estimate)
+	}
+	fmt.Fprintf(w, "Added: %s\n", p.CreatedAt.Format("2006-01-02 15:04:05"))
+	if p.ReadingMinutes != nil {
+		fmt.Fprintf(w, "Reading time: %d min (%d words)\n", *p.ReadingMinutes, *p.WordCount)
+	}
+	if p.Enclosure != nil {
+		duration := ""
+		if p.Enclosure.DurationSeconds != nil {
+			duration = fmt.Sprintf(" (%s)", time.Duration(*p.Enclosure.DurationSeconds)*time.Second)
+		}
+		fmt.Fprintf(w, "Enclosure: %s%s\n", p.Enclosure.Url, duration)
+	}
+	if len(p.Categories) > 0 {
+		fmt.Fprintf(w, "Categories: %s\n", strings.Join(p.Categories, ", "))
+	}
+	if len(p.Tags) > 0 {
+		fmt.Fprintf(w, "Tags: %s\n", strings.Join(p.Tags, ", "))
+	}
+
+	state := []string{"unread"}
+	if p.Read {
+		state[0] = "read"
+	}
+	if p.Saved {
+		state = append(state, "saved")
+	}
+	fmt.Fprintf(w, "Status: %s\n", strings.Join(state, ", "))
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	switch {
+	case p.Content != nil:
+		fmt.Fprintln(w, *p.Content)
+	case p.Description != nil:
+		fmt.Fprintln(w, htmltext.Render(*p.Description))
+		fmt.Fprintln(w, "\n(Only the feed's description is stored; run show with --fetch for the full article.)")
+	default:
+		fmt.Fprintln(w, "No content stored; run show with --fetch for the full article.")
+	}
+}
+
+func (p postDetail) table() ([]string, [][]string) {
+	return []string{"id", "title", "url", "feed", "published_at", "read", "saved", "tags"}, [][]string{{
+		p.ID.String(),
+		p.Title,
+		p.Url,
+		p.FeedName,
+		formatTime(p.PublishedAt),
+		strconv.FormatBool(p.Read),
+		strconv.FormatBool(p.Saved),
+		strings.Join(p.Tags, ","),
+	}}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// smartTerms is a smart folder's definition as the user wrote it
+type smartTerms struct {
+	keywords []string
+	feeds    []string
+	tags     []string
+	since    string
+	until    string
+}
+
+// add reads one term of a --query string: feed:, tag:, since: and until: terms narrow the
+// folder, and anything else is a search keyword
+func (t *smartTerms) add(term string) {
+	name, value, ok := strings.Cut(term, ":")
+	if ok && value != "" {
+		switch strings.ToLower(name) {
+		case "feed":
+			t.feeds = append(t.feeds, value)
+			return
+		case "tag":
+			t.tags = append(t.tags, value)
+			return
+		case "since":
+			t.since = value
+			return
+		case "until":
+			t.until = value
+			return
+		}
+	}
+	t.keywords = append(t.keywords, term)
+}
+
+// smartQuery is the part of a browse query a smart folder fills in
+type smartQuery struct {
+	keywords sql.NullString
+	feedIDs  []uuid.UUID
+	tagIDs   []uuid.UUID
+	since    sql.NullTime
+	until    sql.NullTime
+}
+
+// loadSmartFolder builds the browse query for one of user's smart folders. Relative dates are
+// taken from now, so a "since 7d" folder always shows the last week.
+func loadSmartFolder(s *state, user database.User, name string) (smartQuery, error) {
+	search, err := s.db.GetSavedSearch(s.ctx, database.GetSavedSearchParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return smartQuery{}, fmt.Errorf("smart folder %s doesn't exist", name)
+		}
+		return smartQuery{}, fmt.Errorf("couldn't get smart folder: %w", err)
+	}
+
+	q := smartQuery{keywords: search.Keywords, feedIDs: search.FeedIds, tagIDs: search.TagIds}
+	if search.Since.Valid {
+		t, err := parseTimeArg("since", search.Since.String)
+		if err != nil {
+			return smartQuery{}, err
+		}
+		q.since = sql.NullTime{Time: t, Valid: true}
+	}
+	if search.Until.Valid {
+		t, err := parseTimeArg("until", search.Until.String)
+		if err != nil {
+			return smartQuery{}, err
+		}
+		q.until = sql.NullTime{Time: t, Valid: true}
+	}
+	return q, nil
+}
+
+// handlerSmart manages smart folders, saved searches browse can show: smart create|list|delete
+func handlerSmart(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("smart command requires a subcommand: create, list, delete")
+	}
+
+	sub := command{name: cmd.args[0], args: cmd.args[1:]}
+	switch sub.name {
+	case "create":
+		return handlerSmartCreate(s, sub, user)
+	case "list":
+		return handlerSmartList(s, sub, user)
+	case "delete":
+		return handlerSmartDelete(s, sub, user)
+	default:
+		return fmt.Errorf("unknown smart subcommand: %s", sub.name)
+	}
+}
+
+// handlerSmartCreate saves a search as a named smart folder
+func handlerSmartCreate(s *state, cmd command, user database.User) error {
+	var terms smartTerms
+	flags := newFlagSet("smart create")
+	flags.Func("query", "Match posts containing `terms`", func(value string) error {
+		for _, term := range strings.Fields(value) {
+			terms.add(term)
+		}
+		return nil
+	})
+	flags.Func("feed", "Match posts from the feed at `url`; repeat for more feeds", func(value string) error {
+		terms.feeds = append(terms.feeds, value)
+		return nil
+	})
+	flags.Func("tag", "Match posts with the tag `name`; repeat for more tags", func(value string) error {
+		terms.tags = append(terms.tags, value)
+		return nil
+	})
+	flags.StringVar(&terms.since, "since", "", "Match posts published since `when`")
+	flags.StringVar(&terms.until, "until", "", "Match posts published before `when`")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return errors.New("smart create requires a name argument")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected argument %q", args[1])
+	}
+	name := args[0]
+	if len(terms.keywords) == 0 && len(terms.feeds) == 0 && len(terms.tags) == 0 && terms.since == "" && terms.until == "" {
+		return errors.New("smart create requires --query, --feed, --tag, --since or --until")
+	}
+
+	// Check the dates now rather than every time the folder is browsed
+	for _, flag := range [][2]string{{"--since", terms.since}, {"--until", terms.until}} {
+		if flag[1] == "" {
+			continue
+		}
+		if _, err := parseTimeArg(flag[0], flag[1]); err != nil {
+			return err
+		}
+	}
+
+	feedIDs := []uuid.UUID{}
+	for _, feedURL := range terms.feeds {
+		feed, err := getFeedByURL(s.ctx, s.db, feedURL)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("feed %s doesn't exist", feedURL)
+			}
+			return fmt.Errorf("couldn't find feed: %w", err)
+		}
+		feedIDs = append(feedIDs, feed.ID)
+	}
+
+	tagIDs := []uuid.UUID{}
+	for _, tagName := range terms.tags {
+		tag, err := getTag(s, user, tagName)
+		if err != nil {
+			return err
+		}
+		tagIDs = append(tagIDs, tag.ID)
+	}
+
+	keywords := strings.Join(terms.keywords, " ")
+	search, err := s.db.CreateSavedSearch(s.ctx, database.CreateSavedSearchParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		Name:      name,
+		Keywords:  sql.NullString{String: keywords, Valid: keywords != ""},
+		FeedIds:   feedIDs,
+		TagIds:    tagIDs,
+		Since:     sql.NullString{String: terms.since, Valid: terms.since != ""},
+		Until:     sql.NullString{String: terms.until, Valid: terms.until != ""},
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return fmt.Errorf("smart folder %s already exists", name)
+		}
+		return fmt.Errorf("couldn't create smart folder: %w", err)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Smart folder created: %s (browse it with 'gator browse --smart %s')", search.Name, search.Name),
+		Item: smartFolderEntry{
+			Name:     search.Name,
+			Keywords: nullStringPtr(search.Keywords),
+			Feeds:    terms.feeds,
+			Tags:     terms.tags,
+			Since:    nullStringPtr(search.Since),
+			Until:    nullStringPtr(search.Until),
+		},
+	})
+}
+
+// handlerSmartList lists the current user's smart folders
+func handlerSmartList(s *state, cmd command, user database.User) error {
+	searches, err := s.db.GetSavedSearchesForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get smart folders: %w", err)
+	}
+
+	res := smartFoldersResult{Folders: []smartFolderEntry{}}
+	for _, search := range searches {
+		res.Folders = append(res.Folders, smartFolderEntry{
+			Name:     search.Name,
+			Keywords: nullStringPtr(search.Keywords),
+			Feeds:    search.FeedUrls,
+			Tags:     search.TagNames,
+			Since:    nullStringPtr(search.Since),
+			Until:    nullStringPtr(search.Until),
+		})
+	}
+	return s.emit(res)
+}
+
+// handlerSmartDelete deletes one of the current user's smart folders by name
+func handlerSmartDelete(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("smart delete requires a name argument")
+	}
+
+	name := cmd.args[0]
+	n, err := s.db.DeleteSavedSearch(s.ctx, database.DeleteSavedSearchParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't delete smart folder: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("smart folder %s doesn't exist", name)
+	}
+
+	return s.emit(messageResult{Message: fmt.Sprintf("Smart folder deleted: %s", name)})
+}
+
+// smartFolderEntry is a smart folder in the smart folder listing
+type smartFolderEntry struct {
+	Name     string   `json:"name"`
+	Keywords *string  `json:"keywords"`
+	Feeds    []string `json:"feeds"`
+	Tags     []string `json:"tags"`
+	Since    *string  `json:"since"`
+	Until    *string  `json:"until"`
+}
+
+// smartFoldersResult is the output of smart list
+type smartFoldersResult struct {
+	Folders []smartFolderEntry `json:"folders"`
+}
+
+func (r smartFoldersResult) writeText(w io.Writer) {
+	if len(r.Folders) == 0 {
+		fmt.Fprintln(w, "No smart folders found")
+		return
+	}
+
+	for _, folder := range r.Folders {
+		fmt.Fprintf(w, "* %s\n", folder.Name)
+		if folder.Keywords != nil {
+			fmt.Fprintf(w, "  Keywords: %s\n", *folder.Keywords)
+		}
+		if len(folder.Feeds) > 0 {
+			fmt.Fprintf(w, "  Feeds: %s\n", strings.Join(folder.Feeds, ", "))
+		}
+		if len(folder.Tags) > 0 {
+			fmt.Fprintf(w, "  Tags: %s\n", strings.Join(folder.Tags, ", "))
+		}
+		if folder.Since != nil {
+			fmt.Fprintf(w, "  Since: %s\n", *folder.Since)
+		}
+		if folder.Until != nil {
+			fmt.Fprintf(w, "  Until: %s\n", *folder.Until)
+		}
+	}
+}
+
+func (r smartFoldersResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, folder := range r.Folders {
+		keywords, since, until := "", "", ""
+		if folder.Keywords != nil {
+			keywords = *folder.Keywords
+		}
+		if folder.Since != nil {
+			since = *folder.Since
+		}
+		if folder.Until != nil {
+			until = *folder.Until
+		}
+		rows = append(rows, []string{
+			folder.Name,
+			keywords,
+			strings.Join(folder.Feeds, " "),
+			strings.Join(folder.Tags, " "),
+			since,
+			until,
+		})
+	}
+	return []string{"name", "keywords", "feeds", "tags", "since", "until"}, rows
+}
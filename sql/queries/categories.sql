@@ -0,0 +1,20 @@
+-- name: CreateCategory :one
+INSERT INTO categories (id, created_at, updated_at, user_id, name)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetCategoryByName :one
+SELECT * FROM categories
+WHERE user_id = $1 AND name = $2;
+
+-- name: GetCategoriesForUser :many
+SELECT categories.*, COUNT(feed_follows.id) AS feed_count
+FROM categories
+LEFT JOIN feed_follows ON feed_follows.category_id = categories.id
+WHERE categories.user_id = $1
+GROUP BY categories.id
+ORDER BY categories.name;
+
+-- name: DeleteCategory :exec
+DELETE FROM categories
+WHERE id = $1;
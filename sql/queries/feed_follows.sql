@@ -16,13 +16,64 @@ INNER JOIN users ON inserted_feed_follow.user_id = users.id;
 SELECT 
     feed_follows.*,
     feeds.name AS feed_name,
-    users.name AS user_name
+    users.name AS user_name,
+    categories.name AS category_name
 FROM feed_follows
 INNER JOIN feeds ON feed_follows.feed_id = feeds.id
 INNER JOIN users ON feed_follows.user_id = users.id
+LEFT JOIN categories ON feed_follows.category_id = categories.id
 WHERE feed_follows.user_id = $1;
 
 -- name: DeleteFeedFollow :exec
 DELETE FROM feed_follows
 WHERE user_id = $1 AND feed_id = $2;
 
+
+-- name: SetFeedFollowCategory :exec
+UPDATE feed_follows
+SET category_id = $3, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2;
+
+-- name: GetFeedFollow :one
+SELECT * FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2;
+
+-- name: SetFeedFollowMuted :exec
+UPDATE feed_follows
+SET muted = $3, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2;
+
+-- name: SetFeedFollowNote :exec
+UPDATE feed_follows
+SET note = $3, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2;
+
+-- name: GetFeedsFollowedBySimilarUsers :many
+WITH mine AS (
+    SELECT feed_follows.feed_id,
+        1 + LN(1
+            + (SELECT COUNT(*) FROM post_reads
+               INNER JOIN post_sources ON post_sources.post_id = post_reads.post_id
+               WHERE post_reads.user_id = sqlc.arg(user_id) AND post_sources.feed_id = feed_follows.feed_id)
+            + 2 * (SELECT COUNT(*) FROM saved_posts
+               INNER JOIN post_sources ON post_sources.post_id = saved_posts.post_id
+               WHERE saved_posts.user_id = sqlc.arg(user_id) AND post_sources.feed_id = feed_follows.feed_id)
+        ) AS weight
+    FROM feed_follows
+    WHERE feed_follows.user_id = sqlc.arg(user_id)
+),
+peers AS (
+    SELECT feed_follows.user_id, SUM(mine.weight) AS similarity
+    FROM feed_follows
+    INNER JOIN mine ON mine.feed_id = feed_follows.feed_id
+    WHERE feed_follows.user_id <> sqlc.arg(user_id)
+    GROUP BY feed_follows.user_id
+)
+SELECT feeds.id, feeds.name, feeds.url, COUNT(*) AS followers, SUM(peers.similarity)::float8 AS score
+FROM peers
+INNER JOIN feed_follows ON feed_follows.user_id = peers.user_id
+INNER JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.feed_id NOT IN (SELECT feed_id FROM mine)
+GROUP BY feeds.id
+ORDER BY score DESC, followers DESC, feeds.name
+LIMIT sqlc.arg(result_limit);
@@ -19,13 +19,124 @@ INNER JOIN users ON feeds.user_id = users.id;
 SELECT * FROM feeds
 WHERE url = $1;
 
+-- name: GetFeedsByURLs :many
+SELECT * FROM feeds
+WHERE url = ANY(sqlc.arg(urls)::text[])
+ORDER BY created_at;
+
 -- name: MarkFeedFetched :exec
 UPDATE feeds
 SET last_fetched_at = NOW(), updated_at = NOW()
 WHERE id = $1;
 
--- name: GetNextFeedToFetch :one
+-- name: GetNextFeedsToFetch :many
 SELECT * FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1;
+WHERE NOT paused AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+AND (claimed_until IS NULL OR claimed_until <= NOW())
+ORDER BY COALESCE(next_fetch_at, last_fetched_at + COALESCE(fetch_interval, 0) * INTERVAL '1 second') ASC NULLS FIRST, random()
+LIMIT $1;
+
+-- name: ClaimNextFeedsToFetch :many
+UPDATE feeds
+SET claimed_until = NOW() + sqlc.arg(lease_seconds)::int * INTERVAL '1 second'
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE NOT paused AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    AND (claimed_until IS NULL OR claimed_until <= NOW())
+    ORDER BY COALESCE(next_fetch_at, last_fetched_at + COALESCE(fetch_interval, 0) * INTERVAL '1 second') ASC NULLS FIRST, random()
+    LIMIT sqlc.arg(max_feeds)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+
+-- name: GetFeed :one
+SELECT * FROM feeds
+WHERE id = $1;
+
+-- name: SetFeedFetchInterval :exec
+UPDATE feeds
+SET fetch_interval = $2, next_fetch_at = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: ScheduleFeedFetch :exec
+UPDATE feeds
+SET next_fetch_at = $2, avg_post_interval = $3, claimed_until = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SpreadUnscheduledFeeds :execrows
+UPDATE feeds
+SET next_fetch_at = NOW() + random() * sqlc.arg(spread_seconds)::int * INTERVAL '1 second', updated_at = NOW()
+WHERE next_fetch_at IS NULL AND NOT paused;
+
+-- name: SetFeedExtractContent :exec
+UPDATE feeds
+SET extract_content = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CountOtherFeedFollowers :one
+SELECT COUNT(*) FROM feed_follows
+WHERE feed_id = $1 AND user_id <> $2;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE id = $1;
+
+-- name: SetFeedOwner :exec
+UPDATE feeds
+SET user_id = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: RenameFeed :exec
+UPDATE feeds
+SET name = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedURL :exec
+UPDATE feeds
+SET url = $2, moved_to = NULL, next_fetch_at = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedMovedTo :exec
+UPDATE feeds
+SET moved_to = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedChannel :exec
+UPDATE feeds
+SET channel_title = $2, channel_description = $3, channel_link = $4, channel_language = $5, updated_at = NOW()
+WHERE id = $1
+  AND (channel_title, channel_description, channel_link, channel_language) IS DISTINCT FROM ($2, $3, $4, $5);
+
+-- name: SetFeedPaused :exec
+UPDATE feeds
+SET paused = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CountDueFeeds :one
+SELECT COUNT(*) FROM feeds
+WHERE NOT paused AND (next_fetch_at IS NULL OR next_fetch_at <= NOW());
+
+-- name: CountPausedFeeds :one
+SELECT COUNT(*) FROM feeds
+WHERE paused;
+
+-- name: GetFeedIDsAddedByUser :many
+SELECT id FROM feeds
+WHERE user_id = $1;
 
+-- name: ReassignFeedPosts :execrows
+UPDATE posts SET feed_id = other.feed_id
+FROM (
+    SELECT DISTINCT ON (post_sources.post_id) post_sources.post_id, post_sources.feed_id
+    FROM post_sources
+    INNER JOIN posts AS moved ON moved.id = post_sources.post_id
+    WHERE moved.feed_id = sqlc.arg(feed_id)
+    AND NOT post_sources.feed_id = ANY(sqlc.arg(deleted_feed_ids)::uuid[])
+    AND NOT EXISTS (
+        SELECT 1 FROM posts AS taken
+        WHERE taken.feed_id = post_sources.feed_id AND taken.guid = moved.guid
+    )
+    ORDER BY post_sources.post_id, post_sources.created_at
+) AS other
+WHERE posts.id = other.post_id;
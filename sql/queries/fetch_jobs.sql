@@ -0,0 +1,35 @@
+-- name: EnqueueDueFeeds :execrows
+INSERT INTO fetch_jobs (feed_id, created_at)
+SELECT id, NOW() FROM feeds
+WHERE NOT paused AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+AND (claimed_until IS NULL OR claimed_until <= NOW())
+ON CONFLICT (feed_id) DO NOTHING;
+
+-- name: NotifyFetchJobs :exec
+SELECT pg_notify('gator_fetch_jobs', '');
+
+-- name: ClaimFetchJobs :many
+WITH claimed AS (
+    UPDATE fetch_jobs
+    SET claimed_until = NOW() + sqlc.arg(lease_seconds)::int * INTERVAL '1 second'
+    WHERE feed_id IN (
+        SELECT fetch_jobs.feed_id FROM fetch_jobs
+        INNER JOIN feeds ON fetch_jobs.feed_id = feeds.id
+        WHERE NOT feeds.paused
+        AND (fetch_jobs.claimed_until IS NULL OR fetch_jobs.claimed_until <= NOW())
+        ORDER BY fetch_jobs.created_at
+        LIMIT sqlc.arg(max_jobs)
+        FOR UPDATE OF fetch_jobs SKIP LOCKED
+    )
+    RETURNING feed_id
+)
+SELECT feeds.* FROM feeds
+INNER JOIN claimed ON feeds.id = claimed.feed_id;
+
+-- name: DeleteFetchJob :exec
+DELETE FROM fetch_jobs
+WHERE feed_id = $1;
+
+-- name: CountFetchJobs :one
+SELECT COUNT(*) AS queued, COUNT(*) FILTER (WHERE claimed_until > NOW()) AS claimed
+FROM fetch_jobs;
@@ -0,0 +1,38 @@
+-- name: CreateFetchLog :exec
+INSERT INTO fetch_log (id, created_at, feed_id, status_code, error, duration_ms, item_count, new_count, seen_count)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: TrimFetchLog :exec
+DELETE FROM fetch_log
+WHERE fetch_log.feed_id = sqlc.arg(feed_id) AND fetch_log.id NOT IN (
+    SELECT recent.id FROM fetch_log AS recent
+    WHERE recent.feed_id = sqlc.arg(feed_id)
+    ORDER BY recent.created_at DESC
+    LIMIT sqlc.arg(keep)
+);
+
+-- name: GetFetchLogForFeed :many
+SELECT * FROM fetch_log
+WHERE feed_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: RecordFeedFetchFailure :one
+UPDATE feeds
+SET consecutive_failures = consecutive_failures + 1, failure_count = failure_count + 1, last_error = $2
+WHERE id = $1
+RETURNING consecutive_failures;
+
+-- name: RecordFeedFetchSuccess :exec
+UPDATE feeds
+SET consecutive_failures = 0, last_error = NULL, last_success_at = NOW()
+WHERE id = $1;
+
+-- name: GetFeedHealthForUser :many
+SELECT feeds.*, feed_follows.note, COUNT(fetch_log.id) AS attempts, COUNT(fetch_log.error) AS failures
+FROM feeds
+INNER JOIN feed_follows ON feed_follows.feed_id = feeds.id
+LEFT JOIN fetch_log ON fetch_log.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+GROUP BY feeds.id, feed_follows.note
+ORDER BY feeds.consecutive_failures DESC, COUNT(fetch_log.error) DESC, feeds.name;
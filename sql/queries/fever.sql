@@ -0,0 +1,152 @@
+-- name: GetFeverFeedsForUser :many
+SELECT feeds.fever_id, feeds.name, feeds.url, feeds.last_fetched_at
+FROM feeds
+INNER JOIN feed_follows ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.fever_id;
+
+-- name: GetFeverItemsSince :many
+SELECT
+    posts.fever_id,
+    feeds.fever_id AS feed_fever_id,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.created_at,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = sqlc.arg(user_id)
+    ) AS is_saved
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+)
+AND posts.fever_id > sqlc.arg(since_id)
+ORDER BY posts.fever_id ASC
+LIMIT 50;
+
+-- name: GetFeverItemsBefore :many
+SELECT
+    posts.fever_id,
+    feeds.fever_id AS feed_fever_id,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.created_at,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = sqlc.arg(user_id)
+    ) AS is_saved
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+)
+AND posts.fever_id < sqlc.arg(max_id)
+ORDER BY posts.fever_id DESC
+LIMIT 50;
+
+-- name: GetFeverItemsByIDs :many
+SELECT
+    posts.fever_id,
+    feeds.fever_id AS feed_fever_id,
+    posts.title,
+    posts.url,
+    posts.description,
+    posts.published_at,
+    posts.created_at,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = sqlc.arg(user_id)
+    ) AS is_saved
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+)
+AND posts.fever_id = ANY(sqlc.arg(ids)::BIGINT[])
+ORDER BY posts.fever_id ASC
+LIMIT 50;
+
+-- name: CountFeverItems :one
+SELECT COUNT(*) FROM posts
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = $1
+);
+
+-- name: GetFeverUnreadItemIDs :many
+SELECT posts.fever_id FROM posts
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = $1
+)
+AND NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id AND post_reads.user_id = $1
+)
+ORDER BY posts.fever_id;
+
+-- name: GetFeverSavedItemIDs :many
+SELECT posts.fever_id FROM posts
+INNER JOIN saved_posts ON posts.id = saved_posts.post_id
+WHERE saved_posts.user_id = $1
+ORDER BY posts.fever_id;
+
+-- name: GetPostByFeverID :one
+SELECT posts.* FROM posts
+WHERE posts.fever_id = sqlc.arg(fever_id)
+AND EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+);
+
+-- name: MarkFeverFeedReadBefore :exec
+INSERT INTO post_reads (id, created_at, updated_at, user_id, post_id)
+SELECT gen_random_uuid(), NOW(), NOW(), sqlc.arg(user_id), posts.id
+FROM posts
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feeds ON feeds.id = post_sources.feed_id
+    INNER JOIN feed_follows ON feed_follows.feed_id = feeds.id
+    WHERE post_sources.post_id = posts.id AND feeds.fever_id = sqlc.arg(feed_fever_id)
+    AND feed_follows.user_id = sqlc.arg(user_id)
+)
+AND posts.created_at < sqlc.arg(before)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkAllReadBefore :exec
+INSERT INTO post_reads (id, created_at, updated_at, user_id, post_id)
+SELECT gen_random_uuid(), NOW(), NOW(), sqlc.arg(user_id), posts.id
+FROM posts
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+)
+AND posts.created_at < sqlc.arg(before)
+ON CONFLICT (user_id, post_id) DO NOTHING;
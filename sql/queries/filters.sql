@@ -0,0 +1,23 @@
+-- name: CreateFilter :one
+INSERT INTO filters (id, created_at, updated_at, user_id, feed_id, action, pattern, is_regex, at_ingest)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetFiltersForUser :many
+SELECT filters.*, feeds.url AS feed_url
+FROM filters
+LEFT JOIN feeds ON filters.feed_id = feeds.id
+WHERE filters.user_id = $1
+ORDER BY filters.created_at;
+
+-- name: GetIngestFiltersForFeed :many
+SELECT filters.* FROM filters
+INNER JOIN feeds ON feeds.id = sqlc.arg(feed_id)
+WHERE filters.at_ingest
+AND filters.user_id = feeds.user_id
+AND (filters.feed_id IS NULL OR filters.feed_id = feeds.id)
+ORDER BY filters.created_at;
+
+-- name: DeleteFilter :execrows
+DELETE FROM filters
+WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,50 @@
+-- name: GetGReaderItemIDs :many
+SELECT posts.fever_id
+FROM posts
+WHERE (
+    CASE WHEN sqlc.arg(starred_only)::bool THEN EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = sqlc.arg(user_id)
+    ) ELSE EXISTS (
+        SELECT 1 FROM post_sources
+        INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+        WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+        AND (sqlc.narg(feed_id)::uuid IS NULL OR post_sources.feed_id = sqlc.narg(feed_id))
+        AND (sqlc.narg(category_id)::uuid IS NULL OR feed_follows.category_id = sqlc.narg(category_id))
+    ) END
+)
+AND (NOT sqlc.arg(unread_only)::bool OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+))
+AND (NOT sqlc.arg(read_only)::bool OR EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+))
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.created_at >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR posts.created_at < sqlc.narg(until))
+AND (sqlc.narg(continuation)::bigint IS NULL OR (
+    CASE WHEN sqlc.arg(oldest_first)::bool THEN posts.fever_id > sqlc.narg(continuation)
+    ELSE posts.fever_id < sqlc.narg(continuation) END
+))
+ORDER BY
+    CASE WHEN sqlc.arg(oldest_first)::bool THEN posts.fever_id END ASC,
+    CASE WHEN NOT sqlc.arg(oldest_first)::bool THEN posts.fever_id END DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetGReaderItems :many
+SELECT
+    posts.*,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = sqlc.arg(user_id)
+    ) AS is_saved
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.fever_id = ANY(sqlc.arg(ids)::BIGINT[]);
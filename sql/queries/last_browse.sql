@@ -0,0 +1,13 @@
+-- name: ClearLastBrowse :exec
+DELETE FROM last_browse
+WHERE user_id = $1;
+
+-- name: SaveLastBrowse :exec
+INSERT INTO last_browse (user_id, position, post_id)
+SELECT sqlc.arg(user_id), t.position, t.post_id
+FROM unnest(sqlc.arg(post_ids)::uuid[]) WITH ORDINALITY AS t(post_id, position);
+
+-- name: GetLastBrowsePost :one
+SELECT posts.* FROM posts
+INNER JOIN last_browse ON last_browse.post_id = posts.id
+WHERE last_browse.user_id = $1 AND last_browse.position = $2;
@@ -0,0 +1,9 @@
+-- name: AddPostCategories :exec
+INSERT INTO post_categories (post_id, name)
+SELECT * FROM unnest(sqlc.arg(post_ids)::uuid[], sqlc.arg(names)::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: GetPostCategories :many
+SELECT name FROM post_categories
+WHERE post_id = $1
+ORDER BY name;
@@ -0,0 +1,15 @@
+-- name: CreatePostImage :exec
+INSERT INTO post_images (id, created_at, updated_at, post_id, url, kind, width, height)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (post_id, url) DO NOTHING;
+
+-- name: GetImagesForPost :many
+SELECT * FROM post_images
+WHERE post_id = $1
+ORDER BY CASE kind WHEN 'thumbnail' THEN 0 WHEN 'content' THEN 1 ELSE 2 END, created_at;
+
+-- name: GetPostThumbnails :many
+SELECT DISTINCT ON (post_id) post_id, url
+FROM post_images
+WHERE post_id = ANY(sqlc.arg(post_ids)::uuid[])
+ORDER BY post_id, CASE kind WHEN 'thumbnail' THEN 0 WHEN 'content' THEN 1 ELSE 2 END, created_at;
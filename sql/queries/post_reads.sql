@@ -0,0 +1,74 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_reads (id, created_at, updated_at, user_id, post_id)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkPostUnread :exec
+DELETE FROM post_reads
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: MarkPostsRead :execrows
+INSERT INTO post_reads (id, created_at, updated_at, user_id, post_id)
+SELECT gen_random_uuid(), NOW(), NOW(), sqlc.arg(user_id), posts.id
+FROM posts
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+    AND (sqlc.narg(feed_id)::uuid IS NULL OR post_sources.feed_id = sqlc.narg(feed_id))
+)
+AND (sqlc.narg(before)::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) < sqlc.narg(before))
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: GetUnreadCountsForUser :many
+SELECT post_sources.feed_id, COUNT(DISTINCT posts.id) AS unread_count
+FROM feed_follows
+INNER JOIN post_sources ON post_sources.feed_id = feed_follows.feed_id
+INNER JOIN posts ON posts.id = post_sources.post_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+)
+GROUP BY post_sources.feed_id;
+
+-- name: GetSurprisePostsForUser :many
+WITH recent_reads AS (
+    SELECT post_sources.feed_id, COUNT(*) AS read_count
+    FROM post_reads
+    INNER JOIN post_sources ON post_sources.post_id = post_reads.post_id
+    WHERE post_reads.user_id = sqlc.arg(user_id) AND post_reads.created_at > NOW() - INTERVAL '30 days'
+    GROUP BY post_sources.feed_id
+),
+picks AS (
+    SELECT
+        post_sources.post_id, post_sources.feed_id, COALESCE(recent_reads.read_count, 0) AS read_count,
+        row_number() OVER (PARTITION BY post_sources.feed_id ORDER BY random()) AS feed_rank
+    FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+    LEFT JOIN recent_reads ON recent_reads.feed_id = post_sources.feed_id
+    WHERE NOT EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = post_sources.post_id AND post_reads.user_id = sqlc.arg(user_id)
+    )
+)
+SELECT posts.*, feeds.name AS feed_name, picks.read_count::bigint AS recent_reads
+FROM picks
+INNER JOIN posts ON posts.id = picks.post_id
+INNER JOIN feeds ON feeds.id = picks.feed_id
+WHERE picks.feed_rank <= sqlc.arg(result_limit)::int
+ORDER BY picks.feed_rank, -LN(1 - random()) * (1 + picks.read_count)
+LIMIT sqlc.arg(result_limit);
+
+-- name: GetEngagedPostsForUser :many
+SELECT posts.url, posts.description, posts.content
+FROM posts
+WHERE EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+) OR EXISTS (
+    SELECT 1 FROM saved_posts
+    WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = sqlc.arg(user_id)
+)
+ORDER BY posts.created_at DESC
+LIMIT sqlc.arg(result_limit);
@@ -0,0 +1,34 @@
+-- name: AddPostSource :exec
+INSERT INTO post_sources (post_id, feed_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: LockPostIngest :exec
+SELECT pg_advisory_xact_lock(hashtext('gator post ingest'));
+
+-- name: SetPostGuids :exec
+UPDATE posts SET guid = adopted.guid
+FROM unnest(sqlc.arg(ids)::uuid[], sqlc.arg(guids)::text[]) AS adopted (id, guid)
+WHERE posts.id = adopted.id;
+
+-- name: AddPostSources :exec
+INSERT INTO post_sources (post_id, feed_id, created_at)
+SELECT unnest(sqlc.arg(post_ids)::uuid[]), sqlc.arg(feed_id)::uuid, NOW()
+ON CONFLICT DO NOTHING;
+
+-- name: FindDuplicatePosts :many
+SELECT * FROM posts
+WHERE (feed_id = sqlc.arg(feed_id) AND guid = ANY(sqlc.arg(guids)::text[]))
+OR url = ANY(sqlc.arg(urls)::text[])
+OR canonical_url = ANY(sqlc.arg(canonical_urls)::text[])
+OR content_hash = ANY(sqlc.arg(content_hashes)::text[])
+ORDER BY created_at;
+
+-- name: GetOtherPostSources :many
+SELECT post_sources.post_id, feeds.name AS feed_name
+FROM post_sources
+INNER JOIN posts ON posts.id = post_sources.post_id
+INNER JOIN feeds ON feeds.id = post_sources.feed_id
+WHERE post_sources.post_id = ANY(sqlc.arg(post_ids)::uuid[])
+AND post_sources.feed_id <> posts.feed_id
+ORDER BY feeds.name;
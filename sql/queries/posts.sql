@@ -1,12 +1,390 @@
--- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+-- name: CreatePosts :many
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, enclosure_url, enclosure_type, enclosure_length, duration_seconds, published_at_estimated, canonical_url, content_hash, author, guid)
+SELECT
+    new_posts.id, NOW(), NOW(), new_posts.title, new_posts.url, NULLIF(new_posts.description, ''),
+    NULLIF(new_posts.published_at, '0001-01-01 00:00:00'), sqlc.arg(feed_id)::uuid, NULLIF(new_posts.enclosure_url, ''),
+    NULLIF(new_posts.enclosure_type, ''), NULLIF(new_posts.enclosure_length, 0), NULLIF(new_posts.duration_seconds, 0),
+    new_posts.published_at_estimated, NULLIF(new_posts.canonical_url, ''), NULLIF(new_posts.content_hash, ''),
+    NULLIF(new_posts.author, ''), new_posts.guid
+FROM unnest(
+    sqlc.arg(ids)::uuid[],
+    sqlc.arg(titles)::text[],
+    sqlc.arg(urls)::text[],
+    sqlc.arg(descriptions)::text[],
+    sqlc.arg(published_ats)::timestamp[],
+    sqlc.arg(enclosure_urls)::text[],
+    sqlc.arg(enclosure_types)::text[],
+    sqlc.arg(enclosure_lengths)::bigint[],
+    sqlc.arg(duration_seconds)::integer[],
+    sqlc.arg(published_at_estimated)::boolean[],
+    sqlc.arg(canonical_urls)::text[],
+    sqlc.arg(content_hashes)::text[],
+    sqlc.arg(authors)::text[],
+    sqlc.arg(guids)::text[]
+) AS new_posts (
+    id, title, url, description, published_at, enclosure_url, enclosure_type, enclosure_length,
+    duration_seconds, published_at_estimated, canonical_url, content_hash, author, guid
+)
+ON CONFLICT (feed_id, guid) DO UPDATE SET
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    published_at = CASE WHEN EXCLUDED.published_at_estimated THEN posts.published_at ELSE EXCLUDED.published_at END,
+    canonical_url = EXCLUDED.canonical_url,
+    content_hash = EXCLUDED.content_hash,
+    author = EXCLUDED.author,
+    updated_at = NOW()
+WHERE posts.content_hash IS DISTINCT FROM EXCLUDED.content_hash OR posts.title IS DISTINCT FROM EXCLUDED.title
 RETURNING *;
 
 -- name: GetPostsForUser :many
 SELECT posts.* FROM posts
-INNER JOIN feed_follows ON posts.feed_id = feed_follows.feed_id
-WHERE feed_follows.user_id = $1
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+    AND (sqlc.narg(category_id)::uuid IS NULL OR feed_follows.category_id = sqlc.narg(category_id))
+)
+AND (sqlc.narg(tag_id)::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM post_tags
+    WHERE post_tags.post_id = posts.id AND post_tags.tag_id = sqlc.narg(tag_id)
+))
+AND (sqlc.narg(since)::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) < sqlc.narg(until))
+AND (sqlc.narg(keywords)::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', sqlc.narg(keywords)))
+AND (sqlc.narg(author)::text IS NULL OR strpos(lower(posts.author), lower(sqlc.narg(author))) > 0)
+AND (sqlc.narg(max_words)::int IS NULL OR posts.word_count BETWEEN 1 AND sqlc.narg(max_words))
+AND (sqlc.narg(feed_category)::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_categories
+    WHERE post_categories.post_id = posts.id AND lower(post_categories.name) = lower(sqlc.narg(feed_category))
+))
+AND (COALESCE(cardinality(sqlc.arg(feed_ids)::uuid[]), 0) = 0 OR EXISTS (
+    SELECT 1 FROM post_sources
+    WHERE post_sources.post_id = posts.id AND post_sources.feed_id = ANY(sqlc.arg(feed_ids)::uuid[])
+))
+AND (COALESCE(cardinality(sqlc.arg(tag_ids)::uuid[]), 0) = 0 OR EXISTS (
+    SELECT 1 FROM post_tags
+    WHERE post_tags.post_id = posts.id AND post_tags.tag_id = ANY(sqlc.arg(tag_ids)::uuid[])
+))
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::text = 'published' AND sqlc.arg(sort_desc)::bool THEN posts.published_at END DESC NULLS LAST,
+    CASE WHEN sqlc.arg(sort_by)::text = 'published' AND NOT sqlc.arg(sort_desc)::bool THEN posts.published_at END ASC NULLS LAST,
+    CASE WHEN sqlc.arg(sort_by)::text = 'added' AND sqlc.arg(sort_desc)::bool THEN posts.created_at END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'added' AND NOT sqlc.arg(sort_desc)::bool THEN posts.created_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'updated' AND sqlc.arg(sort_desc)::bool THEN posts.updated_at END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'updated' AND NOT sqlc.arg(sort_desc)::bool THEN posts.updated_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'feed' AND sqlc.arg(sort_desc)::bool THEN feeds.name END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'feed' AND NOT sqlc.arg(sort_desc)::bool THEN feeds.name END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'title' AND sqlc.arg(sort_desc)::bool THEN posts.title END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'title' AND NOT sqlc.arg(sort_desc)::bool THEN posts.title END ASC,
+    posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+
+-- name: GetUnreadPostsForUser :many
+SELECT posts.* FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+    AND (sqlc.narg(category_id)::uuid IS NULL OR feed_follows.category_id = sqlc.narg(category_id))
+)
+AND (sqlc.narg(tag_id)::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM post_tags
+    WHERE post_tags.post_id = posts.id AND post_tags.tag_id = sqlc.narg(tag_id)
+))
+AND (sqlc.narg(since)::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) < sqlc.narg(until))
+AND (sqlc.narg(keywords)::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', sqlc.narg(keywords)))
+AND (sqlc.narg(author)::text IS NULL OR strpos(lower(posts.author), lower(sqlc.narg(author))) > 0)
+AND (sqlc.narg(max_words)::int IS NULL OR posts.word_count BETWEEN 1 AND sqlc.narg(max_words))
+AND (sqlc.narg(feed_category)::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_categories
+    WHERE post_categories.post_id = posts.id AND lower(post_categories.name) = lower(sqlc.narg(feed_category))
+))
+AND (COALESCE(cardinality(sqlc.arg(feed_ids)::uuid[]), 0) = 0 OR EXISTS (
+    SELECT 1 FROM post_sources
+    WHERE post_sources.post_id = posts.id AND post_sources.feed_id = ANY(sqlc.arg(feed_ids)::uuid[])
+))
+AND (COALESCE(cardinality(sqlc.arg(tag_ids)::uuid[]), 0) = 0 OR EXISTS (
+    SELECT 1 FROM post_tags
+    WHERE post_tags.post_id = posts.id AND post_tags.tag_id = ANY(sqlc.arg(tag_ids)::uuid[])
+))
+AND NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+)
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::text = 'published' AND sqlc.arg(sort_desc)::bool THEN posts.published_at END DESC NULLS LAST,
+    CASE WHEN sqlc.arg(sort_by)::text = 'published' AND NOT sqlc.arg(sort_desc)::bool THEN posts.published_at END ASC NULLS LAST,
+    CASE WHEN sqlc.arg(sort_by)::text = 'added' AND sqlc.arg(sort_desc)::bool THEN posts.created_at END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'added' AND NOT sqlc.arg(sort_desc)::bool THEN posts.created_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'updated' AND sqlc.arg(sort_desc)::bool THEN posts.updated_at END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'updated' AND NOT sqlc.arg(sort_desc)::bool THEN posts.updated_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'feed' AND sqlc.arg(sort_desc)::bool THEN feeds.name END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'feed' AND NOT sqlc.arg(sort_desc)::bool THEN feeds.name END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'title' AND sqlc.arg(sort_desc)::bool THEN posts.title END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'title' AND NOT sqlc.arg(sort_desc)::bool THEN posts.title END ASC,
+    posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetPostsForUserByFeed :many
+SELECT posts.* FROM posts
+INNER JOIN post_sources ON post_sources.post_id = posts.id AND post_sources.feed_id = sqlc.arg(feed_id)
+INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+WHERE (sqlc.narg(since)::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) < sqlc.narg(until))
+AND (sqlc.narg(author)::text IS NULL OR strpos(lower(posts.author), lower(sqlc.narg(author))) > 0)
+AND (sqlc.narg(max_words)::int IS NULL OR posts.word_count BETWEEN 1 AND sqlc.narg(max_words))
+AND (sqlc.narg(feed_category)::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_categories
+    WHERE post_categories.post_id = posts.id AND lower(post_categories.name) = lower(sqlc.narg(feed_category))
+))
+AND (NOT sqlc.arg(unread_only)::bool OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+))
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::text = 'published' AND sqlc.arg(sort_desc)::bool THEN posts.published_at END DESC NULLS LAST,
+    CASE WHEN sqlc.arg(sort_by)::text = 'published' AND NOT sqlc.arg(sort_desc)::bool THEN posts.published_at END ASC NULLS LAST,
+    CASE WHEN sqlc.arg(sort_by)::text = 'added' AND sqlc.arg(sort_desc)::bool THEN posts.created_at END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'added' AND NOT sqlc.arg(sort_desc)::bool THEN posts.created_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'updated' AND sqlc.arg(sort_desc)::bool THEN posts.updated_at END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'updated' AND NOT sqlc.arg(sort_desc)::bool THEN posts.updated_at END ASC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'title' AND sqlc.arg(sort_desc)::bool THEN posts.title END DESC,
+    CASE WHEN sqlc.arg(sort_by)::text = 'title' AND NOT sqlc.arg(sort_desc)::bool THEN posts.title END ASC,
+    posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetPost :one
+SELECT * FROM posts
+WHERE id = $1;
+
+-- name: GetPostForUser :one
+SELECT posts.* FROM posts
+WHERE posts.id = sqlc.arg(id)
+AND (EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+) OR EXISTS (
+    SELECT 1 FROM saved_posts
+    WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = sqlc.arg(user_id)
+));
+
+-- name: NotifyNewPosts :exec
+SELECT pg_notify('gator_new_posts', id::text)
+FROM unnest(sqlc.arg(post_ids)::uuid[]) AS id;
+
+-- name: GetNewPostsForUser :many
+SELECT posts.*, feeds.name AS feed_name FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.id = ANY(sqlc.arg(post_ids)::uuid[])
+AND EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+)
+ORDER BY COALESCE(posts.published_at, posts.created_at), posts.id;
+
+-- name: GetPostByURL :one
+SELECT * FROM posts
+WHERE url = $1
+ORDER BY created_at
+LIMIT 1;
+
+-- name: SearchPostsForUser :many
+SELECT posts.*, ts_rank(posts.search_vector, websearch_to_tsquery('english', sqlc.arg(query))) AS rank
+FROM posts
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+)
+AND posts.search_vector @@ websearch_to_tsquery('english', sqlc.arg(query))
+ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg(result_limit);
+
+-- name: SearchPosts :many
+SELECT posts.*, ts_rank(posts.search_vector, websearch_to_tsquery('english', sqlc.arg(query))) AS rank
+FROM posts
+WHERE posts.search_vector @@ websearch_to_tsquery('english', sqlc.arg(query))
+ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg(result_limit);
+
+-- name: GetPostsForFeedWithState :many
+SELECT
+    posts.*,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = sqlc.arg(user_id)
+    ) AS is_saved
+FROM posts
+INNER JOIN post_sources ON post_sources.post_id = posts.id AND post_sources.feed_id = sqlc.arg(feed_id)
 ORDER BY posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg(result_limit);
+
+-- name: GetPostDetail :one
+SELECT
+    posts.*,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = sqlc.arg(user_id)
+    ) AS is_saved
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.id = sqlc.arg(id);
+
+-- name: GetPostsForExport :many
+SELECT
+    posts.*,
+    feeds.name AS feed_name,
+    post_reads.created_at AS read_at,
+    saved_posts.id IS NOT NULL AS is_saved,
+    ARRAY(
+        SELECT tags.name FROM tags
+        INNER JOIN post_tags ON post_tags.tag_id = tags.id
+        WHERE post_tags.post_id = posts.id AND tags.user_id = sqlc.arg(user_id)
+        ORDER BY tags.name
+    )::text[] AS tags
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+LEFT JOIN post_reads ON post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+LEFT JOIN saved_posts ON saved_posts.post_id = posts.id AND saved_posts.user_id = sqlc.arg(user_id)
+WHERE (
+    post_reads.id IS NOT NULL
+    OR saved_posts.id IS NOT NULL
+    OR EXISTS (
+        SELECT 1 FROM post_sources
+        INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+        WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+    )
+)
+AND (sqlc.narg(since)::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) >= sqlc.narg(since))
+AND (NOT sqlc.arg(read_only)::bool OR post_reads.id IS NOT NULL)
+AND (NOT sqlc.arg(saved_only)::bool OR saved_posts.id IS NOT NULL)
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC;
+
+-- name: GetRecentPublishTimesForFeed :many
+SELECT published_at FROM posts
+WHERE feed_id = $1 AND published_at IS NOT NULL
+ORDER BY published_at DESC
 LIMIT $2;
 
+-- name: GetLastPostTimes :many
+SELECT post_sources.feed_id, MAX(COALESCE(posts.published_at, posts.created_at))::timestamp AS last_post_at
+FROM post_sources
+INNER JOIN posts ON posts.id = post_sources.post_id
+GROUP BY post_sources.feed_id;
+
+-- name: PrunePosts :one
+WITH pruned AS (
+    DELETE FROM posts
+    WHERE COALESCE(published_at, created_at) < sqlc.arg(cutoff)
+    AND NOT EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM queued_posts
+        WHERE queued_posts.post_id = posts.id
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.created_at >= sqlc.arg(cutoff)
+    )
+    RETURNING id, feed_id, guid, url
+), tombstones AS (
+    INSERT INTO pruned_posts (feed_id, guid, url, seen_at)
+    SELECT DISTINCT ON (gone.feed_id, gone.url) gone.feed_id, gone.guid, gone.url, NOW()
+    FROM (
+        SELECT pruned.feed_id, pruned.guid, pruned.url FROM pruned
+        UNION ALL
+        SELECT post_sources.feed_id, NULL, pruned.url FROM pruned
+        INNER JOIN post_sources ON post_sources.post_id = pruned.id
+        WHERE post_sources.feed_id <> pruned.feed_id
+    ) AS gone
+    ORDER BY gone.feed_id, gone.url, gone.guid NULLS LAST
+    ON CONFLICT (feed_id, url) DO UPDATE SET
+        guid = COALESCE(EXCLUDED.guid, pruned_posts.guid),
+        seen_at = EXCLUDED.seen_at
+), expired AS (
+    DELETE FROM pruned_posts
+    WHERE seen_at < NOW() - INTERVAL '30 days'
+)
+SELECT COUNT(*) FROM pruned;
+
+-- name: GetPrunedPosts :many
+SELECT guid, url FROM pruned_posts
+WHERE feed_id = sqlc.arg(feed_id)
+AND (guid = ANY(sqlc.arg(guids)::text[]) OR url = ANY(sqlc.arg(urls)::text[]));
+
+-- name: TouchPrunedPosts :exec
+UPDATE pruned_posts SET seen_at = NOW()
+WHERE feed_id = sqlc.arg(feed_id)
+AND (guid = ANY(sqlc.arg(guids)::text[]) OR url = ANY(sqlc.arg(urls)::text[]));
+
+-- name: GetDigestPostsForUser :many
+SELECT posts.*, feeds.name AS feed_name, categories.name AS category_name
+FROM posts
+INNER JOIN post_sources ON post_sources.post_id = posts.id
+INNER JOIN feeds ON post_sources.feed_id = feeds.id
+INNER JOIN feed_follows ON feed_follows.feed_id = feeds.id
+LEFT JOIN categories ON feed_follows.category_id = categories.id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND posts.created_at >= sqlc.arg(since)
+ORDER BY categories.name ASC NULLS LAST, feeds.name ASC, posts.published_at DESC NULLS LAST;
+
+-- name: SetPostContent :exec
+UPDATE posts
+SET content = $2, word_count = $3, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetPodcastEpisodesForUser :many
+SELECT posts.*, feeds.name AS feed_name
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+)
+AND posts.enclosure_url IS NOT NULL
+AND (sqlc.narg(feed_id)::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM post_sources
+    WHERE post_sources.post_id = posts.id AND post_sources.feed_id = sqlc.narg(feed_id)
+))
+ORDER BY posts.published_at DESC NULLS LAST, posts.created_at DESC
+LIMIT sqlc.arg(result_limit);
+
+-- name: DeleteAllPosts :execrows
+DELETE FROM posts;
+
+-- name: DeletePostsForFeedsOfUser :execrows
+WITH user_feeds AS (
+    SELECT id FROM feeds
+    WHERE user_id = $1
+), unlinked AS (
+    DELETE FROM post_sources
+    WHERE feed_id IN (SELECT id FROM user_feeds)
+)
+DELETE FROM posts
+WHERE (posts.feed_id IN (SELECT id FROM user_feeds) OR EXISTS (
+    SELECT 1 FROM post_sources
+    WHERE post_sources.post_id = posts.id AND post_sources.feed_id IN (SELECT id FROM user_feeds)
+))
+AND NOT EXISTS (
+    SELECT 1 FROM post_sources
+    WHERE post_sources.post_id = posts.id AND post_sources.feed_id NOT IN (SELECT id FROM user_feeds)
+);
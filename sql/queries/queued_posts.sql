@@ -0,0 +1,31 @@
+-- name: QueuePost :execrows
+INSERT INTO queued_posts (id, created_at, updated_at, user_id, post_id, position)
+SELECT sqlc.arg(id), sqlc.arg(created_at), sqlc.arg(updated_at), sqlc.arg(user_id), sqlc.arg(post_id),
+    CASE WHEN sqlc.arg(first)::bool THEN COALESCE(MIN(position), 1) - 1
+    ELSE COALESCE(MAX(position), 0) + 1 END
+FROM queued_posts
+WHERE user_id = sqlc.arg(user_id)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET position = EXCLUDED.position, updated_at = EXCLUDED.updated_at
+WHERE sqlc.arg(first)::bool;
+
+-- name: DequeuePost :execrows
+DELETE FROM queued_posts
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetQueuedPostsForUser :many
+SELECT posts.* FROM posts
+INNER JOIN queued_posts ON posts.id = queued_posts.post_id
+WHERE queued_posts.user_id = $1
+ORDER BY queued_posts.position, queued_posts.created_at;
+
+-- name: PopQueuedPost :one
+DELETE FROM queued_posts
+WHERE id = (
+    SELECT id FROM queued_posts
+    WHERE user_id = $1
+    ORDER BY position, created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING post_id;
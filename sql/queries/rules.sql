@@ -0,0 +1,25 @@
+-- name: CreateRule :one
+INSERT INTO rules (id, created_at, updated_at, user_id, expression, action, tag)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetRulesForUser :many
+SELECT * FROM rules
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetRulesForFeed :many
+SELECT rules.*, users.name AS user_name, rules.user_id = feeds.user_id AS owns_feed
+FROM rules
+INNER JOIN users ON users.id = rules.user_id
+INNER JOIN feeds ON feeds.id = sqlc.arg(feed_id)
+WHERE rules.user_id = feeds.user_id
+OR EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.feed_id = feeds.id AND feed_follows.user_id = rules.user_id
+)
+ORDER BY rules.created_at;
+
+-- name: DeleteRule :execrows
+DELETE FROM rules
+WHERE id = $1 AND user_id = $2;
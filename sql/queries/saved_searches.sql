@@ -0,0 +1,29 @@
+-- name: CreateSavedSearch :one
+INSERT INTO saved_searches (id, created_at, updated_at, user_id, name, keywords, feed_ids, tag_ids, since, until)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetSavedSearch :one
+SELECT * FROM saved_searches
+WHERE user_id = $1 AND name = $2;
+
+-- name: GetSavedSearchesForUser :many
+SELECT
+    saved_searches.*,
+    ARRAY(
+        SELECT feeds.url FROM feeds
+        WHERE feeds.id = ANY(saved_searches.feed_ids)
+        ORDER BY feeds.url
+    )::text[] AS feed_urls,
+    ARRAY(
+        SELECT tags.name FROM tags
+        WHERE tags.id = ANY(saved_searches.tag_ids)
+        ORDER BY tags.name
+    )::text[] AS tag_names
+FROM saved_searches
+WHERE saved_searches.user_id = $1
+ORDER BY saved_searches.name;
+
+-- name: DeleteSavedSearch :execrows
+DELETE FROM saved_searches
+WHERE user_id = $1 AND name = $2;
@@ -0,0 +1,60 @@
+-- name: GetFeedStatsForUser :many
+SELECT
+    feeds.id,
+    feeds.created_at,
+    feeds.name,
+    feeds.url,
+    feeds.paused,
+    feeds.consecutive_failures,
+    COUNT(posts.id) AS post_count,
+    COUNT(posts.id) FILTER (
+        WHERE COALESCE(posts.published_at, posts.created_at) >= NOW() - INTERVAL '28 days'
+    ) AS posts_last_28_days,
+    COUNT(posts.id) FILTER (
+        WHERE COALESCE(posts.published_at, posts.created_at) >= NOW() - INTERVAL '90 days'
+    ) AS posts_last_90_days,
+    COUNT(post_reads.id) AS read_count
+FROM feed_follows
+INNER JOIN feeds ON feeds.id = feed_follows.feed_id
+LEFT JOIN post_sources ON post_sources.feed_id = feeds.id
+LEFT JOIN posts ON posts.id = post_sources.post_id
+LEFT JOIN post_reads ON post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+GROUP BY feeds.id
+ORDER BY COUNT(posts.id) DESC, feeds.name;
+
+-- name: GetWeeklyPostCountsForUser :many
+SELECT
+    date_trunc('week', COALESCE(posts.published_at, posts.created_at))::timestamp AS week,
+    COUNT(*) AS post_count
+FROM posts
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+)
+AND COALESCE(posts.published_at, posts.created_at) >= sqlc.arg(since)
+GROUP BY week
+ORDER BY week;
+
+-- name: GetReadStatsForUser :one
+SELECT COUNT(posts.id) AS total_posts, COUNT(post_reads.id) AS read_posts
+FROM posts
+LEFT JOIN post_reads ON post_reads.post_id = posts.id AND post_reads.user_id = sqlc.arg(user_id)
+WHERE EXISTS (
+    SELECT 1 FROM post_sources
+    INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+    WHERE post_sources.post_id = posts.id AND feed_follows.user_id = sqlc.arg(user_id)
+);
+
+-- name: GetDatabaseSize :one
+SELECT pg_database_size(current_database())::bigint AS size;
+
+-- name: GetRecentTitlesForUser :many
+SELECT posts.id, posts.title, post_sources.feed_id, feeds.name AS feed_name
+FROM posts
+INNER JOIN post_sources ON post_sources.post_id = posts.id
+INNER JOIN feed_follows ON feed_follows.feed_id = post_sources.feed_id
+INNER JOIN feeds ON feeds.id = post_sources.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND COALESCE(posts.published_at, posts.created_at) >= sqlc.arg(since);
@@ -0,0 +1,36 @@
+-- name: UpsertTag :one
+INSERT INTO tags (id, created_at, updated_at, user_id, name)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, name) DO UPDATE SET updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: GetTagByName :one
+SELECT * FROM tags
+WHERE user_id = $1 AND name = $2;
+
+-- name: GetTagsForUser :many
+SELECT tags.*, COUNT(post_tags.id) AS post_count
+FROM tags
+LEFT JOIN post_tags ON post_tags.tag_id = tags.id
+WHERE tags.user_id = $1
+GROUP BY tags.id
+ORDER BY tags.name;
+
+-- name: DeleteTag :exec
+DELETE FROM tags
+WHERE id = $1;
+
+-- name: TagPost :exec
+INSERT INTO post_tags (id, created_at, updated_at, tag_id, post_id)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (tag_id, post_id) DO NOTHING;
+
+-- name: UntagPost :exec
+DELETE FROM post_tags
+WHERE tag_id = $1 AND post_id = $2;
+
+-- name: GetTagNamesForPost :many
+SELECT tags.name FROM tags
+INNER JOIN post_tags ON post_tags.tag_id = tags.id
+WHERE post_tags.post_id = $1 AND tags.user_id = $2
+ORDER BY tags.name;
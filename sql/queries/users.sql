@@ -1,10 +1,11 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, api_key)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5
 )
 RETURNING *;
 
@@ -18,3 +19,35 @@ DELETE FROM users;
 -- name: GetUsers :many
 SELECT * FROM users;
 
+
+-- name: GetUserByAPIKey :one
+SELECT * FROM users
+WHERE api_key = $1;
+
+-- name: GetUserByFeverKey :one
+SELECT * FROM users
+WHERE fever_key = $1;
+
+-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetUserDeletionSummary :one
+SELECT
+    (SELECT COUNT(*) FROM feeds WHERE feeds.user_id = sqlc.arg(user_id)) AS feeds,
+    (SELECT COUNT(*) FROM feed_follows
+        INNER JOIN feeds ON feed_follows.feed_id = feeds.id
+        WHERE feeds.user_id = sqlc.arg(user_id) AND feed_follows.user_id <> sqlc.arg(user_id)) AS other_follows,
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.user_id = sqlc.arg(user_id)) AS follows,
+    (SELECT COUNT(*) FROM post_reads WHERE post_reads.user_id = sqlc.arg(user_id)) AS reads;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
+
+-- name: RenameUser :one
+UPDATE users
+SET name = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,25 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (id, created_at, updated_at, user_id, url, feed_id, kind, category_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetWebhooksForUser :many
+SELECT webhooks.*, feeds.url AS feed_url, categories.name AS category_name
+FROM webhooks
+LEFT JOIN feeds ON webhooks.feed_id = feeds.id
+LEFT JOIN categories ON webhooks.category_id = categories.id
+WHERE webhooks.user_id = $1
+ORDER BY webhooks.created_at;
+
+-- name: GetWebhooksForFeed :many
+SELECT * FROM webhooks
+WHERE webhooks.feed_id = sqlc.arg(feed_id)
+OR (webhooks.feed_id IS NULL AND EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.user_id = webhooks.user_id AND feed_follows.feed_id = sqlc.arg(feed_id)
+    AND (webhooks.category_id IS NULL OR feed_follows.category_id = webhooks.category_id)
+));
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1 AND user_id = $2;
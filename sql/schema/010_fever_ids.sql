@@ -0,0 +1,8 @@
+-- +goose Up
+-- Fever clients identify feeds and items by integer IDs
+ALTER TABLE feeds ADD COLUMN fever_id BIGSERIAL UNIQUE;
+ALTER TABLE posts ADD COLUMN fever_id BIGSERIAL UNIQUE;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN fever_id;
+ALTER TABLE feeds DROP COLUMN fever_id;
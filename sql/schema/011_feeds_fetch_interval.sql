@@ -0,0 +1,6 @@
+-- +goose Up
+-- Seconds between fetches; NULL means every agg tick
+ALTER TABLE feeds ADD COLUMN fetch_interval INTEGER CHECK (fetch_interval > 0);
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN fetch_interval;
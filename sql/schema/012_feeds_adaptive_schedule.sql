@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN next_fetch_at TIMESTAMP;
+-- Average seconds between posts, used for adaptive polling
+ALTER TABLE feeds ADD COLUMN avg_post_interval INTEGER;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN avg_post_interval;
+ALTER TABLE feeds DROP COLUMN next_fetch_at;
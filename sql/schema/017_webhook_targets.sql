@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE webhooks ADD COLUMN kind TEXT NOT NULL DEFAULT 'json';
+ALTER TABLE webhooks ADD COLUMN category_id UUID REFERENCES categories(id) ON DELETE CASCADE;
+
+-- +goose Down
+ALTER TABLE webhooks DROP COLUMN category_id;
+ALTER TABLE webhooks DROP COLUMN kind;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE last_browse (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, position)
+);
+
+-- +goose Down
+DROP TABLE last_browse;
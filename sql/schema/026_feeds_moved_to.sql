@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN moved_to TEXT;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN moved_to;
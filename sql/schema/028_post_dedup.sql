@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN canonical_url TEXT;
+ALTER TABLE posts ADD COLUMN content_hash TEXT;
+CREATE INDEX posts_canonical_url_idx ON posts (canonical_url);
+CREATE INDEX posts_content_hash_idx ON posts (content_hash);
+
+-- Every feed that carried a post, including the one it was first saved from
+CREATE TABLE post_sources (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (post_id, feed_id)
+);
+CREATE INDEX post_sources_feed_id_idx ON post_sources (feed_id);
+
+INSERT INTO post_sources (post_id, feed_id, created_at)
+SELECT id, feed_id, created_at FROM posts;
+
+-- +goose Down
+DROP TABLE post_sources;
+DROP INDEX posts_content_hash_idx;
+DROP INDEX posts_canonical_url_idx;
+ALTER TABLE posts DROP COLUMN content_hash;
+ALTER TABLE posts DROP COLUMN canonical_url;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE saved_searches (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    keywords TEXT,
+    feed_ids UUID[] NOT NULL DEFAULT '{}',
+    tag_ids UUID[] NOT NULL DEFAULT '{}',
+    -- since and until are kept as written, so "7d" stays relative to when the folder is browsed
+    since TEXT,
+    until TEXT,
+    UNIQUE(user_id, name)
+);
+
+-- +goose Down
+DROP TABLE saved_searches;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- new_count and seen_count are NULL for failed fetches and for fetches whose posts weren't saved
+ALTER TABLE fetch_log ADD COLUMN new_count INTEGER;
+ALTER TABLE fetch_log ADD COLUMN seen_count INTEGER;
+
+-- +goose Down
+ALTER TABLE fetch_log DROP COLUMN seen_count;
+ALTER TABLE fetch_log DROP COLUMN new_count;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- What the feed says about itself, refreshed on every successful fetch; NULL until then
+ALTER TABLE feeds ADD COLUMN channel_title TEXT;
+ALTER TABLE feeds ADD COLUMN channel_description TEXT;
+ALTER TABLE feeds ADD COLUMN channel_link TEXT;
+ALTER TABLE feeds ADD COLUMN channel_language TEXT;
+-- A follower's own note on why they follow the feed
+ALTER TABLE feed_follows ADD COLUMN note TEXT;
+
+-- +goose Down
+ALTER TABLE feed_follows DROP COLUMN note;
+ALTER TABLE feeds DROP COLUMN channel_language;
+ALTER TABLE feeds DROP COLUMN channel_link;
+ALTER TABLE feeds DROP COLUMN channel_description;
+ALTER TABLE feeds DROP COLUMN channel_title;
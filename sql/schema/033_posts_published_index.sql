@@ -0,0 +1,6 @@
+-- +goose Up
+-- Browsing by date filters on when a post was published, or saved when the feed didn't say
+CREATE INDEX posts_published_or_created_idx ON posts ((COALESCE(published_at, created_at)));
+
+-- +goose Down
+DROP INDEX posts_published_or_created_idx;
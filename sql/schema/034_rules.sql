@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE rules (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expression TEXT NOT NULL,
+    -- tag, mark_read, drop or notify
+    action TEXT NOT NULL,
+    -- the tag a tag rule adds
+    tag TEXT
+);
+
+-- +goose Down
+DROP TABLE rules;
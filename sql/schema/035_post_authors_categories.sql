@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN author TEXT;
+CREATE INDEX posts_author_idx ON posts (lower(author));
+
+-- The categories a feed item was filed under, as the feed names them
+CREATE TABLE post_categories (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    PRIMARY KEY (post_id, name)
+);
+CREATE INDEX post_categories_name_idx ON post_categories (lower(name));
+
+-- +goose Down
+DROP TABLE post_categories;
+DROP INDEX posts_author_idx;
+ALTER TABLE posts DROP COLUMN author;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- A post's guid is its feed item's guid, or its link when the item has none. Posts are unique
+-- by feed and guid rather than by link, so a feed can reuse a link for a new item.
+ALTER TABLE posts ADD COLUMN guid TEXT;
+UPDATE posts SET guid = url;
+ALTER TABLE posts ALTER COLUMN guid SET NOT NULL;
+ALTER TABLE posts DROP CONSTRAINT posts_url_key;
+CREATE UNIQUE INDEX posts_feed_id_guid_key ON posts (feed_id, guid);
+CREATE INDEX posts_url_idx ON posts (url);
+
+-- +goose Down
+-- Links must be unique again, so of the posts sharing one only the first saved is kept
+DELETE FROM posts
+USING posts AS earlier
+WHERE posts.url = earlier.url
+AND (earlier.created_at, earlier.id) < (posts.created_at, posts.id);
+DROP INDEX posts_url_idx;
+DROP INDEX posts_feed_id_guid_key;
+ALTER TABLE posts ADD CONSTRAINT posts_url_key UNIQUE (url);
+ALTER TABLE posts DROP COLUMN guid;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- An agg or fetch claims the due feeds it picks up until claimed_until, so several of them
+-- running against one database don't fetch the same feed. Rescheduling a feed releases it.
+ALTER TABLE feeds ADD COLUMN claimed_until TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN claimed_until;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- The queue between agg --coordinator, which adds due feeds to it, and worker processes, which
+-- claim a job until claimed_until and remove it once they've fetched the feed
+CREATE TABLE fetch_jobs (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    claimed_until TIMESTAMP
+);
+CREATE INDEX fetch_jobs_created_at_idx ON fetch_jobs (created_at);
+
+-- +goose Down
+DROP TABLE fetch_jobs;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Marking posts read or unread announces whose read state changed on gator_post_reads, so post
+-- streams can keep their unread counts current. Statement triggers announce a bulk change such
+-- as catchup once per user rather than once per post.
+-- +goose StatementBegin
+CREATE FUNCTION notify_post_reads() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('gator_post_reads', changed_users.user_id::text)
+    FROM (SELECT DISTINCT user_id FROM changed) AS changed_users;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+CREATE TRIGGER post_reads_inserted AFTER INSERT ON post_reads
+    REFERENCING NEW TABLE AS changed
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_post_reads();
+CREATE TRIGGER post_reads_deleted AFTER DELETE ON post_reads
+    REFERENCING OLD TABLE AS changed
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_post_reads();
+
+-- +goose Down
+DROP TRIGGER post_reads_deleted ON post_reads;
+DROP TRIGGER post_reads_inserted ON post_reads;
+DROP FUNCTION notify_post_reads();
@@ -0,0 +1,16 @@
+-- +goose Up
+-- A user's read-later queue, separate from saved posts: queue pop takes the post with the lowest
+-- position, and queue add --next puts a post before the rest
+CREATE TABLE queued_posts (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    position BIGINT NOT NULL,
+    UNIQUE(user_id, post_id)
+);
+CREATE INDEX queued_posts_user_position_idx ON queued_posts (user_id, position);
+
+-- +goose Down
+DROP TABLE queued_posts;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- How many words of a post's full content are stored, for reading time estimates; NULL until
+-- its content is extracted. Posts extracted before now are counted by whitespace, as gator does.
+ALTER TABLE posts ADD COLUMN word_count INTEGER;
+UPDATE posts
+SET word_count = CASE WHEN btrim(content) = '' THEN 0
+    ELSE cardinality(regexp_split_to_array(btrim(content), '\s+')) END
+WHERE content IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN word_count;
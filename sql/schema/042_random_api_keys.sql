@@ -0,0 +1,12 @@
+-- +goose Up
+-- API keys used to default to a hash of random(), which isn't a secure random number generator.
+-- gator now generates them with crypto/rand, and every existing key is replaced with two
+-- gen_random_uuid() values, which come from the server's secure random source, so users need to
+-- look up their new key with gator apikey show. It's a core function, so no extension is needed.
+ALTER TABLE users ALTER COLUMN api_key DROP DEFAULT;
+UPDATE users SET api_key = encode(sha256((gen_random_uuid()::text || gen_random_uuid()::text)::bytea), 'hex'), updated_at = NOW();
+
+-- +goose Down
+ALTER TABLE users ALTER COLUMN api_key SET DEFAULT (
+    encode(sha256(random()::text::bytea), 'hex')
+);
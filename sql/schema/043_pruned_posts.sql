@@ -0,0 +1,16 @@
+-- +goose Up
+-- Tombstones of pruned posts, so that ingest doesn't save them again as new posts while their
+-- feeds still carry them. The feed that saved a post remembers its guid as well as its link;
+-- other feeds carrying it only the link. seen_at is when a feed last carried the post; the
+-- tombstone is dropped once it hasn't for a while.
+CREATE TABLE pruned_posts (
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    guid TEXT,
+    url TEXT NOT NULL,
+    seen_at TIMESTAMP NOT NULL,
+    UNIQUE(feed_id, url)
+);
+CREATE INDEX pruned_posts_feed_id_guid_idx ON pruned_posts (feed_id, guid);
+
+-- +goose Down
+DROP TABLE pruned_posts;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Fever clients sign in with md5("name:api_key"); keeping it in an indexed column lets the Fever
+-- API find the user directly instead of hashing every user's key on each request
+ALTER TABLE users ADD COLUMN fever_key TEXT NOT NULL GENERATED ALWAYS AS (md5(name || ':' || api_key)) STORED;
+CREATE INDEX users_fever_key_idx ON users (fever_key);
+
+-- +goose Down
+DROP INDEX users_fever_key_idx;
+ALTER TABLE users DROP COLUMN fever_key;
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// stats looks back statsWeeks weeks for posting trends and lists the statsTopFeeds most read feeds
+const (
+	statsWeeks    = 12
+	statsTopFeeds = 5
+)
+
+// deadFeedAge is how long a feed can go without posting before stats calls it dead
+const deadFeedAge = 90 * 24 * time.Hour
+
+// handlerStats summarises followed feeds: how much they post, how much of it gets read, and
+// which have gone quiet or stopped working
+func handlerStats(s *state, cmd command, user database.User) error {
+	ctx := s.ctx
+
+	feeds, err := s.db.GetFeedStatsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed stats: %w", err)
+	}
+
+	reads, err := s.db.GetReadStatsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get read stats: %w", err)
+	}
+
+	firstWeek := startOfWeek(time.Now()).AddDate(0, 0, -7*(statsWeeks-1))
+	weeks, err := s.db.GetWeeklyPostCountsForUser(ctx, database.GetWeeklyPostCountsForUserParams{
+		UserID: user.ID,
+		Since:  firstWeek,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get weekly post counts: %w", err)
+	}
+
+	size, err := s.db.GetDatabaseSize(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't get database size: %w", err)
+	}
+
+	res := statsResult{
+		UserName:      user.Name,
+		TotalPosts:    reads.TotalPosts,
+		ReadPosts:     reads.ReadPosts,
+		ReadRatio:     ratio(reads.ReadPosts, reads.TotalPosts),
+		DatabaseBytes: size,
+		Weekly:        []weekStats{},
+		Feeds:         []feedStatsEntry{},
+		MostRead:      []feedStatsEntry{},
+		Dead:          []feedStatsEntry{},
+	}
+
+	// Weeks without posts have no row, but still belong in the trend
+	counts := map[string]int64{}
+	for _, week := range weeks {
+		counts[week.Week.Format("2006-01-02")] = week.PostCount
+	}
+	for i := range statsWeeks {
+		week := firstWeek.AddDate(0, 0, 7*i)
+		res.Weekly = append(res.Weekly, weekStats{Week: week, Posts: counts[week.Format("2006-01-02")]})
+	}
+
+	for _, feed := range feeds {
+		entry := feedStatsEntry{
+			ID:           feed.ID,
+			Name:         feed.Name,
+			Url:          feed.Url,
+			Posts:        feed.PostCount,
+			PostsPerWeek: float64(feed.PostsLast28Days) / 4,
+			Read:         feed.ReadCount,
+			ReadRatio:    ratio(feed.ReadCount, feed.PostCount),
+		}
+		switch {
+		case feed.Paused && feed.ConsecutiveFailures > 0:
+			entry.Dead = "paused after failing"
+		case feed.PostsLast90Days == 0 && time.Since(feed.CreatedAt) > deadFeedAge:
+			entry.Dead = "no posts in 90 days"
+		}
+
+		res.Feeds = append(res.Feeds, entry)
+		if entry.Dead != "" {
+			res.Dead = append(res.Dead, entry)
+		}
+		if entry.Read > 0 {
+			res.MostRead = append(res.MostRead, entry)
+		}
+	}
+
+	slices.SortStableFunc(res.MostRead, func(a, b feedStatsEntry) int {
+		return cmp.Compare(b.Read, a.Read)
+	})
+	if len(res.MostRead) > statsTopFeeds {
+		res.MostRead = res.MostRead[:statsTopFeeds]
+	}
+
+	return s.emit(res)
+}
+
+// startOfWeek returns midnight on the Monday starting t's week, like Postgres' date_trunc('week')
+func startOfWeek(t time.Time) time.Time {
+	days := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-days, 0, 0, 0, 0, t.Location())
+}
+
+// ratio returns part/whole, or 0 when whole is 0
+func ratio(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
+
+// feedStatsEntry is one feed in stats; PostsPerWeek averages the last four weeks, and Dead says
+// why a feed looks dead, if it does
+type feedStatsEntry struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Url          string    `json:"url"`
+	Posts        int64     `json:"posts"`
+	PostsPerWeek float64   `json:"posts_per_week"`
+	Read         int64     `json:"read"`
+	ReadRatio    float64   `json:"read_ratio"`
+	Dead         string    `json:"dead,omitempty"`
+}
+
+// weekStats is how many posts came in during the week starting Week
+type weekStats struct {
+	Week  time.Time `json:"week"`
+	Posts int64     `json:"posts"`
+}
+
+// statsResult is the output of stats
+type statsResult struct {
+	UserName      string           `json:"user_name"`
+	TotalPosts    int64            `json:"total_posts"`
+	ReadPosts     int64            `json:"read_posts"`
+	ReadRatio     float64          `json:"read_ratio"`
+	DatabaseBytes int64            `json:"database_bytes"`
+	Weekly        []weekStats      `json:"weekly"`
+	Feeds         []feedStatsEntry `json:"feeds"`
+	MostRead      []feedStatsEntry `json:"most_read"`
+	Dead          []feedStatsEntry `json:"dead"`
+}
+
+func (r statsResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Stats for %s\n", r.UserName)
+	fmt.Fprintf(w, "Feeds followed: %d (%d dead)\n", len(r.Feeds), len(r.Dead))
+	fmt.Fprintf(w, "Posts: %d, read %d (%.0f%%)\n", r.TotalPosts, r.ReadPosts, r.ReadRatio*100)
+	fmt.Fprintf(w, "Database size: %s\n", formatSize(r.DatabaseBytes))
+
+	var busiest int64
+	for _, week := range r.Weekly {
+		busiest = max(busiest, week.Posts)
+	}
+	fmt.Fprintf(w, "\nPosts per week:\n")
+	for _, week := range r.Weekly {
+		bar := ""
+		if busiest > 0 {
+			bar = strings.Repeat("█", int(week.Posts*40/busiest))
+		}
+		fmt.Fprintf(w, "  %s  %5d %s\n", week.Week.Format("2006-01-02"), week.Posts, bar)
+	}
+
+	if len(r.Feeds) == 0 {
+		fmt.Fprintln(w, "\nYou aren't following any feeds")
+		return
+	}
+
+	fmt.Fprintf(w, "\nFeeds by posts:\n")
+	fmt.Fprintf(w, "  %7s %7s %7s %5s  %s\n", "posts", "/week", "read", "read%", "feed")
+	for _, feed := range r.Feeds {
+		fmt.Fprintf(w, "  %7d %7.1f %7d %4.0f%%  %s\n", feed.Posts, feed.PostsPerWeek, feed.Read, feed.ReadRatio*100, feed.Name)
+	}
+
+	if len(r.MostRead) > 0 {
+		fmt.Fprintf(w, "\nMost read feeds:\n")
+		for i, feed := range r.MostRead {
+			fmt.Fprintf(w, "  %d. %s (%d read, %.0f%%)\n", i+1, feed.Name, feed.Read, feed.ReadRatio*100)
+		}
+	}
+
+	if len(r.Dead) > 0 {
+		fmt.Fprintf(w, "\nDead feeds:\n")
+		for _, feed := range r.Dead {
+			fmt.Fprintf(w, "  * %s (%s): %s\n", feed.Name, feed.Url, feed.Dead)
+		}
+	}
+}
+
+func (r statsResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, feed := range r.Feeds {
+		rows = append(rows, []string{
+			feed.Name,
+			feed.Url,
+			strconv.FormatInt(feed.Posts, 10),
+			strconv.FormatFloat(feed.PostsPerWeek, 'f', 2, 64),
+			strconv.FormatInt(feed.Read, 10),
+			strconv.FormatFloat(feed.ReadRatio, 'f', 3, 64),
+			feed.Dead,
+		})
+	}
+	return []string{"name", "url", "posts", "posts_per_week", "read", "read_ratio", "dead"}, rows
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+// surpriseEntry is a post surprise picked, with the feed it was picked from and how many of
+// that feed's posts the user read in the last 30 days
+type surpriseEntry struct {
+	apiPost
+	FeedName    string `json:"feed_name"`
+	RecentReads int64  `json:"recent_reads"`
+}
+
+// surpriseResult is the output of surprise
+type surpriseResult struct {
+	UserName string          `json:"user_name"`
+	Posts    []surpriseEntry `json:"posts"`
+}
+
+func (r surpriseResult) writeText(w io.Writer) {
+	if len(r.Posts) == 0 {
+		fmt.Fprintln(w, "No unread posts to pick from")
+		return
+	}
+
+	fmt.Fprintf(w, "%d unread posts picked for %s:\n", len(r.Posts), r.UserName)
+	fmt.Fprintln(w, strings.Repeat("=", 80))
+	for i, post := range r.Posts {
+		fmt.Fprintf(w, "\n[%d] %s\n", i+1, post.Title)
+		fmt.Fprintf(w, "From: %s (%d read in the last 30 days)\n", post.FeedName, post.RecentReads)
+		fmt.Fprintf(w, "URL: %s\n", post.Url)
+		if post.Description != nil {
+			fmt.Fprintf(w, "Description: %s\n", truncateText(htmltext.RenderInline(*post.Description), 200))
+		}
+		if post.PublishedAt != nil {
+			fmt.Fprintf(w, "Published: %s\n", post.PublishedAt.Format("2006-01-02 15:04:05"))
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Open one with 'gator open <number>'")
+}
+
+func (r surpriseResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, post := range r.Posts {
+		rows = append(rows, []string{
+			post.ID.String(),
+			post.Title,
+			post.Url,
+			post.FeedName,
+			strconv.FormatInt(post.RecentReads, 10),
+			formatTime(post.PublishedAt),
+		})
+	}
+	return []string{"id", "title", "url", "feed", "recent_reads", "published_at"}, rows
+}
+
+// handlerSurprise picks unread posts at random, spreading them across followed feeds before
+// taking a second from any one, and favouring feeds the user has read little from lately so that
+// subscriptions they never get to come up
+func handlerSurprise(s *state, cmd command, user database.User) error {
+	flags := newFlagSet("surprise")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	n := 3
+	switch len(args) {
+	case 0:
+	case 1:
+		if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+			return fmt.Errorf("invalid count %q: must be a number of at least 1", args[0])
+		}
+	default:
+		return fmt.Errorf("unexpected surprise argument %q", args[1])
+	}
+	n = min(n, maxPostLimit)
+
+	filters, err := loadFilters(s.ctx, s.db, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// Ask for extra posts, as some may be hidden by the user's filters
+	rows, err := s.db.GetSurprisePostsForUser(s.ctx, database.GetSurprisePostsForUserParams{
+		UserID:      user.ID,
+		ResultLimit: int32(n * 2),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't pick posts: %w", err)
+	}
+
+	res := surpriseResult{UserName: user.Name, Posts: []surpriseEntry{}}
+	ids := []uuid.UUID{}
+	picked := map[uuid.UUID]bool{}
+	for _, row := range rows {
+		if len(res.Posts) == n {
+			break
+		}
+		// A post carried by two followed feeds can be picked from each
+		if picked[row.ID] || filters.hides(row.FeedID, row.Title, row.Description.String) {
+			continue
+		}
+		picked[row.ID] = true
+		res.Posts = append(res.Posts, surpriseEntry{
+			apiPost: toAPIPost(database.Post{
+				ID:                   row.ID,
+				CreatedAt:            row.CreatedAt,
+				UpdatedAt:            row.UpdatedAt,
+				Title:                row.Title,
+				Url:                  row.Url,
+				Description:          row.Description,
+				PublishedAt:          row.PublishedAt,
+				PublishedAtEstimated: row.PublishedAtEstimated,
+				FeedID:               row.FeedID,
+				Author:               row.Author,
+			}),
+			FeedName:    row.FeedName,
+			RecentReads: row.RecentReads,
+		})
+		ids = append(ids, row.ID)
+	}
+
+	// Remember the picks so "gator open <n>" can refer to them, as after browse
+	if err := saveLastBrowse(s, user, ids); err != nil {
+		return err
+	}
+	return s.emit(res)
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+)
+
+// telegramMessageLimit is the longest message Telegram accepts
+const telegramMessageLimit = 4096
+
+// telegramPollTimeout is how long each getUpdates long poll waits for messages
+const telegramPollTimeout = 30 * time.Second
+
+var telegramClient = &http.Client{Timeout: telegramPollTimeout + 10*time.Second}
+
+// telegramUpdate is the subset of a Telegram update gator reads
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+// telegramResponse wraps every Bot API reply
+type telegramResponse struct {
+	OK          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+// telegramCommands maps bot commands onto the CLI handlers they run as the current user
+var telegramCommands = map[string]func(*state, command) error{
+	"follow":   middlewareLoggedIn(handlerFollow),
+	"unfollow": middlewareLoggedIn(handlerUnfollow),
+	"browse":   middlewareLoggedIn(handlerBrowse),
+}
+
+const telegramHelp = `Commands:
+/follow <feed_url> - follow a feed
+/unfollow <feed_url> - unfollow a feed
+/browse [limit] - show recent unread posts`
+
+// handlerTelegram runs the Telegram bot, answering commands from the linked chat until interrupted
+func handlerTelegram(s *state, cmd command) error {
+	if s.cfg.Telegram == nil || s.cfg.Telegram.BotToken == "" {
+		return errors.New("telegram command requires telegram.bot_token in the config")
+	}
+
+	// Anyone can message a bot, so a chat is only linked by sending the code printed here
+	var pairingCode string
+	if s.cfg.Telegram.ChatID == 0 {
+		pairingCode = rand.Text()
+		s.emit(messageResult{Message: fmt.Sprintf("Telegram bot running; send /start %s to it to link your chat", pairingCode)})
+	} else {
+		s.emit(messageResult{Message: fmt.Sprintf("Telegram bot running for chat %d", s.cfg.Telegram.ChatID)})
+	}
+
+	var offset int64
+	for {
+		updates, err := telegramGetUpdates(s.ctx, s.cfg.Telegram.BotToken, offset)
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return s.emit(messageResult{Message: "Shutting down Telegram bot"})
+			}
+			slog.Error("couldn't poll Telegram", "error", err)
+			select {
+			case <-s.ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if update.Message == nil || !strings.HasPrefix(update.Message.Text, "/") {
+				continue
+			}
+			if err := handleTelegramMessage(s, pairingCode, update.Message.Chat.ID, update.Message.Text); err != nil {
+				slog.Error("couldn't handle Telegram message", "error", err)
+			}
+		}
+	}
+}
+
+// handleTelegramMessage runs one bot command and replies with its output. Until a chat is linked,
+// the only command is /start with pairingCode.
+func handleTelegramMessage(s *state, pairingCode string, chatID int64, text string) error {
+	fields := strings.Fields(text)
+	// Commands in groups arrive as /command@botname
+	name, _, _ := strings.Cut(strings.TrimPrefix(fields[0], "/"), "@")
+
+	if s.cfg.Telegram.ChatID == 0 {
+		if name != "start" || len(fields) != 2 || subtle.ConstantTimeCompare([]byte(fields[1]), []byte(pairingCode)) != 1 {
+			return sendTelegramMessage(s.ctx, s.cfg.Telegram.BotToken, chatID, "Send /start with the code gator telegram printed to link this chat.", false)
+		}
+		if err := s.cfg.SetTelegramChat(chatID); err != nil {
+			return fmt.Errorf("couldn't save chat: %w", err)
+		}
+		return sendTelegramMessage(s.ctx, s.cfg.Telegram.BotToken, chatID, "Linked to gator.\n\n"+telegramHelp, false)
+	}
+
+	// Only the linked chat may control gator
+	if chatID != s.cfg.Telegram.ChatID {
+		return nil
+	}
+
+	handler, ok := telegramCommands[name]
+	if !ok {
+		return sendTelegramMessage(s.ctx, s.cfg.Telegram.BotToken, chatID, telegramHelp, false)
+	}
+
+	var out bytes.Buffer
+	botState := *s
+	botState.out = &out
+	botState.output = "plain"
+
+	reply := ""
+	if err := handler(&botState, command{name: name, args: fields[1:]}); err != nil {
+		reply = "Error: " + err.Error()
+	} else {
+		reply = strings.TrimSpace(out.String())
+	}
+	if reply == "" {
+		reply = "Done"
+	}
+
+	return sendTelegramMessage(s.ctx, s.cfg.Telegram.BotToken, chatID, truncateText(reply, telegramMessageLimit), false)
+}
+
+// pushTelegram sends a scrape's new posts to the linked chat if the current user follows the feed
+func pushTelegram(s *state, res scrapeResult) error {
+	if s.cfg.Telegram == nil || s.cfg.Telegram.BotToken == "" || s.cfg.Telegram.ChatID == 0 {
+		return nil
+	}
+	if res.Feed == nil || len(res.NewPosts) == 0 {
+		return nil
+	}
+
+	user, err := s.db.GetUser(s.ctx, s.cfg.CurrentUserName)
+	if err != nil {
+		return fmt.Errorf("couldn't get current user: %w", err)
+	}
+
+	follow, err := s.db.GetFeedFollow(s.ctx, database.GetFeedFollowParams{
+		UserID: user.ID,
+		FeedID: res.Feed.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("couldn't get feed follow: %w", err)
+	}
+	if follow.Muted {
+		return nil
+	}
+
+	filters, err := loadFilters(s.ctx, s.db, user.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range filters.apiPosts(res.NewPosts) {
+		text := fmt.Sprintf("<b>%s</b>\n<a href=\"%s\">%s</a>",
+			html.EscapeString(res.Feed.Name),
+			html.EscapeString(post.Url),
+			html.EscapeString(post.Title),
+		)
+		if err := sendTelegramMessage(s.ctx, s.cfg.Telegram.BotToken, s.cfg.Telegram.ChatID, text, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// telegramGetUpdates long-polls the Bot API for updates at or after offset
+func telegramGetUpdates(ctx context.Context, token string, offset int64) ([]telegramUpdate, error) {
+	params := url.Values{}
+	params.Set("offset", strconv.FormatInt(offset, 10))
+	params.Set("timeout", strconv.Itoa(int(telegramPollTimeout/time.Second)))
+	params.Set("allowed_updates", `["message"]`)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, telegramURL(token, "getUpdates")+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []telegramUpdate
+	if err := telegramDo(req, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// sendTelegramMessage posts text to a chat, as HTML if asHTML is set
+func sendTelegramMessage(ctx context.Context, token string, chatID int64, text string, asHTML bool) error {
+	payload := map[string]interface{}{
+		"chat_id": chatID,
+		"text":    text,
+	}
+	if asHTML {
+		payload["parse_mode"] = "HTML"
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, telegramURL(token, "sendMessage"), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := telegramDo(req, nil); err != nil {
+		return fmt.Errorf("couldn't send Telegram message: %w", err)
+	}
+	return nil
+}
+
+// telegramDo performs a Bot API request and decodes its result into v, if non-nil
+func telegramDo(req *http.Request, v interface{}) error {
+	resp, err := telegramClient.Do(req)
+	if err != nil {
+		// Don't leak the bot token embedded in the request URL
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	var tr telegramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return fmt.Errorf("invalid Telegram response: %w", err)
+	}
+	if !tr.OK {
+		return fmt.Errorf("telegram: %s", tr.Description)
+	}
+
+	if v != nil {
+		return json.Unmarshal(tr.Result, v)
+	}
+	return nil
+}
+
+func telegramURL(token, method string) string {
+	return "https://api.telegram.org/bot" + token + "/" + method
+}
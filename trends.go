@@ -0,0 +1,217 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// trendFeedsShown is how many of the feeds behind a term trends lists
+const trendFeedsShown = 3
+
+// stopwords are left out of trends: words common to any headline, and the filler of news and
+// blog titles
+var stopwords = wordSet(`
+		a about above after again against all also am an and any are as at be because been before
+		being below between both but by can could did do does doing down during each few for from
+		further had has have having he her here hers him his how i if in into is it its itself just
+		me more most my no nor not now of off on once only or other our ours out over own same she
+		should so some such than that the their theirs them then there these they this those through
+		to too under until up very was we were what when where which while who whom why will with
+		would you your yours
+
+		s t re ve ll d m don doesn isn aren wasn won can't don't it's i'm you're we're they're
+		get gets got make makes new news via vs one two first last next today week year years
+		day days time way ways says said use using used need want see look back still like
+		really thing things here's what's how's let's part
+`)
+
+// wordSet makes a set of the space-separated words in list
+func wordSet(list string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range strings.Fields(list) {
+		set[word] = true
+	}
+	return set
+}
+
+// titleTerms splits a title into lower-case words, dropping punctuation, numbers and stopwords,
+// and returns its distinct words and the bigrams of neighbouring words, in order. A stopword
+// between two words keeps them from forming a bigram.
+func titleTerms(title string) []string {
+	var words []string
+	var terms []string
+	seen := map[string]bool{}
+	add := func(term string) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	title = strings.ReplaceAll(strings.ToLower(title), "’", "'")
+	fields := strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '+' && r != '#'
+	})
+	for _, field := range fields {
+		word := strings.TrimSuffix(strings.Trim(field, "'"), "'s")
+		if word == "" || stopwords[word] || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			words = append(words, "")
+			continue
+		}
+		words = append(words, word)
+	}
+
+	for i, word := range words {
+		if word == "" {
+			continue
+		}
+		add(word)
+		if i > 0 && words[i-1] != "" {
+			add(words[i-1] + " " + word)
+		}
+	}
+	return terms
+}
+
+// trendFeed is a feed that carried posts using a trending term
+type trendFeed struct {
+	Name  string `json:"name"`
+	Posts int    `json:"posts"`
+}
+
+// trendTerm is a word or bigram and the number of recent posts whose titles use it
+type trendTerm struct {
+	Term  string      `json:"term"`
+	Posts int         `json:"posts"`
+	Feeds []trendFeed `json:"feeds"`
+}
+
+// trendsResult is the output of trends
+type trendsResult struct {
+	Since time.Time   `json:"since"`
+	Posts int         `json:"posts"`
+	Terms []trendTerm `json:"terms"`
+}
+
+func (r trendsResult) writeText(w io.Writer) {
+	if len(r.Terms) == 0 {
+		fmt.Fprintf(w, "No terms come up in more than one of the %d posts since %s\n", r.Posts, r.Since.Format("2006-01-02 15:04"))
+		return
+	}
+
+	fmt.Fprintf(w, "Trending in %d posts since %s:\n", r.Posts, r.Since.Format("2006-01-02 15:04"))
+	for i, term := range r.Terms {
+		feeds := make([]string, len(term.Feeds))
+		for j, feed := range term.Feeds {
+			feeds[j] = fmt.Sprintf("%s (%d)", feed.Name, feed.Posts)
+		}
+		fmt.Fprintf(w, "%2d. %s - %d posts\n", i+1, term.Term, term.Posts)
+		fmt.Fprintf(w, "    %s\n", strings.Join(feeds, ", "))
+	}
+}
+
+func (r trendsResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, term := range r.Terms {
+		feeds := make([]string, len(term.Feeds))
+		for i, feed := range term.Feeds {
+			feeds[i] = feed.Name
+		}
+		rows = append(rows, []string{term.Term, strconv.Itoa(term.Posts), strings.Join(feeds, "; ")})
+	}
+	return []string{"term", "posts", "feeds"}, rows
+}
+
+// handlerTrends reports the words and word pairs used most in the titles of recent posts from
+// followed feeds, and which feeds use them, to spot a story many sources are covering
+func handlerTrends(s *state, cmd command, user database.User) error {
+	since := time.Now().Add(-7 * 24 * time.Hour)
+	var limit int
+	flags := newFlagSet("trends")
+	flags.IntVar(&limit, "limit", 15, "Show the top `n` terms")
+	flags.Func("since", "Look at posts published since `when`: a duration like 7d or a date (default 7d)", func(value string) error {
+		t, err := parseTimeArg("--since", value)
+		since = t
+		return err
+	})
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected trends argument %q", args[0])
+	}
+	if limit < 1 {
+		return errors.New("--limit must be at least 1")
+	}
+
+	rows, err := s.db.GetRecentTitlesForUser(s.ctx, database.GetRecentTitlesForUserParams{
+		UserID: user.ID,
+		Since:  since,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get recent posts: %w", err)
+	}
+
+	// A post carried by several feeds counts once for the term, and once for each of the feeds
+	type termCount struct {
+		posts map[uuid.UUID]bool
+		feeds map[string]int
+	}
+	counts := map[string]*termCount{}
+	posts := map[uuid.UUID]bool{}
+	for _, row := range rows {
+		posts[row.ID] = true
+		for _, term := range titleTerms(row.Title) {
+			c := counts[term]
+			if c == nil {
+				c = &termCount{posts: map[uuid.UUID]bool{}, feeds: map[string]int{}}
+				counts[term] = c
+			}
+			c.posts[row.ID] = true
+			c.feeds[row.FeedName]++
+		}
+	}
+
+	res := trendsResult{Since: since, Posts: len(posts), Terms: []trendTerm{}}
+	for term, c := range counts {
+		// One post using a word isn't a trend
+		if len(c.posts) < 2 {
+			continue
+		}
+		entry := trendTerm{Term: term, Posts: len(c.posts), Feeds: []trendFeed{}}
+		for name, n := range c.feeds {
+			entry.Feeds = append(entry.Feeds, trendFeed{Name: name, Posts: n})
+		}
+		slices.SortFunc(entry.Feeds, func(a, b trendFeed) int {
+			return cmp.Or(cmp.Compare(b.Posts, a.Posts), cmp.Compare(a.Name, b.Name))
+		})
+		if len(entry.Feeds) > trendFeedsShown {
+			entry.Feeds = entry.Feeds[:trendFeedsShown]
+		}
+		res.Terms = append(res.Terms, entry)
+	}
+
+	// A bigram ranks ahead of a single word used as often, being the more telling of the two
+	slices.SortFunc(res.Terms, func(a, b trendTerm) int {
+		return cmp.Or(
+			cmp.Compare(b.Posts, a.Posts),
+			cmp.Compare(strings.Count(b.Term, " "), strings.Count(a.Term, " ")),
+			cmp.Compare(a.Term, b.Term),
+		)
+	})
+	if len(res.Terms) > limit {
+		res.Terms = res.Terms[:limit]
+	}
+	return s.emit(res)
+}
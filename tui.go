@@ -0,0 +1,496 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/google/uuid"
+	"golang.org/x/term"
+)
+
+// tuiPostLimit is how many posts are loaded per feed
+const tuiPostLimit = 200
+
+// tuiPane identifies which pane has keyboard focus
+type tuiPane int
+
+const (
+	paneFeeds tuiPane = iota
+	panePosts
+	panePreview
+)
+
+// tuiModel holds everything the reader displays
+type tuiModel struct {
+	s      *state
+	user   database.User
+	feeds  []database.GetFeedFollowsForUserRow
+	posts  []database.GetPostsForFeedWithStateRow
+	focus  tuiPane
+	feed   int
+	post   int
+	scroll int
+	status string
+	width  int
+	height int
+}
+
+// handlerTui runs the interactive terminal reader
+func handlerTui(s *state, cmd command, user database.User) error {
+	feeds, err := s.db.GetFeedFollowsForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed follows: %w", err)
+	}
+	if len(feeds) == 0 {
+		return s.emit(messageResult{Message: "Not following any feeds"})
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return errors.New("the TUI needs a terminal")
+	}
+	saved, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("couldn't configure terminal: %w", err)
+	}
+	defer term.Restore(fd, saved)
+
+	// Alternate screen buffer, hidden cursor
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	m := &tuiModel{s: s, user: user, feeds: feeds}
+	if err := m.loadPosts(); err != nil {
+		return err
+	}
+	m.width, m.height = terminalSize()
+
+	// Keys are read in the background, so that a resize is redrawn and a SIGTERM, which cancels
+	// s.ctx, returns and restores the terminal while waiting for one
+	keys := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		in := bufio.NewReader(os.Stdin)
+		for {
+			key, err := readKey(in)
+			if err != nil {
+				readErr <- err
+				return
+			}
+			keys <- key
+		}
+	}()
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+	defer signal.Stop(resized)
+
+	for {
+		m.render()
+
+		select {
+		case <-s.ctx.Done():
+			return nil
+		case err := <-readErr:
+			return err
+		case <-resized:
+			m.width, m.height = terminalSize()
+		case key := <-keys:
+			if key == "q" || key == "ctrl-c" {
+				return nil
+			}
+			m.handleKey(key)
+		}
+	}
+}
+
+// loadPosts fetches posts for the selected feed
+func (m *tuiModel) loadPosts() error {
+	posts, err := m.s.db.GetPostsForFeedWithState(m.s.ctx, database.GetPostsForFeedWithStateParams{
+		UserID:      m.user.ID,
+		FeedID:      m.feeds[m.feed].FeedID,
+		ResultLimit: tuiPostLimit,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get posts: %w", err)
+	}
+
+	m.posts = posts
+	m.post = 0
+	m.scroll = 0
+	return nil
+}
+
+// handleKey applies a single keypress
+func (m *tuiModel) handleKey(key string) {
+	m.status = ""
+
+	switch key {
+	case "tab", "right", "l":
+		if m.focus < panePreview {
+			m.focus++
+		}
+	case "shift-tab", "left", "h":
+		if m.focus > paneFeeds {
+			m.focus--
+		}
+	case "down", "j":
+		m.move(1)
+	case "up", "k":
+		m.move(-1)
+	case "r":
+		m.toggleRead()
+	case "s":
+		m.toggleSaved()
+	case "o":
+		if post, ok := m.selectedPost(); ok {
+			if err := openBrowser(post.Url); err != nil {
+				m.status = fmt.Sprintf("couldn't open browser: %v", err)
+			} else {
+				m.markRead(true)
+			}
+		}
+	case "enter":
+		if m.focus == paneFeeds {
+			m.focus = panePosts
+		} else if m.focus == panePosts {
+			m.focus = panePreview
+			m.markRead(true)
+		}
+	}
+}
+
+// move changes the selection (or scroll offset) of the focused pane
+func (m *tuiModel) move(delta int) {
+	switch m.focus {
+	case paneFeeds:
+		next := clamp(m.feed+delta, 0, len(m.feeds)-1)
+		if next != m.feed {
+			m.feed = next
+			if err := m.loadPosts(); err != nil {
+				m.status = err.Error()
+			}
+		}
+	case panePosts:
+		m.post = clamp(m.post+delta, 0, len(m.posts)-1)
+		m.scroll = 0
+	case panePreview:
+		m.scroll = max(m.scroll+delta, 0)
+	}
+}
+
+func (m *tuiModel) selectedPost() (*database.GetPostsForFeedWithStateRow, bool) {
+	if len(m.posts) == 0 {
+		return nil, false
+	}
+	return &m.posts[m.post], true
+}
+
+func (m *tuiModel) toggleRead() {
+	if post, ok := m.selectedPost(); ok {
+		m.markRead(!post.IsRead)
+	}
+}
+
+// markRead sets the read state of the selected post
+func (m *tuiModel) markRead(read bool) {
+	post, ok := m.selectedPost()
+	if !ok || post.IsRead == read {
+		return
+	}
+
+	var err error
+	if read {
+		err = m.s.db.MarkPostRead(m.s.ctx, database.MarkPostReadParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    m.user.ID,
+			PostID:    post.ID,
+		})
+	} else {
+		err = m.s.db.MarkPostUnread(m.s.ctx, database.MarkPostUnreadParams{
+			UserID: m.user.ID,
+			PostID: post.ID,
+		})
+	}
+	if err != nil {
+		m.status = fmt.Sprintf("couldn't update read state: %v", err)
+		return
+	}
+	post.IsRead = read
+}
+
+func (m *tuiModel) toggleSaved() {
+	post, ok := m.selectedPost()
+	if !ok {
+		return
+	}
+
+	var err error
+	if post.IsSaved {
+		err = m.s.db.UnsavePost(m.s.ctx, database.UnsavePostParams{
+			UserID: m.user.ID,
+			PostID: post.ID,
+		})
+	} else {
+		err = m.s.db.SavePost(m.s.ctx, database.SavePostParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    m.user.ID,
+			PostID:    post.ID,
+		})
+	}
+	if err != nil {
+		m.status = fmt.Sprintf("couldn't update saved state: %v", err)
+		return
+	}
+	post.IsSaved = !post.IsSaved
+
+	// Logging would draw over the screen, so a failure to forward the post goes in the status line
+	if !post.IsSaved {
+		return
+	}
+	saved, err := m.s.db.GetPost(m.s.ctx, post.ID)
+	if err != nil {
+		m.status = fmt.Sprintf("couldn't get post: %v", err)
+		return
+	}
+	var failures []string
+	for _, err := range sendSavedPost(m.s.ctx, m.s.cfg, m.user, saved) {
+		failures = append(failures, err.Error())
+	}
+	m.status = strings.Join(failures, "; ")
+}
+
+// render draws all three panes and the status line
+func (m *tuiModel) render() {
+	feedWidth := m.width / 4
+	postWidth := m.width * 3 / 8
+	previewWidth := m.width - feedWidth - postWidth - 2
+	bodyHeight := m.height - 2
+
+	feedLines := make([]string, 0, len(m.feeds))
+	for _, feed := range m.feeds {
+		feedLines = append(feedLines, feed.FeedName)
+	}
+
+	postLines := make([]string, 0, len(m.posts))
+	for _, post := range m.posts {
+		marker := " "
+		if !post.IsRead {
+			marker = "●"
+		}
+		if post.IsSaved {
+			marker += "★"
+		} else {
+			marker += " "
+		}
+		postLines = append(postLines, marker+" "+post.Title)
+	}
+
+	feedCol := paneLines(feedLines, m.feed, m.focus == paneFeeds, feedWidth, bodyHeight)
+	postCol := paneLines(postLines, m.post, m.focus == panePosts, postWidth, bodyHeight)
+	previewCol := m.previewLines(previewWidth, bodyHeight)
+
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	b.WriteString(fitWidth(fmt.Sprintf(" gator — %s", m.user.Name), m.width))
+	b.WriteString("\r\n")
+	for i := 0; i < bodyHeight; i++ {
+		b.WriteString(feedCol[i])
+		b.WriteString("│")
+		b.WriteString(postCol[i])
+		b.WriteString("│")
+		b.WriteString(previewCol[i])
+		b.WriteString("\r\n")
+	}
+
+	help := " tab/h/l: pane  j/k: move  enter: open  r: read  s: save  o: browser  q: quit"
+	if m.status != "" {
+		help = " " + m.status
+	}
+	b.WriteString("\x1b[7m" + fitWidth(help, m.width) + "\x1b[0m")
+
+	fmt.Print(b.String())
+}
+
+// previewLines renders the selected post, word wrapped and scrolled
+func (m *tuiModel) previewLines(width, height int) []string {
+	var text []string
+	if post, ok := m.selectedPost(); ok {
+		text = append(text, wrapText(post.Title, width)...)
+		text = append(text, fitWidth(post.Url, width))
+		if post.PublishedAt.Valid {
+			text = append(text, post.PublishedAt.Time.Format("2006-01-02 15:04"))
+		}
+		text = append(text, "")
+		// Prefer extracted article text, keeping its paragraph breaks
+		body := ""
+		if post.Content.Valid {
+			body = post.Content.String
+		} else if post.Description.Valid {
+			body = htmltext.Render(post.Description.String)
+		}
+		if body != "" {
+			for _, line := range strings.Split(body, "\n") {
+				if line == "" {
+					text = append(text, "")
+					continue
+				}
+				text = append(text, wrapText(line, width)...)
+			}
+		}
+	}
+
+	m.scroll = clamp(m.scroll, 0, max(len(text)-height, 0))
+	lines := make([]string, height)
+	for i := range lines {
+		line := ""
+		if m.scroll+i < len(text) {
+			line = text[m.scroll+i]
+		}
+		lines[i] = fitWidth(line, width)
+	}
+	return lines
+}
+
+// paneLines renders a scrolling list with the selected row highlighted
+func paneLines(items []string, selected int, focused bool, width, height int) []string {
+	offset := 0
+	if selected >= height {
+		offset = selected - height + 1
+	}
+
+	lines := make([]string, height)
+	for i := range lines {
+		idx := offset + i
+		if idx >= len(items) {
+			lines[i] = strings.Repeat(" ", width)
+			continue
+		}
+
+		line := fitWidth(" "+items[idx], width)
+		if idx == selected {
+			if focused {
+				line = "\x1b[7m" + line + "\x1b[0m"
+			} else {
+				line = "\x1b[1m" + line + "\x1b[0m"
+			}
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+// wrapText breaks s into lines no wider than width
+func wrapText(s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if line != "" && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fitWidth truncates or pads s to exactly width runes
+func fitWidth(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+func clamp(v, lo, hi int) int {
+	if hi < lo {
+		return lo
+	}
+	return min(max(v, lo), hi)
+}
+
+// readKey reads one keypress, decoding the escape sequences for arrows and shift-tab
+func readKey(in *bufio.Reader) (string, error) {
+	b, err := in.ReadByte()
+	if err != nil {
+		return "", err
+	}
+
+	switch b {
+	case 3:
+		return "ctrl-c", nil
+	case '\t':
+		return "tab", nil
+	case '\r', '\n':
+		return "enter", nil
+	case 0x1b:
+		if in.Buffered() < 2 {
+			return "esc", nil
+		}
+		seq := make([]byte, 2)
+		if _, err := in.Read(seq); err != nil {
+			return "", err
+		}
+		switch string(seq) {
+		case "[A":
+			return "up", nil
+		case "[B":
+			return "down", nil
+		case "[C":
+			return "right", nil
+		case "[D":
+			return "left", nil
+		case "[Z":
+			return "shift-tab", nil
+		}
+		return "esc", nil
+	}
+
+	return string(b), nil
+}
+
+// terminalSize returns the terminal's columns and rows, defaulting to 80x24
+func terminalSize() (int, int) {
+	cols, rows, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || rows < 5 || cols < 20 {
+		return 80, 24
+	}
+	return cols, rows
+}
+
+// openBrowser opens url with the platform's default handler
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,7 @@
+package main
+
+import "os"
+
+// notifyResize does nothing on Windows, which has no resize signal; the TUI keeps the size it
+// started with
+func notifyResize(c chan<- os.Signal) {}
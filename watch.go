@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// newPostsChannel is the LISTEN/NOTIFY channel saving posts announces each new post's ID on
+const newPostsChannel = "gator_new_posts"
+
+// postReadsChannel is the LISTEN/NOTIFY channel a trigger on post_reads announces the ID of each
+// user whose posts were marked read or unread on
+const postReadsChannel = "gator_post_reads"
+
+// watchBatchDelay is how long a watcher waits after hearing of a new post for more to arrive, so
+// a feed's new posts are looked up and shown together
+const watchBatchDelay = 250 * time.Millisecond
+
+// sseKeepAlive is how often a stream with nothing to send writes a comment, so proxies don't
+// close it as idle
+const sseKeepAlive = 30 * time.Second
+
+// listenDatabase opens a connection that LISTENs on channels. It reconnects by itself when the
+// connection drops, sending nil on Notify when it's back.
+func listenDatabase(s *state, channels ...string) (*pq.Listener, error) {
+	listener := pq.NewListener(s.cfg.DbURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.Warn("database listener", "channels", channels, "error", err)
+		}
+	})
+	for _, channel := range channels {
+		if err := listener.Listen(channel); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("couldn't listen for %s: %w", channel, err)
+		}
+	}
+	return listener, nil
+}
+
+// hubEvent is one notification passed on by a postHub: the ID of a new post on newPostsChannel,
+// or of a user whose read posts changed on postReadsChannel
+type hubEvent struct {
+	channel string
+	id      uuid.UUID
+}
+
+// postHub passes the events heard on one LISTEN connection to every subscriber
+type postHub struct {
+	mu   sync.Mutex
+	subs map[chan hubEvent]struct{}
+}
+
+// newPostHub starts passing on the events listener hears of, until it's closed
+func newPostHub(listener *pq.Listener) *postHub {
+	h := &postHub{subs: map[chan hubEvent]struct{}{}}
+	go func() {
+		for n := range listener.Notify {
+			// nil means the connection was re-established; events meanwhile are missed
+			if n == nil {
+				continue
+			}
+			id, err := uuid.Parse(n.Extra)
+			if err != nil {
+				continue
+			}
+			h.publish(hubEvent{channel: n.Channel, id: id})
+		}
+	}()
+	return h
+}
+
+// subscribe returns a channel of events, and a function that stops them
+func (h *postHub) subscribe() (<-chan hubEvent, func()) {
+	ch := make(chan hubEvent, 256)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs, ch)
+		h.mu.Unlock()
+	}
+}
+
+// publish passes event to every subscriber. One that has fallen far behind misses it rather
+// than holding up the rest.
+func (h *postHub) publish(event hubEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// nextEvents waits for an event, then gathers any more that arrive within watchBatchDelay. It
+// returns nil when ctx is done, or on a tick of keepAlive, which may be nil.
+func nextEvents(ctx context.Context, events <-chan hubEvent, keepAlive <-chan time.Time) []hubEvent {
+	var batch []hubEvent
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-keepAlive:
+		return nil
+	case event := <-events:
+		batch = append(batch, event)
+	}
+
+	timer := time.NewTimer(watchBatchDelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return batch
+		case <-timer.C:
+			return batch
+		case event := <-events:
+			batch = append(batch, event)
+		}
+	}
+}
+
+// newPostIDs picks the new posts out of a batch of events
+func newPostIDs(batch []hubEvent) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, event := range batch {
+		if event.channel == newPostsChannel {
+			ids = append(ids, event.id)
+		}
+	}
+	return ids
+}
+
+// newPostsForUser looks up the posts among ids that come from feeds userID follows, leaving out
+// those their filters hide
+func newPostsForUser(ctx context.Context, db *database.Queries, userID uuid.UUID, ids []uuid.UUID) ([]database.GetNewPostsForUserRow, error) {
+	rows, err := db.GetNewPostsForUser(ctx, database.GetNewPostsForUserParams{PostIds: ids, UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get new posts: %w", err)
+	}
+	filters, err := loadFilters(ctx, db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := rows[:0]
+	for _, row := range rows {
+		if !filters.hides(row.FeedID, row.Title, row.Description.String) {
+			kept = append(kept, row)
+		}
+	}
+	return kept, nil
+}
+
+// postFromNewRow is the post part of a GetNewPostsForUser row
+func postFromNewRow(row database.GetNewPostsForUserRow) database.Post {
+	return database.Post{
+		ID:                   row.ID,
+		CreatedAt:            row.CreatedAt,
+		UpdatedAt:            row.UpdatedAt,
+		Title:                row.Title,
+		Url:                  row.Url,
+		Description:          row.Description,
+		PublishedAt:          row.PublishedAt,
+		FeedID:               row.FeedID,
+		FeverID:              row.FeverID,
+		SearchVector:         row.SearchVector,
+		EnclosureUrl:         row.EnclosureUrl,
+		EnclosureType:        row.EnclosureType,
+		EnclosureLength:      row.EnclosureLength,
+		DurationSeconds:      row.DurationSeconds,
+		Content:              row.Content,
+		PublishedAtEstimated: row.PublishedAtEstimated,
+		CanonicalUrl:         row.CanonicalUrl,
+		ContentHash:          row.ContentHash,
+		Author:               row.Author,
+		Guid:                 row.Guid,
+		WordCount:            row.WordCount,
+	}
+}
+
+// handlerWatch shows new posts from the feeds the current user follows as they're saved, until
+// interrupted. Posts are saved by agg, fetch or a worker, which may run anywhere.
+func handlerWatch(s *state, cmd command, user database.User) error {
+	flags := newFlagSet("watch")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected watch argument %q", args[0])
+	}
+
+	listener, err := listenDatabase(s, newPostsChannel)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	events, unsubscribe := newPostHub(listener).subscribe()
+	defer unsubscribe()
+
+	if err := s.emit(messageResult{Message: "Watching for new posts"}); err != nil {
+		return err
+	}
+
+	for {
+		batch := nextEvents(s.ctx, events, nil)
+		if s.ctx.Err() != nil {
+			return nil
+		}
+
+		rows, err := newPostsForUser(s.ctx, s.db, user.ID, newPostIDs(batch))
+		if err != nil {
+			slog.Warn("couldn't show new posts", "error", err)
+			continue
+		}
+		for _, row := range rows {
+			err := s.emit(watchedPost{Feed: row.FeedName, Post: toAPIPost(postFromNewRow(row))})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watchedPost is one new post shown by watch
+type watchedPost struct {
+	Feed string  `json:"feed"`
+	Post apiPost `json:"post"`
+}
+
+func (r watchedPost) writeText(w io.Writer) {
+	fmt.Fprintf(w, "[%s] %s: %s\n", r.Post.CreatedAt.Local().Format("15:04"), r.Feed, htmltext.RenderInline(r.Post.Title))
+	fmt.Fprintf(w, "  %s\n", r.Post.Url)
+}
+
+func (r watchedPost) table() ([]string, [][]string) {
+	published := ""
+	if r.Post.PublishedAt != nil {
+		published = r.Post.PublishedAt.Format(time.RFC3339)
+	}
+	return []string{"id", "feed", "title", "url", "published"}, [][]string{{
+		r.Post.ID.String(), r.Feed, r.Post.Title, r.Post.Url, published,
+	}}
+}
+
+// unreadCounts is the data of a stream's "unread" event: the user's unread posts in all and by
+// feed. Feeds left out have none.
+type unreadCounts struct {
+	Unread int64             `json:"unread"`
+	Feeds  []feedUnreadCount `json:"feeds"`
+}
+
+type feedUnreadCount struct {
+	FeedID      uuid.UUID `json:"feed_id"`
+	UnreadCount int64     `json:"unread_count"`
+}
+
+// getUnreadCounts counts the user's unread posts, as the feed follows listing does
+func getUnreadCounts(ctx context.Context, db *database.Queries, userID uuid.UUID) (unreadCounts, error) {
+	rows, err := db.GetUnreadCountsForUser(ctx, userID)
+	if err != nil {
+		return unreadCounts{}, fmt.Errorf("couldn't count unread posts: %w", err)
+	}
+	counts := unreadCounts{Feeds: make([]feedUnreadCount, 0, len(rows))}
+	for _, row := range rows {
+		counts.Unread += row.UnreadCount
+		counts.Feeds = append(counts.Feeds, feedUnreadCount{FeedID: row.FeedID, UnreadCount: row.UnreadCount})
+	}
+	slices.SortFunc(counts.Feeds, func(a, b feedUnreadCount) int {
+		return strings.Compare(a.FeedID.String(), b.FeedID.String())
+	})
+	return counts, nil
+}
+
+// handleStream streams the user's new posts and unread counts as server-sent events until the
+// client goes away: a "post" event with each new post as JSON, and an "unread" event with their
+// unreadCounts when the stream opens and whenever they change, whether through new posts or
+// posts being marked read or unread anywhere
+func (api *apiServer) handleStream(w http.ResponseWriter, r *http.Request, user database.User) {
+	flusher, ok := w.(http.Flusher)
+	if !ok || api.posts == nil || api.closing == nil {
+		respondWithError(w, http.StatusNotImplemented, "streaming isn't available")
+		return
+	}
+
+	events, unsubscribe := api.posts.subscribe()
+	defer unsubscribe()
+
+	counts, err := getUnreadCounts(r.Context(), api.db, user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "couldn't count unread posts")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	writeEvent(w, "unread", "", counts)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	defer context.AfterFunc(api.closing, cancel)()
+	for ctx.Err() == nil {
+		batch := nextEvents(ctx, events, keepAlive.C)
+		if len(batch) == 0 {
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+			continue
+		}
+
+		if err := api.streamBatch(ctx, w, user, batch, &counts); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				slog.Warn("couldn't stream events", "user", user.Name, "error", err)
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// streamBatch writes the events a batch of notifications makes for user: their new posts, then
+// their unread counts if those differ from counts, which it updates
+func (api *apiServer) streamBatch(ctx context.Context, w io.Writer, user database.User, batch []hubEvent, counts *unreadCounts) error {
+	ids := newPostIDs(batch)
+	readsChanged := slices.ContainsFunc(batch, func(event hubEvent) bool {
+		return event.channel == postReadsChannel && event.id == user.ID
+	})
+	if len(ids) == 0 && !readsChanged {
+		return nil
+	}
+
+	if len(ids) > 0 {
+		rows, err := newPostsForUser(ctx, api.db, user.ID, ids)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			writeEvent(w, "post", row.ID.String(), toAPIPost(postFromNewRow(row)))
+		}
+	}
+
+	// Posts from feeds the user doesn't follow leave the counts as they were, so only changes are sent
+	latest, err := getUnreadCounts(ctx, api.db, user.ID)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(latest.Feeds, counts.Feeds) {
+		writeEvent(w, "unread", "", latest)
+		*counts = latest
+	}
+	return nil
+}
+
+// writeEvent writes one server-sent event with data as JSON, and an id when it isn't empty
+func writeEvent(w io.Writer, event, id string, data any) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\n", event)
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "data: %s\n\n", body)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// webFiles is the browser UI served by 'gator serve'. It's a static page that signs in with an
+// API key or token and then uses the JSON API like any other client.
+//
+//go:embed web
+var webFiles embed.FS
+
+// webContentSecurityPolicy keeps scripts to the UI's own, so HTML from a feed's description can't
+// run any; images and media may come from anywhere, as feeds link them
+const webContentSecurityPolicy = "default-src 'self'; img-src * data:; media-src *; style-src 'self'; frame-ancestors 'none'"
+
+// webHandler serves the UI's page at / and its scripts and styles under /static/
+func webHandler() http.Handler {
+	root, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err)
+	}
+	static := http.FileServerFS(root)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", webContentSecurityPolicy)
+		switch {
+		case r.URL.Path == "/":
+			http.ServeFileFS(w, r, root, "index.html")
+		case strings.HasSuffix(r.URL.Path, "/"):
+			http.NotFound(w, r)
+		default:
+			static.ServeHTTP(w, r)
+		}
+	})
+}
@@ -0,0 +1,320 @@
+"use strict";
+
+// The web UI talks to the same JSON API as any other client. The key is kept in localStorage
+// along with the Authorization scheme that accepted it: ApiKey for a user's own key, Bearer for a
+// token from 'gator apikey create'.
+
+const state = {
+  auth: JSON.parse(localStorage.getItem("gator.auth") || "null"),
+  feeds: [],
+  view: null,
+  posts: [],
+  post: null,
+  stream: null,
+};
+
+const $ = (id) => document.getElementById(id);
+
+async function api(method, path) {
+  const resp = await fetch(path, {
+    method,
+    headers: { Authorization: state.auth.scheme + " " + state.auth.key },
+  });
+  if (resp.status === 401) {
+    signOut();
+    throw new Error("signed out");
+  }
+  if (!resp.ok) {
+    const body = await resp.json().catch(() => ({}));
+    throw new Error(body.error || resp.statusText);
+  }
+  return resp.status === 204 ? null : resp.json();
+}
+
+function el(tag, props, ...children) {
+  const node = document.createElement(tag);
+  Object.assign(node, props);
+  node.append(...children.filter((c) => c !== null && c !== undefined));
+  return node;
+}
+
+function formatDate(iso) {
+  return iso ? new Date(iso).toLocaleString() : "";
+}
+
+function showError(err) {
+  if (err.message !== "signed out") {
+    alert(err.message);
+  }
+}
+
+async function signIn(key) {
+  for (const scheme of ["ApiKey", "Bearer"]) {
+    const resp = await fetch("/api/users/me", { headers: { Authorization: scheme + " " + key } });
+    if (resp.ok) {
+      state.auth = { scheme, key };
+      localStorage.setItem("gator.auth", JSON.stringify(state.auth));
+      return resp.json();
+    }
+  }
+  throw new Error("That key wasn't accepted");
+}
+
+function signOut() {
+  if (state.stream) {
+    state.stream.abort();
+    state.stream = null;
+  }
+  state.auth = null;
+  localStorage.removeItem("gator.auth");
+  $("app").hidden = true;
+  $("login").hidden = false;
+}
+
+async function start(user) {
+  $("login").hidden = true;
+  $("app").hidden = false;
+  $("user").textContent = user.name;
+  await loadFeeds();
+  await openView({ kind: "unread" });
+  watchPosts();
+}
+
+// watchPosts follows /api/stream, adding new posts to the lists and updating unread counts as
+// they change, here or in any other client. EventSource
+// can't send an Authorization header, so the stream is read through fetch; it reconnects after a
+// break until the user signs out.
+async function watchPosts() {
+  const controller = new AbortController();
+  state.stream = controller;
+  while (!controller.signal.aborted) {
+    try {
+      const resp = await fetch("/api/stream", {
+        headers: { Authorization: state.auth.scheme + " " + state.auth.key },
+        signal: controller.signal,
+      });
+      if (resp.status === 401) {
+        signOut();
+        return;
+      }
+      if (!resp.ok) {
+        throw new Error(resp.statusText);
+      }
+
+      const reader = resp.body.pipeThrough(new TextDecoderStream()).getReader();
+      let buffered = "";
+      for (;;) {
+        const { value, done } = await reader.read();
+        if (done) {
+          break;
+        }
+        buffered += value;
+        let end;
+        while ((end = buffered.indexOf("\n\n")) >= 0) {
+          handleStreamEvent(buffered.slice(0, end));
+          buffered = buffered.slice(end + 2);
+        }
+      }
+    } catch (err) {
+      if (controller.signal.aborted) {
+        return;
+      }
+    }
+    await new Promise((resolve) => setTimeout(resolve, 5000));
+  }
+}
+
+// handleStreamEvent takes in one server-sent event: a new post, or the user's unread counts
+function handleStreamEvent(text) {
+  let event = "message";
+  let data = "";
+  for (const line of text.split("\n")) {
+    if (line.startsWith("event: ")) {
+      event = line.slice(7);
+    } else if (line.startsWith("data: ")) {
+      data += line.slice(6);
+    }
+  }
+  if (!data) {
+    return;
+  }
+
+  if (event === "unread") {
+    const counts = new Map(JSON.parse(data).feeds.map((f) => [f.feed_id, f.unread_count]));
+    for (const feed of state.feeds) {
+      feed.unread_count = counts.get(feed.feed_id) || 0;
+    }
+    renderFeeds();
+    return;
+  }
+  if (event !== "post") {
+    return;
+  }
+
+  const post = { ...JSON.parse(data), read: false };
+  const view = state.view;
+  if (view && (view.kind === "unread" || (view.kind === "feed" && view.id === post.feed_id))) {
+    if (!state.posts.some((p) => p.id === post.id)) {
+      state.posts.unshift(post);
+      renderPosts();
+    }
+  }
+}
+
+async function loadFeeds() {
+  state.feeds = await api("GET", "/api/feed_follows");
+  state.feeds.sort((a, b) => a.feed_name.localeCompare(b.feed_name));
+  renderFeeds();
+}
+
+function feedName(feedID) {
+  const feed = state.feeds.find((f) => f.feed_id === feedID);
+  return feed ? feed.feed_name : "";
+}
+
+function renderFeeds() {
+  const total = state.feeds.reduce((sum, f) => sum + f.unread_count, 0);
+  const entries = [
+    { view: { kind: "unread" }, label: "Unread", count: total },
+    { view: { kind: "saved" }, label: "Saved" },
+    ...state.feeds.map((f) => ({ view: { kind: "feed", id: f.feed_id }, label: f.feed_name, count: f.unread_count })),
+  ];
+
+  $("feed-list").replaceChildren(
+    ...entries.map(({ view, label, count }) => {
+      const link = el(
+        "a",
+        { href: "#", className: sameView(view, state.view) ? "selected" : "" },
+        el("span", { textContent: label }),
+        count ? el("span", { className: "count", textContent: count }) : null,
+      );
+      link.addEventListener("click", (e) => {
+        e.preventDefault();
+        openView(view).catch(showError);
+      });
+      return el("li", {}, link);
+    }),
+  );
+}
+
+function sameView(a, b) {
+  return b !== null && a.kind === b.kind && a.id === b.id;
+}
+
+async function openView(view) {
+  state.view = view;
+  let title;
+  if (view.kind === "unread") {
+    title = "Unread";
+    state.posts = (await api("GET", "/api/posts?limit=100")).map((p) => ({ ...p, read: false }));
+  } else if (view.kind === "saved") {
+    title = "Saved";
+    state.posts = (await api("GET", "/api/posts/saved")).map((p) => ({ ...p, saved: true }));
+  } else {
+    title = feedName(view.id);
+    state.posts = await api("GET", "/api/feeds/" + view.id + "/posts");
+  }
+  $("posts-title").textContent = title;
+  renderFeeds();
+  renderPosts();
+}
+
+function renderPosts() {
+  if (state.posts.length === 0) {
+    $("post-list").replaceChildren(el("li", { className: "empty", textContent: "No posts" }));
+    return;
+  }
+  $("post-list").replaceChildren(
+    ...state.posts.map((post) => {
+      const link = el(
+        "a",
+        { href: "#", className: state.post && state.post.id === post.id ? "selected" : "" },
+        el("span", { className: "title", textContent: post.title }),
+        el("span", { className: "meta", textContent: [feedName(post.feed_id), formatDate(post.published_at)].filter(Boolean).join(" · ") }),
+      );
+      link.addEventListener("click", (e) => {
+        e.preventDefault();
+        openPost(post.id).catch(showError);
+      });
+      return el("li", { className: post.read ? "" : "unread" }, link);
+    }),
+  );
+}
+
+async function openPost(id) {
+  state.post = await api("GET", "/api/posts/" + id);
+  if (!state.post.read) {
+    await setRead(true).catch(showError);
+  }
+  renderPosts();
+  renderPost();
+}
+
+// setRead marks the open post read or unread and keeps the lists' counts in step
+async function setRead(read) {
+  const post = state.post;
+  await api(read ? "POST" : "DELETE", "/api/posts/" + post.id + "/read");
+  post.read = read;
+  for (const listed of state.posts) {
+    if (listed.id === post.id) {
+      listed.read = read;
+    }
+  }
+  const feed = state.feeds.find((f) => f.feed_id === post.feed_id);
+  if (feed) {
+    feed.unread_count = Math.max(0, feed.unread_count + (read ? -1 : 1));
+  }
+  renderFeeds();
+}
+
+async function setSaved(saved) {
+  await api(saved ? "POST" : "DELETE", "/api/posts/" + state.post.id + "/save");
+  state.post.saved = saved;
+}
+
+function renderPost() {
+  const post = state.post;
+  const readButton = el("button", { type: "button", textContent: post.read ? "Mark unread" : "Mark read" });
+  readButton.addEventListener("click", () => setRead(!post.read).then(renderAll, showError));
+  const saveButton = el("button", { type: "button", textContent: post.saved ? "Unsave" : "Save" });
+  saveButton.addEventListener("click", () => setSaved(!post.saved).then(renderAll, showError));
+
+  // Descriptions are sanitized when gator saves them; extracted content is plain text
+  let body;
+  if (post.content) {
+    body = el("div", { className: "body text", textContent: post.content });
+  } else {
+    body = el("div", { className: "body" });
+    body.innerHTML = post.description || "";
+  }
+
+  $("post").replaceChildren(
+    el("h1", {}, el("a", { href: post.url, target: "_blank", rel: "noopener", textContent: post.title })),
+    el("p", { className: "meta", textContent: [post.feed_name, formatDate(post.published_at), post.tags.join(", ")].filter(Boolean).join(" · ") }),
+    el("div", { className: "actions" }, readButton, saveButton),
+    body,
+  );
+}
+
+function renderAll() {
+  renderPosts();
+  renderPost();
+}
+
+$("login").addEventListener("submit", (e) => {
+  e.preventDefault();
+  $("login-error").textContent = "";
+  signIn($("key").value.trim())
+    .then(start)
+    .catch((err) => {
+      $("login-error").textContent = err.message;
+    });
+});
+
+$("logout").addEventListener("click", signOut);
+
+if (state.auth) {
+  api("GET", "/api/users/me").then(start, signOut);
+} else {
+  signOut();
+}
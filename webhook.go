@@ -0,0 +1,467 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+	"github.com/Utkarsh736/gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+// Webhook delivery makes up to webhookAttempts tries, doubling the wait from webhookBackoff
+const (
+	webhookAttempts = 4
+	webhookBackoff  = time.Second
+)
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// Deliveries wait in a queue of webhookQueueSize for one of webhookSenders to send them, so a
+// slow or dead endpoint holds up other deliveries rather than fetching. On exit, queued
+// deliveries get webhookDrainTimeout to finish.
+const (
+	webhookQueueSize    = 1000
+	webhookSenders      = 4
+	webhookDrainTimeout = 30 * time.Second
+)
+
+// Webhook kinds: json posts webhookPayload, slack and discord post incoming-webhook messages
+const (
+	webhookKindJSON    = "json"
+	webhookKindSlack   = "slack"
+	webhookKindDiscord = "discord"
+)
+
+// webhookSummaryLength caps the post summary shown in Slack and Discord messages
+const webhookSummaryLength = 300
+
+// webhookPayload is the JSON body POSTed for each new post
+type webhookPayload struct {
+	Event string  `json:"event"`
+	Feed  apiFeed `json:"feed"`
+	Post  apiPost `json:"post"`
+}
+
+// slackPayload is a Slack incoming-webhook message with one attachment per post
+type slackPayload struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Fallback   string `json:"fallback"`
+	Color      string `json:"color"`
+	AuthorName string `json:"author_name"`
+	Title      string `json:"title"`
+	TitleLink  string `json:"title_link"`
+	Text       string `json:"text,omitempty"`
+	Ts         int64  `json:"ts,omitempty"`
+}
+
+// discordPayload is a Discord webhook message with one embed per post
+type discordPayload struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string        `json:"title"`
+	URL         string        `json:"url"`
+	Description string        `json:"description,omitempty"`
+	Timestamp   string        `json:"timestamp,omitempty"`
+	Author      discordAuthor `json:"author"`
+}
+
+type discordAuthor struct {
+	Name string `json:"name"`
+}
+
+// handlerWebhook manages webhooks: webhook add|list|remove
+func handlerWebhook(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("webhook command requires a subcommand: add, list, remove")
+	}
+
+	sub := command{name: cmd.args[0], args: cmd.args[1:]}
+	switch sub.name {
+	case "add":
+		return handlerWebhookAdd(s, sub, user)
+	case "list":
+		return handlerWebhookList(s, sub, user)
+	case "remove":
+		return handlerWebhookRemove(s, sub, user)
+	default:
+		return fmt.Errorf("unknown webhook subcommand: %s", sub.name)
+	}
+}
+
+// handlerWebhookAdd registers a webhook for new posts, optionally limited to one feed or category
+func handlerWebhookAdd(s *state, cmd command, user database.User) error {
+	var feedURL, categoryName, kind string
+	flags := newFlagSet("webhook add")
+	flags.StringVar(&kind, "type", webhookKindJSON, "Payload format: `json|slack|discord`")
+	flags.StringVar(&feedURL, "feed", "", "Only send posts from the feed at `feed_url`")
+	flags.StringVar(&categoryName, "category", "", "Only send posts from feeds in this category `name`")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return errors.New("webhook add requires a URL argument")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected webhook add argument %q", args[1])
+	}
+	hookURL := args[0]
+	if u, err := url.Parse(hookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q: must be an http or https URL", hookURL)
+	}
+	if kind != webhookKindJSON && kind != webhookKindSlack && kind != webhookKindDiscord {
+		return fmt.Errorf("unknown webhook type %q: expected json, slack, or discord", kind)
+	}
+	if feedURL != "" && categoryName != "" {
+		return errors.New("a webhook can be limited to a feed or a category, not both")
+	}
+
+	var feedID uuid.NullUUID
+	if feedURL != "" {
+		feed, err := getFeedByURL(s.ctx, s.db, feedURL)
+		if err != nil {
+			return fmt.Errorf("couldn't find feed: %w", err)
+		}
+		feedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+
+	var categoryID uuid.NullUUID
+	if categoryName != "" {
+		category, err := getCategory(s, user, categoryName)
+		if err != nil {
+			return err
+		}
+		categoryID = uuid.NullUUID{UUID: category.ID, Valid: true}
+	}
+
+	webhook, err := s.db.CreateWebhook(s.ctx, database.CreateWebhookParams{
+		ID:         uuid.New(),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+		UserID:     user.ID,
+		Url:        hookURL,
+		FeedID:     feedID,
+		Kind:       kind,
+		CategoryID: categoryID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create webhook: %w", err)
+	}
+
+	return s.emit(messageResult{
+		Message: fmt.Sprintf("Webhook created: %s", webhook.ID),
+		Item: webhookEntry{
+			ID:       webhook.ID,
+			Url:      webhook.Url,
+			Type:     webhook.Kind,
+			FeedUrl:  feedURL,
+			Category: categoryName,
+		},
+	})
+}
+
+// handlerWebhookList lists the current user's webhooks
+func handlerWebhookList(s *state, cmd command, user database.User) error {
+	webhooks, err := s.db.GetWebhooksForUser(s.ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get webhooks: %w", err)
+	}
+
+	res := webhooksResult{Webhooks: []webhookEntry{}}
+	for _, webhook := range webhooks {
+		res.Webhooks = append(res.Webhooks, webhookEntry{
+			ID:       webhook.ID,
+			Url:      webhook.Url,
+			Type:     webhook.Kind,
+			FeedUrl:  webhook.FeedUrl.String,
+			Category: webhook.CategoryName.String,
+		})
+	}
+
+	return s.emit(res)
+}
+
+// handlerWebhookRemove deletes one of the current user's webhooks by ID
+func handlerWebhookRemove(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return errors.New("webhook remove requires an ID argument")
+	}
+
+	id, err := uuid.Parse(cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("invalid webhook ID %q", cmd.args[0])
+	}
+
+	n, err := s.db.DeleteWebhook(s.ctx, database.DeleteWebhookParams{
+		ID:     id,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't delete webhook: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("webhook %s doesn't exist", id)
+	}
+
+	return s.emit(messageResult{Message: fmt.Sprintf("Webhook removed: %s", id)})
+}
+
+// webhookEntry is a webhook in the webhook listing; FeedUrl and Category are empty for all-feed webhooks
+type webhookEntry struct {
+	ID       uuid.UUID `json:"id"`
+	Url      string    `json:"url"`
+	Type     string    `json:"type"`
+	FeedUrl  string    `json:"feed_url,omitempty"`
+	Category string    `json:"category,omitempty"`
+}
+
+// webhooksResult is the output of webhook list
+type webhooksResult struct {
+	Webhooks []webhookEntry `json:"webhooks"`
+}
+
+func (r webhooksResult) writeText(w io.Writer) {
+	if len(r.Webhooks) == 0 {
+		fmt.Fprintln(w, "No webhooks found")
+		return
+	}
+
+	for _, webhook := range r.Webhooks {
+		fmt.Fprintf(w, "* %s\n", webhook.ID)
+		fmt.Fprintf(w, "  URL: %s\n", webhook.Url)
+		fmt.Fprintf(w, "  Type: %s\n", webhook.Type)
+		switch {
+		case webhook.FeedUrl != "":
+			fmt.Fprintf(w, "  Feed: %s\n", webhook.FeedUrl)
+		case webhook.Category != "":
+			fmt.Fprintf(w, "  Category: %s\n", webhook.Category)
+		default:
+			fmt.Fprintln(w, "  Feed: all followed feeds")
+		}
+	}
+}
+
+func (r webhooksResult) table() ([]string, [][]string) {
+	rows := [][]string{}
+	for _, webhook := range r.Webhooks {
+		rows = append(rows, []string{webhook.ID.String(), webhook.Url, webhook.Type, webhook.FeedUrl, webhook.Category})
+	}
+	return []string{"id", "url", "type", "feed", "category"}, rows
+}
+
+// deliverWebhooks queues each new post from a scrape for every matching webhook. Deliveries are
+// sent in the background and failures logged, so one bad endpoint can't stall aggregation.
+func deliverWebhooks(s *state, res scrapeResult) error {
+	if res.Feed == nil || len(res.NewPosts) == 0 {
+		return nil
+	}
+
+	webhooks, err := s.db.GetWebhooksForFeed(s.ctx, res.Feed.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get webhooks: %w", err)
+	}
+
+	// Each webhook only gets the posts its owner's filters let through
+	filters := map[uuid.UUID]postFilters{}
+	for _, webhook := range webhooks {
+		if _, ok := filters[webhook.UserID]; !ok {
+			userFilters, err := loadFilters(s.ctx, s.db, webhook.UserID)
+			if err != nil {
+				return err
+			}
+			filters[webhook.UserID] = userFilters
+		}
+
+		for _, post := range filters[webhook.UserID].apiPosts(res.NewPosts) {
+			body, err := webhookBody(webhook.Kind, *res.Feed, post)
+			if err != nil {
+				return fmt.Errorf("couldn't encode webhook payload: %w", err)
+			}
+			s.webhooks.send(webhookDelivery{webhookID: webhook.ID, url: webhook.Url, post: post.Title, body: body})
+		}
+	}
+
+	return nil
+}
+
+// webhookBody encodes a new post in the format expected by a webhook of the given kind
+func webhookBody(kind string, feed apiFeed, post apiPost) ([]byte, error) {
+	summary := ""
+	if post.Description != nil {
+		summary = truncateText(htmltext.RenderInline(*post.Description), webhookSummaryLength)
+	}
+
+	switch kind {
+	case webhookKindSlack:
+		attachment := slackAttachment{
+			Fallback:   fmt.Sprintf("%s: %s", post.Title, post.Url),
+			Color:      "#2eb67d",
+			AuthorName: feed.Name,
+			Title:      post.Title,
+			TitleLink:  post.Url,
+			Text:       summary,
+		}
+		if post.PublishedAt != nil {
+			attachment.Ts = post.PublishedAt.Unix()
+		}
+		return json.Marshal(slackPayload{
+			Text:        fmt.Sprintf("New post in %s", feed.Name),
+			Attachments: []slackAttachment{attachment},
+		})
+	case webhookKindDiscord:
+		embed := discordEmbed{
+			Title:       truncateText(post.Title, 256),
+			URL:         post.Url,
+			Description: summary,
+			Author:      discordAuthor{Name: feed.Name},
+		}
+		if post.PublishedAt != nil {
+			embed.Timestamp = post.PublishedAt.Format(time.RFC3339)
+		}
+		return json.Marshal(discordPayload{Embeds: []discordEmbed{embed}})
+	default:
+		return json.Marshal(webhookPayload{Event: "post.created", Feed: feed, Post: post})
+	}
+}
+
+// truncateText shortens s to at most n runes, marking the cut with an ellipsis
+func truncateText(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
+
+// webhookDelivery is one post on its way to one webhook
+type webhookDelivery struct {
+	webhookID uuid.UUID
+	url       string
+	post      string
+	body      []byte
+}
+
+// webhookSender sends queued webhook deliveries in the background
+type webhookSender struct {
+	queue  chan webhookDelivery
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// startWebhookSender starts webhookSenders goroutines sending deliveries until close
+func startWebhookSender() *webhookSender {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &webhookSender{queue: make(chan webhookDelivery, webhookQueueSize), ctx: ctx, cancel: cancel}
+	for range webhookSenders {
+		w.wg.Go(func() {
+			for d := range w.queue {
+				// After the drain timeout, what's left is dropped
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := postWebhook(ctx, d.url, d.body); err != nil {
+					slog.Warn("webhook delivery failed", "webhook", d.webhookID, "post", d.post, "error", err)
+				}
+			}
+		})
+	}
+	return w
+}
+
+// send queues d. When the queue is full the delivery is dropped rather than waiting, since
+// waiting would hold up fetching. A nil sender sends d straight away.
+func (w *webhookSender) send(d webhookDelivery) {
+	if w == nil {
+		if err := postWebhook(context.Background(), d.url, d.body); err != nil {
+			slog.Warn("webhook delivery failed", "webhook", d.webhookID, "post", d.post, "error", err)
+		}
+		return
+	}
+
+	select {
+	case w.queue <- d:
+	default:
+		slog.Warn("webhook queue full; dropping delivery", "webhook", d.webhookID, "post", d.post)
+	}
+}
+
+// close stops taking deliveries and waits up to webhookDrainTimeout for the queued ones to be
+// sent, dropping any still left
+func (w *webhookSender) close() {
+	close(w.queue)
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(webhookDrainTimeout):
+		slog.Warn("gave up waiting for webhook deliveries", "dropped", len(w.queue))
+		w.cancel()
+		<-done
+	}
+	w.cancel()
+}
+
+// postWebhook sends body to endpoint, retrying network errors, 429s and 5xx responses with exponential backoff
+func postWebhook(ctx context.Context, endpoint string, body []byte) error {
+	backoff := webhookBackoff
+	var lastErr error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return lastErr
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "gator")
+
+		resp, err := webhookClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode < 300:
+			return nil
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			lastErr = fmt.Errorf("server returned %s", resp.Status)
+		default:
+			return fmt.Errorf("server returned %s", resp.Status)
+		}
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", webhookAttempts, lastErr)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Utkarsh736/gator/internal/database"
+)
+
+// fetchJobsChannel is the LISTEN/NOTIFY channel agg --coordinator wakes workers on
+const fetchJobsChannel = "gator_fetch_jobs"
+
+// workerPollInterval is how often an idle worker checks the queue without being woken, in case
+// a notification went out while it was reconnecting
+const workerPollInterval = time.Minute
+
+// enqueueFetchJobs queues the due feeds for workers and wakes them
+func enqueueFetchJobs(s *state) error {
+	queued, err := s.db.EnqueueDueFeeds(s.ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't queue due feeds: %w", err)
+	}
+	counts, err := s.db.CountFetchJobs(s.ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't count fetch jobs: %w", err)
+	}
+
+	res := enqueueResult{Queued: queued, Waiting: counts.Queued - counts.Claimed, Fetching: counts.Claimed}
+	if res.Waiting > 0 {
+		if err := s.db.NotifyFetchJobs(s.ctx); err != nil {
+			return fmt.Errorf("couldn't notify workers: %w", err)
+		}
+	}
+	return s.emit(res)
+}
+
+// enqueueResult is the output of one agg --coordinator pass
+type enqueueResult struct {
+	Queued   int64 `json:"queued"`
+	Waiting  int64 `json:"waiting"`
+	Fetching int64 `json:"fetching"`
+}
+
+func (r enqueueResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Queued %d due feeds: %d waiting for a worker, %d being fetched\n", r.Queued, r.Waiting, r.Fetching)
+}
+
+func (r enqueueResult) table() ([]string, [][]string) {
+	return []string{"queued", "waiting", "fetching"}, [][]string{{
+		strconv.FormatInt(r.Queued, 10),
+		strconv.FormatInt(r.Waiting, 10),
+		strconv.FormatInt(r.Fetching, 10),
+	}}
+}
+
+// handlerWorker fetches the feeds agg --coordinator queues, until interrupted. Any number of
+// workers can share a queue, on one machine or many.
+func handlerWorker(s *state, cmd command) error {
+	var metricsAddr string
+	notify := s.cfg.Notify
+	flags := newFlagSet("worker")
+	flags.BoolVar(&notify, "notify", notify, "Send a desktop notification for new posts")
+	flags.BoolFunc("no-notify", "Don't send desktop notifications", negatedBool("no-notify", &notify))
+	flags.BoolVar(&s.cfg.SkipImages, "no-images", s.cfg.SkipImages, "Don't fetch images for posts")
+	flags.BoolVar(&s.cfg.AutoUpdateURLs, "auto-update-urls", s.cfg.AutoUpdateURLs, "Follow permanent redirects to a feed's new URL")
+	flags.StringVar(&metricsAddr, "metrics-addr", s.cfg.MetricsAddr, "Serve Prometheus metrics on `addr`")
+	args, err := flags.parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected worker argument %q", args[0])
+	}
+	if err := guardPrivateAddresses(s, false); err != nil {
+		return err
+	}
+
+	var notifier *postNotifier
+	if notify {
+		user, err := s.db.GetUser(s.ctx, s.cfg.CurrentUserName)
+		if err != nil {
+			return fmt.Errorf("--notify requires a logged-in user: %w", err)
+		}
+		notifier = &postNotifier{user: user}
+	}
+
+	listener, err := listenDatabase(s, fetchJobsChannel)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	if metricsAddr != "" {
+		stopMetrics := serveMetrics(metricsAddr, s.db)
+		defer stopMetrics()
+	}
+
+	if err := s.emit(messageResult{Message: "Waiting for feeds to fetch"}); err != nil {
+		return err
+	}
+
+	s.webhooks = startWebhookSender()
+	defer s.webhooks.close()
+
+	// As with agg, feeds being fetched when the worker is interrupted are finished and announced
+	work := s.withContext(context.WithoutCancel(s.ctx))
+	for {
+		if err := runFetchJobs(s, work, notifier); err != nil {
+			slog.Error("couldn't fetch queued feeds", "error", err)
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return s.emit(messageResult{Message: "Shutting down worker"})
+		case <-listener.Notify:
+		case <-time.After(workerPollInterval):
+		}
+	}
+}
+
+// runFetchJobs claims queued feeds, fetch_concurrency at a time, and fetches them until the
+// queue is empty or s is cancelled. A feed's job is done whether or not its fetch succeeds: a
+// failed feed is rescheduled like any other, and queued again once it's due.
+func runFetchJobs(s, work *state, notifier *postNotifier) error {
+	for s.ctx.Err() == nil {
+		feeds, err := work.db.ClaimFetchJobs(work.ctx, database.ClaimFetchJobsParams{
+			LeaseSeconds: int32(feedClaimLease / time.Second),
+			MaxJobs:      int32(s.cfg.FetchConcurrencyLimit()),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't claim fetch jobs: %w", err)
+		}
+		if len(feeds) == 0 {
+			return nil
+		}
+
+		results, errs := scrapeFeeds(work, feeds)
+		for i, feed := range feeds {
+			if err := work.db.DeleteFetchJob(work.ctx, feed.ID); err != nil {
+				slog.Warn("couldn't finish fetch job", "feed", feed.Name, "error", err)
+			}
+			if errs[i] != nil {
+				slog.Warn("couldn't fetch feed", "feed", feed.Name, "error", errs[i])
+				continue
+			}
+			announceScrape(work, notifier, results[i])
+			if err := work.emit(results[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}